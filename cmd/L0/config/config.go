@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"sync"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 	"github.com/joho/godotenv"
@@ -30,6 +31,53 @@ type Config struct {
 		Client1ID string `long:"nats_client_1_id" description:"Nats client id" env:"NATS_CLIENT_1_ID" required:"true" default:"nats-client-1"`
 		Client2ID string `long:"nats_client_2_id" description:"Nats client id" env:"NATS_CLIENT_2_ID" required:"true" default:"nats-client-2"`
 		Subject   string `long:"nats_subject" description:"Nats subject" env:"NATS_SUBJECT" required:"true" default:"test-subject"`
+
+		DurableName   string        `long:"nats_durable_name" description:"Nats durable subscription name" env:"NATS_DURABLE_NAME" default:"orders-durable"`
+		AckWait       time.Duration `long:"nats_ack_wait" description:"Time to wait for an ack before redelivery" env:"NATS_ACK_WAIT" default:"30s"`
+		MaxInflight   int           `long:"nats_max_inflight" description:"Maximum number of unacknowledged messages" env:"NATS_MAX_INFLIGHT" default:"64"`
+		StartPosition string        `long:"nats_start_position" description:"Start position of a new durable: all, last, new, seq:<n>, since:<duration>" env:"NATS_START_POSITION" default:"all"`
+
+		DeadLetterSubject string `long:"nats_dlq_subject" description:"Nats subject for rejected messages" env:"NATS_DLQ_SUBJECT" default:"orders-dlq"`
+		MaxRedeliveries   int    `long:"nats_max_redeliveries" description:"Redeliveries before an unstorable message is rejected, 0 retries forever" env:"NATS_MAX_REDELIVERIES" default:"10"`
+		UpdatePolicy      string `long:"nats_update_policy" description:"Handling of a changed payload for a stored order: update, reject" env:"NATS_UPDATE_POLICY" choice:"update" choice:"reject" default:"update"`
+
+		StatusSubject     string `long:"nats_status_subject" description:"Nats subject for order status events" env:"NATS_STATUS_SUBJECT" default:"order-status"`
+		StatusDurableName string `long:"nats_status_durable_name" description:"Nats durable subscription name for order status events" env:"NATS_STATUS_DURABLE_NAME" default:"order-status-durable"`
+	}
+
+	Validation struct {
+		Mode string `long:"validation_mode" description:"Order validation mode: strict, lenient" env:"VALIDATION_MODE" choice:"strict" choice:"lenient" default:"strict"`
+	}
+
+	Cache struct {
+		MaxEntries     int           `long:"cache_max_entries" description:"Maximum number of cached orders, 0 is unlimited" env:"CACHE_MAX_ENTRIES" default:"100000"`
+		MaxBytes       int64         `long:"cache_max_bytes" description:"Approximate memory budget of cached orders in bytes, 0 is unlimited" env:"CACHE_MAX_BYTES" default:"268435456"`
+		TTL            time.Duration `long:"cache_ttl" description:"Time an order stays cached, 0 keeps it until it is evicted" env:"CACHE_TTL" default:"0"`
+		MissTTL        time.Duration `long:"cache_miss_ttl" description:"Time a missing order is remembered, 0 disables it" env:"CACHE_MISS_TTL" default:"5s"`
+		Policy         string        `long:"cache_policy" description:"Eviction policy of a full cache: lru, lfu" env:"CACHE_POLICY" choice:"lru" choice:"lfu" default:"lru"`
+		ExpiryInterval time.Duration `long:"cache_expiry_interval" description:"Interval between removals of expired cached orders" env:"CACHE_EXPIRY_INTERVAL" default:"1m"`
+
+		SnapshotPath     string        `long:"cache_snapshot_path" description:"File of the cache snapshot loaded on start, empty disables snapshots" env:"CACHE_SNAPSHOT_PATH"`
+		SnapshotInterval time.Duration `long:"cache_snapshot_interval" description:"Interval between cache snapshots" env:"CACHE_SNAPSHOT_INTERVAL" default:"5m"`
+		SnapshotMaxAge   time.Duration `long:"cache_snapshot_max_age" description:"Age of a cache snapshot after which the cache is loaded from the database" env:"CACHE_SNAPSHOT_MAX_AGE" default:"24h"`
+
+		ReconcileInterval time.Duration `long:"cache_reconcile_interval" description:"Interval between reconciliations of the cache with the database, 0 disables them" env:"CACHE_RECONCILE_INTERVAL" default:"10m"`
+	}
+
+	Invalidation struct {
+		Bus        string `long:"invalidation_bus" description:"Bus of cache invalidations between instances: none, nats, postgres" env:"INVALIDATION_BUS" choice:"none" choice:"nats" choice:"postgres" default:"none"`
+		InstanceID string `long:"instance_id" description:"Instance ID in cache invalidations, random if empty" env:"INSTANCE_ID"`
+		Subject    string `long:"invalidation_subject" description:"Nats subject of cache invalidations" env:"INVALIDATION_SUBJECT" default:"cache-invalidation"`
+		Channel    string `long:"invalidation_channel" description:"PostgreSQL channel of cache invalidations" env:"INVALIDATION_CHANNEL" default:"cache_invalidation"`
+	}
+
+	Ingestion struct {
+		Mode string `long:"ingestion_mode" description:"Storage of orders submitted over HTTP: publish, direct" env:"INGESTION_MODE" choice:"publish" choice:"direct" default:"publish"`
+	}
+
+	Purge struct {
+		Retention time.Duration `long:"purge_retention" description:"Time a deleted order is kept before it is purged, 0 disables purging" env:"PURGE_RETENTION" default:"720h"`
+		Interval  time.Duration `long:"purge_interval" description:"Interval between purges of deleted orders" env:"PURGE_INTERVAL" default:"1h"`
 	}
 
 	HttpServer struct {
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"L0/internal/usecase"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cacheHandlers represents the implementation of CacheHandlers interface.
+type cacheHandlers struct {
+	interactor usecase.CacheInteractor
+}
+
+// NewCacheHandlers creates a new instance of cacheHandlers.
+func NewCacheHandlers(interactor usecase.CacheInteractor) *cacheHandlers {
+	return &cacheHandlers{
+		interactor: interactor,
+	}
+}
+
+// ReconcileHandler handles requests to reconcile the order cache with the database right away.
+func (h *cacheHandlers) ReconcileHandler(c *gin.Context) {
+	// The request context cancels the reconciliation when the client goes away
+	report, err := h.interactor.Reconcile(c.Request.Context())
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't reconcile cache: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetReconciliationHandler handles requests to retrieve the outcome of the latest reconciliation.
+func (h *cacheHandlers) GetReconciliationHandler(c *gin.Context) {
+	report := h.interactor.LastReconciliation()
+	if report == nil {
+		abortWithStatus(c, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
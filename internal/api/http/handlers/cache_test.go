@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"L0/internal/entity"
+	"L0/internal/usecase"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	gomock "github.com/golang/mock/gomock"
+)
+
+func TestCacheHandlers_ReconcileHandler(t *testing.T) {
+	type fields struct {
+		interactor *usecase.MockCacheInteractor
+	}
+	report := &entity.Reconciliation{Checked: 2, Cached: 2, Mismatched: 1, Repaired: 1, Complete: true}
+	tests := []struct {
+		name     string
+		setup    func(f fields)
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "success",
+			wantCode: http.StatusOK,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Reconcile(gomock.Any()).Return(report, nil)
+			},
+			wantBody: mustJSON(report),
+		},
+		{
+			name:     "fail: can't reconcile",
+			wantCode: http.StatusInternalServerError,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Reconcile(gomock.Any()).Return(&entity.Reconciliation{Error: "db error"}, fmt.Errorf("db error"))
+			},
+		},
+		{
+			name:     "fail: database timeout",
+			wantCode: http.StatusServiceUnavailable,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Reconcile(gomock.Any()).Return(nil, fmt.Errorf("can't stream orders: %w", context.DeadlineExceeded))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				interactor: usecase.NewMockCacheInteractor(ctrl),
+			}
+			h := &cacheHandlers{
+				interactor: f.interactor,
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/admin/cache/reconciliation", nil)
+
+			tt.setup(f)
+
+			h.ReconcileHandler(c)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("ReconcileHandler() code = %v, wantCode %v", w.Code, tt.wantCode)
+			}
+
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			if w.Body.String() != tt.wantBody {
+				t.Errorf("ReconcileHandler() body = %s, want %s", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCacheHandlers_GetReconciliationHandler(t *testing.T) {
+	type fields struct {
+		interactor *usecase.MockCacheInteractor
+	}
+	tests := []struct {
+		name     string
+		setup    func(f fields)
+		wantCode int
+	}{
+		{
+			name:     "success",
+			wantCode: http.StatusOK,
+			setup: func(f fields) {
+				f.interactor.EXPECT().LastReconciliation().Return(&entity.Reconciliation{Checked: 1, Cached: 1, Complete: true})
+			},
+		},
+		{
+			name:     "fail: not reconciled yet",
+			wantCode: http.StatusNotFound,
+			setup: func(f fields) {
+				f.interactor.EXPECT().LastReconciliation().Return(nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				interactor: usecase.NewMockCacheInteractor(ctrl),
+			}
+			h := &cacheHandlers{
+				interactor: f.interactor,
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/admin/cache/reconciliation", nil)
+
+			tt.setup(f)
+
+			h.GetReconciliationHandler(c)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("GetReconciliationHandler() code = %v, wantCode %v", w.Code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -2,12 +2,157 @@
 package handlers
 
 import (
+	"L0/internal/entity"
+	"L0/internal/validator"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
-// NotImplementedHandler is a handler function for returning a 405 Method Not Allowed status.
-func NotImplementedHandler(c *gin.Context) {
-	c.AbortWithStatus(http.StatusMethodNotAllowed)
+// ProblemContentType is the media type of problem details (RFC 7807).
+const ProblemContentType = "application/problem+json"
+
+// RequestIDHeader is the header carrying the ID of a request.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the key of the request ID in the gin context.
+const requestIDKey = "request_id"
+
+// maxRequestIDLength is the longest request ID accepted from a client.
+const maxRequestIDLength = 128
+
+// Problem types of domain errors, other errors have the type about:blank.
+const (
+	ProblemTypeBlank           = "about:blank"
+	ProblemTypeValidation      = "/problems/validation"
+	ProblemTypeNotFound        = "/problems/not-found"
+	ProblemTypeConflict        = "/problems/conflict"
+	ProblemTypeVersionMismatch = "/problems/version-mismatch"
+	ProblemTypeUnavailable     = "/problems/unavailable"
+)
+
+// domainErrors maps domain errors to their status codes and problem types, the first match wins.
+var domainErrors = []struct {
+	err         error
+	status      int
+	problemType string
+}{
+	{entity.ErrValidation, http.StatusBadRequest, ProblemTypeValidation},
+	{entity.ErrNotFound, http.StatusNotFound, ProblemTypeNotFound},
+	{entity.ErrConflict, http.StatusConflict, ProblemTypeConflict},
+	{entity.ErrVersionMismatch, http.StatusPreconditionFailed, ProblemTypeVersionMismatch},
+	{entity.ErrUnavailable, http.StatusServiceUnavailable, ProblemTypeUnavailable},
+	// A query timeout means the database does not keep up
+	{context.DeadlineExceeded, http.StatusServiceUnavailable, ProblemTypeUnavailable},
+}
+
+// Problem is the body of an error response (RFC 7807).
+type Problem struct {
+	Type      string                 `json:"type"`
+	Title     string                 `json:"title"`
+	Status    int                    `json:"status"`
+	Detail    string                 `json:"detail,omitempty"`
+	Instance  string                 `json:"instance,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Errors    []validator.FieldError `json:"errors,omitempty"`
+}
+
+// RequestIDMiddleware assigns an ID to every request, reported in the X-Request-ID response header
+// and in problem details. An ID sent by the client in the same header is kept.
+func RequestIDMiddleware(c *gin.Context) {
+	id := c.GetHeader(RequestIDHeader)
+	if id == "" || len(id) > maxRequestIDLength {
+		b := make([]byte, 8)
+		_, _ = rand.Read(b)
+		id = hex.EncodeToString(b)
+	}
+
+	c.Set(requestIDKey, id)
+	c.Header(RequestIDHeader, id)
+	c.Next()
+}
+
+// NotFoundHandler is a handler function for returning a 404 Not Found problem for unknown routes.
+func NotFoundHandler(c *gin.Context) {
+	abortWithStatus(c, http.StatusNotFound)
+}
+
+// MethodNotAllowedHandler is a handler function for returning a 405 Method Not Allowed problem
+// for known routes requested with another method.
+func MethodNotAllowedHandler(c *gin.Context) {
+	abortWithStatus(c, http.StatusMethodNotAllowed)
+}
+
+// InternalErrorHandler is a handler function for returning a 500 Internal Server Error problem,
+// it is used once a handler panics.
+func InternalErrorHandler(c *gin.Context) {
+	abortWithStatus(c, http.StatusInternalServerError)
+}
+
+// abortWithDomainError aborts the request with the status code of the domain error wrapped by err,
+// 500 Internal Server Error if there is none.
+func abortWithDomainError(c *gin.Context, err error) {
+	for _, d := range domainErrors {
+		if errors.Is(err, d.err) {
+			abortWithError(c, d.status, err)
+			return
+		}
+	}
+
+	abortWithError(c, http.StatusInternalServerError, err)
+}
+
+// abortWithStatus aborts the request with a problem carrying only the status code.
+func abortWithStatus(c *gin.Context, status int) {
+	abortWithError(c, status, nil)
+}
+
+// abortWithError aborts the request with a problem describing err, which is also attached to the context for logging.
+// The detail of server errors is left out, so that internals are not exposed to clients.
+func abortWithError(c *gin.Context, status int, err error) {
+	abortWithProblem(c, newProblem(c, status, err))
+}
+
+// newProblem creates a problem describing err, which is also attached to the context for logging.
+func newProblem(c *gin.Context, status int, err error) Problem {
+	problem := Problem{
+		Type:      ProblemTypeBlank,
+		Title:     http.StatusText(status),
+		Status:    status,
+		RequestID: c.GetString(requestIDKey),
+	}
+	if c.Request != nil {
+		problem.Instance = c.Request.URL.Path
+	}
+
+	if err != nil {
+		_ = c.Error(err)
+
+		for _, d := range domainErrors {
+			if d.status == status && errors.Is(err, d.err) {
+				problem.Type = d.problemType
+				break
+			}
+		}
+		if status < http.StatusInternalServerError {
+			problem.Detail = err.Error()
+		}
+
+		var validationErr *validator.ValidationError
+		if errors.As(err, &validationErr) {
+			problem.Errors = validationErr.Fields
+		}
+	}
+
+	return problem
+}
+
+// abortWithProblem aborts the request with a problem.
+func abortWithProblem(c *gin.Context, problem Problem) {
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
 }
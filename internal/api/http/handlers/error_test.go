@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"L0/internal/entity"
+	"L0/internal/validator"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAbortWithDomainError(t *testing.T) {
+	fields := []validator.FieldError{{Path: "track_number", Rule: validator.RuleRequired, Message: "must not be empty"}}
+	tests := []struct {
+		name string
+		err  error
+		want Problem
+	}{
+		{
+			name: "validation",
+			err:  fmt.Errorf("can't create order: %w", &validator.ValidationError{Fields: fields}),
+			want: Problem{
+				Type:      ProblemTypeValidation,
+				Title:     "Bad Request",
+				Status:    http.StatusBadRequest,
+				Detail:    "can't create order: validation failed: track_number: must not be empty",
+				Instance:  "/orders",
+				RequestID: "request-1",
+				Errors:    fields,
+			},
+		},
+		{
+			name: "not found",
+			err:  fmt.Errorf("can't delete order: %w", entity.ErrNotFound),
+			want: Problem{Type: ProblemTypeNotFound, Title: "Not Found", Status: http.StatusNotFound, Detail: "can't delete order: not found", Instance: "/orders", RequestID: "request-1"},
+		},
+		{
+			name: "conflict",
+			err:  fmt.Errorf("can't change status: %w", entity.ErrConflict),
+			want: Problem{Type: ProblemTypeConflict, Title: "Conflict", Status: http.StatusConflict, Detail: "can't change status: conflict", Instance: "/orders", RequestID: "request-1"},
+		},
+		{
+			name: "version mismatch",
+			err:  fmt.Errorf("can't update order: %w", entity.ErrVersionMismatch),
+			want: Problem{Type: ProblemTypeVersionMismatch, Title: "Precondition Failed", Status: http.StatusPreconditionFailed, Detail: "can't update order: version mismatch", Instance: "/orders", RequestID: "request-1"},
+		},
+		{
+			name: "unavailable",
+			err:  fmt.Errorf("can't publish order: %w", entity.ErrUnavailable),
+			want: Problem{Type: ProblemTypeUnavailable, Title: "Service Unavailable", Status: http.StatusServiceUnavailable, Instance: "/orders", RequestID: "request-1"},
+		},
+		{
+			name: "query timeout",
+			err:  fmt.Errorf("can't get order: %w", context.DeadlineExceeded),
+			want: Problem{Type: ProblemTypeUnavailable, Title: "Service Unavailable", Status: http.StatusServiceUnavailable, Instance: "/orders", RequestID: "request-1"},
+		},
+		{
+			name: "internal",
+			err:  fmt.Errorf("db password is wrong"),
+			want: Problem{Type: ProblemTypeBlank, Title: "Internal Server Error", Status: http.StatusInternalServerError, Instance: "/orders", RequestID: "request-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/orders", nil)
+			c.Set(requestIDKey, "request-1")
+
+			abortWithDomainError(c, tt.err)
+
+			if w.Code != tt.want.Status {
+				t.Errorf("abortWithDomainError() code = %v, want %v", w.Code, tt.want.Status)
+			}
+			if contentType := w.Header().Get("Content-Type"); contentType != ProblemContentType {
+				t.Errorf("abortWithDomainError() Content-Type = %v, want %v", contentType, ProblemContentType)
+			}
+			var got Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("abortWithDomainError() body = %s, error = %v", w.Body.String(), err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("abortWithDomainError() = %+v, want %+v", got, tt.want)
+			}
+			if len(c.Errors) != 1 {
+				t.Errorf("abortWithDomainError() attached %d errors, want 1", len(c.Errors))
+			}
+		})
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		wantSame  bool
+	}{
+		{
+			name:      "kept from client",
+			requestID: "client-request-1",
+			wantSame:  true,
+		},
+		{
+			name: "generated",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			_, r := gin.CreateTestContext(w)
+			r.Use(RequestIDMiddleware)
+			r.NoRoute(NotFoundHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/unknown", nil)
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			r.ServeHTTP(w, req)
+
+			id := w.Header().Get(RequestIDHeader)
+			if id == "" || (id == tt.requestID) != tt.wantSame {
+				t.Errorf("RequestIDMiddleware() id = %q, request id %q", id, tt.requestID)
+			}
+			var problem Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil || problem.RequestID != id {
+				t.Errorf("RequestIDMiddleware() problem = %s, want request id %q", w.Body.String(), id)
+			}
+		})
+	}
+}
+
+func TestNotFoundHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(w)
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(NotFoundHandler)
+	r.NoMethod(MethodNotAllowedHandler)
+	r.GET("/orders/all", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		method   string
+		path     string
+		wantCode int
+	}{
+		{method: http.MethodGet, path: "/orders/unknown", wantCode: http.StatusNotFound},
+		{method: http.MethodDelete, path: "/orders/all", wantCode: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+		if w.Code != tt.wantCode {
+			t.Errorf("%s %s code = %v, want %v", tt.method, tt.path, w.Code, tt.wantCode)
+		}
+		if contentType := w.Header().Get("Content-Type"); contentType != ProblemContentType {
+			t.Errorf("%s %s Content-Type = %v, want %v", tt.method, tt.path, contentType, ProblemContentType)
+		}
+	}
+}
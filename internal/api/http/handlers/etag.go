@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errPreconditionRequired is returned when a modifying request does not carry an If-Match header.
+var errPreconditionRequired = errors.New("If-Match header is required")
+
+// etag formats an order version as a strong entity tag.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// setETag sets the ETag header of the response to the order version.
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", etag(version))
+}
+
+// parseETag parses an entity tag produced by etag, weak tags are accepted.
+func parseETag(tag string) (int, error) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, fmt.Errorf("invalid entity tag %q", tag)
+	}
+
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid entity tag %q", tag)
+	}
+
+	return version, nil
+}
+
+// ifMatchVersion returns the order version required by the If-Match header of the request,
+// zero for "*" which matches any version.
+// It aborts the request with 428 Precondition Required if the header is missing
+// and with 412 Precondition Failed if it is not a single strong order entity tag,
+// since If-Match uses the strong comparison and a weak tag never matches.
+func ifMatchVersion(c *gin.Context) (int, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		abortWithError(c, http.StatusPreconditionRequired, errPreconditionRequired)
+		return 0, false
+	}
+
+	if header == "*" {
+		return 0, true
+	}
+
+	if strings.HasPrefix(header, "W/") {
+		abortWithError(c, http.StatusPreconditionFailed, fmt.Errorf("weak entity tag %q does not match", header))
+		return 0, false
+	}
+
+	version, err := parseETag(header)
+	if err != nil {
+		abortWithError(c, http.StatusPreconditionFailed, err)
+		return 0, false
+	}
+
+	return version, true
+}
+
+// noneMatch reports whether the If-None-Match header of the request lists the order version.
+func noneMatch(c *gin.Context, version int) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimSpace(tag) == "*" {
+			return true
+		}
+		v, err := parseETag(tag)
+		if err == nil && v == version {
+			return true
+		}
+	}
+
+	return false
+}
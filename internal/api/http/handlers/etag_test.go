@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Test_parseETag(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		want    int
+		wantErr bool
+	}{
+		{name: "strong", tag: `"3"`, want: 3},
+		{name: "weak", tag: `W/"3"`, want: 3},
+		{name: "surrounding spaces", tag: ` "3" `, want: 3},
+		{name: "fail: unquoted", tag: `3`, wantErr: true},
+		{name: "fail: not a number", tag: `"abc"`, wantErr: true},
+		{name: "fail: zero", tag: `"0"`, wantErr: true},
+		{name: "fail: empty", tag: ``, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseETag(tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseETag() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseETag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_noneMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{name: "no header", header: "", want: false},
+		{name: "same version", header: `"3"`, want: true},
+		{name: "other version", header: `"2"`, want: false},
+		{name: "list", header: `"1", W/"3"`, want: true},
+		{name: "any", header: `*`, want: true},
+		{name: "malformed", header: `3`, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/orders/id/b563feb7b2b84b6test", nil)
+			if tt.header != "" {
+				c.Request.Header.Set("If-None-Match", tt.header)
+			}
+
+			if got := noneMatch(c, 3); got != tt.want {
+				t.Errorf("noneMatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -34,6 +34,18 @@ func (m *MockOrderHandlers) EXPECT() *MockOrderHandlersMockRecorder {
 	return m.recorder
 }
 
+// ChangeStatusHandler mocks base method.
+func (m *MockOrderHandlers) ChangeStatusHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ChangeStatusHandler", c)
+}
+
+// ChangeStatusHandler indicates an expected call of ChangeStatusHandler.
+func (mr *MockOrderHandlersMockRecorder) ChangeStatusHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeStatusHandler", reflect.TypeOf((*MockOrderHandlers)(nil).ChangeStatusHandler), c)
+}
+
 // CreateHandler mocks base method.
 func (m *MockOrderHandlers) CreateHandler(c *gin.Context) {
 	m.ctrl.T.Helper()
@@ -58,6 +70,30 @@ func (mr *MockOrderHandlersMockRecorder) DeleteHandler(c interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHandler", reflect.TypeOf((*MockOrderHandlers)(nil).DeleteHandler), c)
 }
 
+// DiffHandler mocks base method.
+func (m *MockOrderHandlers) DiffHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DiffHandler", c)
+}
+
+// DiffHandler indicates an expected call of DiffHandler.
+func (mr *MockOrderHandlersMockRecorder) DiffHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiffHandler", reflect.TypeOf((*MockOrderHandlers)(nil).DiffHandler), c)
+}
+
+// GenerateHandler mocks base method.
+func (m *MockOrderHandlers) GenerateHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GenerateHandler", c)
+}
+
+// GenerateHandler indicates an expected call of GenerateHandler.
+func (mr *MockOrderHandlersMockRecorder) GenerateHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateHandler", reflect.TypeOf((*MockOrderHandlers)(nil).GenerateHandler), c)
+}
+
 // GetAllHandler mocks base method.
 func (m *MockOrderHandlers) GetAllHandler(c *gin.Context) {
 	m.ctrl.T.Helper()
@@ -82,6 +118,30 @@ func (mr *MockOrderHandlersMockRecorder) GetByIdHandler(c interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIdHandler", reflect.TypeOf((*MockOrderHandlers)(nil).GetByIdHandler), c)
 }
 
+// GetByTrackNumberHandler mocks base method.
+func (m *MockOrderHandlers) GetByTrackNumberHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetByTrackNumberHandler", c)
+}
+
+// GetByTrackNumberHandler indicates an expected call of GetByTrackNumberHandler.
+func (mr *MockOrderHandlersMockRecorder) GetByTrackNumberHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByTrackNumberHandler", reflect.TypeOf((*MockOrderHandlers)(nil).GetByTrackNumberHandler), c)
+}
+
+// GetDeletedHandler mocks base method.
+func (m *MockOrderHandlers) GetDeletedHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetDeletedHandler", c)
+}
+
+// GetDeletedHandler indicates an expected call of GetDeletedHandler.
+func (mr *MockOrderHandlersMockRecorder) GetDeletedHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedHandler", reflect.TypeOf((*MockOrderHandlers)(nil).GetDeletedHandler), c)
+}
+
 // GetHTMLOrderHandler mocks base method.
 func (m *MockOrderHandlers) GetHTMLOrderHandler(c *gin.Context) {
 	m.ctrl.T.Helper()
@@ -93,3 +153,300 @@ func (mr *MockOrderHandlersMockRecorder) GetHTMLOrderHandler(c interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHTMLOrderHandler", reflect.TypeOf((*MockOrderHandlers)(nil).GetHTMLOrderHandler), c)
 }
+
+// GetStatusHistoryHandler mocks base method.
+func (m *MockOrderHandlers) GetStatusHistoryHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetStatusHistoryHandler", c)
+}
+
+// GetStatusHistoryHandler indicates an expected call of GetStatusHistoryHandler.
+func (mr *MockOrderHandlersMockRecorder) GetStatusHistoryHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatusHistoryHandler", reflect.TypeOf((*MockOrderHandlers)(nil).GetStatusHistoryHandler), c)
+}
+
+// HistoryHandler mocks base method.
+func (m *MockOrderHandlers) HistoryHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "HistoryHandler", c)
+}
+
+// HistoryHandler indicates an expected call of HistoryHandler.
+func (mr *MockOrderHandlersMockRecorder) HistoryHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HistoryHandler", reflect.TypeOf((*MockOrderHandlers)(nil).HistoryHandler), c)
+}
+
+// IngestionStatusHandler mocks base method.
+func (m *MockOrderHandlers) IngestionStatusHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "IngestionStatusHandler", c)
+}
+
+// IngestionStatusHandler indicates an expected call of IngestionStatusHandler.
+func (mr *MockOrderHandlersMockRecorder) IngestionStatusHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IngestionStatusHandler", reflect.TypeOf((*MockOrderHandlers)(nil).IngestionStatusHandler), c)
+}
+
+// ListByContactHandler mocks base method.
+func (m *MockOrderHandlers) ListByContactHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListByContactHandler", c)
+}
+
+// ListByContactHandler indicates an expected call of ListByContactHandler.
+func (mr *MockOrderHandlersMockRecorder) ListByContactHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByContactHandler", reflect.TypeOf((*MockOrderHandlers)(nil).ListByContactHandler), c)
+}
+
+// ListByCustomerHandler mocks base method.
+func (m *MockOrderHandlers) ListByCustomerHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListByCustomerHandler", c)
+}
+
+// ListByCustomerHandler indicates an expected call of ListByCustomerHandler.
+func (mr *MockOrderHandlersMockRecorder) ListByCustomerHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByCustomerHandler", reflect.TypeOf((*MockOrderHandlers)(nil).ListByCustomerHandler), c)
+}
+
+// PatchHandler mocks base method.
+func (m *MockOrderHandlers) PatchHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PatchHandler", c)
+}
+
+// PatchHandler indicates an expected call of PatchHandler.
+func (mr *MockOrderHandlersMockRecorder) PatchHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchHandler", reflect.TypeOf((*MockOrderHandlers)(nil).PatchHandler), c)
+}
+
+// RestoreHandler mocks base method.
+func (m *MockOrderHandlers) RestoreHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RestoreHandler", c)
+}
+
+// RestoreHandler indicates an expected call of RestoreHandler.
+func (mr *MockOrderHandlersMockRecorder) RestoreHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreHandler", reflect.TypeOf((*MockOrderHandlers)(nil).RestoreHandler), c)
+}
+
+// SearchHandler mocks base method.
+func (m *MockOrderHandlers) SearchHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SearchHandler", c)
+}
+
+// SearchHandler indicates an expected call of SearchHandler.
+func (mr *MockOrderHandlersMockRecorder) SearchHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchHandler", reflect.TypeOf((*MockOrderHandlers)(nil).SearchHandler), c)
+}
+
+// UpdateHandler mocks base method.
+func (m *MockOrderHandlers) UpdateHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateHandler", c)
+}
+
+// UpdateHandler indicates an expected call of UpdateHandler.
+func (mr *MockOrderHandlersMockRecorder) UpdateHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHandler", reflect.TypeOf((*MockOrderHandlers)(nil).UpdateHandler), c)
+}
+
+// VersionHandler mocks base method.
+func (m *MockOrderHandlers) VersionHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "VersionHandler", c)
+}
+
+// VersionHandler indicates an expected call of VersionHandler.
+func (mr *MockOrderHandlersMockRecorder) VersionHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VersionHandler", reflect.TypeOf((*MockOrderHandlers)(nil).VersionHandler), c)
+}
+
+// MockRejectedMessageHandlers is a mock of RejectedMessageHandlers interface.
+type MockRejectedMessageHandlers struct {
+	ctrl     *gomock.Controller
+	recorder *MockRejectedMessageHandlersMockRecorder
+}
+
+// MockRejectedMessageHandlersMockRecorder is the mock recorder for MockRejectedMessageHandlers.
+type MockRejectedMessageHandlersMockRecorder struct {
+	mock *MockRejectedMessageHandlers
+}
+
+// NewMockRejectedMessageHandlers creates a new mock instance.
+func NewMockRejectedMessageHandlers(ctrl *gomock.Controller) *MockRejectedMessageHandlers {
+	mock := &MockRejectedMessageHandlers{ctrl: ctrl}
+	mock.recorder = &MockRejectedMessageHandlersMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRejectedMessageHandlers) EXPECT() *MockRejectedMessageHandlersMockRecorder {
+	return m.recorder
+}
+
+// DiscardHandler mocks base method.
+func (m *MockRejectedMessageHandlers) DiscardHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DiscardHandler", c)
+}
+
+// DiscardHandler indicates an expected call of DiscardHandler.
+func (mr *MockRejectedMessageHandlersMockRecorder) DiscardHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiscardHandler", reflect.TypeOf((*MockRejectedMessageHandlers)(nil).DiscardHandler), c)
+}
+
+// GetAllHandler mocks base method.
+func (m *MockRejectedMessageHandlers) GetAllHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetAllHandler", c)
+}
+
+// GetAllHandler indicates an expected call of GetAllHandler.
+func (mr *MockRejectedMessageHandlersMockRecorder) GetAllHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllHandler", reflect.TypeOf((*MockRejectedMessageHandlers)(nil).GetAllHandler), c)
+}
+
+// GetByIdHandler mocks base method.
+func (m *MockRejectedMessageHandlers) GetByIdHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetByIdHandler", c)
+}
+
+// GetByIdHandler indicates an expected call of GetByIdHandler.
+func (mr *MockRejectedMessageHandlersMockRecorder) GetByIdHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIdHandler", reflect.TypeOf((*MockRejectedMessageHandlers)(nil).GetByIdHandler), c)
+}
+
+// RedriveHandler mocks base method.
+func (m *MockRejectedMessageHandlers) RedriveHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RedriveHandler", c)
+}
+
+// RedriveHandler indicates an expected call of RedriveHandler.
+func (mr *MockRejectedMessageHandlersMockRecorder) RedriveHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedriveHandler", reflect.TypeOf((*MockRejectedMessageHandlers)(nil).RedriveHandler), c)
+}
+
+// MockCacheHandlers is a mock of CacheHandlers interface.
+type MockCacheHandlers struct {
+	ctrl     *gomock.Controller
+	recorder *MockCacheHandlersMockRecorder
+}
+
+// MockCacheHandlersMockRecorder is the mock recorder for MockCacheHandlers.
+type MockCacheHandlersMockRecorder struct {
+	mock *MockCacheHandlers
+}
+
+// NewMockCacheHandlers creates a new mock instance.
+func NewMockCacheHandlers(ctrl *gomock.Controller) *MockCacheHandlers {
+	mock := &MockCacheHandlers{ctrl: ctrl}
+	mock.recorder = &MockCacheHandlersMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCacheHandlers) EXPECT() *MockCacheHandlersMockRecorder {
+	return m.recorder
+}
+
+// GetReconciliationHandler mocks base method.
+func (m *MockCacheHandlers) GetReconciliationHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetReconciliationHandler", c)
+}
+
+// GetReconciliationHandler indicates an expected call of GetReconciliationHandler.
+func (mr *MockCacheHandlersMockRecorder) GetReconciliationHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReconciliationHandler", reflect.TypeOf((*MockCacheHandlers)(nil).GetReconciliationHandler), c)
+}
+
+// ReconcileHandler mocks base method.
+func (m *MockCacheHandlers) ReconcileHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReconcileHandler", c)
+}
+
+// ReconcileHandler indicates an expected call of ReconcileHandler.
+func (mr *MockCacheHandlersMockRecorder) ReconcileHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileHandler", reflect.TypeOf((*MockCacheHandlers)(nil).ReconcileHandler), c)
+}
+
+// MockOpenAPIHandlers is a mock of OpenAPIHandlers interface.
+type MockOpenAPIHandlers struct {
+	ctrl     *gomock.Controller
+	recorder *MockOpenAPIHandlersMockRecorder
+}
+
+// MockOpenAPIHandlersMockRecorder is the mock recorder for MockOpenAPIHandlers.
+type MockOpenAPIHandlersMockRecorder struct {
+	mock *MockOpenAPIHandlers
+}
+
+// NewMockOpenAPIHandlers creates a new mock instance.
+func NewMockOpenAPIHandlers(ctrl *gomock.Controller) *MockOpenAPIHandlers {
+	mock := &MockOpenAPIHandlers{ctrl: ctrl}
+	mock.recorder = &MockOpenAPIHandlersMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOpenAPIHandlers) EXPECT() *MockOpenAPIHandlersMockRecorder {
+	return m.recorder
+}
+
+// SpecHandler mocks base method.
+func (m *MockOpenAPIHandlers) SpecHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SpecHandler", c)
+}
+
+// SpecHandler indicates an expected call of SpecHandler.
+func (mr *MockOpenAPIHandlersMockRecorder) SpecHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SpecHandler", reflect.TypeOf((*MockOpenAPIHandlers)(nil).SpecHandler), c)
+}
+
+// SwaggerUIAssetHandler mocks base method.
+func (m *MockOpenAPIHandlers) SwaggerUIAssetHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SwaggerUIAssetHandler", c)
+}
+
+// SwaggerUIAssetHandler indicates an expected call of SwaggerUIAssetHandler.
+func (mr *MockOpenAPIHandlersMockRecorder) SwaggerUIAssetHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SwaggerUIAssetHandler", reflect.TypeOf((*MockOpenAPIHandlers)(nil).SwaggerUIAssetHandler), c)
+}
+
+// SwaggerUIHandler mocks base method.
+func (m *MockOpenAPIHandlers) SwaggerUIHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SwaggerUIHandler", c)
+}
+
+// SwaggerUIHandler indicates an expected call of SwaggerUIHandler.
+func (mr *MockOpenAPIHandlersMockRecorder) SwaggerUIHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SwaggerUIHandler", reflect.TypeOf((*MockOpenAPIHandlers)(nil).SwaggerUIHandler), c)
+}
@@ -7,9 +7,15 @@ import "github.com/gin-gonic/gin"
 
 // OrderHandlers defines the interface for order handlers.
 type OrderHandlers interface {
-	// CreateHandler handles requests to create an order.
+	// CreateHandler handles requests to submit an order.
 	CreateHandler(c *gin.Context)
 
+	// IngestionStatusHandler handles requests to retrieve the ingestion status of a submitted order.
+	IngestionStatusHandler(c *gin.Context)
+
+	// GenerateHandler handles requests to generate a random order, it is meant for development only.
+	GenerateHandler(c *gin.Context)
+
 	// GetByIdHandler handles requests to retrieve an order by its ID.
 	GetByIdHandler(c *gin.Context)
 
@@ -19,6 +25,81 @@ type OrderHandlers interface {
 	// GetAllHandler handles requests to retrieve all orders.
 	GetAllHandler(c *gin.Context)
 
-	// DeleteHandler handles requests to delete an order.
+	// SearchHandler handles requests to search orders by filters.
+	SearchHandler(c *gin.Context)
+
+	// GetByTrackNumberHandler handles requests to retrieve the most recent order with a track number.
+	GetByTrackNumberHandler(c *gin.Context)
+
+	// ListByCustomerHandler handles requests to list the orders of a customer.
+	ListByCustomerHandler(c *gin.Context)
+
+	// ListByContactHandler handles requests to list the orders delivered to a phone number or an email.
+	ListByContactHandler(c *gin.Context)
+
+	// UpdateHandler handles requests to replace an order.
+	UpdateHandler(c *gin.Context)
+
+	// PatchHandler handles requests to partially update an order with a JSON Merge Patch.
+	PatchHandler(c *gin.Context)
+
+	// DeleteHandler handles requests to soft-delete an order.
 	DeleteHandler(c *gin.Context)
+
+	// RestoreHandler handles requests to restore a soft-deleted order.
+	RestoreHandler(c *gin.Context)
+
+	// ChangeStatusHandler handles requests to move an order to another status.
+	ChangeStatusHandler(c *gin.Context)
+
+	// GetStatusHistoryHandler handles requests to retrieve the status transitions of an order.
+	GetStatusHistoryHandler(c *gin.Context)
+
+	// HistoryHandler handles requests to list the versions of an order.
+	HistoryHandler(c *gin.Context)
+
+	// VersionHandler handles requests to retrieve a version of an order.
+	VersionHandler(c *gin.Context)
+
+	// DiffHandler handles requests to compare two versions of an order.
+	DiffHandler(c *gin.Context)
+
+	// GetDeletedHandler handles requests to list soft-deleted orders.
+	GetDeletedHandler(c *gin.Context)
+}
+
+// RejectedMessageHandlers defines the interface for rejected message handlers.
+type RejectedMessageHandlers interface {
+	// GetAllHandler handles requests to list rejected messages.
+	GetAllHandler(c *gin.Context)
+
+	// GetByIdHandler handles requests to inspect a rejected message by its ID.
+	GetByIdHandler(c *gin.Context)
+
+	// RedriveHandler handles requests to publish a rejected message again.
+	RedriveHandler(c *gin.Context)
+
+	// DiscardHandler handles requests to discard a rejected message.
+	DiscardHandler(c *gin.Context)
+}
+
+// CacheHandlers defines the interface for order cache handlers.
+type CacheHandlers interface {
+	// ReconcileHandler handles requests to reconcile the order cache with the database.
+	ReconcileHandler(c *gin.Context)
+
+	// GetReconciliationHandler handles requests to retrieve the outcome of the latest reconciliation.
+	GetReconciliationHandler(c *gin.Context)
+}
+
+// OpenAPIHandlers defines the interface for API documentation handlers.
+type OpenAPIHandlers interface {
+	// SpecHandler handles requests to retrieve the OpenAPI document.
+	SpecHandler(c *gin.Context)
+
+	// SwaggerUIHandler handles requests to retrieve the Swagger UI page showing the OpenAPI document.
+	SwaggerUIHandler(c *gin.Context)
+
+	// SwaggerUIAssetHandler handles requests to retrieve the bundled scripts and styles of Swagger UI.
+	SwaggerUIAssetHandler(c *gin.Context)
 }
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"L0/internal/api/http/openapi"
+	"L0/internal/validator"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/gin-gonic/gin"
+)
+
+// invalidContentTypeReason prefixes the reason of request errors caused by an undocumented content type.
+const invalidContentTypeReason = "header Content-Type has unexpected value"
+
+// ruleSchema is the rule reported in field errors that are not tied to a single schema keyword.
+const ruleSchema = "schema"
+
+// openAPIHandlers represents the implementation of OpenAPIHandlers interface.
+type openAPIHandlers struct {
+	spec      []byte
+	swaggerUI []byte
+	assets    fs.FS
+}
+
+// NewOpenAPIHandlers creates a new instance of openAPIHandlers serving the embedded OpenAPI document.
+func NewOpenAPIHandlers() *openAPIHandlers {
+	return &openAPIHandlers{
+		spec:      openapi.Spec(),
+		swaggerUI: openapi.SwaggerUI(),
+		assets:    openapi.SwaggerUIAssets(),
+	}
+}
+
+// SpecHandler handles requests to retrieve the OpenAPI document.
+func (h *openAPIHandlers) SpecHandler(c *gin.Context) {
+	c.Data(http.StatusOK, gin.MIMEJSON, h.spec)
+}
+
+// SwaggerUIHandler handles requests to retrieve the Swagger UI page showing the OpenAPI document.
+func (h *openAPIHandlers) SwaggerUIHandler(c *gin.Context) {
+	c.Data(http.StatusOK, gin.MIMEHTML, h.swaggerUI)
+}
+
+// SwaggerUIAssetHandler handles requests to retrieve the bundled scripts and styles of Swagger UI.
+func (h *openAPIHandlers) SwaggerUIAssetHandler(c *gin.Context) {
+	name := strings.TrimPrefix(c.Param("filepath"), "/")
+
+	info, err := fs.Stat(h.assets, name)
+	if err != nil || info.IsDir() {
+		abortWithStatus(c, http.StatusNotFound)
+		return
+	}
+
+	c.FileFromFS(name, http.FS(h.assets))
+}
+
+// NewValidationMiddleware creates a middleware validating requests against the OpenAPI document.
+// An invalid request is aborted with 400 Bad Request, or 415 Unsupported Media Type if its content type
+// is not documented. If validateResponses is set, the responses are validated as well and an invalid one
+// is replaced with 500 Internal Server Error describing the mismatch, which is meant for development only.
+// Requests to undocumented routes are passed as is.
+func NewValidationMiddleware(doc *openapi3.T, validateResponses bool) gin.HandlerFunc {
+	options := &openapi3filter.Options{
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		MultiError:         true,
+	}
+
+	return func(c *gin.Context) {
+		route := openapi.Route(doc, c.Request.Method, c.FullPath())
+		if route == nil {
+			c.Next()
+			return
+		}
+
+		params := make(map[string]string, len(c.Params))
+		for _, p := range c.Params {
+			params[p.Key] = p.Value
+		}
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: params,
+			Route:      route,
+			Options:    options,
+		}
+
+		err := openapi3filter.ValidateRequest(c.Request.Context(), input)
+		if err != nil {
+			status := http.StatusBadRequest
+			if unsupportedContentType(err) {
+				status = http.StatusUnsupportedMediaType
+			}
+			abortWithError(c, status, requestValidationError(err))
+			return
+		}
+
+		if !validateResponses {
+			c.Next()
+			return
+		}
+
+		// The response is held back until it is validated
+		w := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		err = openapi3filter.ValidateResponse(c.Request.Context(), &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 w.status,
+			Header:                 w.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(w.body.Bytes())),
+			Options:                options,
+		})
+		if err != nil {
+			problem := newProblem(c, http.StatusInternalServerError, fmt.Errorf("invalid response: %w", err))
+			problem.Detail = fmt.Sprintf("response %d doesn't match the openapi document: %v", w.status, err)
+			abortWithProblem(c, problem)
+			return
+		}
+
+		w.flush()
+	}
+}
+
+// unsupportedContentType reports whether a request failed validation because its content type is not documented.
+func unsupportedContentType(err error) bool {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		for _, err := range e {
+			if unsupportedContentType(err) {
+				return true
+			}
+		}
+	case *openapi3filter.RequestError:
+		return strings.HasPrefix(e.Reason, invalidContentTypeReason)
+	}
+
+	return false
+}
+
+// requestValidationError converts the errors of request validation to a validation error listing the invalid fields.
+// Parameters are reported by their location and name, such as query.limit, body fields by their JSON path.
+func requestValidationError(err error) *validator.ValidationError {
+	validationErr := &validator.ValidationError{}
+	collectFieldErrors(err, "", &validationErr.Fields)
+
+	return validationErr
+}
+
+// collectFieldErrors appends the field errors of a validation error found under a path.
+func collectFieldErrors(err error, path string, fields *[]validator.FieldError) {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		for _, err := range e {
+			collectFieldErrors(err, path, fields)
+		}
+	case *openapi3filter.RequestError:
+		if e.Parameter != nil {
+			path = e.Parameter.In + "." + e.Parameter.Name
+		}
+		if e.Err == nil {
+			*fields = append(*fields, validator.FieldError{Path: path, Rule: ruleSchema, Message: e.Reason})
+			return
+		}
+		collectFieldErrors(e.Err, path, fields)
+	case *openapi3.SchemaError:
+		// Body fields are reported by their path in the body, as the order validator does
+		if pointer := e.JSONPointer(); len(pointer) > 0 {
+			path = strings.TrimPrefix(path+"."+strings.Join(pointer, "."), ".")
+		}
+		*fields = append(*fields, validator.FieldError{Path: path, Rule: e.SchemaField, Message: e.Reason})
+	default:
+		rule := ruleSchema
+		if errors.Is(err, openapi3filter.ErrInvalidRequired) {
+			rule = validator.RuleRequired
+		}
+		*fields = append(*fields, validator.FieldError{Path: path, Rule: rule, Message: err.Error()})
+	}
+}
+
+// bufferedWriter holds a response back until it is flushed.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status  int
+	body    bytes.Buffer
+	written bool
+}
+
+// WriteHeader keeps the status code of the response.
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+// WriteHeaderNow marks the header of the response as written.
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+// Write keeps the body of the response.
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+// WriteString keeps the body of the response.
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+// Status returns the status code of the response.
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+// Size returns the size of the body of the response.
+func (w *bufferedWriter) Size() int {
+	return w.body.Len()
+}
+
+// Written reports whether the response has been written.
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
+
+// Flush is a no-op, the response is sent by flush once validated.
+func (w *bufferedWriter) Flush() {}
+
+// flush sends the response held back.
+func (w *bufferedWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.body.Len() == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	_, _ = w.ResponseWriter.Write(w.body.Bytes())
+}
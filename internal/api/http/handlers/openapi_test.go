@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"L0/internal/api/http/openapi"
+	"L0/internal/entity"
+	"L0/internal/usecase"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	gomock "github.com/golang/mock/gomock"
+)
+
+func TestValidationMiddleware(t *testing.T) {
+	type fields struct {
+		orderInteractor *usecase.MockOrderInteractor
+		ingestion       *usecase.MockIngestionInteractor
+	}
+	created := time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)
+	order := &entity.Order{
+		OrderUID:    "b563feb7b2b84b6test",
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Delivery:    entity.Delivery{Name: "Test Testov", Phone: "+9720000000", Email: "test@gmail.com"},
+		Payment:     entity.Payment{Transaction: "b563feb7b2b84b6test", Currency: "USD", Amount: 1817},
+		Items:       []entity.Item{{ChrtID: 9934930, TrackNumber: "WBILMTESTTRACK", Price: 453, Status: 202}},
+		Locale:      "en",
+		DateCreated: created,
+		Status:      entity.StatusCreated,
+		Version:     2,
+		Deleted:     &entity.OrderDeletion{DeletedAt: created, DeletedBy: "support"},
+	}
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		setup       func(f fields)
+		wantCode    int
+		wantErrors  []string
+	}{
+		{
+			name:   "success: documented response",
+			method: http.MethodGet,
+			target: "/orders/id/b563feb7b2b84b6test",
+			setup: func(f fields) {
+				f.orderInteractor.EXPECT().GetByUid(gomock.Any(), "b563feb7b2b84b6test").Return(order, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:   "success: documented problem",
+			method: http.MethodGet,
+			target: "/orders/id/b563feb7b2b84b6test",
+			setup: func(f fields) {
+				f.orderInteractor.EXPECT().GetByUid(gomock.Any(), "b563feb7b2b84b6test").Return(nil, nil)
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:   "success: page of orders",
+			method: http.MethodGet,
+			target: "/orders/all?limit=10&summary=true&order=desc",
+			setup: func(f fields) {
+				f.orderInteractor.EXPECT().List(gomock.Any(), gomock.Any()).Return(&entity.OrderPage{Orders: []*entity.Order{order}}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:       "fail: invalid query parameter",
+			method:     http.MethodGet,
+			target:     "/orders/all?limit=ten&order=up",
+			setup:      func(f fields) {},
+			wantCode:   http.StatusBadRequest,
+			wantErrors: []string{"query.limit", "query.order"},
+		},
+		{
+			name:        "fail: invalid body",
+			method:      http.MethodPost,
+			target:      "/orders",
+			contentType: gin.MIMEJSON,
+			body:        `{"order_uid":1,"delivery":{"phone":2}}`,
+			setup:       func(f fields) {},
+			wantCode:    http.StatusBadRequest,
+			wantErrors:  []string{"order_uid", "delivery.phone"},
+		},
+		{
+			name:        "fail: undocumented content type",
+			method:      http.MethodPatch,
+			target:      "/orders/id/b563feb7b2b84b6test",
+			contentType: "text/plain",
+			body:        `track_number=WBILMTESTTRACK`,
+			setup:       func(f fields) {},
+			wantCode:    http.StatusUnsupportedMediaType,
+		},
+		{
+			name:   "fail: undocumented response",
+			method: http.MethodGet,
+			target: "/orders/ingestion/b563feb7b2b84b6test",
+			setup: func(f fields) {
+				f.ingestion.EXPECT().Status(gomock.Any(), "b563feb7b2b84b6test", time.Duration(0)).Return(&entity.Ingestion{OrderUID: "b563feb7b2b84b6test", State: "lost"}, nil)
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Errorf("openapi.Load() error = %v", err)
+		return
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				orderInteractor: usecase.NewMockOrderInteractor(ctrl),
+				ingestion:       usecase.NewMockIngestionInteractor(ctrl),
+			}
+			h := &orderHandlers{
+				interactor: f.orderInteractor,
+				ingestion:  f.ingestion,
+			}
+			w := httptest.NewRecorder()
+			_, r := gin.CreateTestContext(w)
+			orderGroup := r.Group(openapi.Prefix, NewValidationMiddleware(doc, true))
+			orderGroup.POST("", h.CreateHandler)
+			orderGroup.GET("/all", h.GetAllHandler)
+			orderGroup.GET("/id/:uid", h.GetByIdHandler)
+			orderGroup.PATCH("/id/:uid", h.PatchHandler)
+			orderGroup.GET("/ingestion/:uid", h.IngestionStatusHandler)
+
+			tt.setup(f)
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("%s %s code = %v, wantCode %v, body %s", tt.method, tt.target, w.Code, tt.wantCode, w.Body.String())
+			}
+			if len(tt.wantErrors) == 0 {
+				return
+			}
+			var problem Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Errorf("%s %s body = %s, error = %v", tt.method, tt.target, w.Body.String(), err)
+				return
+			}
+			paths := make(map[string]bool, len(problem.Errors))
+			for _, e := range problem.Errors {
+				paths[e.Path] = true
+			}
+			for _, path := range tt.wantErrors {
+				if !paths[path] {
+					t.Errorf("%s %s errors = %+v, want an error at %s", tt.method, tt.target, problem.Errors, path)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenAPIHandlers_SpecHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+
+	NewOpenAPIHandlers().SpecHandler(c)
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil || w.Code != http.StatusOK || doc.OpenAPI == "" {
+		t.Errorf("SpecHandler() code = %v, openapi = %q, error = %v", w.Code, doc.OpenAPI, err)
+	}
+}
+
+func TestOpenAPIHandlers_SwaggerUIAssetHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		filepath string
+		wantCode int
+		wantType string
+	}{
+		{name: "success: styles", filepath: "/swagger-ui.css", wantCode: http.StatusOK, wantType: "text/css"},
+		{name: "success: scripts", filepath: "/swagger-ui-bundle.js", wantCode: http.StatusOK, wantType: "javascript"},
+		{name: "fail: missing file", filepath: "/missing.js", wantCode: http.StatusNotFound},
+		{name: "fail: directory", filepath: "/", wantCode: http.StatusNotFound},
+	}
+	h := NewOpenAPIHandlers()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/docs/assets"+tt.filepath, nil)
+			c.Params = gin.Params{{Key: "filepath", Value: tt.filepath}}
+
+			h.SwaggerUIAssetHandler(c)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("SwaggerUIAssetHandler() code = %v, wantCode %v", w.Code, tt.wantCode)
+			}
+			if contentType := w.Header().Get("Content-Type"); !strings.Contains(contentType, tt.wantType) {
+				t.Errorf("SwaggerUIAssetHandler() Content-Type = %v, want %v", contentType, tt.wantType)
+			}
+		})
+	}
+}
@@ -1,46 +1,139 @@
 package handlers
 
 import (
+	"L0/internal/entity"
 	"L0/internal/nats"
 	"L0/internal/usecase"
 	"L0/internal/utils"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// mergePatchContentType is the media type of JSON Merge Patch documents (RFC 7396).
+const mergePatchContentType = "application/merge-patch+json"
+
 // orderHandlers represents the implementation of OrderHandlers interface.
 type orderHandlers struct {
 	interactor  usecase.OrderInteractor
+	ingestion   usecase.IngestionInteractor
 	natsService nats.NATSService
 }
 
 // NewOrderHandlers creates a new instance of orderHandlers.
-func NewOrderHandlers(interactor usecase.OrderInteractor, natsService nats.NATSService) *orderHandlers {
+func NewOrderHandlers(
+	interactor usecase.OrderInteractor,
+	ingestion usecase.IngestionInteractor,
+	natsService nats.NATSService,
+) *orderHandlers {
 	return &orderHandlers{
 		interactor:  interactor,
+		ingestion:   ingestion,
 		natsService: natsService,
 	}
 }
 
-// CreateOrderHandler handles requests to create an order.
+// submitResponse acknowledges an order accepted for ingestion.
+type submitResponse struct {
+	OrderUID  string `json:"order_uid"`
+	StatusURL string `json:"status_url"`
+}
+
+// CreateHandler handles requests to submit an order.
+// The order is accepted once it is valid and published or stored, depending on the ingestion mode,
+// the response points to the ingestion status of the order, which tells when it is stored.
 func (h *orderHandlers) CreateHandler(c *gin.Context) {
+	ctx := context.Background()
+
+	var order entity.Order
+	err := c.ShouldBindJSON(&order)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, fmt.Errorf("can't decode order: %w", err))
+		return
+	}
+
+	err = h.ingestion.Submit(ctx, &order)
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't submit order: %w", err))
+		return
+	}
+
+	statusURL := "/orders/ingestion/" + url.PathEscape(order.OrderUID)
+	c.Header("Location", statusURL)
+	c.JSON(http.StatusAccepted, submitResponse{
+		OrderUID:  order.OrderUID,
+		StatusURL: statusURL,
+	})
+}
+
+// IngestionStatusHandler handles requests to retrieve the ingestion status of a submitted order.
+// The wait query parameter, a duration such as 10s or a number of seconds, holds the request
+// until the order is persisted or rejected or the duration elapses.
+func (h *orderHandlers) IngestionStatusHandler(c *gin.Context) {
+	wait, err := waitParam(c)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// The request context ends the wait when the client goes away
+	ingestion, err := h.ingestion.Status(c.Request.Context(), c.Param("uid"), wait)
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't get ingestion status: %w", err))
+		return
+	}
+
+	if ingestion == nil {
+		abortWithStatus(c, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, ingestion)
+}
+
+// waitParam parses the wait parameter of an ingestion status request.
+func waitParam(c *gin.Context) (time.Duration, error) {
+	v := c.Query("wait")
+	if v == "" {
+		return 0, nil
+	}
+
+	if seconds, err := strconv.Atoi(v); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	wait, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid wait: %w", err)
+	}
+
+	return wait, nil
+}
+
+// GenerateHandler handles requests to generate a random order and publish it to NATS.
+// It is meant for development and is routed in debug mode only.
+func (h *orderHandlers) GenerateHandler(c *gin.Context) {
 	order := utils.GenerateOrder()
 
 	// Marshal the order into JSON format
 	orderJSON, err := json.Marshal(order)
 	if err != nil {
-		c.AbortWithError(http.StatusInternalServerError, fmt.Errorf("can't marshal order: %w", err))
+		abortWithDomainError(c, fmt.Errorf("can't marshal order: %w", err))
 		return
 	}
 
 	// Publish the order to NATS
 	err = h.natsService.Publish(orderJSON)
 	if err != nil {
-		c.AbortWithError(http.StatusInternalServerError, fmt.Errorf("can't publish order: %w", err))
+		abortWithDomainError(c, fmt.Errorf("can't publish order: %w", err))
+		return
 	}
 	c.JSON(http.StatusOK, order)
 }
@@ -53,50 +146,497 @@ func (h *orderHandlers) GetByIdHandler(c *gin.Context) {
 
 	order, err := h.interactor.GetByUid(ctx, uid)
 	if err != nil {
-		c.AbortWithError(http.StatusInternalServerError, fmt.Errorf("can't get order: %w", err))
+		abortWithDomainError(c, fmt.Errorf("can't get order: %w", err))
 		return
 	}
 
 	if order == nil {
-		c.AbortWithStatus(http.StatusNotFound)
+		abortWithStatus(c, http.StatusNotFound)
+		return
+	}
+
+	setETag(c, order.Version)
+	if noneMatch(c, order.Version) {
+		c.AbortWithStatus(http.StatusNotModified)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// UpdateHandler handles requests to replace an order.
+// The If-Match header must carry the ETag of the order being replaced.
+func (h *orderHandlers) UpdateHandler(c *gin.Context) {
+	ctx := context.Background()
+
+	uid := c.Param("uid")
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	var order entity.Order
+	err := c.ShouldBindJSON(&order)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, fmt.Errorf("can't decode order: %w", err))
 		return
 	}
 
+	err = h.interactor.Update(ctx, uid, &order, version)
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't update order: %w", err))
+		return
+	}
+
+	setETag(c, order.Version)
 	c.JSON(http.StatusOK, order)
 }
 
-// DeleteHandler handles requests to delete an order.
+// PatchHandler handles requests to partially update an order with a JSON Merge Patch.
+// The If-Match header must carry the ETag of the order being patched.
+func (h *orderHandlers) PatchHandler(c *gin.Context) {
+	ctx := context.Background()
+
+	uid := c.Param("uid")
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	contentType := c.ContentType()
+	if contentType != mergePatchContentType && contentType != gin.MIMEJSON {
+		abortWithError(c, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported content type %q", contentType))
+		return
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, fmt.Errorf("can't read patch: %w", err))
+		return
+	}
+
+	order, err := h.interactor.Patch(ctx, uid, patch, version)
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't patch order: %w", err))
+		return
+	}
+
+	setETag(c, order.Version)
+	c.JSON(http.StatusOK, order)
+}
+
+// DeleteHandler handles requests to soft-delete an order.
+// Query parameters deleted_by and reason describe the deletion,
+// the If-Match header must carry the ETag of the order being deleted.
 func (h *orderHandlers) DeleteHandler(c *gin.Context) {
 	ctx := context.Background()
 
 	uid := c.Param("uid")
 
-	err := h.interactor.Delete(ctx, uid)
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	err := h.interactor.Delete(ctx, uid, c.Query("deleted_by"), c.Query("reason"), version)
 	if err != nil {
-		c.AbortWithError(http.StatusInternalServerError, fmt.Errorf("can't delete order: %w", err))
+		abortWithDomainError(c, fmt.Errorf("can't delete order: %w", err))
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
-// GetAllHandler handles requests to retrieve all orders.
+// RestoreHandler handles requests to restore a soft-deleted order.
+func (h *orderHandlers) RestoreHandler(c *gin.Context) {
+	ctx := context.Background()
+
+	uid := c.Param("uid")
+
+	order, err := h.interactor.Restore(ctx, uid)
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't restore order: %w", err))
+		return
+	}
+
+	setETag(c, order.Version)
+	c.JSON(http.StatusOK, order)
+}
+
+// ChangeStatusHandler handles requests to move an order to another status.
+// The body carries the new status, the actor and an optional reason of the change.
+func (h *orderHandlers) ChangeStatusHandler(c *gin.Context) {
+	ctx := context.Background()
+
+	var update entity.StatusUpdate
+	err := c.ShouldBindJSON(&update)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, fmt.Errorf("can't decode status update: %w", err))
+		return
+	}
+	update.OrderUID = c.Param("uid")
+
+	order, err := h.interactor.ChangeStatus(ctx, update)
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't change order status: %w", err))
+		return
+	}
+
+	setETag(c, order.Version)
+	c.JSON(http.StatusOK, order)
+}
+
+// GetStatusHistoryHandler handles requests to retrieve the status transitions of an order.
+func (h *orderHandlers) GetStatusHistoryHandler(c *gin.Context) {
+	ctx := context.Background()
+
+	uid := c.Param("uid")
+
+	history, err := h.interactor.StatusHistory(ctx, uid)
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't get status history: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// HistoryHandler handles requests to list the versions of an order.
+func (h *orderHandlers) HistoryHandler(c *gin.Context) {
+	ctx := context.Background()
+
+	uid := c.Param("uid")
+
+	versions, err := h.interactor.History(ctx, uid)
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't get order history: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// VersionHandler handles requests to retrieve a version of an order with its snapshot.
+func (h *orderHandlers) VersionHandler(c *gin.Context) {
+	ctx := context.Background()
+
+	uid := c.Param("uid")
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, fmt.Errorf("invalid version: %w", err))
+		return
+	}
+
+	v, err := h.interactor.Version(ctx, uid, version)
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't get order version: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, v)
+}
+
+// DiffHandler handles requests to compare two versions of an order.
+// Query parameters from and to are the numbers of the versions.
+func (h *orderHandlers) DiffHandler(c *gin.Context) {
+	ctx := context.Background()
+
+	uid := c.Param("uid")
+
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, fmt.Errorf("invalid from: %w", err))
+		return
+	}
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, fmt.Errorf("invalid to: %w", err))
+		return
+	}
+
+	diff, err := h.interactor.Diff(ctx, uid, from, to)
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't compare order versions: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// GetDeletedHandler handles requests to list soft-deleted orders page by page.
+// It accepts the pagination parameters of GetAllHandler and always responds with summaries.
+func (h *orderHandlers) GetDeletedHandler(c *gin.Context) {
+	ctx := context.Background()
+
+	query, err := orderListQuery(c)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := h.interactor.ListDeleted(ctx, query)
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't get deleted orders: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, newOrderListResponse(page, true))
+}
+
+// GetByTrackNumberHandler handles requests to retrieve the most recent order with a track number.
+func (h *orderHandlers) GetByTrackNumberHandler(c *gin.Context) {
+	ctx := context.Background()
+
+	order, err := h.interactor.GetByTrackNumber(ctx, c.Param("track_number"))
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't get order by track number: %w", err))
+		return
+	}
+
+	if order == nil {
+		abortWithStatus(c, http.StatusNotFound)
+		return
+	}
+
+	setETag(c, order.Version)
+	if noneMatch(c, order.Version) {
+		c.AbortWithStatus(http.StatusNotModified)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// ListByCustomerHandler handles requests to list the orders of a customer, most recent first.
+// The summary query parameter includes summary fields instead of only order IDs.
+func (h *orderHandlers) ListByCustomerHandler(c *gin.Context) {
+	ctx := context.Background()
+
+	summary, err := summaryParam(c)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	orders, err := h.interactor.ListByCustomer(ctx, c.Param("customer_id"))
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't get orders of customer: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, newOrderListResponse(&entity.OrderPage{Orders: orders}, summary))
+}
+
+// ListByContactHandler handles requests to list the orders delivered to a contact, most recent first.
+// Exactly one of the phone and email query parameters must be set,
+// the summary query parameter includes summary fields instead of only order IDs.
+func (h *orderHandlers) ListByContactHandler(c *gin.Context) {
+	ctx := context.Background()
+
+	summary, err := summaryParam(c)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	phone, email := c.Query("phone"), c.Query("email")
+
+	var orders []*entity.Order
+	switch {
+	case phone != "" && email == "":
+		orders, err = h.interactor.ListByPhone(ctx, phone)
+	case email != "" && phone == "":
+		orders, err = h.interactor.ListByEmail(ctx, email)
+	default:
+		abortWithError(c, http.StatusBadRequest, errors.New("exactly one of phone and email is required"))
+		return
+	}
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't get orders of contact: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, newOrderListResponse(&entity.OrderPage{Orders: orders}, summary))
+}
+
+// orderListResponse is a page of listed orders.
+type orderListResponse struct {
+	Orders     []entity.OrderSummary `json:"orders"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+// GetAllHandler handles requests to list orders page by page.
+// Query parameters: limit, cursor, sort (date_created or order_uid), order (asc or desc)
+// and summary to include summary fields instead of only order IDs.
 func (h *orderHandlers) GetAllHandler(c *gin.Context) {
 	ctx := context.Background()
 
-	orders, err := h.interactor.GetAll(ctx)
+	query, err := orderListQuery(c)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	summary, err := summaryParam(c)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := h.interactor.List(ctx, query)
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't get orders: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, newOrderListResponse(page, summary))
+}
+
+// SearchHandler handles requests to search orders page by page.
+// Besides the parameters of GetAllHandler it accepts filters: track_number, customer_id,
+// delivery_service, locale, created_from and created_to (RFC 3339), bank, provider, currency,
+// phone, email, city, brand and nm_id.
+func (h *orderHandlers) SearchHandler(c *gin.Context) {
+	ctx := context.Background()
+
+	listQuery, err := orderListQuery(c)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	filter, err := orderFilter(c)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	summary, err := summaryParam(c)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := h.interactor.Search(ctx, entity.OrderSearchQuery{OrderListQuery: listQuery, Filter: filter})
 	if err != nil {
-		c.AbortWithError(http.StatusInternalServerError, fmt.Errorf("can't get orders: %w", err))
+		abortWithDomainError(c, fmt.Errorf("can't search orders: %w", err))
 		return
 	}
 
-	ids := make([]string, 0, len(orders))
-	for _, order := range orders {
-		ids = append(ids, order.OrderUID)
+	c.JSON(http.StatusOK, newOrderListResponse(page, summary))
+}
+
+// newOrderListResponse converts a page of orders into a response with order IDs or summaries.
+func newOrderListResponse(page *entity.OrderPage, summary bool) orderListResponse {
+	resp := orderListResponse{
+		Orders: make([]entity.OrderSummary, 0, len(page.Orders)),
+	}
+	for _, order := range page.Orders {
+		if summary {
+			resp.Orders = append(resp.Orders, order.Summary())
+		} else {
+			resp.Orders = append(resp.Orders, entity.OrderSummary{OrderUID: order.OrderUID})
+		}
+	}
+	if page.Next != nil {
+		resp.NextCursor = page.Next.Encode()
+	}
+
+	return resp
+}
+
+// summaryParam parses the summary parameter of an order listing request.
+func summaryParam(c *gin.Context) (bool, error) {
+	v := c.Query("summary")
+	if v == "" {
+		return false, nil
+	}
+
+	summary, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid summary: %w", err)
+	}
+
+	return summary, nil
+}
+
+// orderFilter parses the filter parameters of an order search request.
+func orderFilter(c *gin.Context) (entity.OrderFilter, error) {
+	filter := entity.OrderFilter{
+		TrackNumber:     c.Query("track_number"),
+		CustomerID:      c.Query("customer_id"),
+		DeliveryService: c.Query("delivery_service"),
+		Locale:          c.Query("locale"),
+		PaymentBank:     c.Query("bank"),
+		PaymentProvider: c.Query("provider"),
+		PaymentCurrency: c.Query("currency"),
+		DeliveryPhone:   c.Query("phone"),
+		DeliveryEmail:   c.Query("email"),
+		DeliveryCity:    c.Query("city"),
+		ItemBrand:       c.Query("brand"),
+	}
+
+	if v := c.Query("created_from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid created_from: %w", err)
+		}
+		filter.CreatedFrom = &from
+	}
+
+	if v := c.Query("created_to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid created_to: %w", err)
+		}
+		filter.CreatedTo = &to
+	}
+
+	if v := c.Query("nm_id"); v != "" {
+		nmID, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid nm_id: %w", err)
+		}
+		filter.ItemNmID = &nmID
+	}
+
+	return filter, nil
+}
+
+// orderListQuery parses the pagination parameters of an order listing request.
+func orderListQuery(c *gin.Context) (entity.OrderListQuery, error) {
+	var query entity.OrderListQuery
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return query, fmt.Errorf("invalid limit: %w", err)
+		}
+		query.Limit = limit
+	}
+
+	if v := c.Query("cursor"); v != "" {
+		cursor, err := entity.DecodeOrderCursor(v)
+		if err != nil {
+			return query, err
+		}
+		query.After = cursor
+	}
+
+	query.Sort = entity.OrderSort(c.Query("sort"))
+
+	switch c.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		query.Desc = true
+	default:
+		return query, fmt.Errorf("invalid order %q, want asc or desc", c.Query("order"))
 	}
-	fmt.Println(ids)
 
-	c.JSON(http.StatusOK, ids)
+	return query, nil
 }
 
 // GetHTMLOrderHandler handles requests to retrieve an HTML representation of an order.
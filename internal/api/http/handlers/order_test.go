@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"L0/internal/entity"
+	"L0/internal/nats"
 	"L0/internal/usecase"
 	"context"
 	"encoding/json"
@@ -9,6 +10,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	reflect "reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -24,6 +26,223 @@ func MustParseTime(layout string, s string) time.Time {
 	return tt
 }
 
+func TestOrderHandlers_CreateHandler(t *testing.T) {
+	type fields struct {
+		ingestion *usecase.MockIngestionInteractor
+	}
+	tests := []struct {
+		name         string
+		body         string
+		setup        func(f fields)
+		wantCode     int
+		wantLocation string
+	}{
+		{
+			name: "success",
+			body: `{"order_uid":"b563feb7b2b84b6test","track_number":"WBILMTESTTRACK"}`,
+			setup: func(f fields) {
+				f.ingestion.EXPECT().Submit(gomock.Any(), &entity.Order{OrderUID: "b563feb7b2b84b6test", TrackNumber: "WBILMTESTTRACK"}).Return(nil)
+			},
+			wantCode:     http.StatusAccepted,
+			wantLocation: "/orders/ingestion/b563feb7b2b84b6test",
+		},
+		{
+			name:     "fail: malformed body",
+			body:     `{"order_uid":`,
+			setup:    func(f fields) {},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "fail: invalid order",
+			body: `{"track_number":"WBILMTESTTRACK"}`,
+			setup: func(f fields) {
+				f.ingestion.EXPECT().Submit(gomock.Any(), gomock.Any()).Return(fmt.Errorf("invalid order: %w", entity.ErrValidation))
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "fail: can't submit",
+			body: `{"order_uid":"b563feb7b2b84b6test"}`,
+			setup: func(f fields) {
+				f.ingestion.EXPECT().Submit(gomock.Any(), gomock.Any()).Return(fmt.Errorf("some error"))
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				ingestion: usecase.NewMockIngestionInteractor(ctrl),
+			}
+			h := &orderHandlers{
+				ingestion: f.ingestion,
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			tt.setup(f)
+
+			h.CreateHandler(c)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("CreateHandler() code = %v, wantCode %v", w.Code, tt.wantCode)
+			}
+			if location := w.Header().Get("Location"); location != tt.wantLocation {
+				t.Errorf("CreateHandler() Location = %v, want %v", location, tt.wantLocation)
+			}
+		})
+	}
+}
+
+func TestOrderHandlers_GenerateHandler(t *testing.T) {
+	type fields struct {
+		natsService *nats.MockNATSService
+	}
+	tests := []struct {
+		name     string
+		setup    func(f fields)
+		wantCode int
+	}{
+		{
+			name: "success",
+			setup: func(f fields) {
+				f.natsService.EXPECT().Publish(gomock.Any()).Return(nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "fail: NATS unavailable",
+			setup: func(f fields) {
+				f.natsService.EXPECT().Publish(gomock.Any()).Return(fmt.Errorf("%w: can't publish message: connection closed", entity.ErrUnavailable))
+			},
+			wantCode: http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				natsService: nats.NewMockNATSService(ctrl),
+			}
+			h := &orderHandlers{
+				natsService: f.natsService,
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/dev/orders/random", nil)
+
+			tt.setup(f)
+
+			h.GenerateHandler(c)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("GenerateHandler() code = %v, wantCode %v", w.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestOrderHandlers_IngestionStatusHandler(t *testing.T) {
+	type fields struct {
+		ingestion *usecase.MockIngestionInteractor
+	}
+	ingestion := &entity.Ingestion{OrderUID: "b563feb7b2b84b6test", State: entity.IngestionPersisted, Sequence: 42}
+	tests := []struct {
+		name     string
+		query    string
+		setup    func(f fields)
+		wantCode int
+		wantBody *entity.Ingestion
+	}{
+		{
+			name: "success",
+			setup: func(f fields) {
+				f.ingestion.EXPECT().Status(gomock.Any(), "b563feb7b2b84b6test", time.Duration(0)).Return(ingestion, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: ingestion,
+		},
+		{
+			name:  "success: wait in seconds",
+			query: "?wait=10",
+			setup: func(f fields) {
+				f.ingestion.EXPECT().Status(gomock.Any(), "b563feb7b2b84b6test", 10*time.Second).Return(ingestion, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: ingestion,
+		},
+		{
+			name:  "success: wait as duration",
+			query: "?wait=1500ms",
+			setup: func(f fields) {
+				f.ingestion.EXPECT().Status(gomock.Any(), "b563feb7b2b84b6test", 1500*time.Millisecond).Return(ingestion, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: ingestion,
+		},
+		{
+			name:     "fail: invalid wait",
+			query:    "?wait=soon",
+			setup:    func(f fields) {},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:  "fail: wait too long",
+			query: "?wait=1h",
+			setup: func(f fields) {
+				f.ingestion.EXPECT().Status(gomock.Any(), gomock.Any(), time.Hour).Return(nil, fmt.Errorf("%w: wait too long", entity.ErrValidation))
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "fail: not found",
+			setup: func(f fields) {
+				f.ingestion.EXPECT().Status(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "fail: can't get status",
+			setup: func(f fields) {
+				f.ingestion.EXPECT().Status(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("some error"))
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				ingestion: usecase.NewMockIngestionInteractor(ctrl),
+			}
+			h := &orderHandlers{
+				ingestion: f.ingestion,
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/orders/ingestion/b563feb7b2b84b6test"+tt.query, nil)
+			c.Params = gin.Params{{Key: "uid", Value: "b563feb7b2b84b6test"}}
+
+			tt.setup(f)
+
+			h.IngestionStatusHandler(c)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("IngestionStatusHandler() code = %v, wantCode %v", w.Code, tt.wantCode)
+			}
+			if tt.wantBody != nil && w.Body.String() != mustJSON(tt.wantBody) {
+				t.Errorf("IngestionStatusHandler() body = %v, want %v", w.Body.String(), mustJSON(tt.wantBody))
+			}
+		})
+	}
+}
+
 func TestOrderHandlers_GetByIdHandler(t *testing.T) {
 	type fields struct {
 		orderInteractor *usecase.MockOrderInteractor
@@ -33,11 +252,13 @@ func TestOrderHandlers_GetByIdHandler(t *testing.T) {
 		uid string
 	}
 	tests := []struct {
-		name     string
-		args     args
-		wantBody *entity.Order
-		setup    func(a args, f fields)
-		wantCode int
+		name        string
+		args        args
+		ifNoneMatch string
+		wantBody    *entity.Order
+		setup       func(a args, f fields)
+		wantCode    int
+		wantETag    string
 	}{
 		{
 			name: "success GetById usecase",
@@ -93,6 +314,7 @@ func TestOrderHandlers_GetByIdHandler(t *testing.T) {
 				SmID:              99,
 				DateCreated:       MustParseTime(time.RFC3339, "2021-11-26T06:22:19Z"),
 				OofShard:          "1",
+				Version:           3,
 			},
 			setup: func(a args, f fields) {
 				order := &entity.Order{
@@ -143,10 +365,39 @@ func TestOrderHandlers_GetByIdHandler(t *testing.T) {
 					SmID:              99,
 					DateCreated:       MustParseTime(time.RFC3339, "2021-11-26T06:22:19Z"),
 					OofShard:          "1",
+					Version:           3,
 				}
 				f.orderInteractor.EXPECT().GetByUid(a.ctx, a.uid).Return(order, nil)
 			},
 			wantCode: 200,
+			wantETag: `"3"`,
+		},
+		{
+			name: "success: not modified",
+			args: args{
+				ctx: context.Background(),
+				uid: "b563feb7b2b84b6test",
+			},
+			ifNoneMatch: `"2", W/"3"`,
+			setup: func(a args, f fields) {
+				f.orderInteractor.EXPECT().GetByUid(a.ctx, a.uid).Return(&entity.Order{OrderUID: a.uid, Version: 3}, nil)
+			},
+			wantCode: http.StatusNotModified,
+			wantETag: `"3"`,
+		},
+		{
+			name: "success: modified",
+			args: args{
+				ctx: context.Background(),
+				uid: "b563feb7b2b84b6test",
+			},
+			ifNoneMatch: `"2"`,
+			wantBody:    &entity.Order{OrderUID: "b563feb7b2b84b6test", Version: 3},
+			setup: func(a args, f fields) {
+				f.orderInteractor.EXPECT().GetByUid(a.ctx, a.uid).Return(&entity.Order{OrderUID: a.uid, Version: 3}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantETag: `"3"`,
 		},
 		{
 			name: "fail: can't get order by uid from interactor",
@@ -160,6 +411,18 @@ func TestOrderHandlers_GetByIdHandler(t *testing.T) {
 			},
 			wantCode: 500,
 		},
+		{
+			name: "fail: database timeout",
+			args: args{
+				ctx: context.Background(),
+				uid: "b563feb7b2b84b6test",
+			},
+			wantBody: nil,
+			setup: func(a args, f fields) {
+				f.orderInteractor.EXPECT().GetByUid(a.ctx, a.uid).Return(nil, fmt.Errorf("can't get order: %w", context.DeadlineExceeded))
+			},
+			wantCode: 503,
+		},
 		{
 			name: "fail: there is no such order",
 			args: args{
@@ -186,6 +449,10 @@ func TestOrderHandlers_GetByIdHandler(t *testing.T) {
 
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/orders/id/"+tt.args.uid, nil)
+			if tt.ifNoneMatch != "" {
+				c.Request.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
 			c.Params = gin.Params{{Key: "uid", Value: tt.args.uid}}
 
 			tt.setup(tt.args, f)
@@ -197,6 +464,10 @@ func TestOrderHandlers_GetByIdHandler(t *testing.T) {
 				return
 			}
 
+			if etag := w.Header().Get("ETag"); etag != tt.wantETag {
+				t.Errorf("orderHandlers.GetOrderByUid() ETag = %v, want %v", etag, tt.wantETag)
+			}
+
 			if w.Code != http.StatusOK {
 				return
 			}
@@ -226,6 +497,7 @@ func TestOrderHandlers_DeleteHandler(t *testing.T) {
 	tests := []struct {
 		name     string
 		args     args
+		ifMatch  string
 		setup    func(f fields)
 		wantCode int
 	}{
@@ -235,9 +507,10 @@ func TestOrderHandlers_DeleteHandler(t *testing.T) {
 				ctx: context.Background(),
 				uid: "b563feb7b2b84b6test",
 			},
+			ifMatch:  `"1"`,
 			wantCode: http.StatusNoContent,
 			setup: func(f fields) {
-				f.interactor.EXPECT().Delete(gomock.Any(), "b563feb7b2b84b6test").Return(nil)
+				f.interactor.EXPECT().Delete(gomock.Any(), "b563feb7b2b84b6test", "admin", "duplicate", 1).Return(nil)
 			},
 		},
 		{
@@ -246,10 +519,77 @@ func TestOrderHandlers_DeleteHandler(t *testing.T) {
 				ctx: context.Background(),
 				uid: "b563feb7b2b84b6test",
 			},
+			ifMatch:  `"1"`,
 			wantCode: http.StatusInternalServerError,
 			setup: func(f fields) {
-				f.interactor.EXPECT().Delete(gomock.Any(), "b563feb7b2b84b6test").Return(fmt.Errorf("some error"))
+				f.interactor.EXPECT().Delete(gomock.Any(), "b563feb7b2b84b6test", "admin", "duplicate", 1).Return(fmt.Errorf("some error"))
+			},
+		},
+		{
+			name: "fail: not found",
+			args: args{
+				ctx: context.Background(),
+				uid: "b563feb7b2b84b6test",
+			},
+			ifMatch:  `"1"`,
+			wantCode: http.StatusNotFound,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Delete(gomock.Any(), "b563feb7b2b84b6test", "admin", "duplicate", 1).Return(entity.ErrNotFound)
+			},
+		},
+		{
+			name: "success: any version",
+			args: args{
+				ctx: context.Background(),
+				uid: "b563feb7b2b84b6test",
+			},
+			ifMatch:  "*",
+			wantCode: http.StatusNoContent,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Delete(gomock.Any(), "b563feb7b2b84b6test", "admin", "duplicate", 0).Return(nil)
+			},
+		},
+		{
+			name: "fail: version mismatch",
+			args: args{
+				ctx: context.Background(),
+				uid: "b563feb7b2b84b6test",
+			},
+			ifMatch:  `"1"`,
+			wantCode: http.StatusPreconditionFailed,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Delete(gomock.Any(), "b563feb7b2b84b6test", "admin", "duplicate", 1).
+					Return(fmt.Errorf("%w: order is at version 2", entity.ErrVersionMismatch))
+			},
+		},
+		{
+			name: "fail: missing If-Match",
+			args: args{
+				ctx: context.Background(),
+				uid: "b563feb7b2b84b6test",
+			},
+			wantCode: http.StatusPreconditionRequired,
+			setup:    func(f fields) {},
+		},
+		{
+			name: "fail: malformed If-Match",
+			args: args{
+				ctx: context.Background(),
+				uid: "b563feb7b2b84b6test",
+			},
+			ifMatch:  "1",
+			wantCode: http.StatusPreconditionFailed,
+			setup:    func(f fields) {},
+		},
+		{
+			name: "fail: weak If-Match",
+			args: args{
+				ctx: context.Background(),
+				uid: "b563feb7b2b84b6test",
 			},
+			ifMatch:  `W/"2"`,
+			wantCode: http.StatusPreconditionFailed,
+			setup:    func(f fields) {},
 		},
 	}
 	for _, tt := range tests {
@@ -263,6 +603,10 @@ func TestOrderHandlers_DeleteHandler(t *testing.T) {
 				interactor: f.interactor,
 			}
 			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodDelete, "/orders/id/"+tt.args.uid+"?deleted_by=admin&reason=duplicate", nil)
+			if tt.ifMatch != "" {
+				c.Request.Header.Set("If-Match", tt.ifMatch)
+			}
 			c.Params = gin.Params{{Key: "uid", Value: tt.args.uid}}
 
 			tt.setup(f)
@@ -276,7 +620,7 @@ func TestOrderHandlers_DeleteHandler(t *testing.T) {
 	}
 }
 
-func TestOrderHandlers_GetAllHandler(t *testing.T) {
+func TestOrderHandlers_RestoreHandler(t *testing.T) {
 	type fields struct {
 		interactor *usecase.MockOrderInteractor
 	}
@@ -284,27 +628,27 @@ func TestOrderHandlers_GetAllHandler(t *testing.T) {
 		name     string
 		setup    func(f fields)
 		wantCode int
-		wantBody interface{}
 	}{
 		{
 			name:     "success",
 			wantCode: http.StatusOK,
 			setup: func(f fields) {
-				orders := []*entity.Order{
-					{OrderUID: "b563feb7b2b84b6test1"},
-					{OrderUID: "b563feb7b2b84b6test2"},
-				}
-				f.interactor.EXPECT().GetAll(gomock.Any()).Return(orders, nil)
+				f.interactor.EXPECT().Restore(gomock.Any(), "b563feb7b2b84b6test").Return(&entity.Order{OrderUID: "b563feb7b2b84b6test"}, nil)
 			},
-			wantBody: []string{"b563feb7b2b84b6test1", "b563feb7b2b84b6test2"},
 		},
 		{
-			name:     "fail: can't get orders",
+			name:     "fail: not found",
+			wantCode: http.StatusNotFound,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Restore(gomock.Any(), "b563feb7b2b84b6test").Return(nil, entity.ErrNotFound)
+			},
+		},
+		{
+			name:     "fail: can't restore order",
 			wantCode: http.StatusInternalServerError,
 			setup: func(f fields) {
-				f.interactor.EXPECT().GetAll(gomock.Any()).Return(nil, fmt.Errorf("some error"))
+				f.interactor.EXPECT().Restore(gomock.Any(), "b563feb7b2b84b6test").Return(nil, fmt.Errorf("some error"))
 			},
-			wantBody: nil,
 		},
 	}
 	for _, tt := range tests {
@@ -319,30 +663,959 @@ func TestOrderHandlers_GetAllHandler(t *testing.T) {
 			}
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/orders/id/b563feb7b2b84b6test/restore", nil)
+			c.Params = gin.Params{{Key: "uid", Value: "b563feb7b2b84b6test"}}
 
 			tt.setup(f)
 
-			h.GetAllHandler(c)
+			h.RestoreHandler(c)
 
 			if w.Code != tt.wantCode {
-				t.Errorf("GetAllHandler() code = %v, wantCode %v", c.Writer.Status(), tt.wantCode)
-			}
-
-			if w.Code != http.StatusOK {
-				return
-			}
-
-			var got []string
-			fmt.Printf("body: %s\n", w.Body.String())
-			err := json.Unmarshal(w.Body.Bytes(), &got)
-			if err != nil {
-				t.Errorf("can't unmarshal body: %v", err)
-				return
-			}
-
-			if !reflect.DeepEqual(got, tt.wantBody) {
-				t.Errorf("userInteractor.GetUser() = %v, want %v", &got, tt.wantBody)
+				t.Errorf("RestoreHandler() code = %v, wantCode %v", w.Code, tt.wantCode)
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestOrderHandlers_ChangeStatusHandler(t *testing.T) {
+	type fields struct {
+		interactor *usecase.MockOrderInteractor
+	}
+	update := entity.StatusUpdate{OrderUID: "b563feb7b2b84b6test", Status: entity.StatusPaid, Actor: "billing"}
+	tests := []struct {
+		name     string
+		body     string
+		setup    func(f fields)
+		wantCode int
+	}{
+		{
+			name:     "success",
+			body:     `{"status":"paid","actor":"billing"}`,
+			wantCode: http.StatusOK,
+			setup: func(f fields) {
+				f.interactor.EXPECT().ChangeStatus(gomock.Any(), update).
+					Return(&entity.Order{OrderUID: "b563feb7b2b84b6test", Status: entity.StatusPaid}, nil)
+			},
+		},
+		{
+			name:     "fail: invalid body",
+			body:     `{"status":`,
+			wantCode: http.StatusBadRequest,
+			setup:    func(f fields) {},
+		},
+		{
+			name:     "fail: unknown status",
+			body:     `{"status":"lost","actor":"billing"}`,
+			wantCode: http.StatusBadRequest,
+			setup: func(f fields) {
+				f.interactor.EXPECT().ChangeStatus(gomock.Any(), gomock.Any()).Return(nil, entity.ErrValidation)
+			},
+		},
+		{
+			name:     "fail: not found",
+			body:     `{"status":"paid","actor":"billing"}`,
+			wantCode: http.StatusNotFound,
+			setup: func(f fields) {
+				f.interactor.EXPECT().ChangeStatus(gomock.Any(), update).Return(nil, entity.ErrNotFound)
+			},
+		},
+		{
+			name:     "fail: transition not allowed",
+			body:     `{"status":"paid","actor":"billing"}`,
+			wantCode: http.StatusConflict,
+			setup: func(f fields) {
+				f.interactor.EXPECT().ChangeStatus(gomock.Any(), update).Return(nil, entity.ErrConflict)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				interactor: usecase.NewMockOrderInteractor(ctrl),
+			}
+			h := &orderHandlers{
+				interactor: f.interactor,
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/orders/id/b563feb7b2b84b6test/status", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "uid", Value: "b563feb7b2b84b6test"}}
+
+			tt.setup(f)
+
+			h.ChangeStatusHandler(c)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("ChangeStatusHandler() code = %v, wantCode %v", w.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestOrderHandlers_GetStatusHistoryHandler(t *testing.T) {
+	type fields struct {
+		interactor *usecase.MockOrderInteractor
+	}
+	tests := []struct {
+		name     string
+		setup    func(f fields)
+		wantCode int
+	}{
+		{
+			name:     "success",
+			wantCode: http.StatusOK,
+			setup: func(f fields) {
+				f.interactor.EXPECT().StatusHistory(gomock.Any(), "b563feb7b2b84b6test").Return([]*entity.StatusChange{}, nil)
+			},
+		},
+		{
+			name:     "fail: not found",
+			wantCode: http.StatusNotFound,
+			setup: func(f fields) {
+				f.interactor.EXPECT().StatusHistory(gomock.Any(), "b563feb7b2b84b6test").Return(nil, entity.ErrNotFound)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				interactor: usecase.NewMockOrderInteractor(ctrl),
+			}
+			h := &orderHandlers{
+				interactor: f.interactor,
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/orders/id/b563feb7b2b84b6test/status", nil)
+			c.Params = gin.Params{{Key: "uid", Value: "b563feb7b2b84b6test"}}
+
+			tt.setup(f)
+
+			h.GetStatusHistoryHandler(c)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("GetStatusHistoryHandler() code = %v, wantCode %v", w.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestOrderHandlers_VersionHandler(t *testing.T) {
+	type fields struct {
+		interactor *usecase.MockOrderInteractor
+	}
+	tests := []struct {
+		name     string
+		version  string
+		setup    func(f fields)
+		wantCode int
+	}{
+		{
+			name:     "success",
+			version:  "2",
+			wantCode: http.StatusOK,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Version(gomock.Any(), "b563feb7b2b84b6test", 2).
+					Return(&entity.OrderVersion{OrderUID: "b563feb7b2b84b6test", Version: 2}, nil)
+			},
+		},
+		{
+			name:     "fail: invalid version",
+			version:  "last",
+			wantCode: http.StatusBadRequest,
+			setup:    func(f fields) {},
+		},
+		{
+			name:     "fail: not found",
+			version:  "9",
+			wantCode: http.StatusNotFound,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Version(gomock.Any(), "b563feb7b2b84b6test", 9).Return(nil, entity.ErrNotFound)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				interactor: usecase.NewMockOrderInteractor(ctrl),
+			}
+			h := &orderHandlers{
+				interactor: f.interactor,
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/orders/id/b563feb7b2b84b6test/history/"+tt.version, nil)
+			c.Params = gin.Params{{Key: "uid", Value: "b563feb7b2b84b6test"}, {Key: "version", Value: tt.version}}
+
+			tt.setup(f)
+
+			h.VersionHandler(c)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("VersionHandler() code = %v, wantCode %v", w.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestOrderHandlers_DiffHandler(t *testing.T) {
+	type fields struct {
+		interactor *usecase.MockOrderInteractor
+	}
+	tests := []struct {
+		name     string
+		query    string
+		setup    func(f fields)
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "success",
+			query:    "?from=1&to=2",
+			wantCode: http.StatusOK,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Diff(gomock.Any(), "b563feb7b2b84b6test", 1, 2).Return(&entity.OrderDiff{
+					OrderUID: "b563feb7b2b84b6test",
+					From:     1,
+					To:       2,
+					Changes:  []entity.FieldChange{{Path: "locale", From: "en", To: "ru"}},
+				}, nil)
+			},
+			wantBody: `{"order_uid":"b563feb7b2b84b6test","from":1,"to":2,"changes":[{"path":"locale","from":"en","to":"ru"}]}`,
+		},
+		{
+			name:     "fail: missing to",
+			query:    "?from=1",
+			wantCode: http.StatusBadRequest,
+			setup:    func(f fields) {},
+		},
+		{
+			name:     "fail: not found",
+			query:    "?from=1&to=5",
+			wantCode: http.StatusNotFound,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Diff(gomock.Any(), "b563feb7b2b84b6test", 1, 5).Return(nil, entity.ErrNotFound)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				interactor: usecase.NewMockOrderInteractor(ctrl),
+			}
+			h := &orderHandlers{
+				interactor: f.interactor,
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/orders/id/b563feb7b2b84b6test/diff"+tt.query, nil)
+			c.Params = gin.Params{{Key: "uid", Value: "b563feb7b2b84b6test"}}
+
+			tt.setup(f)
+
+			h.DiffHandler(c)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("DiffHandler() code = %v, wantCode %v", w.Code, tt.wantCode)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("DiffHandler() body = %v, want %v", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestOrderHandlers_GetDeletedHandler(t *testing.T) {
+	type fields struct {
+		interactor *usecase.MockOrderInteractor
+	}
+	created := MustParseTime(time.RFC3339, "2021-11-26T06:22:19Z")
+	deletion := &entity.OrderDeletion{
+		DeletedAt: MustParseTime(time.RFC3339, "2024-02-01T10:00:00Z"),
+		DeletedBy: "admin",
+		Reason:    "duplicate",
+	}
+	amount, itemsCount := 1817, 0
+	tests := []struct {
+		name     string
+		query    string
+		setup    func(f fields)
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "success",
+			query:    "?limit=1",
+			wantCode: http.StatusOK,
+			setup: func(f fields) {
+				page := &entity.OrderPage{
+					Orders: []*entity.Order{
+						{OrderUID: "b563feb7b2b84b6test", DateCreated: created, Payment: entity.Payment{Amount: amount}, Deleted: deletion},
+					},
+				}
+				f.interactor.EXPECT().ListDeleted(gomock.Any(), entity.OrderListQuery{Limit: 1}).Return(page, nil)
+			},
+			wantBody: mustJSON(orderListResponse{
+				Orders: []entity.OrderSummary{
+					{OrderUID: "b563feb7b2b84b6test", DateCreated: &created, Amount: &amount, ItemsCount: &itemsCount, Deleted: deletion},
+				},
+			}),
+		},
+		{
+			name:     "fail: invalid limit",
+			query:    "?limit=ten",
+			wantCode: http.StatusBadRequest,
+			setup:    func(f fields) {},
+		},
+		{
+			name:     "fail: invalid query",
+			query:    "?limit=1000",
+			wantCode: http.StatusBadRequest,
+			setup: func(f fields) {
+				f.interactor.EXPECT().ListDeleted(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("%w: limit", entity.ErrValidation))
+			},
+		},
+		{
+			name:     "fail: can't get orders",
+			wantCode: http.StatusInternalServerError,
+			setup: func(f fields) {
+				f.interactor.EXPECT().ListDeleted(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("some error"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				interactor: usecase.NewMockOrderInteractor(ctrl),
+			}
+			h := &orderHandlers{
+				interactor: f.interactor,
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/orders/deleted"+tt.query, nil)
+
+			tt.setup(f)
+
+			h.GetDeletedHandler(c)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("GetDeletedHandler() code = %v, wantCode %v", w.Code, tt.wantCode)
+			}
+
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			if w.Body.String() != tt.wantBody {
+				t.Errorf("GetDeletedHandler() body = %s, want %s", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestOrderHandlers_GetByTrackNumberHandler(t *testing.T) {
+	type fields struct {
+		interactor *usecase.MockOrderInteractor
+	}
+	const track = "WBILMTESTTRACK"
+	tests := []struct {
+		name     string
+		setup    func(f fields)
+		wantCode int
+		wantETag string
+	}{
+		{
+			name: "success",
+			setup: func(f fields) {
+				f.interactor.EXPECT().GetByTrackNumber(gomock.Any(), track).Return(&entity.Order{OrderUID: "b563feb7b2b84b6test", TrackNumber: track, Version: 2}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantETag: `"2"`,
+		},
+		{
+			name: "fail: not found",
+			setup: func(f fields) {
+				f.interactor.EXPECT().GetByTrackNumber(gomock.Any(), track).Return(nil, nil)
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "fail: can't get order",
+			setup: func(f fields) {
+				f.interactor.EXPECT().GetByTrackNumber(gomock.Any(), track).Return(nil, fmt.Errorf("some error"))
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				interactor: usecase.NewMockOrderInteractor(ctrl),
+			}
+			h := &orderHandlers{
+				interactor: f.interactor,
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/orders/track/"+track, nil)
+			c.Params = gin.Params{{Key: "track_number", Value: track}}
+
+			tt.setup(f)
+
+			h.GetByTrackNumberHandler(c)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("GetByTrackNumberHandler() code = %v, wantCode %v", w.Code, tt.wantCode)
+			}
+			if etag := w.Header().Get("ETag"); etag != tt.wantETag {
+				t.Errorf("GetByTrackNumberHandler() ETag = %v, want %v", etag, tt.wantETag)
+			}
+		})
+	}
+}
+
+func TestOrderHandlers_ListByCustomerHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	interactor := usecase.NewMockOrderInteractor(ctrl)
+	h := &orderHandlers{
+		interactor: interactor,
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/orders/customer/test", nil)
+	c.Params = gin.Params{{Key: "customer_id", Value: "test"}}
+
+	interactor.EXPECT().ListByCustomer(gomock.Any(), "test").Return([]*entity.Order{
+		{OrderUID: "order_uid_2"},
+		{OrderUID: "order_uid_1"},
+	}, nil)
+
+	h.ListByCustomerHandler(c)
+
+	want := mustJSON(orderListResponse{
+		Orders: []entity.OrderSummary{{OrderUID: "order_uid_2"}, {OrderUID: "order_uid_1"}},
+	})
+	if w.Code != http.StatusOK || w.Body.String() != want {
+		t.Errorf("ListByCustomerHandler() code = %v, body = %s, want %s", w.Code, w.Body.String(), want)
+	}
+}
+
+func TestOrderHandlers_ListByContactHandler(t *testing.T) {
+	type fields struct {
+		interactor *usecase.MockOrderInteractor
+	}
+	tests := []struct {
+		name     string
+		query    string
+		setup    func(f fields)
+		wantCode int
+	}{
+		{
+			name:  "success: by phone",
+			query: "?phone=%2B9720000000",
+			setup: func(f fields) {
+				f.interactor.EXPECT().ListByPhone(gomock.Any(), "+9720000000").Return([]*entity.Order{}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:  "success: by email",
+			query: "?email=test@gmail.com",
+			setup: func(f fields) {
+				f.interactor.EXPECT().ListByEmail(gomock.Any(), "test@gmail.com").Return([]*entity.Order{}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "fail: no contact",
+			setup:    func(f fields) {},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "fail: both contacts",
+			query:    "?phone=%2B9720000000&email=test@gmail.com",
+			setup:    func(f fields) {},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:  "fail: can't get orders",
+			query: "?email=test@gmail.com",
+			setup: func(f fields) {
+				f.interactor.EXPECT().ListByEmail(gomock.Any(), "test@gmail.com").Return(nil, fmt.Errorf("some error"))
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				interactor: usecase.NewMockOrderInteractor(ctrl),
+			}
+			h := &orderHandlers{
+				interactor: f.interactor,
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/orders/contact"+tt.query, nil)
+
+			tt.setup(f)
+
+			h.ListByContactHandler(c)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("ListByContactHandler() code = %v, wantCode %v", w.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestOrderHandlers_GetAllHandler(t *testing.T) {
+	type fields struct {
+		interactor *usecase.MockOrderInteractor
+	}
+	created := MustParseTime(time.RFC3339, "2021-11-26T06:22:19Z")
+	next := &entity.OrderCursor{Sort: entity.OrderSortDateCreated, DateCreated: created, OrderUID: "b563feb7b2b84b6test2"}
+	amount, itemsCount := 1817, 0
+	tests := []struct {
+		name     string
+		query    string
+		setup    func(f fields)
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "success: first page",
+			query:    "?limit=2",
+			wantCode: http.StatusOK,
+			setup: func(f fields) {
+				page := &entity.OrderPage{
+					Orders: []*entity.Order{
+						{OrderUID: "b563feb7b2b84b6test1"},
+						{OrderUID: "b563feb7b2b84b6test2"},
+					},
+					Next: next,
+				}
+				f.interactor.EXPECT().List(gomock.Any(), entity.OrderListQuery{Limit: 2}).Return(page, nil)
+			},
+			wantBody: mustJSON(orderListResponse{
+				Orders: []entity.OrderSummary{
+					{OrderUID: "b563feb7b2b84b6test1"},
+					{OrderUID: "b563feb7b2b84b6test2"},
+				},
+				NextCursor: next.Encode(),
+			}),
+		},
+		{
+			name:     "success: last page with summary",
+			query:    "?cursor=" + next.Encode() + "&sort=date_created&summary=true",
+			wantCode: http.StatusOK,
+			setup: func(f fields) {
+				page := &entity.OrderPage{
+					Orders: []*entity.Order{
+						{OrderUID: "b563feb7b2b84b6test3", DateCreated: created, Payment: entity.Payment{Amount: amount, Currency: "USD"}},
+					},
+				}
+				f.interactor.EXPECT().List(gomock.Any(), entity.OrderListQuery{Sort: entity.OrderSortDateCreated, After: next}).Return(page, nil)
+			},
+			wantBody: mustJSON(orderListResponse{
+				Orders: []entity.OrderSummary{
+					{OrderUID: "b563feb7b2b84b6test3", DateCreated: &created, Amount: &amount, Currency: "USD", ItemsCount: &itemsCount},
+				},
+			}),
+		},
+		{
+			name:     "success: empty",
+			query:    "?sort=order_uid&order=desc",
+			wantCode: http.StatusOK,
+			setup: func(f fields) {
+				f.interactor.EXPECT().List(gomock.Any(), entity.OrderListQuery{Sort: entity.OrderSortOrderUID, Desc: true}).Return(&entity.OrderPage{}, nil)
+			},
+			wantBody: `{"orders":[]}`,
+		},
+		{
+			name:     "fail: invalid limit",
+			query:    "?limit=ten",
+			wantCode: http.StatusBadRequest,
+			setup:    func(f fields) {},
+		},
+		{
+			name:     "fail: invalid cursor",
+			query:    "?cursor=@@@",
+			wantCode: http.StatusBadRequest,
+			setup:    func(f fields) {},
+		},
+		{
+			name:     "fail: invalid order",
+			query:    "?order=up",
+			wantCode: http.StatusBadRequest,
+			setup:    func(f fields) {},
+		},
+		{
+			name:     "fail: invalid query",
+			query:    "?limit=1000",
+			wantCode: http.StatusBadRequest,
+			setup: func(f fields) {
+				f.interactor.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("%w: limit", entity.ErrValidation))
+			},
+		},
+		{
+			name:     "fail: can't get orders",
+			wantCode: http.StatusInternalServerError,
+			setup: func(f fields) {
+				f.interactor.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("some error"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				interactor: usecase.NewMockOrderInteractor(ctrl),
+			}
+			h := &orderHandlers{
+				interactor: f.interactor,
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/orders/all"+tt.query, nil)
+
+			tt.setup(f)
+
+			h.GetAllHandler(c)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("GetAllHandler() code = %v, wantCode %v", w.Code, tt.wantCode)
+			}
+
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			if w.Body.String() != tt.wantBody {
+				t.Errorf("GetAllHandler() body = %s, want %s", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+// mustJSON returns the JSON encoding of v.
+func mustJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+func TestOrderHandlers_SearchHandler(t *testing.T) {
+	type fields struct {
+		interactor *usecase.MockOrderInteractor
+	}
+	from := MustParseTime(time.RFC3339, "2021-11-01T00:00:00Z")
+	to := MustParseTime(time.RFC3339, "2021-12-01T00:00:00Z")
+	nmID := 2389212
+	tests := []struct {
+		name     string
+		query    string
+		setup    func(f fields)
+		wantCode int
+		wantBody string
+	}{
+		{
+			name: "success: all filters",
+			query: "?track_number=WBILMTESTTRACK&customer_id=test&delivery_service=meest&locale=en" +
+				"&created_from=2021-11-01T00:00:00Z&created_to=2021-12-01T00:00:00Z" +
+				"&bank=alpha&provider=wbpay&currency=USD&phone=%2B9720000000&email=test@gmail.com&city=Kiryat+Mozkin" +
+				"&brand=Vivienne+Sabo&nm_id=2389212&limit=10&sort=order_uid",
+			wantCode: http.StatusOK,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Search(gomock.Any(), entity.OrderSearchQuery{
+					OrderListQuery: entity.OrderListQuery{Limit: 10, Sort: entity.OrderSortOrderUID},
+					Filter: entity.OrderFilter{
+						TrackNumber:     "WBILMTESTTRACK",
+						CustomerID:      "test",
+						DeliveryService: "meest",
+						Locale:          "en",
+						CreatedFrom:     &from,
+						CreatedTo:       &to,
+						PaymentBank:     "alpha",
+						PaymentProvider: "wbpay",
+						PaymentCurrency: "USD",
+						DeliveryPhone:   "+9720000000",
+						DeliveryEmail:   "test@gmail.com",
+						DeliveryCity:    "Kiryat Mozkin",
+						ItemBrand:       "Vivienne Sabo",
+						ItemNmID:        &nmID,
+					},
+				}).Return(&entity.OrderPage{Orders: []*entity.Order{{OrderUID: "b563feb7b2b84b6test"}}}, nil)
+			},
+			wantBody: `{"orders":[{"order_uid":"b563feb7b2b84b6test"}]}`,
+		},
+		{
+			name:     "fail: invalid date",
+			query:    "?created_from=yesterday",
+			wantCode: http.StatusBadRequest,
+			setup:    func(f fields) {},
+		},
+		{
+			name:     "fail: invalid nm_id",
+			query:    "?nm_id=abc",
+			wantCode: http.StatusBadRequest,
+			setup:    func(f fields) {},
+		},
+		{
+			name:     "fail: invalid query",
+			query:    "?created_from=2021-12-01T00:00:00Z&created_to=2021-11-01T00:00:00Z",
+			wantCode: http.StatusBadRequest,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Search(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("%w: range", entity.ErrValidation))
+			},
+		},
+		{
+			name:     "fail: can't search orders",
+			wantCode: http.StatusInternalServerError,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Search(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("some error"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				interactor: usecase.NewMockOrderInteractor(ctrl),
+			}
+			h := &orderHandlers{
+				interactor: f.interactor,
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/orders/search"+tt.query, nil)
+
+			tt.setup(f)
+
+			h.SearchHandler(c)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("SearchHandler() code = %v, wantCode %v", w.Code, tt.wantCode)
+			}
+
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			if w.Body.String() != tt.wantBody {
+				t.Errorf("SearchHandler() body = %s, want %s", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestOrderHandlers_UpdateHandler(t *testing.T) {
+	type fields struct {
+		interactor *usecase.MockOrderInteractor
+	}
+	tests := []struct {
+		name     string
+		body     string
+		setup    func(f fields)
+		wantCode int
+	}{
+		{
+			name:     "success",
+			body:     `{"order_uid":"b563feb7b2b84b6test","locale":"ru"}`,
+			wantCode: http.StatusOK,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Update(gomock.Any(), "b563feb7b2b84b6test", &entity.Order{OrderUID: "b563feb7b2b84b6test", Locale: "ru"}, 2).Return(nil)
+			},
+		},
+		{
+			name:     "fail: malformed body",
+			body:     `{"order_uid":`,
+			wantCode: http.StatusBadRequest,
+			setup:    func(f fields) {},
+		},
+		{
+			name:     "fail: invalid order",
+			body:     `{}`,
+			wantCode: http.StatusBadRequest,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Update(gomock.Any(), "b563feb7b2b84b6test", gomock.Any(), 2).Return(fmt.Errorf("%w: some field", entity.ErrValidation))
+			},
+		},
+		{
+			name:     "fail: not found",
+			body:     `{}`,
+			wantCode: http.StatusNotFound,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Update(gomock.Any(), "b563feb7b2b84b6test", gomock.Any(), 2).Return(entity.ErrNotFound)
+			},
+		},
+		{
+			name:     "fail: conflict",
+			body:     `{}`,
+			wantCode: http.StatusConflict,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Update(gomock.Any(), "b563feb7b2b84b6test", gomock.Any(), 2).Return(fmt.Errorf("%w: duplicate", entity.ErrConflict))
+			},
+		},
+		{
+			name:     "fail: can't update order",
+			body:     `{}`,
+			wantCode: http.StatusInternalServerError,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Update(gomock.Any(), "b563feb7b2b84b6test", gomock.Any(), 2).Return(fmt.Errorf("some error"))
+			},
+		},
+		{
+			name:     "fail: version mismatch",
+			body:     `{}`,
+			wantCode: http.StatusPreconditionFailed,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Update(gomock.Any(), "b563feb7b2b84b6test", gomock.Any(), 2).
+					Return(fmt.Errorf("%w: order is at version 3", entity.ErrVersionMismatch))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				interactor: usecase.NewMockOrderInteractor(ctrl),
+			}
+			h := &orderHandlers{
+				interactor: f.interactor,
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/orders/id/b563feb7b2b84b6test", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Request.Header.Set("If-Match", `"2"`)
+			c.Params = gin.Params{{Key: "uid", Value: "b563feb7b2b84b6test"}}
+
+			tt.setup(f)
+
+			h.UpdateHandler(c)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("UpdateHandler() code = %v, wantCode %v", w.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestOrderHandlers_PatchHandler(t *testing.T) {
+	type fields struct {
+		interactor *usecase.MockOrderInteractor
+	}
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		setup       func(f fields)
+		wantCode    int
+		wantBody    string
+	}{
+		{
+			name:        "success",
+			contentType: "application/merge-patch+json",
+			body:        `{"locale":"ru"}`,
+			wantCode:    http.StatusOK,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Patch(gomock.Any(), "b563feb7b2b84b6test", []byte(`{"locale":"ru"}`), 2).
+					Return(&entity.Order{OrderUID: "b563feb7b2b84b6test", Locale: "ru"}, nil)
+			},
+			wantBody: mustJSON(&entity.Order{OrderUID: "b563feb7b2b84b6test", Locale: "ru"}),
+		},
+		{
+			name:        "success: application/json",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"locale":"ru"}`,
+			wantCode:    http.StatusOK,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Patch(gomock.Any(), "b563feb7b2b84b6test", gomock.Any(), 2).
+					Return(&entity.Order{OrderUID: "b563feb7b2b84b6test"}, nil)
+			},
+			wantBody: mustJSON(&entity.Order{OrderUID: "b563feb7b2b84b6test"}),
+		},
+		{
+			name:        "fail: unsupported content type",
+			contentType: "text/plain",
+			body:        `{"locale":"ru"}`,
+			wantCode:    http.StatusUnsupportedMediaType,
+			setup:       func(f fields) {},
+		},
+		{
+			name:        "fail: invalid patch",
+			contentType: "application/merge-patch+json",
+			body:        `{"locale":`,
+			wantCode:    http.StatusBadRequest,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Patch(gomock.Any(), "b563feb7b2b84b6test", gomock.Any(), 2).Return(nil, fmt.Errorf("%w: can't apply patch", entity.ErrValidation))
+			},
+		},
+		{
+			name:        "fail: not found",
+			contentType: "application/merge-patch+json",
+			body:        `{"locale":"ru"}`,
+			wantCode:    http.StatusNotFound,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Patch(gomock.Any(), "b563feb7b2b84b6test", gomock.Any(), 2).Return(nil, entity.ErrNotFound)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				interactor: usecase.NewMockOrderInteractor(ctrl),
+			}
+			h := &orderHandlers{
+				interactor: f.interactor,
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPatch, "/orders/id/b563feb7b2b84b6test", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", tt.contentType)
+			c.Request.Header.Set("If-Match", `"2"`)
+			c.Params = gin.Params{{Key: "uid", Value: "b563feb7b2b84b6test"}}
+
+			tt.setup(f)
+
+			h.PatchHandler(c)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("PatchHandler() code = %v, wantCode %v", w.Code, tt.wantCode)
+			}
+
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			if w.Body.String() != tt.wantBody {
+				t.Errorf("PatchHandler() body = %s, want %s", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
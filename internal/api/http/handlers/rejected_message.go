@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"L0/internal/entity"
+	"L0/internal/usecase"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rejectedMessageHandlers represents the implementation of RejectedMessageHandlers interface.
+type rejectedMessageHandlers struct {
+	interactor usecase.RejectedMessageInteractor
+}
+
+// NewRejectedMessageHandlers creates a new instance of rejectedMessageHandlers.
+func NewRejectedMessageHandlers(interactor usecase.RejectedMessageInteractor) *rejectedMessageHandlers {
+	return &rejectedMessageHandlers{
+		interactor: interactor,
+	}
+}
+
+// GetAllHandler handles requests to list rejected messages.
+func (h *rejectedMessageHandlers) GetAllHandler(c *gin.Context) {
+	ctx := context.Background()
+
+	msgs, err := h.interactor.GetAll(ctx)
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't get rejected messages: %w", err))
+		return
+	}
+
+	if msgs == nil {
+		msgs = []*entity.RejectedMessage{}
+	}
+
+	c.JSON(http.StatusOK, msgs)
+}
+
+// GetByIdHandler handles requests to inspect a rejected message by its ID.
+func (h *rejectedMessageHandlers) GetByIdHandler(c *gin.Context) {
+	ctx := context.Background()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, fmt.Errorf("invalid id: %w", err))
+		return
+	}
+
+	msg, err := h.interactor.GetById(ctx, id)
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't get rejected message: %w", err))
+		return
+	}
+
+	if msg == nil {
+		abortWithStatus(c, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, msg)
+}
+
+// RedriveHandler handles requests to publish a rejected message again.
+func (h *rejectedMessageHandlers) RedriveHandler(c *gin.Context) {
+	ctx := context.Background()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, fmt.Errorf("invalid id: %w", err))
+		return
+	}
+
+	err = h.interactor.Redrive(ctx, id)
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't redrive rejected message: %w", err))
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// DiscardHandler handles requests to discard a rejected message.
+func (h *rejectedMessageHandlers) DiscardHandler(c *gin.Context) {
+	ctx := context.Background()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, fmt.Errorf("invalid id: %w", err))
+		return
+	}
+
+	err = h.interactor.Discard(ctx, id)
+	if err != nil {
+		abortWithDomainError(c, fmt.Errorf("can't discard rejected message: %w", err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"L0/internal/entity"
+	"L0/internal/usecase"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	gomock "github.com/golang/mock/gomock"
+)
+
+func TestRejectedMessageHandlers_RedriveHandler(t *testing.T) {
+	type fields struct {
+		interactor *usecase.MockRejectedMessageInteractor
+	}
+	tests := []struct {
+		name     string
+		id       string
+		setup    func(f fields)
+		wantCode int
+	}{
+		{
+			name:     "success",
+			id:       "1",
+			wantCode: http.StatusAccepted,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Redrive(gomock.Any(), int64(1)).Return(nil)
+			},
+		},
+		{
+			name:     "fail: invalid id",
+			id:       "abc",
+			wantCode: http.StatusBadRequest,
+			setup:    func(f fields) {},
+		},
+		{
+			name:     "fail: not found",
+			id:       "1",
+			wantCode: http.StatusNotFound,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Redrive(gomock.Any(), int64(1)).Return(fmt.Errorf("wrapped: %w", entity.ErrNotFound))
+			},
+		},
+		{
+			name:     "fail: can't redrive",
+			id:       "1",
+			wantCode: http.StatusInternalServerError,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Redrive(gomock.Any(), int64(1)).Return(fmt.Errorf("some error"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				interactor: usecase.NewMockRejectedMessageInteractor(ctrl),
+			}
+			h := NewRejectedMessageHandlers(f.interactor)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: tt.id}}
+
+			tt.setup(f)
+
+			h.RedriveHandler(c)
+
+			if c.Writer.Status() != tt.wantCode {
+				t.Errorf("RedriveHandler() code = %v, wantCode %v", c.Writer.Status(), tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRejectedMessageHandlers_DiscardHandler(t *testing.T) {
+	type fields struct {
+		interactor *usecase.MockRejectedMessageInteractor
+	}
+	tests := []struct {
+		name     string
+		setup    func(f fields)
+		wantCode int
+	}{
+		{
+			name:     "success",
+			wantCode: http.StatusNoContent,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Discard(gomock.Any(), int64(1)).Return(nil)
+			},
+		},
+		{
+			name:     "fail: not found",
+			wantCode: http.StatusNotFound,
+			setup: func(f fields) {
+				f.interactor.EXPECT().Discard(gomock.Any(), int64(1)).Return(entity.ErrNotFound)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				interactor: usecase.NewMockRejectedMessageInteractor(ctrl),
+			}
+			h := NewRejectedMessageHandlers(f.interactor)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			tt.setup(f)
+
+			h.DiscardHandler(c)
+
+			if c.Writer.Status() != tt.wantCode {
+				t.Errorf("DiscardHandler() code = %v, wantCode %v", c.Writer.Status(), tt.wantCode)
+			}
+		})
+	}
+}
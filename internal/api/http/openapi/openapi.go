@@ -0,0 +1,135 @@
+// Package openapi provides the OpenAPI document of the HTTP API.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// Prefix is the path prefix of the routes described by the document.
+const Prefix = "/orders"
+
+// SwaggerUIAssetsPath is the path prefix the Swagger UI page loads its scripts and styles from.
+const SwaggerUIAssetsPath = "/docs/assets"
+
+// mergePatchContentType is the media type of JSON Merge Patch documents (RFC 7396).
+const mergePatchContentType = "application/merge-patch+json"
+
+//go:embed openapi.json
+var spec []byte
+
+//go:embed swagger.html
+var swaggerUI []byte
+
+// ginParam matches the path parameters of gin routes.
+var ginParam = regexp.MustCompile(`[:*]([^/]+)`)
+
+func init() {
+	// Merge patches are JSON documents
+	openapi3filter.RegisterBodyDecoder(mergePatchContentType, openapi3filter.RegisteredBodyDecoder("application/json"))
+}
+
+// Spec returns the OpenAPI document in JSON.
+func Spec() []byte {
+	return spec
+}
+
+// SwaggerUI returns the Swagger UI page showing the OpenAPI document served at /openapi.json.
+func SwaggerUI() []byte {
+	return swaggerUI
+}
+
+// SwaggerUIAssets returns the bundled scripts and styles of Swagger UI, served under SwaggerUIAssetsPath.
+func SwaggerUIAssets() fs.FS {
+	return swaggerFiles.FS
+}
+
+// Load parses and validates the OpenAPI document.
+func Load() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("can't load openapi document: %w", err)
+	}
+
+	err = doc.Validate(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("invalid openapi document: %w", err)
+	}
+
+	return doc, nil
+}
+
+// PathTemplate converts the path of a gin route to an OpenAPI path template, /orders/id/:uid to /orders/id/{uid}.
+func PathTemplate(path string) string {
+	return ginParam.ReplaceAllString(path, "{$1}")
+}
+
+// CheckRoutes compares the routes under Prefix with the operations of the document.
+// Returns an error listing the routes missing from the document and the operations without a route.
+func CheckRoutes(doc *openapi3.T, routes gin.RoutesInfo) error {
+	routed := make(map[string]bool)
+	var errs []string
+	for _, route := range routes {
+		if route.Path != Prefix && !strings.HasPrefix(route.Path, Prefix+"/") {
+			continue
+		}
+
+		path := PathTemplate(route.Path)
+		routed[route.Method+" "+path] = true
+
+		item := doc.Paths.Find(path)
+		if item == nil || item.GetOperation(route.Method) == nil {
+			errs = append(errs, fmt.Sprintf("route %s %s is not documented", route.Method, route.Path))
+		}
+	}
+
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			if !routed[method+" "+path] {
+				errs = append(errs, fmt.Sprintf("operation %s %s has no route", method, path))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// Route returns the documented route of a request matched by a gin route, nil if it is not documented.
+func Route(doc *openapi3.T, method, path string) *routers.Route {
+	template := PathTemplate(path)
+
+	item := doc.Paths.Find(template)
+	if item == nil {
+		return nil
+	}
+
+	operation := item.GetOperation(method)
+	if operation == nil {
+		return nil
+	}
+
+	return &routers.Route{
+		Spec:      doc,
+		Path:      template,
+		PathItem:  item,
+		Method:    method,
+		Operation: operation,
+	}
+}
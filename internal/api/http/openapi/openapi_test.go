@@ -0,0 +1,85 @@
+package openapi
+
+import (
+	"io/fs"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLoad(t *testing.T) {
+	doc, err := Load()
+	if err != nil {
+		t.Errorf("Load() error = %v", err)
+		return
+	}
+	if doc.Paths.Find("/orders/id/{uid}") == nil {
+		t.Errorf("Load() document misses /orders/id/{uid}")
+	}
+}
+
+func TestPathTemplate(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/orders", want: "/orders"},
+		{path: "/orders/id/:uid", want: "/orders/id/{uid}"},
+		{path: "/orders/id/:uid/history/:version", want: "/orders/id/{uid}/history/{version}"},
+		{path: "/static/*filepath", want: "/static/{filepath}"},
+	}
+	for _, tt := range tests {
+		if got := PathTemplate(tt.path); got != tt.want {
+			t.Errorf("PathTemplate(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCheckRoutes(t *testing.T) {
+	doc, err := Load()
+	if err != nil {
+		t.Errorf("Load() error = %v", err)
+		return
+	}
+
+	// Every documented operation is routed
+	var routes gin.RoutesInfo
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			routes = append(routes, gin.RouteInfo{Method: method, Path: strings.NewReplacer("{", ":", "}", "").Replace(path)})
+		}
+	}
+	routes = append(routes, gin.RouteInfo{Method: http.MethodGet, Path: "/admin/cache/reconciliation"})
+	if err := CheckRoutes(doc, routes); err != nil {
+		t.Errorf("CheckRoutes() error = %v", err)
+	}
+
+	// An undocumented route and an operation without a route are reported
+	drifted := append(gin.RoutesInfo{{Method: http.MethodPost, Path: "/orders/new"}}, routes[1:]...)
+	err = CheckRoutes(doc, drifted)
+	if err == nil || !strings.Contains(err.Error(), "route POST /orders/new is not documented") ||
+		!strings.Contains(err.Error(), "has no route") {
+		t.Errorf("CheckRoutes() error = %v", err)
+	}
+}
+
+func TestSwaggerUI(t *testing.T) {
+	// The page loads nothing but the bundled assets
+	refs := regexp.MustCompile(`(?:href|src)="([^"]+)"`).FindAllStringSubmatch(string(SwaggerUI()), -1)
+	if len(refs) == 0 {
+		t.Errorf("SwaggerUI() page references no assets")
+	}
+	for _, ref := range refs {
+		name, ok := strings.CutPrefix(ref[1], SwaggerUIAssetsPath+"/")
+		if !ok {
+			t.Errorf("SwaggerUI() page references %s outside of %s", ref[1], SwaggerUIAssetsPath)
+			continue
+		}
+		if _, err := fs.Stat(SwaggerUIAssets(), name); err != nil {
+			t.Errorf("SwaggerUI() page references %s, error = %v", ref[1], err)
+		}
+	}
+}
@@ -3,15 +3,18 @@ package http
 
 import (
 	"fmt"
-	"net/http"
 	"time"
 
 	"L0/internal/api/http/handlers"
+	"L0/internal/api/http/openapi"
 	"L0/internal/cache"
 	"L0/internal/db"
+	"L0/internal/entity"
+	"L0/internal/invalidation"
 	"L0/internal/nats"
 	"L0/internal/repository"
 	"L0/internal/usecase"
+	"L0/internal/validator"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -22,35 +25,60 @@ import (
 
 // routerHandlers contains handlers for router.
 type routerHandlers struct {
-	orderHandlers handlers.OrderHandlers
+	orderHandlers           handlers.OrderHandlers
+	rejectedMessageHandlers handlers.RejectedMessageHandlers
+	cacheHandlers           handlers.CacheHandlers
+	openAPIHandlers         handlers.OpenAPIHandlers
 }
 
 // router represents an HTTP router.
 type router struct {
-	router   *gin.Engine
-	db       *sqlx.DB
-	handlers routerHandlers
-	logger   *zap.Logger
-	cache    cache.Cache
-	connect  stan.Conn
-	subject  string
+	router      *gin.Engine
+	db          *sqlx.DB
+	handlers    routerHandlers
+	logger      *zap.Logger
+	cache       cache.OrderCache
+	validator   validator.OrderValidator
+	connect     stan.Conn
+	subject     string
+	broadcaster invalidation.Broadcaster
+	ingestion   usecase.IngestionMode
+	policy      entity.UpdatePolicy
+	debug       bool
 }
 
 // NewRouter creates a new instance of HTTP router.
-func NewRouter(db *sqlx.DB, logger *zap.Logger, cache cache.Cache, connect stan.Conn, subject string) *router {
+func NewRouter(
+	db *sqlx.DB,
+	logger *zap.Logger,
+	cache cache.OrderCache,
+	validator validator.OrderValidator,
+	connect stan.Conn,
+	subject string,
+	broadcaster invalidation.Broadcaster,
+	ingestion usecase.IngestionMode,
+	policy entity.UpdatePolicy,
+	debug bool,
+) *router {
 	return &router{
-		router:  gin.New(),
-		db:      db,
-		logger:  logger,
-		cache:   cache,
-		connect: connect,
-		subject: subject,
+		router:      gin.New(),
+		db:          db,
+		logger:      logger,
+		cache:       cache,
+		validator:   validator,
+		connect:     connect,
+		subject:     subject,
+		broadcaster: broadcaster,
+		ingestion:   ingestion,
+		policy:      policy,
+		debug:       debug,
 	}
 }
 
 // Init initializes the HTTP router.
 func (r *router) Init() error {
 	r.router.Use(
+		handlers.RequestIDMiddleware,
 		gin.Logger(),
 		gin.CustomRecovery(r.recovery),
 	)
@@ -64,23 +92,30 @@ func (r *router) Init() error {
 
 // recovery recovers from panics in HTTP handlers.
 func (r *router) recovery(c *gin.Context, recovered interface{}) {
-	defer func() {
-		if e := recover(); e != nil {
-			r.logger.Fatal("http server panic", zap.Any("panic", recovered))
-			c.AbortWithStatus(http.StatusInternalServerError)
-		}
-	}()
+	r.logger.Error("http server panic",
+		zap.Any("panic", recovered),
+		zap.String("request_id", c.Writer.Header().Get(handlers.RequestIDHeader)),
+	)
+	handlers.InternalErrorHandler(c)
 }
 
 // registerRoutes registers routes in the HTTP router.
+// The order routes are validated against the OpenAPI document, which must describe all of them.
 func (r *router) registerRoutes() error {
-	r.router.NoMethod(handlers.NotImplementedHandler)
-	r.router.NoRoute(handlers.NotImplementedHandler)
+	doc, err := openapi.Load()
+	if err != nil {
+		return err
+	}
+
+	r.router.HandleMethodNotAllowed = true
+	r.router.NoMethod(handlers.MethodNotAllowedHandler)
+	r.router.NoRoute(handlers.NotFoundHandler)
 
 	corsMiddleware := cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
-		AllowHeaders:     []string{"Authorization", "Content-Type"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		AllowHeaders:     []string{"Authorization", "Content-Type", "If-Match", "If-None-Match", handlers.RequestIDHeader},
+		ExposeHeaders:    []string{"ETag", handlers.RequestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	})
@@ -91,21 +126,72 @@ func (r *router) registerRoutes() error {
 
 	pgSource := db.NewSource(r.db)
 	orderRepository := repository.NewOrderRepository(pgSource)
-	orderInteractor := usecase.NewOrderInteractor(orderRepository, r.cache)
+	rejectedMessageRepository := repository.NewRejectedMessageRepository(pgSource)
+	ingestionRepository := repository.NewIngestionRepository(pgSource)
+	orderInteractor := usecase.NewOrderInteractor(orderRepository, r.cache, r.validator, r.broadcaster)
 	natsService := nats.NewNatsService(
-		orderRepository,
-		r.cache,
+		orderInteractor,
+		rejectedMessageRepository,
+		ingestionRepository,
+		r.validator,
 		r.connect,
 		r.subject,
+		nats.SubscriptionConfig{},
+		r.logger,
 	)
-	r.handlers.orderHandlers = handlers.NewOrderHandlers(orderInteractor, natsService)
+	rejectedMessageInteractor := usecase.NewRejectedMessageInteractor(rejectedMessageRepository, natsService)
+	ingestionInteractor := usecase.NewIngestionInteractor(orderInteractor, ingestionRepository, r.validator, natsService, r.ingestion, r.policy)
+	r.handlers.orderHandlers = handlers.NewOrderHandlers(orderInteractor, ingestionInteractor, natsService)
+	r.handlers.rejectedMessageHandlers = handlers.NewRejectedMessageHandlers(rejectedMessageInteractor)
+	r.handlers.cacheHandlers = handlers.NewCacheHandlers(usecase.NewCacheInteractor(orderRepository, r.cache))
+	r.handlers.openAPIHandlers = handlers.NewOpenAPIHandlers()
+
+	r.router.GET("/openapi.json", r.handlers.openAPIHandlers.SpecHandler)
+	r.router.GET("/docs", r.handlers.openAPIHandlers.SwaggerUIHandler)
+	r.router.GET(openapi.SwaggerUIAssetsPath+"/*filepath", r.handlers.openAPIHandlers.SwaggerUIAssetHandler)
 
-	orderGroup := r.router.Group("/orders")
+	// Responses are validated in debug mode only, since they are held back until validated
+	orderGroup := r.router.Group(openapi.Prefix, handlers.NewValidationMiddleware(doc, r.debug))
 	orderGroup.GET("/", r.handlers.orderHandlers.GetHTMLOrderHandler)
+	orderGroup.POST("", r.handlers.orderHandlers.CreateHandler)
+	orderGroup.GET("/ingestion/:uid", r.handlers.orderHandlers.IngestionStatusHandler)
 	orderGroup.GET("/id/:uid", r.handlers.orderHandlers.GetByIdHandler)
 	orderGroup.GET("/all", r.handlers.orderHandlers.GetAllHandler)
-	orderGroup.POST("/new", r.handlers.orderHandlers.CreateHandler)
+	orderGroup.GET("/search", r.handlers.orderHandlers.SearchHandler)
+	orderGroup.GET("/track/:track_number", r.handlers.orderHandlers.GetByTrackNumberHandler)
+	orderGroup.GET("/customer/:customer_id", r.handlers.orderHandlers.ListByCustomerHandler)
+	orderGroup.GET("/contact", r.handlers.orderHandlers.ListByContactHandler)
+	orderGroup.PUT("/id/:uid", r.handlers.orderHandlers.UpdateHandler)
+	orderGroup.PATCH("/id/:uid", r.handlers.orderHandlers.PatchHandler)
 	orderGroup.DELETE("/id/:uid", r.handlers.orderHandlers.DeleteHandler)
+	orderGroup.POST("/id/:uid/restore", r.handlers.orderHandlers.RestoreHandler)
+	orderGroup.POST("/id/:uid/status", r.handlers.orderHandlers.ChangeStatusHandler)
+	orderGroup.GET("/id/:uid/status", r.handlers.orderHandlers.GetStatusHistoryHandler)
+	orderGroup.GET("/id/:uid/history", r.handlers.orderHandlers.HistoryHandler)
+	orderGroup.GET("/id/:uid/history/:version", r.handlers.orderHandlers.VersionHandler)
+	orderGroup.GET("/id/:uid/diff", r.handlers.orderHandlers.DiffHandler)
+	orderGroup.GET("/deleted", r.handlers.orderHandlers.GetDeletedHandler)
+
+	rejectedGroup := orderGroup.Group("/rejected")
+	rejectedGroup.GET("", r.handlers.rejectedMessageHandlers.GetAllHandler)
+	rejectedGroup.GET("/:id", r.handlers.rejectedMessageHandlers.GetByIdHandler)
+	rejectedGroup.POST("/:id/redrive", r.handlers.rejectedMessageHandlers.RedriveHandler)
+	rejectedGroup.DELETE("/:id", r.handlers.rejectedMessageHandlers.DiscardHandler)
+
+	// Random orders are generated for development only
+	if r.debug {
+		devGroup := r.router.Group("/dev")
+		devGroup.POST("/orders/random", r.handlers.orderHandlers.GenerateHandler)
+	}
+
+	adminGroup := r.router.Group("/admin")
+	adminGroup.GET("/cache/reconciliation", r.handlers.cacheHandlers.GetReconciliationHandler)
+	adminGroup.POST("/cache/reconciliation", r.handlers.cacheHandlers.ReconcileHandler)
+
+	err = openapi.CheckRoutes(doc, r.router.Routes())
+	if err != nil {
+		return fmt.Errorf("openapi document doesn't match routes: %w", err)
+	}
 
 	return nil
 }
@@ -12,6 +12,10 @@ import (
 	"go.uber.org/zap"
 
 	"L0/internal/cache"
+	"L0/internal/entity"
+	"L0/internal/invalidation"
+	"L0/internal/usecase"
+	"L0/internal/validator"
 )
 
 // RequestTimeOut defines the timeout duration for HTTP requests.
@@ -38,22 +42,29 @@ type server struct {
 }
 
 // NewServer creates a new instance of the HTTP server.
-// It takes the server address, database connection, logger, and cache as input parameters.
+// It takes the server address, database connection, logger, cache, order validator, NATS connection,
+// the broadcaster of order changes, the ingestion mode of submitted orders, the update policy for changed
+// orders stored in the direct mode and the developer mode flag as input parameters.
 // Returns the HTTP server instance.
 func NewServer(
 	addr string,
 	db *sqlx.DB,
 	logger *zap.Logger,
-	cache cache.Cache,
+	cache cache.OrderCache,
+	validator validator.OrderValidator,
 	connect stan.Conn,
 	subject string,
+	broadcaster invalidation.Broadcaster,
+	ingestion usecase.IngestionMode,
+	policy entity.UpdatePolicy,
+	debug bool,
 ) *server {
 	s := &server{
 		db:     db,
 		logger: logger,
 	}
 
-	r := NewRouter(db, logger, cache, connect, subject)
+	r := NewRouter(db, logger, cache, validator, connect, subject, broadcaster, ingestion, policy, debug)
 	err := r.Init()
 	if err != nil {
 		s.logger.Error("can't init router:", zap.Error(err))
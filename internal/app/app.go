@@ -5,8 +5,12 @@ import (
 	"L0/internal/api/http"
 	"L0/internal/cache"
 	"L0/internal/db"
+	"L0/internal/entity"
+	"L0/internal/invalidation"
 	"L0/internal/nats"
 	"L0/internal/repository"
+	"L0/internal/usecase"
+	"L0/internal/validator"
 	"context"
 	"fmt"
 	"sync"
@@ -22,7 +26,10 @@ type App struct {
 	dbConn     *sqlx.DB
 	logger     *zap.Logger
 	httpServer http.Server
-	cache      cache.Cache
+	cache      cache.OrderCache
+	validator  validator.OrderValidator
+	// watermark tracks the sequence up to which every order message is handled, recorded in cache snapshots.
+	watermark *nats.Watermark
 }
 
 // NewApp creates a new instance of the application.
@@ -30,7 +37,14 @@ func NewApp(cfg *config.Config, logger *zap.Logger) *App {
 	return &App{
 		config: cfg,
 		logger: logger,
-		cache:  cache.NewCache(),
+		cache: cache.NewOrderCache(cache.Options[*entity.Order]{
+			MaxEntries: cfg.Cache.MaxEntries,
+			MaxBytes:   cfg.Cache.MaxBytes,
+			TTL:        cfg.Cache.TTL,
+			Policy:     cache.Policy(cfg.Cache.Policy),
+		}, cfg.Cache.MissTTL),
+		validator: validator.NewOrderValidator(validator.Mode(cfg.Validation.Mode)),
+		watermark: nats.NewWatermark(),
 	}
 }
 
@@ -65,6 +79,26 @@ func (a *App) Start(ctx context.Context) {
 		logger.Error("db migration error", zap.Error(err))
 	}
 
+	// Initialize repositories
+	pgSource := db.NewSource(a.dbConn)
+	orderRepository := repository.NewOrderRepository(pgSource)
+	rejectedMessageRepository := repository.NewRejectedMessageRepository(pgSource)
+	ingestionRepository := repository.NewIngestionRepository(pgSource)
+
+	// Initialize cache invalidation between instances
+	instanceID := a.config.Invalidation.InstanceID
+	if instanceID == "" {
+		instanceID, err = invalidation.NewInstanceID()
+		if err != nil {
+			logger.Fatal("can't generate instance id", zap.Error(err))
+		}
+	}
+	bus, err := a.initInvalidationBus(instanceID)
+	if err != nil {
+		logger.Error("can't init invalidation bus, cache invalidation is disabled", zap.Error(err))
+	}
+	invalidator := invalidation.NewService(bus, instanceID, a.cache, orderRepository, logger)
+
 	wg := &sync.WaitGroup{}
 
 	// Start HTTP server
@@ -87,7 +121,19 @@ func (a *App) Start(ctx context.Context) {
 			logger.Error("NATS connection error", zap.Error(err))
 			return
 		}
-		a.httpServer = http.NewServer(addr, a.dbConn, logger, a.cache, conn, a.config.Nats.Subject)
+		a.httpServer = http.NewServer(
+			addr,
+			a.dbConn,
+			logger,
+			a.cache,
+			a.validator,
+			conn,
+			a.config.Nats.Subject,
+			invalidator,
+			usecase.IngestionMode(a.config.Ingestion.Mode),
+			entity.UpdatePolicy(a.config.Nats.UpdatePolicy),
+			a.config.Debug,
+		)
 		if a.httpServer == nil {
 			cancelApp()
 			logger.Fatal("can't create http server")
@@ -102,14 +148,57 @@ func (a *App) Start(ctx context.Context) {
 		}
 	}()
 
-	// Initialize order repository
-	orderRepository := repository.NewOrderRepository(db.NewSource(a.dbConn))
+	orderInteractor := usecase.NewOrderInteractor(orderRepository, a.cache, a.validator, invalidator)
 
 	// Load cache
-	if err := a.cache.Load(appCtx, orderRepository); err != nil {
-		logger.Error("can't load cache", zap.Error(err))
+	snapshotSequence := a.loadCache(appCtx, orderRepository)
+
+	// Start removal of expired cached orders
+	if a.config.Cache.TTL > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runCacheExpiry(appCtx, a.config.Cache.ExpiryInterval)
+		}()
+	}
+
+	// Start applying cache invalidations of other instances
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		logger.Info("listen to cache invalidations", zap.String("instance", instanceID), zap.String("bus", a.config.Invalidation.Bus))
+		if err := invalidator.Listen(appCtx); err != nil {
+			logger.Error("cache invalidation error", zap.Error(err))
+		}
+	}()
+
+	// Start cache snapshots
+	if a.config.Cache.SnapshotPath != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runCacheSnapshots(appCtx, a.config.Cache.SnapshotPath, a.config.Cache.SnapshotInterval)
+		}()
+	}
+
+	// Start reconciliation of the cache with the database
+	if a.config.Cache.ReconcileInterval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runCacheReconciliation(appCtx, usecase.NewCacheInteractor(orderRepository, a.cache), a.config.Cache.ReconcileInterval)
+		}()
+	}
+
+	// Start purging of deleted orders
+	if a.config.Purge.Retention > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runPurge(appCtx, orderInteractor, a.config.Purge.Retention, a.config.Purge.Interval)
+		}()
 	}
-	logger.Info("load cache")
 
 	// Start NATS subscription
 	wg.Add(1)
@@ -127,14 +216,37 @@ func (a *App) Start(ctx context.Context) {
 		}
 
 		natsService := nats.NewNatsService(
-			orderRepository,
-			a.cache,
+			orderInteractor,
+			rejectedMessageRepository,
+			ingestionRepository,
+			a.validator,
 			conn,
 			a.config.Nats.Subject,
+			nats.SubscriptionConfig{
+				DurableName:       a.config.Nats.DurableName,
+				AckWait:           a.config.Nats.AckWait,
+				MaxInflight:       a.config.Nats.MaxInflight,
+				StartPosition:     snapshotStartPosition(a.config.Nats.StartPosition, snapshotSequence),
+				DeadLetterSubject: a.config.Nats.DeadLetterSubject,
+				MaxRedeliveries:   a.config.Nats.MaxRedeliveries,
+				UpdatePolicy:      entity.UpdatePolicy(a.config.Nats.UpdatePolicy),
+				Watermark:         a.watermark,
+			},
+			logger,
 		)
-		err = natsService.Subscribe(
-			context.Background(),
-		)
+
+		// Start the subscription to order status events
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := natsService.SubscribeStatus(appCtx, a.config.Nats.StatusSubject, a.config.Nats.StatusDurableName, orderInteractor)
+			if err != nil {
+				a.logger.Error("NATS status subscription error", zap.Error(err))
+			}
+		}()
+
+		err = natsService.Subscribe(appCtx)
 		if err != nil {
 			a.logger.Error("NATS subscription error", zap.Error(err))
 		}
@@ -167,7 +279,7 @@ func (a *App) initDb(
 	db, err := sqlx.ConnectContext(
 		ctx,
 		"postgres",
-		fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s", host, port, user, password, dbName, sslmode),
+		dataSourceName(host, port, dbName, user, password, sslmode),
 	)
 	if err != nil {
 		return nil, err
@@ -175,3 +287,20 @@ func (a *App) initDb(
 
 	return db, nil
 }
+
+// dataSourceName returns the connection string of the configured database.
+func (a *App) dataSourceName() string {
+	return dataSourceName(
+		a.config.DB.Host,
+		a.config.DB.Port,
+		a.config.DB.Name,
+		a.config.DB.Username,
+		a.config.DB.Password,
+		a.config.DB.SSLMode,
+	)
+}
+
+// dataSourceName returns the connection string of a database.
+func dataSourceName(host string, port int, dbName string, user string, password string, sslmode string) string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s", host, port, user, password, dbName, sslmode)
+}
@@ -0,0 +1,173 @@
+package app
+
+import (
+	"L0/internal/cache"
+	"L0/internal/repository"
+	"L0/internal/usecase"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// snapshotCatchUpMargin is subtracted from the time of a cache snapshot when the cache catches up with
+// the database. It covers transactions that started before the snapshot was taken and committed after it,
+// and the clock skew between the database and the application.
+const snapshotCatchUpMargin = time.Minute
+
+// errSnapshotStale is returned when a cache snapshot is older than the configured maximum age.
+var errSnapshotStale = errors.New("cache snapshot is stale")
+
+// runCacheExpiry removes expired orders from the cache once per interval until the context is canceled.
+func (a *App) runCacheExpiry(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		a.logger.Error("removal of expired cached orders is disabled", zap.Duration("interval", interval))
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if removed := a.cache.RemoveExpired(); removed > 0 {
+			a.logger.Debug("removed expired cached orders", zap.Int("count", removed))
+		}
+	}
+}
+
+// runCacheReconciliation compares the cache with the database and repairs the divergence once per interval
+// until the context is canceled. Divergence is logged as a warning, since the cache should never drift.
+func (a *App) runCacheReconciliation(ctx context.Context, caches usecase.CacheInteractor, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		report, err := caches.Reconcile(ctx)
+		if err != nil {
+			a.logger.Error("can't reconcile cache", zap.Error(err))
+			continue
+		}
+
+		fields := []zap.Field{
+			zap.Int("checked", report.Checked),
+			zap.Int("stale", report.Stale),
+			zap.Int("mismatched", report.Mismatched),
+			zap.Int("missing", report.Missing),
+			zap.Int("repaired", report.Repaired),
+			zap.Int("loaded", report.Loaded),
+			zap.Bool("complete", report.Complete),
+		}
+		if report.Drift() > 0 {
+			a.logger.Warn("cache drifted from database", fields...)
+		} else {
+			a.logger.Debug("cache matches database", fields...)
+		}
+	}
+}
+
+// loadCache fills the cache from the snapshot file, if it is configured, valid and fresh,
+// and falls back to loading the cache from the repository.
+// Returns the sequence of the last NATS message the restored snapshot reflects, zero if the cache is loaded
+// from the repository.
+func (a *App) loadCache(ctx context.Context, orderRepository repository.OrderRepository) uint64 {
+	if path := a.config.Cache.SnapshotPath; path != "" {
+		sequence, err := a.restoreCache(ctx, path, orderRepository)
+		if err == nil {
+			return sequence
+		}
+		if errors.Is(err, os.ErrNotExist) {
+			a.logger.Info("no cache snapshot, loading cache from database", zap.String("path", path))
+		} else {
+			a.logger.Warn("can't restore cache snapshot, loading cache from database", zap.String("path", path), zap.Error(err))
+		}
+	}
+
+	if err := a.cache.Load(ctx, orderRepository); err != nil {
+		a.logger.Error("can't load cache", zap.Error(err))
+	}
+	a.logger.Info("load cache", zap.Int("orders", a.cache.Len()))
+
+	return 0
+}
+
+// restoreCache fills the cache from the snapshot file and catches up with the orders changed since it was taken.
+// Returns the sequence of the last NATS message the snapshot reflects.
+func (a *App) restoreCache(ctx context.Context, path string, orderRepository repository.OrderRepository) (uint64, error) {
+	snapshot, err := cache.ReadSnapshot(path)
+	if err != nil {
+		return 0, err
+	}
+
+	age := time.Since(snapshot.TakenAt)
+	if maxAge := a.config.Cache.SnapshotMaxAge; maxAge > 0 && age > maxAge {
+		return 0, fmt.Errorf("%w: taken %s ago", errSnapshotStale, age.Round(time.Second))
+	}
+
+	err = a.cache.Restore(ctx, snapshot, snapshot.TakenAt.Add(-snapshotCatchUpMargin), orderRepository)
+	if err != nil {
+		return 0, err
+	}
+	a.watermark.Advance(snapshot.Sequence)
+
+	a.logger.Info("restore cache snapshot",
+		zap.Int("orders", a.cache.Len()),
+		zap.Time("taken_at", snapshot.TakenAt),
+		zap.Uint64("sequence", snapshot.Sequence),
+	)
+
+	return snapshot.Sequence, nil
+}
+
+// snapshotStartPosition returns the start position of a new durable subscription for a cache restored
+// from a snapshot reflecting the messages up to sequence. Replaying the whole channel is narrowed down
+// to the messages after the snapshot, any other configured position is kept.
+// An existing durable resumes after its last acknowledged message regardless of the start position.
+func snapshotStartPosition(configured string, sequence uint64) string {
+	if sequence == 0 || (configured != "" && configured != "all") {
+		return configured
+	}
+
+	return fmt.Sprintf("seq:%d", sequence+1)
+}
+
+// runCacheSnapshots writes a snapshot of the cache to the file at path once per interval until the context is canceled.
+func (a *App) runCacheSnapshots(ctx context.Context, path string, interval time.Duration) {
+	if interval <= 0 {
+		a.logger.Error("cache snapshots are disabled", zap.Duration("interval", interval))
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// The sequence is read first, so the snapshot reflects at least the messages up to it
+		snapshot := a.cache.Snapshot(a.watermark.Sequence())
+		if err := cache.WriteSnapshot(path, snapshot); err != nil {
+			a.logger.Error("can't write cache snapshot", zap.String("path", path), zap.Error(err))
+			continue
+		}
+		a.logger.Debug("wrote cache snapshot", zap.Int("orders", len(snapshot.Orders)), zap.Uint64("sequence", snapshot.Sequence))
+	}
+}
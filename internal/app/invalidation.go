@@ -0,0 +1,39 @@
+package app
+
+import (
+	"L0/internal/invalidation"
+	"fmt"
+
+	"github.com/nats-io/stan.go"
+)
+
+// Buses of cache invalidations.
+const (
+	invalidationBusNone     = "none"
+	invalidationBusNats     = "nats"
+	invalidationBusPostgres = "postgres"
+)
+
+// initInvalidationBus creates the configured bus of cache invalidations, nil if invalidation is disabled.
+// The NATS bus connects with its own client ID derived from the instance ID.
+func (a *App) initInvalidationBus(instance string) (invalidation.Bus, error) {
+	cfg := a.config.Invalidation
+	switch cfg.Bus {
+	case invalidationBusNone, "":
+		return nil, nil
+	case invalidationBusNats:
+		conn, err := stan.Connect(
+			a.config.Nats.ClusterID,
+			"invalidation-"+instance,
+			stan.NatsURL(fmt.Sprintf("nats://%s:%d", a.config.Nats.Host, a.config.Nats.Port)),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("can't connect to NATS: %w", err)
+		}
+		return invalidation.NewNatsBus(conn, cfg.Subject), nil
+	case invalidationBusPostgres:
+		return invalidation.NewPostgresBus(a.dbConn, a.dataSourceName(), cfg.Channel, a.logger), nil
+	default:
+		return nil, fmt.Errorf("unknown invalidation bus %q", cfg.Bus)
+	}
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS rejected_at_idx;
+
+DROP TABLE IF EXISTS rejected_messages;
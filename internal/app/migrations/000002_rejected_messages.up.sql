@@ -0,0 +1,12 @@
+-- Создание таблицы RejectedMessage
+CREATE TABLE IF NOT EXISTS rejected_messages (
+    id BIGSERIAL PRIMARY KEY,
+    subject VARCHAR(255),
+    sequence BIGINT,
+    data BYTEA,
+    category VARCHAR(255),
+    error TEXT,
+    rejected_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS rejected_at_idx ON rejected_messages (rejected_at);
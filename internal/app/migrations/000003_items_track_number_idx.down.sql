@@ -0,0 +1 @@
+DROP INDEX IF EXISTS items_track_number_idx;
@@ -0,0 +1,2 @@
+-- Индекс для пакетной загрузки товаров заказов по трек-номеру
+CREATE INDEX IF NOT EXISTS items_track_number_idx ON items (track_number);
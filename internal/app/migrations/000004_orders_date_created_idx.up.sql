@@ -0,0 +1,2 @@
+-- Индекс для постраничной выдачи заказов по дате создания
+CREATE INDEX IF NOT EXISTS orders_date_created_idx ON orders (date_created, order_uid);
@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS items_nm_id_idx;
+DROP INDEX IF EXISTS items_brand_idx;
+
+DROP INDEX IF EXISTS deliveries_city_idx;
+DROP INDEX IF EXISTS deliveries_email_idx;
+DROP INDEX IF EXISTS deliveries_phone_idx;
+
+DROP INDEX IF EXISTS payments_currency_idx;
+DROP INDEX IF EXISTS payments_provider_idx;
+DROP INDEX IF EXISTS payments_bank_idx;
+
+DROP INDEX IF EXISTS orders_payment_transaction_idx;
+DROP INDEX IF EXISTS orders_delivery_uid_idx;
+DROP INDEX IF EXISTS orders_locale_idx;
+DROP INDEX IF EXISTS orders_delivery_service_idx;
+DROP INDEX IF EXISTS orders_customer_id_idx;
+DROP INDEX IF EXISTS orders_track_number_idx;
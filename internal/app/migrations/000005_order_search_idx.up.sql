@@ -0,0 +1,18 @@
+-- Индексы для поиска заказов
+CREATE INDEX IF NOT EXISTS orders_track_number_idx ON orders (track_number);
+CREATE INDEX IF NOT EXISTS orders_customer_id_idx ON orders (customer_id);
+CREATE INDEX IF NOT EXISTS orders_delivery_service_idx ON orders (delivery_service);
+CREATE INDEX IF NOT EXISTS orders_locale_idx ON orders (locale);
+CREATE INDEX IF NOT EXISTS orders_delivery_uid_idx ON orders (delivery_uid);
+CREATE INDEX IF NOT EXISTS orders_payment_transaction_idx ON orders (payment_transaction);
+
+CREATE INDEX IF NOT EXISTS payments_bank_idx ON payments (bank);
+CREATE INDEX IF NOT EXISTS payments_provider_idx ON payments (provider);
+CREATE INDEX IF NOT EXISTS payments_currency_idx ON payments (currency);
+
+CREATE INDEX IF NOT EXISTS deliveries_phone_idx ON deliveries (phone);
+CREATE INDEX IF NOT EXISTS deliveries_email_idx ON deliveries (email);
+CREATE INDEX IF NOT EXISTS deliveries_city_idx ON deliveries (city);
+
+CREATE INDEX IF NOT EXISTS items_brand_idx ON items (brand);
+CREATE INDEX IF NOT EXISTS items_nm_id_idx ON items (nm_id);
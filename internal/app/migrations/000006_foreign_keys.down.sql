@@ -0,0 +1,40 @@
+DROP INDEX IF EXISTS payments_order_uid_idx;
+DROP INDEX IF EXISTS items_order_uid_idx;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_payment_transaction_fkey;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_delivery_uid_fkey;
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_order_uid_fkey;
+ALTER TABLE items DROP CONSTRAINT IF EXISTS items_order_uid_fkey;
+
+ALTER TABLE payments DROP COLUMN IF EXISTS order_uid;
+ALTER TABLE items DROP COLUMN IF EXISTS order_uid;
+
+-- Возврат записей из карантина
+INSERT INTO orders (order_uid, track_number, entry, delivery_uid, payment_transaction, locale,
+    internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard)
+SELECT order_uid, track_number, entry, delivery_uid, payment_transaction, locale,
+    internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard
+FROM orders_quarantine
+ON CONFLICT DO NOTHING;
+
+INSERT INTO items (chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status)
+SELECT chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status
+FROM items_quarantine
+ON CONFLICT DO NOTHING;
+
+INSERT INTO payments (transaction, request_id, currency, provider, amount, payment_dt, bank,
+    delivery_cost, goods_total, custom_fee)
+SELECT transaction, request_id, currency, provider, amount, payment_dt, bank,
+    delivery_cost, goods_total, custom_fee
+FROM payments_quarantine
+ON CONFLICT DO NOTHING;
+
+INSERT INTO deliveries (delivery_uid, name, phone, zip, city, address, region, email)
+SELECT delivery_uid, name, phone, zip, city, address, region, email
+FROM deliveries_quarantine
+ON CONFLICT DO NOTHING;
+
+DROP TABLE IF EXISTS deliveries_quarantine;
+DROP TABLE IF EXISTS payments_quarantine;
+DROP TABLE IF EXISTS items_quarantine;
+DROP TABLE IF EXISTS orders_quarantine;
@@ -0,0 +1,89 @@
+-- Привязка товаров и оплат к заказу
+ALTER TABLE items ADD COLUMN IF NOT EXISTS order_uid VARCHAR(255);
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS order_uid VARCHAR(255);
+
+-- Таблицы карантина для записей, которые нельзя связать с заказом.
+-- Записи не удаляются безвозвратно, их можно разобрать вручную, reason объясняет причину
+CREATE TABLE IF NOT EXISTS orders_quarantine (LIKE orders);
+CREATE TABLE IF NOT EXISTS items_quarantine (LIKE items);
+CREATE TABLE IF NOT EXISTS payments_quarantine (LIKE payments);
+CREATE TABLE IF NOT EXISTS deliveries_quarantine (LIKE deliveries);
+
+ALTER TABLE orders_quarantine ADD COLUMN reason TEXT NOT NULL, ADD COLUMN quarantined_at TIMESTAMP NOT NULL;
+ALTER TABLE items_quarantine ADD COLUMN reason TEXT NOT NULL, ADD COLUMN quarantined_at TIMESTAMP NOT NULL;
+ALTER TABLE payments_quarantine ADD COLUMN reason TEXT NOT NULL, ADD COLUMN quarantined_at TIMESTAMP NOT NULL;
+ALTER TABLE deliveries_quarantine ADD COLUMN reason TEXT NOT NULL, ADD COLUMN quarantined_at TIMESTAMP NOT NULL;
+
+-- Заказы без доставки или оплаты нельзя прочитать, переносим их в карантин
+WITH moved AS (
+    DELETE FROM orders o
+    WHERE NOT EXISTS (SELECT 1 FROM deliveries d WHERE d.delivery_uid = o.delivery_uid)
+       OR NOT EXISTS (SELECT 1 FROM payments p WHERE p.transaction = o.payment_transaction)
+    RETURNING o.*
+)
+INSERT INTO orders_quarantine
+SELECT moved.*, 'order has no delivery or payment', now() FROM moved;
+
+-- Заполнение order_uid по старым связям.
+-- Товар связан с заказом только номером отслеживания, который не уникален,
+-- поэтому товар привязывается, только если этот номер есть ровно у одного заказа
+UPDATE items i SET order_uid = o.order_uid
+FROM orders o
+WHERE i.order_uid IS NULL AND i.track_number = o.track_number
+  AND (SELECT COUNT(*) FROM orders o2 WHERE o2.track_number = i.track_number) = 1;
+
+UPDATE payments p SET order_uid = o.order_uid
+FROM orders o
+WHERE p.order_uid IS NULL AND p.transaction = o.payment_transaction;
+
+-- Перенос в карантин записей, не принадлежащих ровно одному заказу
+WITH moved AS (
+    DELETE FROM items i WHERE i.order_uid IS NULL RETURNING i.*
+)
+INSERT INTO items_quarantine
+SELECT moved.*,
+    CASE WHEN EXISTS (SELECT 1 FROM orders o WHERE o.track_number = moved.track_number)
+        THEN 'track number belongs to several orders'
+        ELSE 'no order has the track number'
+    END,
+    now()
+FROM moved;
+
+WITH moved AS (
+    DELETE FROM payments p WHERE p.order_uid IS NULL RETURNING p.*
+)
+INSERT INTO payments_quarantine
+SELECT moved.*, 'no order has the transaction', now() FROM moved;
+
+WITH moved AS (
+    DELETE FROM deliveries d
+    WHERE NOT EXISTS (SELECT 1 FROM orders o WHERE o.delivery_uid = d.delivery_uid)
+    RETURNING d.*
+)
+INSERT INTO deliveries_quarantine
+SELECT moved.*, 'no order has the delivery', now() FROM moved;
+
+ALTER TABLE items ALTER COLUMN order_uid SET NOT NULL;
+ALTER TABLE payments ALTER COLUMN order_uid SET NOT NULL;
+
+-- Внешние ключи. Товары и оплата создаются раньше заказа, поэтому проверка откладывается до конца транзакции
+ALTER TABLE items ADD CONSTRAINT items_order_uid_fkey
+    FOREIGN KEY (order_uid) REFERENCES orders (order_uid)
+    ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED;
+
+ALTER TABLE payments ADD CONSTRAINT payments_order_uid_fkey
+    FOREIGN KEY (order_uid) REFERENCES orders (order_uid)
+    ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED;
+
+-- Доставка может использоваться несколькими заказами и удаляется приложением
+ALTER TABLE orders ADD CONSTRAINT orders_delivery_uid_fkey
+    FOREIGN KEY (delivery_uid) REFERENCES deliveries (delivery_uid)
+    DEFERRABLE INITIALLY DEFERRED;
+
+-- При обновлении заказа старая оплата удаляется до изменения заказа
+ALTER TABLE orders ADD CONSTRAINT orders_payment_transaction_fkey
+    FOREIGN KEY (payment_transaction) REFERENCES payments (transaction)
+    DEFERRABLE INITIALLY DEFERRED;
+
+CREATE INDEX IF NOT EXISTS items_order_uid_idx ON items (order_uid);
+CREATE UNIQUE INDEX IF NOT EXISTS payments_order_uid_idx ON payments (order_uid);
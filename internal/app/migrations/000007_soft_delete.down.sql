@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS orders_deleted_at_idx;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS delete_reason;
+ALTER TABLE orders DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE orders DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,7 @@
+-- Мягкое удаление заказов
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS deleted_by VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS delete_reason TEXT NOT NULL DEFAULT '';
+
+-- Индекс для списка удаленных заказов и их очистки
+CREATE INDEX IF NOT EXISTS orders_deleted_at_idx ON orders (deleted_at) WHERE deleted_at IS NOT NULL;
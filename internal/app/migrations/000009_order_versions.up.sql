@@ -0,0 +1,9 @@
+-- Версии заказов. Таблица только пополняется и хранит версии и после окончательного удаления заказа
+CREATE TABLE IF NOT EXISTS order_versions (
+    order_uid VARCHAR(255) NOT NULL,
+    version INT NOT NULL,
+    operation VARCHAR(16) NOT NULL,
+    data JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (order_uid, version)
+);
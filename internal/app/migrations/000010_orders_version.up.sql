@@ -0,0 +1,7 @@
+-- Версия заказа для оптимистичной блокировки
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+
+-- Версия заказа продолжает нумерацию уже сохраненных версий
+UPDATE orders o SET version = v.version
+FROM (SELECT order_uid, MAX(version) AS version FROM order_versions GROUP BY order_uid) v
+WHERE o.order_uid = v.order_uid;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS order_versions_created_at_idx;
@@ -0,0 +1,2 @@
+-- Индекс для поиска заказов, измененных после снимка кэша
+CREATE INDEX IF NOT EXISTS order_versions_created_at_idx ON order_versions (created_at);
@@ -0,0 +1,9 @@
+-- Создание таблицы состояний приема заказов
+CREATE TABLE IF NOT EXISTS order_ingestions (
+    order_uid VARCHAR(255) PRIMARY KEY,
+    state VARCHAR(32) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    sequence BIGINT NOT NULL DEFAULT 0,
+    received_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"L0/internal/usecase"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// runPurge permanently deletes orders soft-deleted more than retention ago, once per interval,
+// until the context is canceled.
+func (a *App) runPurge(ctx context.Context, orders usecase.OrderInteractor, retention time.Duration, interval time.Duration) {
+	if interval <= 0 {
+		a.logger.Error("purge of deleted orders is disabled", zap.Duration("interval", interval))
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := orders.Purge(ctx, retention)
+		if err != nil {
+			a.logger.Error("can't purge deleted orders", zap.Error(err))
+		} else if purged > 0 {
+			a.logger.Info("purged deleted orders", zap.Int64("count", purged))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -2,69 +2,600 @@
 package cache
 
 import (
+	"container/heap"
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"L0/internal/entity"
 	"L0/internal/repository"
 )
 
+// Policy chooses the entry evicted when the cache is over its limits.
+type Policy string
+
+// Eviction policies.
+const (
+	// PolicyLRU evicts the least recently used entry.
+	PolicyLRU Policy = "lru"
+	// PolicyLFU evicts the least frequently used entry, the least recently used one among equals.
+	PolicyLFU Policy = "lfu"
+)
+
+// Options configures the limits of a cache, zero values disable the corresponding limit.
+type Options[V any] struct {
+	// MaxEntries is the maximum number of entries.
+	MaxEntries int
+	// MaxBytes is the approximate memory budget of the values, measured by SizeOf.
+	MaxBytes int64
+	// TTL is the time an entry stays in the cache after it is set.
+	TTL time.Duration
+	// Policy is the eviction policy, PolicyLRU by default.
+	Policy Policy
+	// SizeOf returns the approximate size of a value in bytes, required by MaxBytes.
+	SizeOf func(value V) int64
+}
+
+// entry is a cached value with its bookkeeping.
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	size      int64
+	expiresAt time.Time
+	// hits is the number of reads of the entry.
+	hits uint64
+	// used is the cache clock at the last access of the entry.
+	used uint64
+	// index is the position of the entry in the eviction heap.
+	index int
+}
+
+// evictionHeap orders entries so that the next one to evict is on top.
+type evictionHeap[K comparable, V any] struct {
+	entries []*entry[K, V]
+	less    func(a, b *entry[K, V]) bool
+}
+
+func (h *evictionHeap[K, V]) Len() int { return len(h.entries) }
+
+func (h *evictionHeap[K, V]) Less(i, j int) bool { return h.less(h.entries[i], h.entries[j]) }
+
+func (h *evictionHeap[K, V]) Swap(i, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+	h.entries[i].index = i
+	h.entries[j].index = j
+}
+
+func (h *evictionHeap[K, V]) Push(x any) {
+	e := x.(*entry[K, V])
+	e.index = len(h.entries)
+	h.entries = append(h.entries, e)
+}
+
+func (h *evictionHeap[K, V]) Pop() any {
+	n := len(h.entries)
+	e := h.entries[n-1]
+	h.entries[n-1] = nil
+	h.entries = h.entries[:n-1]
+	return e
+}
+
 // Cache represents a structure for caching data.
-type cache struct {
-	data  map[string]interface{}
-	mutex sync.RWMutex
+type cache[K comparable, V any] struct {
+	opts    Options[V]
+	data    map[K]*entry[K, V]
+	evict   *evictionHeap[K, V]
+	bytes   int64
+	clock   uint64
+	removed uint64
+	// complete is set when the cache holds all values of its kind.
+	complete bool
+	// onSet and onRemove are called when a value enters and leaves the cache, a replaced value leaves it first.
+	// They are called with the cache locked and must not use the cache.
+	onSet    func(key K, value V)
+	onRemove func(key K, value V)
+	now      func() time.Time
+	mutex    sync.Mutex
 }
 
 // NewCache creates a new instance of Cache.
-func NewCache() *cache {
-	return &cache{
-		data: make(map[string]interface{}),
+func NewCache[K comparable, V any](opts Options[V]) *cache[K, V] {
+	less := func(a, b *entry[K, V]) bool { return a.used < b.used }
+	if opts.Policy == PolicyLFU {
+		less = func(a, b *entry[K, V]) bool {
+			if a.hits != b.hits {
+				return a.hits < b.hits
+			}
+			return a.used < b.used
+		}
+	}
+
+	return &cache[K, V]{
+		opts:  opts,
+		data:  make(map[K]*entry[K, V]),
+		evict: &evictionHeap[K, V]{less: less},
+		now:   time.Now,
 	}
 }
 
 // Set sets a value in the cache for the specified key.
-func (c *cache) Set(key string, value interface{}) {
+func (c *cache[K, V]) Set(key K, value V) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.set(key, value)
+}
+
+// setIf sets a value for the specified key if cond reports true for the current value,
+// ok is false if the key is not cached. It returns whether the value has been set.
+func (c *cache[K, V]) setIf(key K, value V, cond func(current V, ok bool) bool) bool {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	c.data[key] = value
+
+	var current V
+	e, ok := c.data[key]
+	if ok {
+		current = e.value
+	}
+	if !cond(current, ok) {
+		return false
+	}
+
+	c.set(key, value)
+	return true
+}
+
+// set sets a value in the cache, the cache must be locked.
+func (c *cache[K, V]) set(key K, value V) {
+	var size int64
+	if c.opts.SizeOf != nil {
+		size = c.opts.SizeOf(value)
+	}
+	var expiresAt time.Time
+	if c.opts.TTL > 0 {
+		expiresAt = c.now().Add(c.opts.TTL)
+	}
+
+	e, ok := c.data[key]
+	if !ok {
+		e = &entry[K, V]{key: key}
+		c.data[key] = e
+		heap.Push(c.evict, e)
+	} else if c.onRemove != nil {
+		c.onRemove(key, e.value)
+	}
+	c.bytes += size - e.size
+	e.value, e.size, e.expiresAt = value, size, expiresAt
+	c.touch(e)
+	if c.onSet != nil {
+		c.onSet(key, value)
+	}
+
+	c.evictOverflow(e)
 }
 
 // Get returns the value from the cache for the specified key.
-func (c *cache) Get(key string) (interface{}, bool) {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-	value, ok := c.data[key]
-	return value, ok
+func (c *cache[K, V]) Get(key K) (V, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	e, ok := c.data[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	if c.expired(e) {
+		c.remove(e)
+		c.lost()
+		var zero V
+		return zero, false
+	}
+	e.hits++
+	c.touch(e)
+	return e.value, true
 }
 
 // GetAll returns all values in the cache.
-func (c *cache) GetAll() ([]interface{}, bool) {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-	values := make([]interface{}, 0, len(c.data))
-	for _, value := range c.data {
-		values = append(values, value)
+// The boolean reports whether they are all values of their kind.
+func (c *cache[K, V]) GetAll() ([]V, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.removeExpired()
+	values := make([]V, 0, len(c.data))
+	for _, e := range c.data {
+		values = append(values, e.value)
 	}
-	return values, len(values) > 0
+	return values, c.complete
 }
 
 // Delete deletes a value from the cache for the specified key.
-func (c *cache) Delete(key string) {
+func (c *cache[K, V]) Delete(key K) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if e, ok := c.data[key]; ok {
+		c.remove(e)
+	}
+}
+
+// deleteIf deletes the value for the specified key if cond reports true for it.
+// It returns whether the value has been deleted.
+func (c *cache[K, V]) deleteIf(key K, cond func(current V) bool) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	e, ok := c.data[key]
+	if !ok || !cond(e.value) {
+		return false
+	}
+	c.remove(e)
+	return true
+}
+
+// entries returns the cached values by their keys without counting it as their use.
+// The boolean reports whether they are all values of their kind.
+func (c *cache[K, V]) entries() (map[K]V, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	values := make(map[K]V, len(c.data))
+	for key, e := range c.data {
+		values[key] = e.value
+	}
+	return values, c.complete
+}
+
+// RemoveExpired removes the entries whose TTL has passed.
+func (c *cache[K, V]) RemoveExpired() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.removeExpired()
+}
+
+// Len returns the number of entries in the cache.
+func (c *cache[K, V]) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.data)
+}
+
+// losses returns the number of entries evicted or expired so far.
+func (c *cache[K, V]) losses() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.removed
+}
+
+// markComplete marks the cache as holding all values of its kind
+// unless an entry has been evicted or expired after losses returned since.
+// It returns whether the cache is marked complete.
+func (c *cache[K, V]) markComplete(since uint64) bool {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	delete(c.data, key)
+	c.complete = c.removed == since
+	return c.complete
+}
+
+// touch moves an entry to the most recently used position.
+func (c *cache[K, V]) touch(e *entry[K, V]) {
+	c.clock++
+	e.used = c.clock
+	heap.Fix(c.evict, e.index)
+}
+
+// expired reports whether the TTL of an entry has passed.
+func (c *cache[K, V]) expired(e *entry[K, V]) bool {
+	return !e.expiresAt.IsZero() && !c.now().Before(e.expiresAt)
+}
+
+// remove removes an entry from the cache.
+func (c *cache[K, V]) remove(e *entry[K, V]) {
+	heap.Remove(c.evict, e.index)
+	delete(c.data, e.key)
+	c.bytes -= e.size
+	if c.onRemove != nil {
+		c.onRemove(e.key, e.value)
+	}
+}
+
+// lost records an entry evicted or expired, so the cache no longer holds all values of its kind.
+func (c *cache[K, V]) lost() {
+	c.removed++
+	c.complete = false
+}
+
+// removeExpired removes the expired entries and returns their number.
+func (c *cache[K, V]) removeExpired() int {
+	if c.opts.TTL <= 0 {
+		return 0
+	}
+
+	var removed int
+	for _, e := range c.data {
+		if c.expired(e) {
+			c.remove(e)
+			c.lost()
+			removed++
+		}
+	}
+	return removed
+}
+
+// overflow reports whether the cache is over its limits.
+func (c *cache[K, V]) overflow() bool {
+	return c.opts.MaxEntries > 0 && len(c.data) > c.opts.MaxEntries ||
+		c.opts.MaxBytes > 0 && c.bytes > c.opts.MaxBytes
+}
+
+// evictOverflow evicts entries until the cache is within its limits.
+// The entry just set is evicted last, otherwise a new entry would never survive LFU eviction.
+func (c *cache[K, V]) evictOverflow(keep *entry[K, V]) {
+	if !c.overflow() {
+		return
+	}
+
+	// A value over the whole budget is not worth evicting the others
+	if c.opts.MaxBytes > 0 && keep.size > c.opts.MaxBytes {
+		c.remove(keep)
+		c.lost()
+		return
+	}
+
+	heap.Remove(c.evict, keep.index)
+	for c.evict.Len() > 0 && c.overflow() {
+		c.remove(c.evict.entries[0])
+		c.lost()
+	}
+	heap.Push(c.evict, keep)
+
+	if c.overflow() {
+		c.remove(keep)
+		c.lost()
+	}
+}
+
+// errCacheFull stops loading a cache that has started to evict entries.
+var errCacheFull = errors.New("cache is full")
+
+// orderCache represents a cache of orders by their UIDs.
+type orderCache struct {
+	*cache[string, *entity.Order]
+	// missing holds the UIDs of orders recently found missing in the repository.
+	missing *cache[string, struct{}]
+	missTTL time.Duration
+	// index holds the secondary indexes of the cached orders, it is guarded by the cache mutex.
+	index orderIndex
+	// fills holds the generations of the orders being filled, it is guarded by the cache mutex.
+	fills map[string]*fill
+	// reconciling serializes reconciliations, last is the outcome of the latest one.
+	reconciling sync.Mutex
+	last        atomic.Pointer[entity.Reconciliation]
+}
+
+// fill tracks the changes of an order while Fill loads it.
+type fill struct {
+	// generation is incremented whenever the order is set or deleted.
+	generation uint64
+	// refs is the number of loads in progress.
+	refs int
+}
+
+// NewOrderCache creates a new instance of OrderCache.
+// Orders are measured by OrderSize unless opts.SizeOf is set.
+// Missing orders are remembered for missTTL, zero disables it.
+func NewOrderCache(opts Options[*entity.Order], missTTL time.Duration) *orderCache {
+	if opts.SizeOf == nil {
+		opts.SizeOf = OrderSize
+	}
+
+	c := &orderCache{
+		cache: NewCache[string, *entity.Order](opts),
+		missing: NewCache[string, struct{}](Options[struct{}]{
+			MaxEntries: opts.MaxEntries,
+			TTL:        missTTL,
+		}),
+		missTTL: missTTL,
+		index:   newOrderIndex(),
+		fills:   make(map[string]*fill),
+	}
+	c.onSet = func(key string, order *entity.Order) {
+		c.index.add(key, order)
+		c.changed(key)
+	}
+	c.onRemove = func(key string, order *entity.Order) {
+		c.index.remove(key, order)
+		c.changed(key)
+	}
+
+	return c
+}
+
+// Set sets an order in the cache for the specified UID, so it is no longer missing.
+func (c *orderCache) Set(key string, order *entity.Order) {
+	c.missing.Delete(key)
+	c.cache.Set(key, order)
+}
+
+// Delete deletes the order with the specified UID, also if it is not cached, so that a concurrent Fill
+// does not put back the order it has loaded before the deletion.
+func (c *orderCache) Delete(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.changed(key)
+	if e, ok := c.data[key]; ok {
+		c.remove(e)
+	}
+}
+
+// Fill loads an order with load and caches it, or remembers it as missing if load returns nil.
+// The result is not cached if the order is set or deleted while it is loaded, since it may be stale then.
+func (c *orderCache) Fill(key string, load func() (*entity.Order, error)) (*entity.Order, error) {
+	c.mutex.Lock()
+	f, ok := c.fills[key]
+	if !ok {
+		f = &fill{}
+		c.fills[key] = f
+	}
+	f.refs++
+	generation := f.generation
+	c.mutex.Unlock()
+
+	order, err := load()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	f.refs--
+	if f.refs == 0 {
+		delete(c.fills, key)
+	}
+	if err != nil || f.generation != generation {
+		return order, err
+	}
+
+	if order == nil {
+		c.SetMissing(key)
+	} else {
+		c.missing.Delete(key)
+		c.set(key, order)
+	}
+
+	return order, nil
+}
+
+// changed records a change of the order with the specified UID for the fills in progress,
+// the cache must be locked.
+func (c *orderCache) changed(key string) {
+	if f, ok := c.fills[key]; ok {
+		f.generation++
+	}
+}
+
+// SetMissing remembers that the order with the specified UID is missing in the repository.
+func (c *orderCache) SetMissing(key string) {
+	if c.missTTL <= 0 {
+		return
+	}
+	c.missing.Set(key, struct{}{})
+}
+
+// Missing reports whether the order with the specified UID has recently been found missing.
+func (c *orderCache) Missing(key string) bool {
+	_, ok := c.missing.Get(key)
+	return ok
+}
+
+// Find returns the cached orders with the value of an indexed field.
+// The boolean reports whether they are all such orders, that is the cache holds all orders.
+func (c *orderCache) Find(index Index, value string) ([]*entity.Order, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	orders := []*entity.Order{}
+	for _, uid := range c.index.lookup(index, value) {
+		e := c.data[uid]
+		if c.expired(e) {
+			c.remove(e)
+			c.lost()
+			continue
+		}
+		e.hits++
+		c.touch(e)
+		orders = append(orders, e.value)
+	}
+
+	return orders, c.complete
+}
+
+// RemoveExpired removes the orders and the missing UIDs whose TTL has passed.
+func (c *orderCache) RemoveExpired() int {
+	c.missing.RemoveExpired()
+	return c.cache.RemoveExpired()
 }
 
 // Load loads data into the cache from the repository.
-func (c *cache) Load(ctx context.Context, orderRepository repository.OrderRepository) error {
-	orders, err := orderRepository.GetAll(ctx)
+// Loading stops once the cache is full, then the cache is not complete and GetAll reports it.
+func (c *orderCache) Load(ctx context.Context, orderRepository repository.OrderRepository) error {
+	since := c.losses()
+	err := orderRepository.Stream(ctx, func(order *entity.Order) error {
+		c.Set(order.OrderUID, order)
+		if c.losses() != since {
+			return errCacheFull
+		}
+		return nil
+	})
+	if errors.Is(err, errCacheFull) {
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("can't get orders from database: %w", err)
 	}
 
-	for _, order := range orders {
+	c.markComplete(since)
+
+	return nil
+}
+
+// Snapshot copies the cached orders into a snapshot reflecting NATS messages up to sequence.
+// The sequence must be read before the snapshot is taken, so that the orders reflect at least those messages.
+func (c *orderCache) Snapshot(sequence uint64) *Snapshot {
+	takenAt := c.now()
+	orders, complete := c.GetAll()
+
+	return &Snapshot{
+		TakenAt:  takenAt,
+		Sequence: sequence,
+		Complete: complete,
+		Orders:   orders,
+	}
+}
+
+// Restore loads the orders of a snapshot into the cache, then refreshes the orders changed
+// in the repository at or after changedSince, so that the cache catches up with the repository.
+// If the catch-up fails, the orders of the snapshot are removed, since some of them may be outdated.
+func (c *orderCache) Restore(
+	ctx context.Context,
+	snapshot *Snapshot,
+	changedSince time.Time,
+	orderRepository repository.OrderRepository,
+) error {
+	since := c.losses()
+	for _, order := range snapshot.Orders {
 		c.Set(order.OrderUID, order)
 	}
 
+	err := c.catchUp(ctx, changedSince, orderRepository)
+	if err != nil {
+		for _, order := range snapshot.Orders {
+			c.Delete(order.OrderUID)
+		}
+		return err
+	}
+
+	if snapshot.Complete {
+		c.markComplete(since)
+	}
+
+	return nil
+}
+
+// catchUp refreshes the orders changed in the repository at or after since,
+// orders deleted or purged since then are removed from the cache.
+func (c *orderCache) catchUp(ctx context.Context, since time.Time, orderRepository repository.OrderRepository) error {
+	orderUIDs, err := orderRepository.ChangedSince(ctx, since)
+	if err != nil {
+		return fmt.Errorf("can't get changed orders from database: %w", err)
+	}
+
+	for _, uid := range orderUIDs {
+		order, err := orderRepository.GetByUid(ctx, uid)
+		if err != nil {
+			return fmt.Errorf("can't get changed order from database: %w", err)
+		}
+
+		if order == nil {
+			c.Delete(uid)
+		} else {
+			c.Set(uid, order)
+		}
+	}
+
 	return nil
 }
@@ -1,104 +1,247 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: ./interfaces.go
+// Source: L0/internal/cache (interfaces: OrderCache)
 
 // Package cache is a generated GoMock package.
 package cache
 
 import (
+	entity "L0/internal/entity"
 	repository "L0/internal/repository"
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
 
-// MockCache is a mock of Cache interface.
-type MockCache struct {
+// MockOrderCache is a mock of OrderCache interface.
+type MockOrderCache struct {
 	ctrl     *gomock.Controller
-	recorder *MockCacheMockRecorder
+	recorder *MockOrderCacheMockRecorder
 }
 
-// MockCacheMockRecorder is the mock recorder for MockCache.
-type MockCacheMockRecorder struct {
-	mock *MockCache
+// MockOrderCacheMockRecorder is the mock recorder for MockOrderCache.
+type MockOrderCacheMockRecorder struct {
+	mock *MockOrderCache
 }
 
-// NewMockCache creates a new mock instance.
-func NewMockCache(ctrl *gomock.Controller) *MockCache {
-	mock := &MockCache{ctrl: ctrl}
-	mock.recorder = &MockCacheMockRecorder{mock}
+// NewMockOrderCache creates a new mock instance.
+func NewMockOrderCache(ctrl *gomock.Controller) *MockOrderCache {
+	mock := &MockOrderCache{ctrl: ctrl}
+	mock.recorder = &MockOrderCacheMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockCache) EXPECT() *MockCacheMockRecorder {
+func (m *MockOrderCache) EXPECT() *MockOrderCacheMockRecorder {
 	return m.recorder
 }
 
 // Delete mocks base method.
-func (m *MockCache) Delete(key string) {
+func (m *MockOrderCache) Delete(arg0 string) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Delete", key)
+	m.ctrl.Call(m, "Delete", arg0)
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockCacheMockRecorder) Delete(key interface{}) *gomock.Call {
+func (mr *MockOrderCacheMockRecorder) Delete(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCache)(nil).Delete), key)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOrderCache)(nil).Delete), arg0)
+}
+
+// Fill mocks base method.
+func (m *MockOrderCache) Fill(arg0 string, arg1 func() (*entity.Order, error)) (*entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fill", arg0, arg1)
+	ret0, _ := ret[0].(*entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Fill indicates an expected call of Fill.
+func (mr *MockOrderCacheMockRecorder) Fill(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fill", reflect.TypeOf((*MockOrderCache)(nil).Fill), arg0, arg1)
+}
+
+// Find mocks base method.
+func (m *MockOrderCache) Find(arg0 Index, arg1 string) ([]*entity.Order, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", arg0, arg1)
+	ret0, _ := ret[0].([]*entity.Order)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockOrderCacheMockRecorder) Find(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockOrderCache)(nil).Find), arg0, arg1)
 }
 
 // Get mocks base method.
-func (m *MockCache) Get(key string) (interface{}, bool) {
+func (m *MockOrderCache) Get(arg0 string) (*entity.Order, bool) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", key)
-	ret0, _ := ret[0].(interface{})
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(*entity.Order)
 	ret1, _ := ret[1].(bool)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockCacheMockRecorder) Get(key interface{}) *gomock.Call {
+func (mr *MockOrderCacheMockRecorder) Get(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCache)(nil).Get), key)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOrderCache)(nil).Get), arg0)
 }
 
 // GetAll mocks base method.
-func (m *MockCache) GetAll() ([]interface{}, bool) {
+func (m *MockOrderCache) GetAll() ([]*entity.Order, bool) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAll")
-	ret0, _ := ret[0].([]interface{})
+	ret0, _ := ret[0].([]*entity.Order)
 	ret1, _ := ret[1].(bool)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockCacheMockRecorder) GetAll() *gomock.Call {
+func (mr *MockOrderCacheMockRecorder) GetAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockOrderCache)(nil).GetAll))
+}
+
+// LastReconciliation mocks base method.
+func (m *MockOrderCache) LastReconciliation() *entity.Reconciliation {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastReconciliation")
+	ret0, _ := ret[0].(*entity.Reconciliation)
+	return ret0
+}
+
+// LastReconciliation indicates an expected call of LastReconciliation.
+func (mr *MockOrderCacheMockRecorder) LastReconciliation() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastReconciliation", reflect.TypeOf((*MockOrderCache)(nil).LastReconciliation))
+}
+
+// Len mocks base method.
+func (m *MockOrderCache) Len() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Len")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Len indicates an expected call of Len.
+func (mr *MockOrderCacheMockRecorder) Len() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockCache)(nil).GetAll))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Len", reflect.TypeOf((*MockOrderCache)(nil).Len))
 }
 
 // Load mocks base method.
-func (m *MockCache) Load(ctx context.Context, orderRepository repository.OrderRepository) error {
+func (m *MockOrderCache) Load(arg0 context.Context, arg1 repository.OrderRepository) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Load", ctx, orderRepository)
+	ret := m.ctrl.Call(m, "Load", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Load indicates an expected call of Load.
-func (mr *MockCacheMockRecorder) Load(ctx, orderRepository interface{}) *gomock.Call {
+func (mr *MockOrderCacheMockRecorder) Load(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockOrderCache)(nil).Load), arg0, arg1)
+}
+
+// Missing mocks base method.
+func (m *MockOrderCache) Missing(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Missing", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Missing indicates an expected call of Missing.
+func (mr *MockOrderCacheMockRecorder) Missing(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockCache)(nil).Load), ctx, orderRepository)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Missing", reflect.TypeOf((*MockOrderCache)(nil).Missing), arg0)
+}
+
+// Reconcile mocks base method.
+func (m *MockOrderCache) Reconcile(arg0 context.Context, arg1 repository.OrderRepository) (*entity.Reconciliation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconcile", arg0, arg1)
+	ret0, _ := ret[0].(*entity.Reconciliation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reconcile indicates an expected call of Reconcile.
+func (mr *MockOrderCacheMockRecorder) Reconcile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockOrderCache)(nil).Reconcile), arg0, arg1)
+}
+
+// RemoveExpired mocks base method.
+func (m *MockOrderCache) RemoveExpired() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveExpired")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// RemoveExpired indicates an expected call of RemoveExpired.
+func (mr *MockOrderCacheMockRecorder) RemoveExpired() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveExpired", reflect.TypeOf((*MockOrderCache)(nil).RemoveExpired))
+}
+
+// Restore mocks base method.
+func (m *MockOrderCache) Restore(arg0 context.Context, arg1 *Snapshot, arg2 time.Time, arg3 repository.OrderRepository) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockOrderCacheMockRecorder) Restore(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockOrderCache)(nil).Restore), arg0, arg1, arg2, arg3)
 }
 
 // Set mocks base method.
-func (m *MockCache) Set(key string, value interface{}) {
+func (m *MockOrderCache) Set(arg0 string, arg1 *entity.Order) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Set", key, value)
+	m.ctrl.Call(m, "Set", arg0, arg1)
 }
 
 // Set indicates an expected call of Set.
-func (mr *MockCacheMockRecorder) Set(key, value interface{}) *gomock.Call {
+func (mr *MockOrderCacheMockRecorder) Set(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockOrderCache)(nil).Set), arg0, arg1)
+}
+
+// SetMissing mocks base method.
+func (m *MockOrderCache) SetMissing(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMissing", arg0)
+}
+
+// SetMissing indicates an expected call of SetMissing.
+func (mr *MockOrderCacheMockRecorder) SetMissing(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMissing", reflect.TypeOf((*MockOrderCache)(nil).SetMissing), arg0)
+}
+
+// Snapshot mocks base method.
+func (m *MockOrderCache) Snapshot(arg0 uint64) *Snapshot {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot", arg0)
+	ret0, _ := ret[0].(*Snapshot)
+	return ret0
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockOrderCacheMockRecorder) Snapshot(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockCache)(nil).Set), key, value)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockOrderCache)(nil).Snapshot), arg0)
 }
@@ -1,12 +1,21 @@
 package cache
 
 import (
+	"L0/internal/entity"
+	"L0/internal/repository"
+	"context"
+	"errors"
+	"reflect"
+	"sort"
 	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
 )
 
 // TestCache_Set проверяет функцию Set для структуры cache.
 func TestCache_Set(t *testing.T) {
-	c := NewCache()
+	c := NewCache[string, string](Options[string]{})
 
 	c.Set("key", "value")
 
@@ -19,7 +28,7 @@ func TestCache_Set(t *testing.T) {
 
 // TestCache_Get проверяет функцию Get для структуры cache.
 func TestCache_Get(t *testing.T) {
-	c := NewCache()
+	c := NewCache[string, string](Options[string]{})
 
 	c.Set("key", "value")
 
@@ -32,11 +41,18 @@ func TestCache_Get(t *testing.T) {
 
 // TestCache_GetAll проверяет функцию GetAll для структуры cache.
 func TestCache_GetAll(t *testing.T) {
-	c := NewCache()
+	c := NewCache[string, string](Options[string]{})
 
 	c.Set("key", "value")
 
 	val, ok := c.GetAll()
+	if ok {
+		t.Errorf("Cache is complete before it was loaded")
+	}
+
+	c.markComplete(c.losses())
+
+	val, ok = c.GetAll()
 	if !ok {
 		t.Errorf("Cache get value = %v, want value = value", val)
 	}
@@ -52,7 +68,7 @@ func TestCache_GetAll(t *testing.T) {
 
 // TestCache_Delete проверяет функцию Delete для структуры cache.
 func TestCache_Delete(t *testing.T) {
-	c := NewCache()
+	c := NewCache[string, string](Options[string]{})
 
 	c.Set("key", "value")
 
@@ -63,3 +79,451 @@ func TestCache_Delete(t *testing.T) {
 		t.Errorf("Cache value was not deleted")
 	}
 }
+
+// TestOrderCache_Load проверяет функцию Load для структуры orderCache.
+func TestOrderCache_Load(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         Options[*entity.Order]
+		streamErr    error
+		wantErr      bool
+		wantLen      int
+		wantComplete bool
+	}{
+		{
+			name:         "complete",
+			opts:         Options[*entity.Order]{},
+			wantLen:      3,
+			wantComplete: true,
+		},
+		{
+			name:         "stops when full",
+			opts:         Options[*entity.Order]{MaxEntries: 2},
+			wantLen:      2,
+			wantComplete: false,
+		},
+		{
+			name:         "fail: can't stream orders",
+			opts:         Options[*entity.Order]{},
+			streamErr:    errors.New("stream error"),
+			wantErr:      true,
+			wantLen:      3,
+			wantComplete: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repo := repository.NewMockOrderRepository(ctrl)
+			repo.EXPECT().Stream(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, fn func(order *entity.Order) error) error {
+					for _, uid := range []string{"order_uid_1", "order_uid_2", "order_uid_3"} {
+						if err := fn(&entity.Order{OrderUID: uid}); err != nil {
+							return err
+						}
+					}
+					return tt.streamErr
+				},
+			)
+
+			c := NewOrderCache(tt.opts, 0)
+
+			err := c.Load(context.Background(), repo)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Cache load error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if c.Len() != tt.wantLen {
+				t.Errorf("Cache len = %v, want %v", c.Len(), tt.wantLen)
+			}
+			if _, complete := c.GetAll(); complete != tt.wantComplete {
+				t.Errorf("Cache complete = %v, want %v", complete, tt.wantComplete)
+			}
+		})
+	}
+}
+
+// TestOrderCache_Restore проверяет восстановление кэша из снимка и догрузку измененных заказов.
+func TestOrderCache_Restore(t *testing.T) {
+	since := MustParseTime(time.RFC3339, "2024-02-01T10:00:00Z")
+	snapshot := &Snapshot{
+		TakenAt:  since,
+		Complete: true,
+		Orders: []*entity.Order{
+			{OrderUID: "order_uid_1", Version: 1},
+			{OrderUID: "order_uid_2", Version: 1},
+		},
+	}
+	tests := []struct {
+		name         string
+		opts         Options[*entity.Order]
+		setup        func(repo *repository.MockOrderRepository)
+		wantErr      bool
+		wantVersions map[string]int
+		wantComplete bool
+	}{
+		{
+			name: "catches up with changed orders",
+			setup: func(repo *repository.MockOrderRepository) {
+				repo.EXPECT().ChangedSince(gomock.Any(), since).Return([]string{"order_uid_1", "order_uid_2", "order_uid_3"}, nil)
+				repo.EXPECT().GetByUid(gomock.Any(), "order_uid_1").Return(&entity.Order{OrderUID: "order_uid_1", Version: 2}, nil)
+				repo.EXPECT().GetByUid(gomock.Any(), "order_uid_2").Return(nil, nil)
+				repo.EXPECT().GetByUid(gomock.Any(), "order_uid_3").Return(&entity.Order{OrderUID: "order_uid_3", Version: 1}, nil)
+			},
+			wantVersions: map[string]int{"order_uid_1": 2, "order_uid_3": 1},
+			wantComplete: true,
+		},
+		{
+			name: "incomplete when full",
+			opts: Options[*entity.Order]{MaxEntries: 1},
+			setup: func(repo *repository.MockOrderRepository) {
+				repo.EXPECT().ChangedSince(gomock.Any(), since).Return([]string{}, nil)
+			},
+			wantVersions: map[string]int{"order_uid_2": 1},
+			wantComplete: false,
+		},
+		{
+			name: "fail: can't get changed orders",
+			setup: func(repo *repository.MockOrderRepository) {
+				repo.EXPECT().ChangedSince(gomock.Any(), since).Return(nil, errors.New("db error"))
+			},
+			wantErr:      true,
+			wantVersions: map[string]int{},
+		},
+		{
+			name: "fail: can't get changed order",
+			setup: func(repo *repository.MockOrderRepository) {
+				repo.EXPECT().ChangedSince(gomock.Any(), since).Return([]string{"order_uid_3"}, nil)
+				repo.EXPECT().GetByUid(gomock.Any(), "order_uid_3").Return(nil, errors.New("db error"))
+			},
+			wantErr:      true,
+			wantVersions: map[string]int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repo := repository.NewMockOrderRepository(ctrl)
+			tt.setup(repo)
+
+			c := NewOrderCache(tt.opts, 0)
+
+			err := c.Restore(context.Background(), snapshot, since, repo)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Cache restore error = %v, wantErr %v", err, tt.wantErr)
+			}
+			orders, complete := c.GetAll()
+			versions := make(map[string]int, len(orders))
+			for _, order := range orders {
+				versions[order.OrderUID] = order.Version
+			}
+			if !reflect.DeepEqual(versions, tt.wantVersions) {
+				t.Errorf("Cache versions = %v, want %v", versions, tt.wantVersions)
+			}
+			if complete != tt.wantComplete {
+				t.Errorf("Cache complete = %v, want %v", complete, tt.wantComplete)
+			}
+		})
+	}
+}
+
+// TestOrderCache_Snapshot проверяет снятие снимка кэша.
+func TestOrderCache_Snapshot(t *testing.T) {
+	now := MustParseTime(time.RFC3339, "2024-02-01T10:00:00Z")
+	c := NewOrderCache(Options[*entity.Order]{}, 0)
+	c.now = func() time.Time { return now }
+	c.Set("order_uid_1", &entity.Order{OrderUID: "order_uid_1"})
+	c.markComplete(c.losses())
+
+	snapshot := c.Snapshot(42)
+	if !snapshot.TakenAt.Equal(now) || snapshot.Sequence != 42 || !snapshot.Complete || len(snapshot.Orders) != 1 {
+		t.Errorf("Cache snapshot = %+v", snapshot)
+	}
+}
+
+// TestOrderCache_Missing проверяет запоминание отсутствующих заказов.
+func TestOrderCache_Missing(t *testing.T) {
+	now := MustParseTime(time.RFC3339, "2024-02-01T10:00:00Z")
+	c := NewOrderCache(Options[*entity.Order]{}, time.Minute)
+	c.missing.now = func() time.Time { return now }
+
+	c.SetMissing("order_uid_1")
+	c.SetMissing("order_uid_2")
+	if !c.Missing("order_uid_1") || !c.Missing("order_uid_2") {
+		t.Errorf("Cache forgot a missing order")
+	}
+
+	c.Set("order_uid_1", &entity.Order{OrderUID: "order_uid_1"})
+	if c.Missing("order_uid_1") {
+		t.Errorf("Cache reports a set order as missing")
+	}
+
+	now = now.Add(time.Minute)
+	if c.Missing("order_uid_2") {
+		t.Errorf("Cache reports an expired missing order")
+	}
+
+	disabled := NewOrderCache(Options[*entity.Order]{}, 0)
+	disabled.SetMissing("order_uid_1")
+	if disabled.Missing("order_uid_1") {
+		t.Errorf("Cache with disabled negative entries reports a missing order")
+	}
+}
+
+// TestOrderCache_Fill проверяет, что загруженный заказ не попадает в кэш, если он изменился во время загрузки.
+func TestOrderCache_Fill(t *testing.T) {
+	loaded := &entity.Order{OrderUID: "order_uid_1", Version: 1}
+	tests := []struct {
+		name        string
+		order       *entity.Order
+		err         error
+		during      func(c *orderCache)
+		wantErr     bool
+		wantCached  bool
+		wantMissing bool
+	}{
+		{name: "cached", order: loaded, wantCached: true},
+		{name: "missing", wantMissing: true},
+		{name: "error", err: errors.New("db error"), wantErr: true},
+		{
+			name:   "deleted during load",
+			order:  loaded,
+			during: func(c *orderCache) { c.Delete("order_uid_1") },
+		},
+		{
+			name:  "set during load",
+			order: loaded,
+			during: func(c *orderCache) {
+				c.Set("order_uid_1", &entity.Order{OrderUID: "order_uid_1", Version: 2})
+			},
+			wantCached: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewOrderCache(Options[*entity.Order]{}, time.Minute)
+
+			got, err := c.Fill("order_uid_1", func() (*entity.Order, error) {
+				if tt.during != nil {
+					tt.during(c)
+				}
+				return tt.order, tt.err
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Cache fill error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.order {
+				t.Errorf("Cache fill = %v, want %v", got, tt.order)
+			}
+
+			cached, ok := c.Get("order_uid_1")
+			if ok != tt.wantCached {
+				t.Errorf("Cache fill cached = %v, want %v", ok, tt.wantCached)
+			}
+			if ok && cached == loaded && tt.during != nil {
+				t.Errorf("Cache fill replaced an order set during the load")
+			}
+			if c.Missing("order_uid_1") != tt.wantMissing {
+				t.Errorf("Cache fill missing = %v, want %v", c.Missing("order_uid_1"), tt.wantMissing)
+			}
+			if len(c.fills) != 0 {
+				t.Errorf("Cache fill left %d fills in progress", len(c.fills))
+			}
+		})
+	}
+}
+
+// TestOrderCache_Find проверяет поиск заказов по вторичным индексам.
+func TestOrderCache_Find(t *testing.T) {
+	c := NewOrderCache(Options[*entity.Order]{MaxEntries: 2}, 0)
+	c.Set("order_uid_1", &entity.Order{OrderUID: "order_uid_1", TrackNumber: "WBILMTESTTRACK", CustomerID: "test"})
+	c.Set("order_uid_2", &entity.Order{OrderUID: "order_uid_2", CustomerID: "test", Delivery: entity.Delivery{
+		Phone: "+9720000000",
+		Email: "test@gmail.com",
+	}})
+	c.markComplete(c.losses())
+
+	find := func(index Index, value string) []string {
+		orders, _ := c.Find(index, value)
+		uids := make([]string, 0, len(orders))
+		for _, order := range orders {
+			uids = append(uids, order.OrderUID)
+		}
+		sort.Strings(uids)
+		return uids
+	}
+
+	if got := find(IndexCustomerID, "test"); !reflect.DeepEqual(got, []string{"order_uid_1", "order_uid_2"}) {
+		t.Errorf("Cache found by customer %v", got)
+	}
+	if got := find(IndexPhone, "+9720000000"); !reflect.DeepEqual(got, []string{"order_uid_2"}) {
+		t.Errorf("Cache found by phone %v", got)
+	}
+	if _, complete := c.Find(IndexEmail, "other@gmail.com"); !complete {
+		t.Errorf("Complete cache reports an incomplete search")
+	}
+
+	// A replaced order is indexed by its new values only
+	c.Set("order_uid_1", &entity.Order{OrderUID: "order_uid_1", TrackNumber: "WBILMNEWTRACK", CustomerID: "test"})
+	if got := find(IndexTrackNumber, "WBILMTESTTRACK"); len(got) != 0 {
+		t.Errorf("Cache found by stale track number %v", got)
+	}
+	if got := find(IndexTrackNumber, "WBILMNEWTRACK"); !reflect.DeepEqual(got, []string{"order_uid_1"}) {
+		t.Errorf("Cache found by track number %v", got)
+	}
+
+	c.Delete("order_uid_2")
+	if got := find(IndexEmail, "test@gmail.com"); len(got) != 0 {
+		t.Errorf("Cache found deleted order %v", got)
+	}
+
+	// An evicted order leaves the index, and the cache no longer holds all orders
+	c.Set("order_uid_3", &entity.Order{OrderUID: "order_uid_3", CustomerID: "test"})
+	c.Set("order_uid_4", &entity.Order{OrderUID: "order_uid_4", CustomerID: "test"})
+	orders, complete := c.Find(IndexCustomerID, "test")
+	if len(orders) != 2 || complete {
+		t.Errorf("Cache found %d orders, complete %v after eviction", len(orders), complete)
+	}
+}
+
+// TestOrderSize проверяет оценку размера заказа.
+func TestOrderSize(t *testing.T) {
+	order := &entity.Order{OrderUID: "b563feb7b2b84b6test"}
+	withItem := &entity.Order{OrderUID: "b563feb7b2b84b6test", Items: []entity.Item{{Name: "Mascaras"}}}
+
+	if OrderSize(withItem) <= OrderSize(order) {
+		t.Errorf("OrderSize() = %v, want more than %v", OrderSize(withItem), OrderSize(order))
+	}
+}
+
+// TestCache_Evict проверяет вытеснение записей при превышении ограничений кэша.
+func TestCache_Evict(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  Options[string]
+		setup func(c *cache[string, string])
+		want  []string
+	}{
+		{
+			name: "lru: least recently used",
+			opts: Options[string]{MaxEntries: 2},
+			setup: func(c *cache[string, string]) {
+				c.Set("a", "a")
+				c.Set("b", "b")
+				c.Get("a")
+				c.Set("c", "c")
+			},
+			want: []string{"a", "c"},
+		},
+		{
+			name: "lfu: least frequently used",
+			opts: Options[string]{MaxEntries: 2, Policy: PolicyLFU},
+			setup: func(c *cache[string, string]) {
+				c.Set("a", "a")
+				c.Set("b", "b")
+				c.Get("a")
+				c.Get("a")
+				c.Get("b")
+				c.Set("c", "c")
+			},
+			want: []string{"a", "c"},
+		},
+		{
+			name: "lfu: least recently used among equals",
+			opts: Options[string]{MaxEntries: 2, Policy: PolicyLFU},
+			setup: func(c *cache[string, string]) {
+				c.Set("a", "a")
+				c.Set("b", "b")
+				c.Get("b")
+				c.Get("a")
+				c.Set("c", "c")
+			},
+			want: []string{"a", "c"},
+		},
+		{
+			name: "byte budget",
+			opts: Options[string]{MaxBytes: 5, SizeOf: func(v string) int64 { return int64(len(v)) }},
+			setup: func(c *cache[string, string]) {
+				c.Set("a", "aa")
+				c.Set("b", "bb")
+				c.Set("c", "cc")
+			},
+			want: []string{"bb", "cc"},
+		},
+		{
+			name: "byte budget: replaced value",
+			opts: Options[string]{MaxBytes: 4, SizeOf: func(v string) int64 { return int64(len(v)) }},
+			setup: func(c *cache[string, string]) {
+				c.Set("a", "aa")
+				c.Set("b", "bb")
+				c.Set("b", "b")
+				c.Set("c", "cc")
+			},
+			want: []string{"b", "cc"},
+		},
+		{
+			name: "byte budget: value over budget",
+			opts: Options[string]{MaxBytes: 2, SizeOf: func(v string) int64 { return int64(len(v)) }},
+			setup: func(c *cache[string, string]) {
+				c.Set("a", "aa")
+				c.Set("b", "bbb")
+			},
+			want: []string{"aa"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCache[string, string](tt.opts)
+			c.markComplete(c.losses())
+
+			tt.setup(c)
+
+			got, complete := c.GetAll()
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Cache values = %v, want %v", got, tt.want)
+			}
+			if complete {
+				t.Errorf("Cache is complete after eviction")
+			}
+		})
+	}
+}
+
+// TestCache_TTL проверяет удаление записей с истекшим временем жизни.
+func TestCache_TTL(t *testing.T) {
+	now := MustParseTime(time.RFC3339, "2024-02-01T10:00:00Z")
+	c := NewCache[string, string](Options[string]{TTL: time.Minute})
+	c.now = func() time.Time { return now }
+	c.markComplete(c.losses())
+
+	c.Set("a", "a")
+	now = now.Add(30 * time.Second)
+	c.Set("b", "b")
+	now = now.Add(30 * time.Second)
+
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("Cache returned an expired value")
+	}
+	if _, ok := c.Get("b"); !ok {
+		t.Errorf("Cache lost a live value")
+	}
+
+	now = now.Add(30 * time.Second)
+	if removed := c.RemoveExpired(); removed != 1 {
+		t.Errorf("Cache removed %v expired values, want 1", removed)
+	}
+	if c.Len() != 0 {
+		t.Errorf("Cache len = %v, want 0", c.Len())
+	}
+	if _, complete := c.GetAll(); complete {
+		t.Errorf("Cache is complete after expiry")
+	}
+}
+
+func MustParseTime(layout string, s string) time.Time {
+	tt, err := time.Parse(layout, s)
+	if err != nil {
+		panic(err)
+	}
+	return tt
+}
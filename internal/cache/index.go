@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"L0/internal/entity"
+)
+
+// Index is a field of orders the order cache is indexed by.
+type Index string
+
+// Indexed fields of orders.
+const (
+	IndexTrackNumber Index = "track_number"
+	IndexCustomerID  Index = "customer_id"
+	IndexPhone       Index = "phone"
+	IndexEmail       Index = "email"
+)
+
+// indexes lists the indexed fields of orders.
+var indexes = []Index{IndexTrackNumber, IndexCustomerID, IndexPhone, IndexEmail}
+
+// indexValue returns the value of an indexed field of an order.
+func indexValue(order *entity.Order, index Index) string {
+	switch index {
+	case IndexTrackNumber:
+		return order.TrackNumber
+	case IndexCustomerID:
+		return order.CustomerID
+	case IndexPhone:
+		return order.Delivery.Phone
+	case IndexEmail:
+		return order.Delivery.Email
+	default:
+		return ""
+	}
+}
+
+// orderIndex maps the values of the indexed fields to the UIDs of the cached orders having them.
+// It is changed and read with the order cache locked.
+type orderIndex map[Index]map[string]map[string]struct{}
+
+// newOrderIndex creates an empty index of all indexed fields.
+func newOrderIndex() orderIndex {
+	x := make(orderIndex, len(indexes))
+	for _, index := range indexes {
+		x[index] = make(map[string]map[string]struct{})
+	}
+	return x
+}
+
+// add indexes an order, empty values are not indexed.
+func (x orderIndex) add(uid string, order *entity.Order) {
+	for _, index := range indexes {
+		value := indexValue(order, index)
+		if value == "" {
+			continue
+		}
+
+		uids, ok := x[index][value]
+		if !ok {
+			uids = make(map[string]struct{})
+			x[index][value] = uids
+		}
+		uids[uid] = struct{}{}
+	}
+}
+
+// remove removes an order from the index.
+func (x orderIndex) remove(uid string, order *entity.Order) {
+	for _, index := range indexes {
+		value := indexValue(order, index)
+		uids, ok := x[index][value]
+		if !ok {
+			continue
+		}
+
+		delete(uids, uid)
+		if len(uids) == 0 {
+			delete(x[index], value)
+		}
+	}
+}
+
+// lookup returns the UIDs of the orders with the value of an indexed field.
+func (x orderIndex) lookup(index Index, value string) []string {
+	uids := make([]string, 0, len(x[index][value]))
+	for uid := range x[index][value] {
+		uids = append(uids, uid)
+	}
+	return uids
+}
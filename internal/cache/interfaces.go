@@ -1,36 +1,90 @@
 package cache
 
 import (
+	"L0/internal/entity"
 	"L0/internal/repository"
 	"context"
+	"time"
 )
 
-//go:generate mockgen -source=./interfaces.go -destination=cache_mock.go -package=cache
+//go:generate mockgen -destination=cache_mock.go -package=cache -self_package=L0/internal/cache L0/internal/cache OrderCache
 
-// Cache represents a structure for caching data.
-type Cache interface {
+// Cache represents a structure for caching values of type V by keys of type K.
+type Cache[K comparable, V any] interface {
 	// Set sets a value in the cache for the specified key.
 	// It takes a key and a value as input parameters.
 	// Returns nothing.
-	Set(key string, value interface{})
+	Set(key K, value V)
 
 	// Get returns the value from the cache for the specified key.
 	// It takes a key as input parameter.
 	// Returns the value and a boolean indicating whether the key was found in the cache.
-	Get(key string) (interface{}, bool)
+	Get(key K) (V, bool)
 
 	// GetAll returns all values in the cache.
-	// Returns a slice of values.
-	// Returns nothing.
-	GetAll() ([]interface{}, bool)
+	// Returns a slice of values and a boolean indicating whether they are all values of their kind,
+	// that is the cache has been completely loaded and no entry has been evicted or expired since.
+	GetAll() ([]V, bool)
 
 	// Delete deletes a value from the cache for the specified key.
 	// It takes a key as input parameter.
 	// Returns nothing.
-	Delete(key string)
+	Delete(key K)
+
+	// RemoveExpired removes the entries whose TTL has passed.
+	// Returns the number of removed entries.
+	RemoveExpired() int
+
+	// Len returns the number of entries in the cache.
+	Len() int
+}
+
+// OrderCache represents a cache of orders by their UIDs.
+type OrderCache interface {
+	Cache[string, *entity.Order]
+
+	// SetMissing remembers for a short time that an order is missing in the repository.
+	// It takes the UID of the order as input parameter.
+	// Returns nothing.
+	SetMissing(key string)
+
+	// Missing reports whether an order has recently been found missing in the repository.
+	// It takes the UID of the order as input parameter.
+	// Returns true until the entry expires or the order is set.
+	Missing(key string) bool
 
-	// Load loads data into the cache from the repository.
+	// Fill loads an order that is not cached and caches it, or remembers it as missing if it is nil.
+	// It takes the UID of the order and the function loading it as input parameters.
+	// Returns the loaded order or the error of load. The order is not cached if it has been set or deleted
+	// while it was loaded, so that a stale order is not put back after an update or a deletion.
+	Fill(key string, load func() (*entity.Order, error)) (*entity.Order, error)
+
+	// Find returns the cached orders with the value of an indexed field.
+	// It takes the indexed field and its value as input parameters.
+	// Returns a slice of orders and a boolean indicating whether they are all such orders,
+	// that is the cache holds all orders.
+	Find(index Index, value string) ([]*entity.Order, bool)
+
+	// Load loads data into the cache from the repository until the cache is full.
 	// It takes a context and an OrderRepository as input parameters.
 	// Returns an error if the operation fails.
 	Load(ctx context.Context, orderRepository repository.OrderRepository) error
+
+	// Snapshot copies the cached orders into a snapshot.
+	// It takes the sequence of the last NATS message the orders reflect as input parameter.
+	// Returns the snapshot.
+	Snapshot(sequence uint64) *Snapshot
+
+	// Restore loads a snapshot into the cache and catches up with the orders changed in the repository since.
+	// It takes a context, a snapshot, the time to catch up from and an OrderRepository as input parameters.
+	// Returns an error if the catch-up fails, then the orders of the snapshot are not kept.
+	Restore(ctx context.Context, snapshot *Snapshot, since time.Time, orderRepository repository.OrderRepository) error
+
+	// Reconcile compares the cache with the live orders in the repository and repairs the divergence.
+	// It takes a context and an OrderRepository as input parameters.
+	// Returns the outcome of the comparison or an error if the repository can't be read.
+	Reconcile(ctx context.Context, orderRepository repository.OrderRepository) (*entity.Reconciliation, error)
+
+	// LastReconciliation returns the outcome of the latest reconciliation, nil if there has been none.
+	LastReconciliation() *entity.Reconciliation
 }
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"L0/internal/entity"
+	"L0/internal/repository"
+)
+
+// Reconcile compares the cached orders with the live orders in the repository and repairs the divergence:
+// cached orders absent from the repository are removed, cached orders whose content differs are replaced
+// and orders absent from the cache are added until it is full.
+// An entry changed while the repository is read is left as is and compared again next time.
+// Returns the outcome, which is also kept for LastReconciliation, or an error if the repository can't be read.
+func (c *orderCache) Reconcile(ctx context.Context, orderRepository repository.OrderRepository) (*entity.Reconciliation, error) {
+	c.reconciling.Lock()
+	defer c.reconciling.Unlock()
+
+	report := &entity.Reconciliation{StartedAt: c.now()}
+	defer func() {
+		report.FinishedAt = c.now()
+		c.last.Store(report)
+	}()
+
+	// The cache is read first, so that every cached order has been stored by the time the repository is read
+	since := c.losses()
+	cached, complete := c.entries()
+	report.Cached = len(cached)
+
+	// Orders are added until one is evicted, a full cache can't hold them all
+	fill := true
+	err := orderRepository.Stream(ctx, func(order *entity.Order) error {
+		report.Checked++
+
+		current, ok := cached[order.OrderUID]
+		if ok {
+			delete(cached, order.OrderUID)
+			if orderHash(current) == orderHash(order) {
+				return nil
+			}
+
+			report.Mismatched++
+			if c.setIf(order.OrderUID, order, func(v *entity.Order, ok bool) bool { return ok && v == current }) {
+				report.Repaired++
+			}
+			return nil
+		}
+
+		if !fill {
+			return nil
+		}
+		// An order cached meanwhile is at least as recent as this one
+		if !c.setIf(order.OrderUID, order, func(_ *entity.Order, ok bool) bool { return !ok }) {
+			return nil
+		}
+		if complete {
+			report.Missing++
+			report.Repaired++
+		} else {
+			report.Loaded++
+		}
+		if c.losses() != since {
+			fill = false
+		}
+
+		return nil
+	})
+	if err != nil {
+		report.Error = err.Error()
+		return report, fmt.Errorf("can't get orders from database: %w", err)
+	}
+
+	// The orders left were deleted or never stored under their keys
+	for key, current := range cached {
+		report.Stale++
+		if c.deleteIf(key, func(v *entity.Order) bool { return v == current }) {
+			report.Repaired++
+		}
+	}
+
+	report.Complete = c.markComplete(since)
+
+	return report, nil
+}
+
+// LastReconciliation returns the outcome of the latest reconciliation, nil if there has been none.
+func (c *orderCache) LastReconciliation() *entity.Reconciliation {
+	return c.last.Load()
+}
+
+// orderHash returns the SHA-256 hash of the content of an order.
+// The creation date is taken in UTC at the precision of the database and no items equal empty items,
+// so that an order decoded from a message matches the same order read from the database.
+func orderHash(order *entity.Order) [sha256.Size]byte {
+	normalized := *order
+	normalized.DateCreated = entity.StoredTime(order.DateCreated)
+	if len(normalized.Items) == 0 {
+		normalized.Items = nil
+	}
+
+	data, _ := json.Marshal(&normalized)
+	return sha256.Sum256(data)
+}
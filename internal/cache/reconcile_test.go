@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"L0/internal/entity"
+	"L0/internal/repository"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// TestOrderCache_Reconcile проверяет сверку кэша с базой данных и исправление расхождений.
+func TestOrderCache_Reconcile(t *testing.T) {
+	created := MustParseTime(time.RFC3339, "2024-02-01T10:00:00+03:00")
+	tests := []struct {
+		name         string
+		opts         Options[*entity.Order]
+		complete     bool
+		cached       map[string]*entity.Order
+		stored       []*entity.Order
+		want         entity.Reconciliation
+		wantVersions map[string]int
+	}{
+		{
+			name:     "matches",
+			complete: true,
+			cached: map[string]*entity.Order{
+				"order_uid_1": {OrderUID: "order_uid_1", DateCreated: created, Items: []entity.Item{}, Version: 1},
+			},
+			stored: []*entity.Order{
+				{OrderUID: "order_uid_1", DateCreated: created.UTC(), Version: 1},
+			},
+			want:         entity.Reconciliation{Checked: 1, Cached: 1, Complete: true},
+			wantVersions: map[string]int{"order_uid_1": 1},
+		},
+		{
+			name:     "matches: sub-microsecond timestamp",
+			complete: true,
+			cached: map[string]*entity.Order{
+				"order_uid_1": {OrderUID: "order_uid_1", DateCreated: created.Add(1500 * time.Nanosecond), Version: 1},
+			},
+			stored: []*entity.Order{
+				{OrderUID: "order_uid_1", DateCreated: created.UTC().Add(2 * time.Microsecond), Version: 1},
+			},
+			want:         entity.Reconciliation{Checked: 1, Cached: 1, Complete: true},
+			wantVersions: map[string]int{"order_uid_1": 1},
+		},
+		{
+			name:     "repairs drift",
+			complete: true,
+			cached: map[string]*entity.Order{
+				"order_uid_1": {OrderUID: "order_uid_1", Version: 1},
+				"order_uid_2": {OrderUID: "order_uid_2", Version: 1},
+				"message_id":  {OrderUID: "order_uid_4", Version: 1},
+			},
+			stored: []*entity.Order{
+				{OrderUID: "order_uid_1", Version: 2},
+				{OrderUID: "order_uid_3", Version: 1},
+				{OrderUID: "order_uid_4", Version: 1},
+			},
+			want:         entity.Reconciliation{Checked: 3, Cached: 3, Stale: 2, Mismatched: 1, Missing: 2, Repaired: 5, Complete: true},
+			wantVersions: map[string]int{"order_uid_1": 2, "order_uid_3": 1, "order_uid_4": 1},
+		},
+		{
+			name: "fills incomplete cache",
+			cached: map[string]*entity.Order{
+				"order_uid_1": {OrderUID: "order_uid_1", Version: 1},
+			},
+			stored: []*entity.Order{
+				{OrderUID: "order_uid_1", Version: 1},
+				{OrderUID: "order_uid_2", Version: 1},
+			},
+			want:         entity.Reconciliation{Checked: 2, Cached: 1, Loaded: 1, Complete: true},
+			wantVersions: map[string]int{"order_uid_1": 1, "order_uid_2": 1},
+		},
+		{
+			name: "stops filling full cache",
+			opts: Options[*entity.Order]{MaxEntries: 1},
+			stored: []*entity.Order{
+				{OrderUID: "order_uid_1", Version: 1},
+				{OrderUID: "order_uid_2", Version: 1},
+				{OrderUID: "order_uid_3", Version: 1},
+			},
+			want:         entity.Reconciliation{Checked: 3, Loaded: 2, Complete: false},
+			wantVersions: map[string]int{"order_uid_2": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repo := repository.NewMockOrderRepository(ctrl)
+			repo.EXPECT().Stream(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(order *entity.Order) error) error {
+				for _, order := range tt.stored {
+					if err := fn(order); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+
+			c := NewOrderCache(tt.opts, 0)
+			for key, order := range tt.cached {
+				c.Set(key, order)
+			}
+			if tt.complete {
+				c.markComplete(c.losses())
+			}
+
+			got, err := c.Reconcile(context.Background(), repo)
+			if err != nil {
+				t.Errorf("Cache reconcile error = %v", err)
+				return
+			}
+			got.StartedAt, got.FinishedAt = time.Time{}, time.Time{}
+			if *got != tt.want {
+				t.Errorf("Cache reconcile = %+v, want %+v", *got, tt.want)
+			}
+
+			entries, _ := c.entries()
+			versions := make(map[string]int, len(entries))
+			for key, order := range entries {
+				versions[key] = order.Version
+			}
+			if !reflect.DeepEqual(versions, tt.wantVersions) {
+				t.Errorf("Cache versions = %v, want %v", versions, tt.wantVersions)
+			}
+			if c.LastReconciliation() != got {
+				t.Errorf("Cache keeps another reconciliation")
+			}
+		})
+	}
+}
+
+// TestOrderCache_Reconcile_Error проверяет, что при ошибке чтения базы данных кэш не изменяется.
+func TestOrderCache_Reconcile_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := repository.NewMockOrderRepository(ctrl)
+	repo.EXPECT().Stream(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+
+	c := NewOrderCache(Options[*entity.Order]{}, 0)
+	c.Set("order_uid_1", &entity.Order{OrderUID: "order_uid_1"})
+
+	got, err := c.Reconcile(context.Background(), repo)
+	if err == nil || got.Error == "" {
+		t.Errorf("Cache reconcile error = %v, report error = %q", err, got.Error)
+	}
+	if _, ok := c.Get("order_uid_1"); !ok {
+		t.Errorf("Cache removed an order after a failed reconciliation")
+	}
+}
+
+// TestOrderCache_Reconcile_Concurrent проверяет, что заказ, измененный во время сверки, не перезаписывается.
+func TestOrderCache_Reconcile_Concurrent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := repository.NewMockOrderRepository(ctrl)
+
+	c := NewOrderCache(Options[*entity.Order]{}, 0)
+	c.Set("order_uid_1", &entity.Order{OrderUID: "order_uid_1", Version: 1})
+	c.Set("order_uid_2", &entity.Order{OrderUID: "order_uid_2", Version: 1})
+
+	repo.EXPECT().Stream(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(order *entity.Order) error) error {
+		// The orders change while the database is read
+		c.Set("order_uid_1", &entity.Order{OrderUID: "order_uid_1", Version: 3})
+		c.Set("order_uid_2", &entity.Order{OrderUID: "order_uid_2", Version: 2})
+		return fn(&entity.Order{OrderUID: "order_uid_1", Version: 2})
+	})
+
+	got, err := c.Reconcile(context.Background(), repo)
+	if err != nil {
+		t.Errorf("Cache reconcile error = %v", err)
+		return
+	}
+	if got.Mismatched != 1 || got.Stale != 1 || got.Repaired != 0 {
+		t.Errorf("Cache reconcile = %+v", *got)
+	}
+	if order, _ := c.Get("order_uid_1"); order.Version != 3 {
+		t.Errorf("Cache overwrote a changed order with version %d", order.Version)
+	}
+	if _, ok := c.Get("order_uid_2"); !ok {
+		t.Errorf("Cache removed a changed order")
+	}
+}
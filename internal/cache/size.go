@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"L0/internal/entity"
+	"unsafe"
+)
+
+// OrderSize returns the approximate memory used by an order in bytes:
+// its structs and the contents of their strings.
+func OrderSize(order *entity.Order) int64 {
+	size := int64(unsafe.Sizeof(*order)) +
+		stringsSize(order.OrderUID, order.TrackNumber, order.Entry, order.Locale, order.InternalSignature,
+			order.CustomerID, order.DeliveryService, order.Shardkey, order.OofShard, string(order.Status))
+
+	d := order.Delivery
+	size += stringsSize(d.Name, d.Phone, d.Zip, d.City, d.Address, d.Region, d.Email)
+
+	p := order.Payment
+	size += stringsSize(p.Transaction, p.RequestID, p.Currency, p.Provider, p.Bank)
+
+	size += int64(cap(order.Items)) * int64(unsafe.Sizeof(entity.Item{}))
+	for _, item := range order.Items {
+		size += stringsSize(item.TrackNumber, item.Rid, item.Name, item.Size, item.Brand)
+	}
+
+	if order.Deleted != nil {
+		size += int64(unsafe.Sizeof(*order.Deleted)) + stringsSize(order.Deleted.DeletedBy, order.Deleted.Reason)
+	}
+
+	return size
+}
+
+// stringsSize returns the total length of the strings.
+func stringsSize(values ...string) int64 {
+	var size int64
+	for _, v := range values {
+		size += int64(len(v))
+	}
+	return size
+}
@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"L0/internal/entity"
+)
+
+// snapshotMagic starts every snapshot file and changes with the format of the file.
+const snapshotMagic = "L0CACHE1"
+
+// ErrSnapshotCorrupt is returned when a snapshot file is truncated, altered or of another format.
+var ErrSnapshotCorrupt = errors.New("cache snapshot is corrupt")
+
+// Snapshot is a copy of the order cache written to disk to fill the cache quickly on restart.
+type Snapshot struct {
+	// TakenAt is the time the orders were copied from the cache.
+	TakenAt time.Time
+	// Sequence is the sequence of the last NATS message the orders reflect.
+	Sequence uint64
+	// Complete reports whether the orders are all live orders.
+	Complete bool
+	Orders   []*entity.Order
+}
+
+// WriteSnapshot writes a snapshot to the file at path.
+// The file holds the magic, the gzipped gob of the snapshot and the SHA-256 checksum of both.
+// The snapshot is written to a temporary file that replaces the previous one, so a crash never leaves a partial file.
+func WriteSnapshot(path string, snapshot *Snapshot) error {
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return fmt.Errorf("can't create snapshot directory: %w", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("can't create snapshot file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	err = encodeSnapshot(f, snapshot)
+	if err != nil {
+		return err
+	}
+
+	err = f.Sync()
+	if err != nil {
+		return fmt.Errorf("can't sync snapshot file: %w", err)
+	}
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("can't close snapshot file: %w", err)
+	}
+
+	err = os.Rename(f.Name(), path)
+	if err != nil {
+		return fmt.Errorf("can't replace snapshot file: %w", err)
+	}
+
+	return nil
+}
+
+// ReadSnapshot reads a snapshot from the file at path.
+// It returns an error wrapping os.ErrNotExist if there is no file
+// and an error wrapping ErrSnapshotCorrupt if the file does not pass its checksum or can't be decoded.
+func ReadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read snapshot file: %w", err)
+	}
+
+	return decodeSnapshot(data)
+}
+
+// encodeSnapshot writes the magic, the gzipped gob of a snapshot and their checksum to w.
+func encodeSnapshot(w io.Writer, snapshot *Snapshot) error {
+	buf := bufio.NewWriter(w)
+	checksum := sha256.New()
+	body := io.MultiWriter(buf, checksum)
+
+	_, err := io.WriteString(body, snapshotMagic)
+	if err != nil {
+		return fmt.Errorf("can't write snapshot: %w", err)
+	}
+
+	zw, err := gzip.NewWriterLevel(body, gzip.BestSpeed)
+	if err != nil {
+		return fmt.Errorf("can't compress snapshot: %w", err)
+	}
+	err = gob.NewEncoder(zw).Encode(snapshot)
+	if err != nil {
+		return fmt.Errorf("can't encode snapshot: %w", err)
+	}
+	err = zw.Close()
+	if err != nil {
+		return fmt.Errorf("can't compress snapshot: %w", err)
+	}
+
+	_, err = buf.Write(checksum.Sum(nil))
+	if err != nil {
+		return fmt.Errorf("can't write snapshot: %w", err)
+	}
+
+	err = buf.Flush()
+	if err != nil {
+		return fmt.Errorf("can't write snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// decodeSnapshot verifies the checksum of an encoded snapshot and decodes it.
+func decodeSnapshot(data []byte) (*Snapshot, error) {
+	if len(data) < len(snapshotMagic)+sha256.Size {
+		return nil, fmt.Errorf("%w: file is too short", ErrSnapshotCorrupt)
+	}
+
+	body, sum := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	if string(body[:len(snapshotMagic)]) != snapshotMagic {
+		return nil, fmt.Errorf("%w: unknown format", ErrSnapshotCorrupt)
+	}
+	checksum := sha256.Sum256(body)
+	if !bytes.Equal(checksum[:], sum) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrSnapshotCorrupt)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(body[len(snapshotMagic):]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSnapshotCorrupt, err)
+	}
+	defer zr.Close()
+
+	var snapshot Snapshot
+	err = gob.NewDecoder(zr).Decode(&snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSnapshotCorrupt, err)
+	}
+
+	return &snapshot, nil
+}
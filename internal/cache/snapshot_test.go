@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"L0/internal/entity"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestSnapshot_WriteRead проверяет запись снимка кэша в файл и его чтение.
+func TestSnapshot_WriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshots", "cache.snapshot")
+	want := &Snapshot{
+		TakenAt:  MustParseTime(time.RFC3339, "2024-02-01T10:00:00Z"),
+		Sequence: 42,
+		Complete: true,
+		Orders: []*entity.Order{
+			{
+				OrderUID:    "order_uid_1",
+				TrackNumber: "WBILMTESTTRACK",
+				Items:       []entity.Item{{ChrtID: 9934930, Name: "Mascaras"}},
+				DateCreated: MustParseTime(time.RFC3339, "2021-11-26T06:22:19Z"),
+				Version:     2,
+			},
+		},
+	}
+
+	if err := WriteSnapshot(path, want); err != nil {
+		t.Fatalf("WriteSnapshot() error = %v", err)
+	}
+	// The previous snapshot is replaced
+	if err := WriteSnapshot(path, want); err != nil {
+		t.Fatalf("WriteSnapshot() error = %v", err)
+	}
+
+	got, err := ReadSnapshot(path)
+	if err != nil {
+		t.Fatalf("ReadSnapshot() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadSnapshot() = %+v, want %+v", got, want)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("can't read snapshot directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("snapshot directory has %d files, want 1", len(entries))
+	}
+}
+
+// TestReadSnapshot_Corrupt проверяет обнаружение поврежденного снимка кэша.
+func TestReadSnapshot_Corrupt(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cache.snapshot")
+	if err := WriteSnapshot(path, &Snapshot{Sequence: 1}); err != nil {
+		t.Fatalf("WriteSnapshot() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("can't read snapshot: %v", err)
+	}
+
+	flipped := append([]byte{}, data...)
+	flipped[len(snapshotMagic)+1] ^= 0xff
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "truncated", data: data[:len(data)-1]},
+		{name: "altered", data: flipped},
+		{name: "too short", data: data[:10]},
+		{name: "unknown format", data: append([]byte("L0CACHE0"), data[len(snapshotMagic):]...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			if err := os.WriteFile(path, tt.data, 0o644); err != nil {
+				t.Fatalf("can't write snapshot: %v", err)
+			}
+
+			_, err := ReadSnapshot(path)
+			if !errors.Is(err, ErrSnapshotCorrupt) {
+				t.Errorf("ReadSnapshot() error = %v, want %v", err, ErrSnapshotCorrupt)
+			}
+		})
+	}
+
+	_, err = ReadSnapshot(filepath.Join(dir, "missing"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ReadSnapshot() error = %v, want %v", err, os.ErrNotExist)
+	}
+}
@@ -19,12 +19,17 @@ func (s *source) CreateDelivery(ctx context.Context, delivery *entity.Delivery)
 	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
 	defer dbCancel()
 
+	return createDelivery(dbCtx, s.db, delivery)
+}
+
+// createDelivery inserts a delivery record using the given executor.
+func createDelivery(ctx context.Context, ex executor, delivery *entity.Delivery) (string, error) {
 	// Generate a unique identifier for the delivery
 	deliveryUID := uuid.New().String()
 
 	// Execute the SQL query to insert the delivery record into the database
-	row := s.db.QueryRowContext(
-		dbCtx,
+	_, err := ex.ExecContext(
+		ctx,
 		`INSERT INTO deliveries 
 			(delivery_uid, name, phone, zip, city, address, region, email) 
 		VALUES 
@@ -38,7 +43,7 @@ func (s *source) CreateDelivery(ctx context.Context, delivery *entity.Delivery)
 		delivery.Region,
 		delivery.Email,
 	)
-	if err := row.Err(); err != nil {
+	if err != nil {
 		return "", fmt.Errorf("can't execute query: %w", err)
 	}
 
@@ -54,9 +59,14 @@ func (s *source) GetDeliveryById(ctx context.Context, id string) (*entity.Delive
 	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
 	defer dbCancel()
 
+	return getDeliveryById(dbCtx, s.db, id)
+}
+
+// getDeliveryById retrieves a delivery record by its unique identifier using the given executor.
+func getDeliveryById(ctx context.Context, ex executor, id string) (*entity.DeliveryDB, error) {
 	// Execute the SQL query to retrieve the delivery record by ID from the database
-	row := s.db.QueryRowxContext(
-		dbCtx,
+	row := ex.QueryRowxContext(
+		ctx,
 		"SELECT * FROM deliveries WHERE delivery_uid = $1",
 		id,
 	)
@@ -85,9 +95,14 @@ func (s *source) GetDeliveryByEmail(ctx context.Context, email string) (*entity.
 	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
 	defer dbCancel()
 
-	// Execute the SQL query to retrieve the delivery record by email from the database
-	row := s.db.QueryRowxContext(
-		dbCtx,
+	return getDeliveryByEmail(dbCtx, s.db, email)
+}
+
+// getDeliveryByEmail retrieves a delivery record by email address using the given executor.
+func getDeliveryByEmail(ctx context.Context, ex executor, email string) (*entity.DeliveryDB, error) {
+	// Execute the SQL query to retrieve the delivery record by email address from the database
+	row := ex.QueryRowxContext(
+		ctx,
 		"SELECT * FROM deliveries WHERE email = $1",
 		email,
 	)
@@ -116,9 +131,14 @@ func (s *source) GetDeliveryByPhone(ctx context.Context, phone string) (*entity.
 	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
 	defer dbCancel()
 
+	return getDeliveryByPhone(dbCtx, s.db, phone)
+}
+
+// getDeliveryByPhone retrieves a delivery record by phone number using the given executor.
+func getDeliveryByPhone(ctx context.Context, ex executor, phone string) (*entity.DeliveryDB, error) {
 	// Execute the SQL query to retrieve the delivery record by phone number from the database
-	row := s.db.QueryRowxContext(
-		dbCtx,
+	row := ex.QueryRowxContext(
+		ctx,
 		"SELECT * FROM deliveries WHERE phone = $1",
 		phone,
 	)
@@ -138,3 +158,52 @@ func (s *source) GetDeliveryByPhone(ctx context.Context, phone string) (*entity.
 	// Return the delivery record
 	return &delivery, nil
 }
+
+// resolveDelivery returns the UID of a stored delivery identical to the given one,
+// looked up by phone and then by email, or creates a new delivery record.
+func resolveDelivery(ctx context.Context, ex executor, delivery *entity.Delivery) (string, error) {
+	// Check if a delivery with the same phone number exists
+	if delivery.Phone != "" {
+		stored, err := getDeliveryByPhone(ctx, ex, delivery.Phone)
+		if err != nil && err != sql.ErrNoRows {
+			return "", fmt.Errorf("can't get delivery by phone: %w", err)
+		}
+		if stored != nil && stored.Delivery() == *delivery {
+			return stored.DeliveryUID, nil
+		}
+	}
+
+	// Check if a delivery with the same email exists
+	if delivery.Email != "" {
+		stored, err := getDeliveryByEmail(ctx, ex, delivery.Email)
+		if err != nil && err != sql.ErrNoRows {
+			return "", fmt.Errorf("can't get delivery by email: %w", err)
+		}
+		if stored != nil && stored.Delivery() == *delivery {
+			return stored.DeliveryUID, nil
+		}
+	}
+
+	// Create a new delivery record
+	deliveryUID, err := createDelivery(ctx, ex, delivery)
+	if err != nil {
+		return "", fmt.Errorf("can't create delivery: %w", err)
+	}
+
+	return deliveryUID, nil
+}
+
+// deleteUnusedDelivery deletes a delivery record unless an order uses it, using the given executor.
+func deleteUnusedDelivery(ctx context.Context, ex executor, deliveryUID string) error {
+	_, err := ex.ExecContext(
+		ctx,
+		`DELETE FROM deliveries WHERE delivery_uid = $1
+		AND NOT EXISTS (SELECT 1 FROM orders WHERE delivery_uid = $1)`,
+		deliveryUID,
+	)
+	if err != nil {
+		return fmt.Errorf("can't delete delivery: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"L0/internal/entity"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SaveIngestion inserts or updates the ingestion record of an order in a single transaction.
+// Records are ordered by the sequence of the NATS message carrying the order, see supersedes,
+// so a redelivery or another instance never moves a submission back, while a resubmitted order
+// starts a new submission. A received state resets the reception time, other states keep it.
+// It takes a context and an ingestion entity as input parameters.
+// Returns an error if the operation fails.
+func (s *source) SaveIngestion(ctx context.Context, ingestion *entity.Ingestion) error {
+	// Create a database context with a timeout
+	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
+	defer dbCancel()
+
+	return s.withTx(dbCtx, func(tx *sqlx.Tx) error {
+		// Insert the record if the order has none yet
+		result, err := tx.ExecContext(
+			dbCtx,
+			`INSERT INTO order_ingestions
+			(order_uid, state, reason, sequence, received_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $5)
+			ON CONFLICT (order_uid) DO NOTHING`,
+			ingestion.OrderUID,
+			ingestion.State,
+			ingestion.Reason,
+			ingestion.Sequence,
+			ingestion.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("can't execute query: %w", err)
+		}
+		inserted, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("can't get affected rows: %w", err)
+		}
+		if inserted > 0 {
+			return nil
+		}
+
+		// Lock the existing record and keep it if it is newer
+		var stored entity.Ingestion
+		err = tx.QueryRowxContext(
+			dbCtx,
+			"SELECT * FROM order_ingestions WHERE order_uid = $1 FOR UPDATE",
+			ingestion.OrderUID,
+		).StructScan(&stored)
+		if err != nil {
+			return fmt.Errorf("can't get ingestion: %w", err)
+		}
+		if !supersedes(ingestion, &stored) {
+			return nil
+		}
+
+		receivedAt := stored.ReceivedAt
+		if ingestion.State == entity.IngestionReceived {
+			receivedAt = ingestion.UpdatedAt
+		}
+		_, err = tx.ExecContext(
+			dbCtx,
+			`UPDATE order_ingestions SET state = $2, reason = $3, sequence = $4, received_at = $5, updated_at = $6
+			WHERE order_uid = $1`,
+			ingestion.OrderUID,
+			ingestion.State,
+			ingestion.Reason,
+			ingestion.Sequence,
+			receivedAt,
+			ingestion.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("can't update ingestion: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// supersedes reports whether the ingestion record next replaces the stored one.
+// A record with a zero sequence is written by the submitting side before or instead of publishing,
+// so it belongs to the latest submission and always replaces the stored record.
+// A record written by the subscriber replaces records of earlier messages, including the zero sequence
+// of a submission it has not seen yet, and moves the state of the same message forward until it is final.
+func supersedes(next, stored *entity.Ingestion) bool {
+	switch {
+	case next.Sequence == 0:
+		return true
+	case next.Sequence != stored.Sequence:
+		return next.Sequence > stored.Sequence
+	default:
+		return !stored.State.Terminal()
+	}
+}
+
+// GetIngestion retrieves the ingestion record of an order from the database.
+// It takes a context and an order UID as input parameters.
+// Returns the record, sql.ErrNoRows if there is none, or an error if the operation fails.
+func (s *source) GetIngestion(ctx context.Context, uid string) (*entity.Ingestion, error) {
+	// Create a database context with a timeout
+	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
+	defer dbCancel()
+
+	// Query the record from the database by order UID
+	row := s.db.QueryRowxContext(
+		dbCtx,
+		"SELECT * FROM order_ingestions WHERE order_uid = $1",
+		uid,
+	)
+	if err := row.Err(); err != nil {
+		return nil, fmt.Errorf("can't execute query: %w", err)
+	}
+
+	// Scan the record from the database into a struct
+	var ingestion entity.Ingestion
+	if err := row.StructScan(&ingestion); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("can't scan ingestion: %w", err)
+	}
+
+	return &ingestion, nil
+}
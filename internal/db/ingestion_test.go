@@ -0,0 +1,236 @@
+package db
+
+import (
+	"L0/internal/entity"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func Test_source_SaveIngestion(t *testing.T) {
+	receivedAt := MustParseTime(time.RFC3339, "2021-11-27T10:00:00Z")
+	updatedAt := MustParseTime(time.RFC3339, "2021-11-27T10:00:01Z")
+	columns := []string{"order_uid", "state", "reason", "sequence", "received_at", "updated_at"}
+
+	tests := []struct {
+		name      string
+		ingestion *entity.Ingestion
+		setup     func(mock sqlmock.Sqlmock)
+		wantErr   bool
+	}{
+		{
+			name:      "ok: inserted",
+			ingestion: &entity.Ingestion{OrderUID: "order_uid_1", State: entity.IngestionRejected, Reason: "invalid order", Sequence: 42, UpdatedAt: updatedAt},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO order_ingestions .* ON CONFLICT \(order_uid\) DO NOTHING`).
+					WithArgs("order_uid_1", entity.IngestionRejected, "invalid order", uint64(42), updatedAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:      "ok: resubmitted after persisted",
+			ingestion: &entity.Ingestion{OrderUID: "order_uid_1", State: entity.IngestionReceived, UpdatedAt: updatedAt},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO order_ingestions`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`SELECT \* FROM order_ingestions WHERE order_uid = \$1 FOR UPDATE`).
+					WithArgs("order_uid_1").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("order_uid_1", "persisted", "", 42, receivedAt, receivedAt))
+				mock.ExpectExec(`UPDATE order_ingestions SET .* WHERE order_uid = \$1`).
+					WithArgs("order_uid_1", entity.IngestionReceived, "", uint64(0), updatedAt, updatedAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:      "ok: persisted keeps reception time",
+			ingestion: &entity.Ingestion{OrderUID: "order_uid_1", State: entity.IngestionPersisted, Sequence: 42, UpdatedAt: updatedAt},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO order_ingestions`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`SELECT \* FROM order_ingestions`).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("order_uid_1", "validated", "", 42, receivedAt, receivedAt))
+				mock.ExpectExec(`UPDATE order_ingestions`).
+					WithArgs("order_uid_1", entity.IngestionPersisted, "", uint64(42), receivedAt, updatedAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:      "ok: redelivery ignored",
+			ingestion: &entity.Ingestion{OrderUID: "order_uid_1", State: entity.IngestionValidated, Sequence: 42, UpdatedAt: updatedAt},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO order_ingestions`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`SELECT \* FROM order_ingestions`).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("order_uid_1", "persisted", "", 42, receivedAt, receivedAt))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:      "fail: query error",
+			ingestion: &entity.Ingestion{OrderUID: "order_uid_1", State: entity.IngestionReceived, UpdatedAt: updatedAt},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO order_ingestions`).WillReturnError(fmt.Errorf("query error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name:      "fail: update error",
+			ingestion: &entity.Ingestion{OrderUID: "order_uid_1", State: entity.IngestionReceived, UpdatedAt: updatedAt},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO order_ingestions`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`SELECT \* FROM order_ingestions`).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("order_uid_1", "persisted", "", 42, receivedAt, receivedAt))
+				mock.ExpectExec(`UPDATE order_ingestions`).WillReturnError(fmt.Errorf("query error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Errorf("can't connect to database: %v", err)
+				return
+			}
+			defer db.Close()
+
+			s := &source{
+				db: sqlx.NewDb(db, "sqlmock"),
+			}
+
+			tt.setup(mock)
+
+			err = s.SaveIngestion(context.Background(), tt.ingestion)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("source.SaveIngestion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+// Test_supersedes replays the records written while an order is submitted, redelivered and resubmitted.
+func Test_supersedes(t *testing.T) {
+	steps := []struct {
+		name string
+		next entity.Ingestion
+		want entity.IngestionState
+	}{
+		{name: "received", next: entity.Ingestion{State: entity.IngestionReceived}, want: entity.IngestionReceived},
+		{name: "validated", next: entity.Ingestion{State: entity.IngestionValidated, Sequence: 42}, want: entity.IngestionValidated},
+		{name: "persisted", next: entity.Ingestion{State: entity.IngestionPersisted, Sequence: 42}, want: entity.IngestionPersisted},
+		{name: "redelivery validated", next: entity.Ingestion{State: entity.IngestionValidated, Sequence: 42}, want: entity.IngestionPersisted},
+		{name: "redelivery rejected", next: entity.Ingestion{State: entity.IngestionRejected, Sequence: 42}, want: entity.IngestionPersisted},
+		{name: "resubmitted", next: entity.Ingestion{State: entity.IngestionReceived}, want: entity.IngestionReceived},
+		{name: "resubmission validated", next: entity.Ingestion{State: entity.IngestionValidated, Sequence: 50}, want: entity.IngestionValidated},
+		{name: "late redelivery of first message", next: entity.Ingestion{State: entity.IngestionPersisted, Sequence: 42}, want: entity.IngestionValidated},
+		{name: "resubmission rejected", next: entity.Ingestion{State: entity.IngestionRejected, Sequence: 50}, want: entity.IngestionRejected},
+		{name: "resubmitted invalid", next: entity.Ingestion{State: entity.IngestionRejected}, want: entity.IngestionRejected},
+		{name: "resubmitted again", next: entity.Ingestion{State: entity.IngestionReceived}, want: entity.IngestionReceived},
+		{name: "redrive persisted", next: entity.Ingestion{State: entity.IngestionPersisted, Sequence: 57}, want: entity.IngestionPersisted},
+	}
+
+	var stored entity.Ingestion
+	for i, step := range steps {
+		next := step.next
+		if i == 0 || supersedes(&next, &stored) {
+			stored = next
+		}
+		if stored.State != step.want {
+			t.Errorf("%s: state = %s, want %s", step.name, stored.State, step.want)
+		}
+	}
+}
+
+func Test_source_GetIngestion(t *testing.T) {
+	receivedAt := MustParseTime(time.RFC3339, "2021-11-27T10:00:00Z")
+	updatedAt := MustParseTime(time.RFC3339, "2021-11-27T10:00:01Z")
+	columns := []string{"order_uid", "state", "reason", "sequence", "received_at", "updated_at"}
+
+	tests := []struct {
+		name       string
+		setup      func(mock sqlmock.Sqlmock)
+		want       *entity.Ingestion
+		wantErr    bool
+		wantNoRows bool
+	}{
+		{
+			name: "ok",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT \* FROM order_ingestions WHERE order_uid = \$1`).
+					WithArgs("order_uid_1").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("order_uid_1", "persisted", "", 42, receivedAt, updatedAt))
+			},
+			want: &entity.Ingestion{
+				OrderUID:   "order_uid_1",
+				State:      entity.IngestionPersisted,
+				Sequence:   42,
+				ReceivedAt: receivedAt,
+				UpdatedAt:  updatedAt,
+			},
+		},
+		{
+			name: "fail: no rows",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT \* FROM order_ingestions`).WillReturnRows(sqlmock.NewRows(columns))
+			},
+			wantErr:    true,
+			wantNoRows: true,
+		},
+		{
+			name: "fail: query error",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT \* FROM order_ingestions`).WillReturnError(fmt.Errorf("query error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Errorf("can't connect to database: %v", err)
+				return
+			}
+			defer db.Close()
+
+			s := &source{
+				db: sqlx.NewDb(db, "sqlmock"),
+			}
+
+			tt.setup(mock)
+
+			got, err := s.GetIngestion(context.Background(), "order_uid_1")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("source.GetIngestion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if errors.Is(err, sql.ErrNoRows) != tt.wantNoRows {
+				t.Errorf("source.GetIngestion() error = %v, wantNoRows %v", err, tt.wantNoRows)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("source.GetIngestion() = %+v, want %+v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
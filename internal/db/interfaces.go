@@ -5,16 +5,28 @@ package db
 import (
 	"L0/internal/entity"
 	"context"
+	"time"
 )
 
 //go:generate mockgen -source=interfaces.go -destination=source_mock.go -package=db
 
 // OrderSource provides methods for working with orders in the database.
 type OrderSource interface {
-	// CreateOrder creates a new order in the database.
+	// CreateOrder creates a new order with its delivery, payment and items in a single transaction.
 	// It returns the unique identifier of the created order or an error if the operation fails.
 	CreateOrder(ctx context.Context, order *entity.Order) (string, error)
 
+	// SaveOrder idempotently stores an order with its delivery, payment and items in a single transaction.
+	// An identical payload for a stored order is a no-op, a changed one is handled according to the policy.
+	// It returns the outcome of the operation, an error wrapping entity.ErrConflict, or an error if the operation fails.
+	SaveOrder(ctx context.Context, order *entity.Order, policy entity.UpdatePolicy) (entity.SaveOutcome, error)
+
+	// UpdateOrder replaces a stored order at the given version with its delivery, payment and items
+	// in a single transaction, zero matches any version.
+	// It returns sql.ErrNoRows if the order does not exist, an error wrapping entity.ErrVersionMismatch
+	// or entity.ErrConflict, or an error if the operation fails.
+	UpdateOrder(ctx context.Context, order *entity.Order, version int) error
+
 	// GetOrderByUid returns an order from the database by its unique identifier.
 	// It returns the order and an error if the order with the specified identifier is not found.
 	GetOrderByUid(ctx context.Context, uid string) (*entity.Order, error)
@@ -23,10 +35,54 @@ type OrderSource interface {
 	// It returns a list of orders and an error if the operation fails.
 	GetAllOrders(ctx context.Context) ([]*entity.Order, error)
 
-	// DeleteOrder deletes an order record from the database.
-	// It takes a context and an order UID as input parameters.
-	// Returns an error if the operation fails.
-	DeleteOrder(ctx context.Context, orderUID string) error
+	// StreamOrders passes all orders from the database to fn one by one without loading them all into memory.
+	// It returns the error returned by fn or an error if the operation fails.
+	StreamOrders(ctx context.Context, fn func(order *entity.Order) error) error
+
+	// ListOrders returns a page of orders using keyset pagination.
+	// It returns the page with the cursor of the next page, if any, or an error if the operation fails.
+	ListOrders(ctx context.Context, query entity.OrderListQuery) (*entity.OrderPage, error)
+
+	// SearchOrders returns a page of orders matching a filter using keyset pagination.
+	// It returns the page with the cursor of the next page, if any, or an error if the operation fails.
+	SearchOrders(ctx context.Context, query entity.OrderSearchQuery) (*entity.OrderPage, error)
+
+	// DeleteOrder soft-deletes an order at the given version, recording the author and the reason of the deletion.
+	// Zero matches any version.
+	// It returns sql.ErrNoRows if the order does not exist or is already deleted, an error wrapping
+	// entity.ErrVersionMismatch if the order is at another version, or an error if the operation fails.
+	DeleteOrder(ctx context.Context, orderUID string, deletedBy string, reason string, version int) error
+
+	// RestoreOrder restores a soft-deleted order.
+	// It returns sql.ErrNoRows if the order does not exist or is not deleted, or an error if the operation fails.
+	RestoreOrder(ctx context.Context, orderUID string) error
+
+	// PurgeDeletedOrders permanently deletes orders soft-deleted more than olderThan ago
+	// with their payments, items and deliveries no other order uses.
+	// It returns the number of purged orders or an error if the operation fails.
+	PurgeDeletedOrders(ctx context.Context, olderThan time.Duration) (int64, error)
+
+	// ChangeOrderStatus moves an order from change.From to change.To and records the transition in the status history.
+	// It returns sql.ErrNoRows if the order does not exist, is deleted or is not in change.From,
+	// or an error if the operation fails.
+	ChangeOrderStatus(ctx context.Context, change *entity.StatusChange) error
+
+	// GetStatusHistory returns the status transitions of an order, oldest first.
+	// It returns a list of status changes or an error if the operation fails.
+	GetStatusHistory(ctx context.Context, orderUID string) ([]*entity.StatusChange, error)
+
+	// GetOrderVersions returns the versions of an order without snapshots, oldest first.
+	// It returns a list of versions or an error if the operation fails.
+	GetOrderVersions(ctx context.Context, orderUID string) ([]*entity.OrderVersion, error)
+
+	// GetOrderVersion returns a version of an order with its snapshot.
+	// It returns sql.ErrNoRows if the version does not exist or an error if the operation fails.
+	GetOrderVersion(ctx context.Context, orderUID string, version int) (*entity.OrderVersion, error)
+
+	// GetChangedOrderUids returns the unique identifiers of orders with versions taken at or after since,
+	// including deleted and purged orders.
+	// It returns a list of order UIDs or an error if the operation fails.
+	GetChangedOrderUids(ctx context.Context, since time.Time) ([]string, error)
 }
 
 // DeliverySource provides methods for working with deliveries in the database.
@@ -50,9 +106,9 @@ type DeliverySource interface {
 
 // PaymentSource provides methods for working with payments in the database.
 type PaymentSource interface {
-	// CreatePayment creates a new payment record in the database.
+	// CreatePayment creates a new payment record of an order in the database.
 	// It returns the unique identifier of the created payment or an error if the operation fails.
-	CreatePayment(ctx context.Context, payment *entity.Payment) (string, error)
+	CreatePayment(ctx context.Context, orderUID string, payment *entity.Payment) (string, error)
 
 	// GetPaymentByTransaction returns a payment record from the database by its transaction identifier.
 	// It returns the payment record and an error if the record with the specified transaction identifier is not found.
@@ -61,13 +117,13 @@ type PaymentSource interface {
 
 // ItemSource provides methods for working with items in the database.
 type ItemSource interface {
-	// CreateItem creates a new item in the database.
+	// CreateItem creates a new item of an order in the database.
 	// It returns the unique identifier of the created item or an error if the operation fails.
-	CreateItem(ctx context.Context, item *entity.Item) (string, error)
+	CreateItem(ctx context.Context, orderUID string, item *entity.Item) (string, error)
 
-	// CreateItems creates multiple new items in the database.
+	// CreateItems creates multiple new items of an order in the database in a single transaction.
 	// It returns a list of unique identifiers of the created items or an error if the operation fails.
-	CreateItems(ctx context.Context, items []entity.Item) ([]string, error)
+	CreateItems(ctx context.Context, orderUID string, items []entity.Item) ([]string, error)
 
 	// GetItemByUid returns an item from the database by its unique identifier.
 	// It returns the item and an error if the item with the specified identifier is not found.
@@ -77,3 +133,34 @@ type ItemSource interface {
 	// It returns a list of items and an error if items with the specified tracking number are not found.
 	GetItemsByTrackNumber(ctx context.Context, trackNumber string) ([]entity.Item, error)
 }
+
+// RejectedMessageSource provides methods for working with rejected NATS messages in the database.
+type RejectedMessageSource interface {
+	// CreateRejectedMessage stores a rejected message in the database.
+	// It returns the identifier of the stored message or an error if the operation fails.
+	CreateRejectedMessage(ctx context.Context, msg *entity.RejectedMessage) (int64, error)
+
+	// GetRejectedMessageById returns a rejected message from the database by its identifier.
+	// It returns the message and an error if the message with the specified identifier is not found.
+	GetRejectedMessageById(ctx context.Context, id int64) (*entity.RejectedMessage, error)
+
+	// GetAllRejectedMessages returns all rejected messages from the database, newest first.
+	// It returns a list of messages and an error if the operation fails.
+	GetAllRejectedMessages(ctx context.Context) ([]*entity.RejectedMessage, error)
+
+	// DeleteRejectedMessage deletes a rejected message from the database.
+	// It returns sql.ErrNoRows if the message with the specified identifier is not found.
+	DeleteRejectedMessage(ctx context.Context, id int64) error
+}
+
+// IngestionSource provides methods for working with order ingestion records in the database.
+type IngestionSource interface {
+	// SaveIngestion inserts or updates the ingestion record of an order.
+	// A record of an earlier NATS message or a repeated record of a final state is ignored,
+	// a record with a zero sequence starts or finishes a new submission. It returns an error if the operation fails.
+	SaveIngestion(ctx context.Context, ingestion *entity.Ingestion) error
+
+	// GetIngestion returns the ingestion record of an order from the database.
+	// It returns sql.ErrNoRows if the order has no record.
+	GetIngestion(ctx context.Context, uid string) (*entity.Ingestion, error)
+}
@@ -7,22 +7,39 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
-// CreateItem creates a new item record in the database.
-// It takes a context and an item entity as input parameters.
+// itemColumns lists the columns of the items table mapped to entity.Item.
+const itemColumns = `chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status`
+
+// itemRow represents an item row with the order it belongs to.
+type itemRow struct {
+	OrderUID string `db:"order_uid"`
+	entity.Item
+}
+
+// CreateItem creates a new item record of an order in the database.
+// It takes a context, an order UID and an item entity as input parameters.
 // Returns the unique identifier of the created item or an error if the operation fails.
-func (s *source) CreateItem(ctx context.Context, item *entity.Item) (string, error) {
+func (s *source) CreateItem(ctx context.Context, orderUID string, item *entity.Item) (string, error) {
 	// Create a database context with a timeout
 	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
 	defer dbCancel()
 
+	return createItem(dbCtx, s.db, orderUID, item)
+}
+
+// createItem inserts an item record of an order using the given executor.
+func createItem(ctx context.Context, ex executor, orderUID string, item *entity.Item) (string, error) {
 	// Execute the SQL query to insert the item record into the database
-	row := s.db.QueryRowContext(
-		dbCtx,
+	_, err := ex.ExecContext(
+		ctx,
 		`INSERT INTO items
-		(chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		(chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status, order_uid) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
 		item.ChrtID,
 		item.TrackNumber,
 		item.Price,
@@ -34,8 +51,9 @@ func (s *source) CreateItem(ctx context.Context, item *entity.Item) (string, err
 		item.NmID,
 		item.Brand,
 		item.Status,
+		orderUID,
 	)
-	if err := row.Err(); err != nil {
+	if err != nil {
 		return "", fmt.Errorf("can't execute query: %w", err)
 	}
 
@@ -43,65 +61,41 @@ func (s *source) CreateItem(ctx context.Context, item *entity.Item) (string, err
 	return item.Rid, nil
 }
 
-// CreateItems creates multiple new item records in the database.
-// It takes a context and a slice of item entities as input parameters.
+// CreateItems creates multiple new item records of an order in the database in a single transaction.
+// It takes a context, an order UID and a slice of item entities as input parameters.
 // Returns a slice of unique identifiers of the created items or an error if the operation fails.
-func (s *source) CreateItems(ctx context.Context, items []entity.Item) ([]string, error) {
+func (s *source) CreateItems(ctx context.Context, orderUID string, items []entity.Item) ([]string, error) {
 	// Create a database context with a timeout
 	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
 	defer dbCancel()
 
-	// Begin a transaction
-	tx, err := s.db.BeginTx(dbCtx, nil)
+	var rids []string
+	err := s.withTx(dbCtx, func(tx *sqlx.Tx) error {
+		var err error
+		rids, err = createItems(dbCtx, tx, orderUID, items)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer tx.Rollback()
 
+	return rids, nil
+}
+
+// createItems inserts multiple item records of an order using the given executor.
+func createItems(ctx context.Context, ex executor, orderUID string, items []entity.Item) ([]string, error) {
 	// Create a slice to store the unique identifiers of the created items
 	rids := make([]string, 0, len(items))
 
-	// Prepare the SQL query for inserting items into the database
-	stmt, err := tx.PrepareContext(dbCtx,
-		`INSERT INTO items
-        (chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status) 
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING rid`,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("can't prepare query: %w", err)
-	}
-	defer stmt.Close()
-
-	// Iterate over each item and execute the SQL query to insert it into the database
-	for _, item := range items {
-		var rid string
-		err := stmt.QueryRowContext(dbCtx,
-			item.ChrtID,
-			item.TrackNumber,
-			item.Price,
-			item.Rid,
-			item.Name,
-			item.Sale,
-			item.Size,
-			item.TotalPrice,
-			item.NmID,
-			item.Brand,
-			item.Status,
-		).Scan(&rid)
+	// Iterate over each item and insert it into the database
+	for i := range items {
+		rid, err := createItem(ctx, ex, orderUID, &items[i])
 		if err != nil {
-			if err == sql.ErrNoRows {
-				return nil, sql.ErrNoRows
-			}
-			return nil, fmt.Errorf("can't execute query: %w", err)
+			return nil, err
 		}
 		rids = append(rids, rid)
 	}
 
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		return nil, err
-	}
-
 	// Return the unique identifiers of the created items
 	return rids, nil
 }
@@ -117,7 +111,7 @@ func (s *source) GetItemByUid(ctx context.Context, uid string) (*entity.Item, er
 	// Execute the SQL query to retrieve the item record by ID from the database
 	row := s.db.QueryRowxContext(
 		dbCtx,
-		"SELECT * FROM items WHERE chrt_id = $1",
+		"SELECT "+itemColumns+" FROM items WHERE chrt_id = $1",
 		uid,
 	)
 	if err := row.Err(); err != nil {
@@ -145,37 +139,72 @@ func (s *source) GetItemsByTrackNumber(ctx context.Context, trackNumber string)
 	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
 	defer dbCancel()
 
-	// Execute the SQL query to retrieve item records by tracking number from the database
-	rows, err := s.db.QueryxContext(
-		dbCtx,
-		"SELECT * FROM items WHERE track_number = $1",
-		trackNumber,
+	return getItemsByTrackNumber(dbCtx, s.db, trackNumber)
+}
+
+// getItemsByTrackNumber retrieves item records by tracking number using the given executor.
+func getItemsByTrackNumber(ctx context.Context, ex executor, trackNumber string) ([]entity.Item, error) {
+	return selectItems(ctx, ex, "track_number", trackNumber)
+}
+
+// getItemsByOrderUid retrieves the item records of an order using the given executor.
+func getItemsByOrderUid(ctx context.Context, ex executor, orderUID string) ([]entity.Item, error) {
+	return selectItems(ctx, ex, "order_uid", orderUID)
+}
+
+// selectItems retrieves item records having the given value in the column using the given executor.
+func selectItems(ctx context.Context, ex executor, column string, value string) ([]entity.Item, error) {
+	// Execute the SQL query to retrieve item records from the database
+	rows, err := ex.QueryxContext(
+		ctx,
+		"SELECT "+itemColumns+" FROM items WHERE "+column+" = $1",
+		value,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("can't execute query: %w", err)
 	}
 	defer rows.Close()
 
-	// Create a slice to store pointers to item structs temporarily
-	var itemPointers []*entity.Item
+	items := []entity.Item{}
 	for rows.Next() {
 		var item entity.Item
 		if err := rows.StructScan(&item); err != nil {
-			if err == sql.ErrNoRows {
-				return nil, sql.ErrNoRows
-			}
 			return nil, fmt.Errorf("can't scan item: %w", err)
 		}
-		itemPointers = append(itemPointers, &item)
+		items = append(items, item)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error after scanning items: %w", err)
 	}
 
-	// Convert pointers to item structs into item structs before returning
-	items := make([]entity.Item, len(itemPointers))
-	for i, ptr := range itemPointers {
-		items[i] = *ptr
+	return items, nil
+}
+
+// getItemsByOrderUids retrieves the items of several orders in one query using the given executor.
+// It returns the items grouped by order UID.
+func getItemsByOrderUids(ctx context.Context, ex executor, orderUIDs []string) (map[string][]entity.Item, error) {
+	// Execute the SQL query to retrieve item records for all orders at once
+	rows, err := ex.QueryxContext(
+		ctx,
+		"SELECT order_uid, "+itemColumns+" FROM items WHERE order_uid = ANY($1)",
+		pq.Array(orderUIDs),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("can't execute query: %w", err)
+	}
+	defer rows.Close()
+
+	// Group items by order UID
+	items := make(map[string][]entity.Item, len(orderUIDs))
+	for rows.Next() {
+		var row itemRow
+		if err := rows.StructScan(&row); err != nil {
+			return nil, fmt.Errorf("can't scan item: %w", err)
+		}
+		items[row.OrderUID] = append(items[row.OrderUID], row.Item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after scanning items: %w", err)
 	}
 
 	return items, nil
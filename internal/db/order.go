@@ -6,10 +6,23 @@ import (
 	"L0/internal/entity"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// uniqueViolation is the PostgreSQL error code for unique constraint violations.
+const uniqueViolation = "23505"
+
 // CreateOrder creates a new order record in the database.
+// The delivery, payment, items and order rows are written in a single transaction,
+// so a failure at any step leaves no partial aggregate behind.
 // It takes a context and an order entity as input parameters.
 // Returns the unique identifier of the created order or an error if the operation fails.
 func (s *source) CreateOrder(ctx context.Context, order *entity.Order) (string, error) {
@@ -17,63 +30,49 @@ func (s *source) CreateOrder(ctx context.Context, order *entity.Order) (string,
 	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
 	defer dbCancel()
 
-	// Initialize a variable to store the delivery UID
-	var deliveryUID string
-
-	// Check if delivery information is provided
-	if order.Delivery.Phone != "" {
-		// Get delivery information by phone number
-		delivery, err := s.GetDeliveryByPhone(dbCtx, order.Delivery.Phone)
-		if err != nil && err != sql.ErrNoRows {
-			return "", fmt.Errorf("can't get delivery by phone: %w", err)
-		}
-		// If delivery information exists, assign its UID
-		if delivery != nil {
-			deliveryUID = delivery.DeliveryUID
-		}
+	err := s.withTx(dbCtx, func(tx *sqlx.Tx) error {
+		return createOrder(dbCtx, tx, order)
+	})
+	if err != nil {
+		return "", conflictError(err)
 	}
 
-	// If delivery information by phone is not found, check by email
-	if order.Delivery.Email != "" && deliveryUID == "" {
-		// Get delivery information by email
-		delivery, err := s.GetDeliveryByEmail(dbCtx, order.Delivery.Email)
-		if err != nil && err != sql.ErrNoRows {
-			return "", fmt.Errorf("can't get delivery by email: %w", err)
-		}
-		// If delivery information exists, assign its UID
-		if delivery != nil {
-			deliveryUID = delivery.DeliveryUID
-		}
-	}
+	// Return the unique identifier of the created order
+	return order.OrderUID, nil
+}
 
-	// If delivery information is not found, create a new delivery record
-	if deliveryUID == "" {
-		var err error
-		deliveryUID, err = s.CreateDelivery(dbCtx, &order.Delivery)
-		if err != nil {
-			return "", fmt.Errorf("can't create delivery: %w", err)
-		}
+// createOrder inserts the whole order aggregate and records its first version using the given executor.
+// A new order always starts in the created status at version 1. Versions are kept when an order is purged,
+// so an order created again with the same UID continues its version history instead.
+// The creation date is bound in UTC, because the column has no time zone and would drop the offset.
+func createOrder(ctx context.Context, ex executor, order *entity.Order) error {
+	// Reuse an identical delivery or create a new one
+	deliveryUID, err := resolveDelivery(ctx, ex, &order.Delivery)
+	if err != nil {
+		return err
 	}
 
 	// Create payment record
-	_, err := s.CreatePayment(dbCtx, &order.Payment)
+	_, err = createPayment(ctx, ex, order.OrderUID, &order.Payment)
 	if err != nil {
-		return "", fmt.Errorf("can't create payment: %w", err)
+		return fmt.Errorf("can't create payment: %w", err)
 	}
 
 	// Create item records
-	_, err = s.CreateItems(dbCtx, order.Items)
+	_, err = createItems(ctx, ex, order.OrderUID, order.Items)
 	if err != nil {
-		return "", fmt.Errorf("can't create items: %w", err)
+		return fmt.Errorf("can't create items: %w", err)
 	}
 
-	// Insert order record into the database
-	row := s.db.QueryRowContext(
-		dbCtx,
+	// Insert order record into the database, numbering it after the versions kept from a purged order, if any
+	err = ex.QueryRowxContext(
+		ctx,
 		`INSERT INTO orders
 		(order_uid, track_number, entry, delivery_uid, payment_transaction, locale, 
 		internal_signature, customer_id, delivery_service, shardkey, sm_id, 
-		date_created, oof_shard) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		date_created, oof_shard, status, version) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14,
+		(SELECT COALESCE(MAX(version), 0) + 1 FROM order_versions WHERE order_uid = $1))
+		RETURNING version`,
 		order.OrderUID,
 		order.TrackNumber,
 		order.Entry,
@@ -85,206 +84,681 @@ func (s *source) CreateOrder(ctx context.Context, order *entity.Order) (string,
 		order.DeliveryService,
 		order.Shardkey,
 		order.SmID,
-		order.DateCreated,
+		entity.StoredTime(order.DateCreated),
 		order.OofShard,
-	)
-	if err := row.Err(); err != nil {
-		return "", fmt.Errorf("can't execute query: %w", err)
+		entity.StatusCreated,
+	).Scan(&order.Version)
+	if err != nil {
+		return fmt.Errorf("can't execute query: %w", err)
 	}
+	order.Status = entity.StatusCreated
 
-	// Return the unique identifier of the created order
-	return order.OrderUID, nil
+	return recordVersion(ctx, ex, entity.VersionCreated, order)
 }
 
-// GetOrderByUid retrieves an order record from the database by its unique identifier.
-// It takes a context and an order UID as input parameters.
-// Returns the order record or an error if the operation fails.
-func (s *source) GetOrderByUid(ctx context.Context, orderUID string) (*entity.Order, error) {
+// SaveOrder idempotently stores an order in a single transaction.
+// A new order is created, an identical payload is a no-op and a changed payload
+// either replaces the stored order or fails with entity.ErrConflict, depending on the policy.
+// A changed payload for a soft-deleted order always fails with entity.ErrConflict.
+// The payload never changes the status or the deletion, the order gets the stored ones
+// and the version after saving.
+// If a concurrent save creates the same order first, the transaction is retried once,
+// so the payload is compared with the order created by the other save.
+// It takes a context, an order entity and an update policy as input parameters.
+// Returns the outcome of the operation or an error if the operation fails.
+func (s *source) SaveOrder(ctx context.Context, order *entity.Order, policy entity.UpdatePolicy) (entity.SaveOutcome, error) {
 	// Create a database context with a timeout
 	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
 	defer dbCancel()
 
-	// Query the order record from the database by UID
-	row := s.db.QueryRowxContext(
-		dbCtx,
-		`SELECT * FROM orders WHERE order_uid = $1`,
-		orderUID,
-	)
-	if err := row.Err(); err != nil {
-		return nil, fmt.Errorf("can't execute query: %w", err)
+	outcome, err := s.saveOrder(dbCtx, order, policy)
+	if outcome == entity.SaveCreated && isUniqueViolation(err) {
+		// Another save created the order after it was found missing
+		outcome, err = s.saveOrder(dbCtx, order, policy)
+	}
+	if err != nil {
+		return "", conflictError(err)
 	}
 
-	// Initialize a variable to store the order details
-	var orderDB entity.OrderDB
+	return outcome, nil
+}
 
-	// Scan the order record from the database into a struct
-	if err := row.StructScan(&orderDB); err != nil {
+// saveOrder runs a single SaveOrder transaction.
+// Returns the outcome the transaction attempted along with its error, if any.
+func (s *source) saveOrder(ctx context.Context, order *entity.Order, policy entity.UpdatePolicy) (entity.SaveOutcome, error) {
+	var outcome entity.SaveOutcome
+	err := s.withTx(ctx, func(tx *sqlx.Tx) error {
+		// Lock the stored order row, if any, until the transaction ends
+		var stored entity.OrderDB
+		err := tx.QueryRowxContext(
+			ctx,
+			`SELECT * FROM orders WHERE order_uid = $1 FOR UPDATE`,
+			order.OrderUID,
+		).StructScan(&stored)
 		if err == sql.ErrNoRows {
-			return nil, sql.ErrNoRows
+			outcome = entity.SaveCreated
+			return createOrder(ctx, tx, order)
+		}
+		if err != nil {
+			return fmt.Errorf("can't get stored order: %w", err)
+		}
+		order.Status = stored.Status
+		order.Deleted = stored.Deletion()
+		order.Version = stored.Version
+
+		// Compare the stored aggregate with the payload
+		current, err := loadOrder(ctx, tx, &stored)
+		if err != nil {
+			return fmt.Errorf("can't load stored order: %w", err)
+		}
+		if sameOrder(current, order) {
+			outcome = entity.SaveUnchanged
+			return nil
+		}
+
+		// A deleted order must be restored before it can be changed
+		if stored.DeletedAt != nil {
+			return fmt.Errorf("%w: order %s is deleted", entity.ErrConflict, order.OrderUID)
+		}
+		if policy != entity.UpdatePolicyUpdate {
+			return fmt.Errorf("%w: order %s already exists with different content", entity.ErrConflict, order.OrderUID)
 		}
-		return nil, fmt.Errorf("can't scan row: %w", err)
+		outcome = entity.SaveUpdated
+		return replaceOrder(ctx, tx, &stored, order)
+	})
+
+	return outcome, err
+}
+
+// UpdateOrder replaces a stored order with its delivery, payment and items in a single transaction.
+// The status is kept, the order gets the stored one and the new version.
+// It takes a context, an order entity and the version the stored order must be at, zero matches any version.
+// Returns sql.ErrNoRows if the order does not exist or is deleted, an error wrapping entity.ErrVersionMismatch
+// if the order is at another version, or an error if the operation fails.
+func (s *source) UpdateOrder(ctx context.Context, order *entity.Order, version int) error {
+	// Create a database context with a timeout
+	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
+	defer dbCancel()
+
+	err := s.withTx(dbCtx, func(tx *sqlx.Tx) error {
+		// Lock the stored order row until the transaction ends
+		var stored entity.OrderDB
+		err := tx.QueryRowxContext(
+			dbCtx,
+			`SELECT * FROM orders WHERE order_uid = $1 AND deleted_at IS NULL FOR UPDATE`,
+			order.OrderUID,
+		).StructScan(&stored)
+		if err == sql.ErrNoRows {
+			return sql.ErrNoRows
+		}
+		if err != nil {
+			return fmt.Errorf("can't get stored order: %w", err)
+		}
+		if err := checkVersion(&stored, version); err != nil {
+			return err
+		}
+		order.Status = stored.Status
+
+		return replaceOrder(dbCtx, tx, &stored, order)
+	})
+	if err != nil {
+		return conflictError(err)
 	}
 
-	// Initialize an order entity
-	order := entity.Order{
-		OrderUID:          orderDB.OrderUID,
-		TrackNumber:       orderDB.TrackNumber,
-		Entry:             orderDB.Entry,
-		Locale:            orderDB.Locale,
-		InternalSignature: orderDB.InternalSignature,
-		CustomerID:        orderDB.CustomerID,
-		DeliveryService:   orderDB.DeliveryService,
-		Shardkey:          orderDB.Shardkey,
-		SmID:              orderDB.SmID,
-		DateCreated:       orderDB.DateCreated,
-		OofShard:          orderDB.OofShard,
+	return nil
+}
+
+// replaceOrder overwrites the stored order with the given one, increments its version
+// and records the new version using the given executor.
+// The payment and items of the stored order are replaced, the delivery is resolved anew
+// because delivery records may be shared between orders. The creation date is bound in UTC, as in createOrder.
+func replaceOrder(ctx context.Context, ex executor, stored *entity.OrderDB, order *entity.Order) error {
+	// Remove the items and payment of the stored order
+	_, err := ex.ExecContext(ctx, "DELETE FROM items WHERE order_uid = $1", stored.OrderUID)
+	if err != nil {
+		return fmt.Errorf("can't delete items: %w", err)
+	}
+	_, err = ex.ExecContext(ctx, "DELETE FROM payments WHERE order_uid = $1", stored.OrderUID)
+	if err != nil {
+		return fmt.Errorf("can't delete payment: %w", err)
 	}
 
-	// Get delivery details by delivery UID
-	delivery, err := s.GetDeliveryById(dbCtx, orderDB.DeliveryUID)
+	// Reuse an identical delivery or create a new one
+	deliveryUID, err := resolveDelivery(ctx, ex, &order.Delivery)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, sql.ErrNoRows
+		return err
+	}
+
+	// Create the new payment and items
+	_, err = createPayment(ctx, ex, order.OrderUID, &order.Payment)
+	if err != nil {
+		return fmt.Errorf("can't create payment: %w", err)
+	}
+	_, err = createItems(ctx, ex, order.OrderUID, order.Items)
+	if err != nil {
+		return fmt.Errorf("can't create items: %w", err)
+	}
+
+	// Update the order record
+	err = ex.QueryRowxContext(
+		ctx,
+		`UPDATE orders SET
+		track_number = $2, entry = $3, delivery_uid = $4, payment_transaction = $5, locale = $6,
+		internal_signature = $7, customer_id = $8, delivery_service = $9, shardkey = $10, sm_id = $11,
+		date_created = $12, oof_shard = $13, version = version + 1
+		WHERE order_uid = $1 RETURNING version`,
+		order.OrderUID,
+		order.TrackNumber,
+		order.Entry,
+		deliveryUID,
+		order.Payment.Transaction,
+		order.Locale,
+		order.InternalSignature,
+		order.CustomerID,
+		order.DeliveryService,
+		order.Shardkey,
+		order.SmID,
+		entity.StoredTime(order.DateCreated),
+		order.OofShard,
+	).Scan(&order.Version)
+	if err != nil {
+		return fmt.Errorf("can't update order: %w", err)
+	}
+
+	// Remove the previous delivery if no order uses it anymore
+	if stored.DeliveryUID != deliveryUID {
+		err = deleteUnusedDelivery(ctx, ex, stored.DeliveryUID)
+		if err != nil {
+			return err
 		}
-		return nil, fmt.Errorf("can't get delivery: %w", err)
 	}
-	order.Delivery = entity.Delivery{
-		Name:    delivery.Name,
-		Phone:   delivery.Phone,
-		Zip:     delivery.Zip,
-		City:    delivery.City,
-		Address: delivery.Address,
-		Region:  delivery.Region,
-		Email:   delivery.Email,
+
+	return recordVersion(ctx, ex, entity.VersionUpdated, order)
+}
+
+// loadOrder assembles the order aggregate for a stored order row using the given executor.
+func loadOrder(ctx context.Context, ex executor, orderDB *entity.OrderDB) (*entity.Order, error) {
+	order := orderDB.Order()
+
+	// Get delivery details by delivery UID
+	delivery, err := getDeliveryById(ctx, ex, orderDB.DeliveryUID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get delivery: %w", err)
 	}
+	order.Delivery = delivery.Delivery()
 
 	// Get payment details by transaction ID
-	payment, err := s.GetPaymentByTransaction(dbCtx, orderDB.PaymentTransaction)
+	payment, err := getPaymentByTransaction(ctx, ex, orderDB.PaymentTransaction)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, sql.ErrNoRows
-		}
 		return nil, fmt.Errorf("can't get payment: %w", err)
 	}
 	order.Payment = *payment
 
-	// Get items associated with the order by track number
-	items, err := s.GetItemsByTrackNumber(dbCtx, orderDB.TrackNumber)
+	// Get items of the order
+	items, err := getItemsByOrderUid(ctx, ex, orderDB.OrderUID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get items: %w", err)
+	}
+	order.Items = items
+
+	return order, nil
+}
+
+// sameOrder reports whether two orders have the same content.
+// Items are compared regardless of their order and timestamps as the database stores them,
+// the status, the version and the deletion of an order are not a part of its content.
+func sameOrder(a, b *entity.Order) bool {
+	normalize := func(o *entity.Order) entity.Order {
+		n := *o
+		n.DateCreated = entity.StoredTime(o.DateCreated)
+		n.Status = ""
+		n.Version = 0
+		n.Deleted = nil
+		n.Items = append([]entity.Item{}, o.Items...)
+		sort.Slice(n.Items, func(i, j int) bool { return n.Items[i].Rid < n.Items[j].Rid })
+		return n
+	}
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+// checkVersion reports an error wrapping entity.ErrVersionMismatch if the stored order is not at the version.
+// Zero matches any version.
+func checkVersion(stored *entity.OrderDB, version int) error {
+	if version != 0 && stored.Version != version {
+		return fmt.Errorf("%w: order %s is at version %d, not %d", entity.ErrVersionMismatch, stored.OrderUID, stored.Version, version)
+	}
+	return nil
+}
+
+// isUniqueViolation reports whether an error is caused by a unique constraint violation.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+// conflictError wraps unique constraint violations with entity.ErrConflict.
+func conflictError(err error) error {
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%w: %w", entity.ErrConflict, err)
+	}
+	return err
+}
+
+// orderBatchSize is the number of orders whose items are loaded with a single query while streaming.
+const orderBatchSize = 500
+
+// selectOrders selects orders joined with their delivery and payment.
+// Delivery and payment columns are prefixed so that they can be scanned into an orderRow.
+const selectOrders = `SELECT
+	o.order_uid, o.track_number, o.entry, o.delivery_uid, o.payment_transaction, o.locale,
+	o.internal_signature, o.customer_id, o.delivery_service, o.shardkey, o.sm_id,
+	o.date_created, o.oof_shard, o.status, o.version, o.deleted_at, o.deleted_by, o.delete_reason,
+	d.delivery_uid AS "delivery.delivery_uid", d.name AS "delivery.name", d.phone AS "delivery.phone",
+	d.zip AS "delivery.zip", d.city AS "delivery.city", d.address AS "delivery.address",
+	d.region AS "delivery.region", d.email AS "delivery.email",
+	p.transaction AS "payment.transaction", p.request_id AS "payment.request_id",
+	p.currency AS "payment.currency", p.provider AS "payment.provider", p.amount AS "payment.amount",
+	p.payment_dt AS "payment.payment_dt", p.bank AS "payment.bank",
+	p.delivery_cost AS "payment.delivery_cost", p.goods_total AS "payment.goods_total",
+	p.custom_fee AS "payment.custom_fee"
+FROM orders o
+JOIN deliveries d ON d.delivery_uid = o.delivery_uid
+JOIN payments p ON p.transaction = o.payment_transaction`
+
+// orderRow represents an order row joined with its delivery and payment.
+type orderRow struct {
+	entity.OrderDB
+	Delivery entity.DeliveryDB `db:"delivery"`
+	Payment  entity.Payment    `db:"payment"`
+}
+
+// order converts the joined row into an order entity with the given items.
+func (r *orderRow) order(items []entity.Item) *entity.Order {
+	if items == nil {
+		items = []entity.Item{}
+	}
+
+	order := r.OrderDB.Order()
+	order.Delivery = r.Delivery.Delivery()
+	order.Payment = r.Payment
+	order.Items = items
+	return order
+}
+
+// GetOrderByUid retrieves an order record from the database by its unique identifier.
+// The order, its delivery and payment are read with one query and its items with another.
+// Soft-deleted orders are not returned.
+// It takes a context and an order UID as input parameters.
+// Returns the order record or an error if the operation fails.
+func (s *source) GetOrderByUid(ctx context.Context, orderUID string) (*entity.Order, error) {
+	// Create a database context with a timeout
+	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
+	defer dbCancel()
+
+	// Query the order record joined with its delivery and payment
+	var row orderRow
+	err := s.db.QueryRowxContext(
+		dbCtx,
+		selectOrders+` WHERE o.order_uid = $1 AND o.deleted_at IS NULL`,
+		orderUID,
+	).StructScan(&row)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, sql.ErrNoRows
 		}
+		return nil, fmt.Errorf("can't get order: %w", err)
+	}
+
+	// Get items of the order
+	items, err := getItemsByOrderUid(dbCtx, s.db, row.OrderUID)
+	if err != nil {
 		return nil, fmt.Errorf("can't get items: %w", err)
 	}
-	order.Items = items
 
 	// Return the order entity
-	return &order, nil
+	return row.order(items), nil
 }
 
 // GetAllOrders retrieves all order records from the database.
 // It takes a context as an input parameter.
 // Returns a slice of order records or an error if the operation fails.
 func (s *source) GetAllOrders(ctx context.Context) ([]*entity.Order, error) {
-	// Create a database context with a timeout
-	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
-	defer dbCancel()
+	var orders []*entity.Order
+	err := s.StreamOrders(ctx, func(order *entity.Order) error {
+		orders = append(orders, order)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the slice of order entities
+	return orders, nil
+}
 
-	// Query all order records from the database
+// StreamOrders reads all live orders from the database and passes them to fn one by one.
+// Orders are read with a single joined query and their items are loaded in batches
+// of orderBatchSize, so the number of queries does not grow with every order.
+// The whole stream is bounded by the given context only, as a full scan may exceed QueryTimeout.
+// It stops and returns the error returned by fn, if any.
+func (s *source) StreamOrders(ctx context.Context, fn func(order *entity.Order) error) error {
+	// Query all order records joined with their delivery and payment
 	rows, err := s.db.QueryxContext(
-		dbCtx,
-		`SELECT * FROM orders`,
+		ctx,
+		selectOrders+` WHERE o.deleted_at IS NULL ORDER BY o.order_uid`,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("can't execute query: %w", err)
+		return fmt.Errorf("can't execute query: %w", err)
 	}
 	defer rows.Close()
 
-	// Initialize a slice to store order entities
-	var orders []*entity.Order
+	batch := make([]orderRow, 0, orderBatchSize)
 
-	// Iterate over each row and scan order details into an entity
-	for rows.Next() {
-		var orderDB entity.OrderDB
-		if err := rows.StructScan(&orderDB); err != nil {
-			return nil, fmt.Errorf("can't scan row: %w", err)
+	// flush loads the items of the buffered orders and passes the orders to fn
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
 		}
 
-		// Initialize an order entity
-		order := entity.Order{
-			OrderUID:          orderDB.OrderUID,
-			TrackNumber:       orderDB.TrackNumber,
-			Entry:             orderDB.Entry,
-			Locale:            orderDB.Locale,
-			InternalSignature: orderDB.InternalSignature,
-			CustomerID:        orderDB.CustomerID,
-			DeliveryService:   orderDB.DeliveryService,
-			Shardkey:          orderDB.Shardkey,
-			SmID:              orderDB.SmID,
-			DateCreated:       orderDB.DateCreated,
-			OofShard:          orderDB.OofShard,
+		orderUIDs := make([]string, len(batch))
+		for i := range batch {
+			orderUIDs[i] = batch[i].OrderUID
 		}
-
-		// Get delivery details by delivery UID
-		delivery, err := s.GetDeliveryById(dbCtx, orderDB.DeliveryUID)
+		items, err := getItemsByOrderUids(ctx, s.db, orderUIDs)
 		if err != nil {
-			return nil, fmt.Errorf("can't get delivery: %w", err)
-		}
-		order.Delivery = entity.Delivery{
-			Name:    delivery.Name,
-			Phone:   delivery.Phone,
-			Zip:     delivery.Zip,
-			City:    delivery.City,
-			Address: delivery.Address,
-			Region:  delivery.Region,
-			Email:   delivery.Email,
+			return fmt.Errorf("can't get items: %w", err)
 		}
 
-		// Get payment details by transaction ID
-		payment, err := s.GetPaymentByTransaction(dbCtx, orderDB.PaymentTransaction)
-		if err != nil {
-			return nil, fmt.Errorf("can't get payment: %w", err)
+		for i := range batch {
+			if err := fn(batch[i].order(items[batch[i].OrderUID])); err != nil {
+				return err
+			}
 		}
-		order.Payment = *payment
+		batch = batch[:0]
 
-		// Get items associated with the order by track number
-		items, err := s.GetItemsByTrackNumber(dbCtx, orderDB.TrackNumber)
-		if err != nil {
-			return nil, fmt.Errorf("can't get items: %w", err)
+		return nil
+	}
+
+	// Iterate over each row and flush full batches
+	for rows.Next() {
+		var row orderRow
+		if err := rows.StructScan(&row); err != nil {
+			return fmt.Errorf("can't scan row: %w", err)
 		}
-		order.Items = items
+		batch = append(batch, row)
 
-		// Append the order entity to the slice
-		orders = append(orders, &order)
+		if len(batch) == orderBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
 	}
 
 	// Check for errors after iterating over rows
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating over rows: %w", err)
+		return fmt.Errorf("error iterating over rows: %w", err)
 	}
 
-	// Return the slice of order entities
-	return orders, nil
+	return flush()
+}
+
+// ListOrders retrieves a page of orders using keyset pagination.
+// It takes a context and a list query as input parameters.
+// Returns the page of orders or an error if the operation fails.
+func (s *source) ListOrders(ctx context.Context, query entity.OrderListQuery) (*entity.OrderPage, error) {
+	return s.SearchOrders(ctx, entity.OrderSearchQuery{OrderListQuery: query})
 }
 
-// DeleteOrder deletes an order record from the database.
+// SearchOrders retrieves a page of orders matching a filter using keyset pagination.
+// The order, delivery and payment rows of the page are read with one query and the items with another.
+// It takes a context and a search query as input parameters.
+// Returns the page of orders or an error if the operation fails.
+func (s *source) SearchOrders(ctx context.Context, query entity.OrderSearchQuery) (*entity.OrderPage, error) {
+	// Create a database context with a timeout
+	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
+	defer dbCancel()
+
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := filterConditions(&query.Filter, arg)
+
+	// Add the keyset condition and the ordering for the requested sort
+	direction, comparison := "ASC", ">"
+	if query.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	var orderBy string
+	switch query.Sort {
+	case entity.OrderSortDateCreated:
+		if query.After != nil {
+			conditions = append(conditions, fmt.Sprintf(`(o.date_created, o.order_uid) %s (%s, %s)`,
+				comparison, arg(entity.StoredTime(query.After.DateCreated)), arg(query.After.OrderUID)))
+		}
+		orderBy = fmt.Sprintf(` ORDER BY o.date_created %[1]s, o.order_uid %[1]s`, direction)
+	case entity.OrderSortOrderUID:
+		if query.After != nil {
+			conditions = append(conditions, fmt.Sprintf(`o.order_uid %s %s`, comparison, arg(query.After.OrderUID)))
+		}
+		orderBy = fmt.Sprintf(` ORDER BY o.order_uid %s`, direction)
+	default:
+		return nil, fmt.Errorf("unknown sort %q", query.Sort)
+	}
+
+	where := ` WHERE ` + strings.Join(conditions, ` AND `)
+
+	// Request one extra row to find out whether there is a next page
+	limit := ` LIMIT ` + arg(query.Limit+1)
+
+	var rows []orderRow
+	err := sqlx.SelectContext(dbCtx, s.db, &rows, selectOrders+where+orderBy+limit, args...)
+	if err != nil {
+		return nil, fmt.Errorf("can't execute query: %w", err)
+	}
+
+	page := &entity.OrderPage{
+		Orders: make([]*entity.Order, 0, len(rows)),
+	}
+	if len(rows) > query.Limit {
+		rows = rows[:query.Limit]
+		last := rows[len(rows)-1]
+		page.Next = entity.CursorFor(last.OrderDB.Order(), query.Sort, query.Desc)
+	}
+	if len(rows) == 0 {
+		return page, nil
+	}
+
+	// Get items of all orders of the page at once
+	orderUIDs := make([]string, len(rows))
+	for i := range rows {
+		orderUIDs[i] = rows[i].OrderUID
+	}
+	items, err := getItemsByOrderUids(dbCtx, s.db, orderUIDs)
+	if err != nil {
+		return nil, fmt.Errorf("can't get items: %w", err)
+	}
+
+	for i := range rows {
+		page.Orders = append(page.Orders, rows[i].order(items[rows[i].OrderUID]))
+	}
+
+	return page, nil
+}
+
+// filterConditions converts a filter into SQL conditions on the selectOrders query.
+// The arg function registers a query argument and returns its placeholder.
+func filterConditions(filter *entity.OrderFilter, arg func(v interface{}) string) []string {
+	conditions := []string{`o.deleted_at IS NULL`}
+	if filter.Deleted {
+		conditions[0] = `o.deleted_at IS NOT NULL`
+	}
+
+	equal := func(column, value string) {
+		if value != "" {
+			conditions = append(conditions, column+` = `+arg(value))
+		}
+	}
+
+	equal(`o.track_number`, filter.TrackNumber)
+	equal(`o.customer_id`, filter.CustomerID)
+	equal(`o.delivery_service`, filter.DeliveryService)
+	equal(`o.locale`, filter.Locale)
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, `o.date_created >= `+arg(filter.CreatedFrom.UTC()))
+	}
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, `o.date_created < `+arg(filter.CreatedTo.UTC()))
+	}
+
+	equal(`p.bank`, filter.PaymentBank)
+	equal(`p.provider`, filter.PaymentProvider)
+	equal(`p.currency`, filter.PaymentCurrency)
+
+	equal(`d.phone`, filter.DeliveryPhone)
+	equal(`d.email`, filter.DeliveryEmail)
+	equal(`d.city`, filter.DeliveryCity)
+
+	// Items are matched by a single item having all of the requested values
+	var itemConditions []string
+	if filter.ItemBrand != "" {
+		itemConditions = append(itemConditions, `i.brand = `+arg(filter.ItemBrand))
+	}
+	if filter.ItemNmID != nil {
+		itemConditions = append(itemConditions, `i.nm_id = `+arg(*filter.ItemNmID))
+	}
+	if len(itemConditions) > 0 {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM items i WHERE i.order_uid = o.order_uid AND `+
+			strings.Join(itemConditions, ` AND `)+`)`)
+	}
+
+	return conditions
+}
+
+// DeleteOrder soft-deletes an order, recording who deleted it and why.
+// The order is kept in the database until it is restored or purged, the deleted order is recorded as a new version.
+// It takes a context, an order UID, the author and the reason of the deletion and the version the order
+// must be at as input parameters, zero matches any version.
+// Returns sql.ErrNoRows if the order does not exist or is already deleted, an error wrapping
+// entity.ErrVersionMismatch if the order is at another version, or an error if the operation fails.
+func (s *source) DeleteOrder(ctx context.Context, orderUID string, deletedBy string, reason string, version int) error {
+	// Create a database context with a timeout
+	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
+	defer dbCancel()
+
+	return s.withTx(dbCtx, func(tx *sqlx.Tx) error {
+		// Lock the stored order row until the transaction ends
+		var stored entity.OrderDB
+		err := tx.QueryRowxContext(
+			dbCtx,
+			`SELECT * FROM orders WHERE order_uid = $1 AND deleted_at IS NULL FOR UPDATE`,
+			orderUID,
+		).StructScan(&stored)
+		if err == sql.ErrNoRows {
+			return sql.ErrNoRows
+		}
+		if err != nil {
+			return fmt.Errorf("can't get stored order: %w", err)
+		}
+		if err := checkVersion(&stored, version); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(
+			dbCtx,
+			`UPDATE orders SET deleted_at = now(), deleted_by = $2, delete_reason = $3, version = version + 1
+			WHERE order_uid = $1`,
+			orderUID,
+			deletedBy,
+			reason,
+		)
+		if err != nil {
+			return fmt.Errorf("can't execute query: %w", err)
+		}
+
+		return recordStoredVersion(dbCtx, tx, entity.VersionDeleted, orderUID)
+	})
+}
+
+// RestoreOrder restores a soft-deleted order and records the restored order as a new version.
 // It takes a context and an order UID as input parameters.
-// Returns an error if the operation fails.
-func (s *source) DeleteOrder(ctx context.Context, orderUID string) error {
+// Returns sql.ErrNoRows if the order does not exist or is not deleted, or an error if the operation fails.
+func (s *source) RestoreOrder(ctx context.Context, orderUID string) error {
 	// Create a database context with a timeout
 	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
 	defer dbCancel()
 
-	// Execute the SQL query to delete the order record from the database
-	_, err := s.db.ExecContext(
-		dbCtx,
-		"DELETE FROM orders WHERE order_uid = $1",
-		orderUID,
-	)
+	return s.withTx(dbCtx, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(
+			dbCtx,
+			`UPDATE orders SET deleted_at = NULL, deleted_by = '', delete_reason = '', version = version + 1
+			WHERE order_uid = $1 AND deleted_at IS NOT NULL`,
+			orderUID,
+		)
+		if err != nil {
+			return fmt.Errorf("can't execute query: %w", err)
+		}
+
+		// Report a missing order
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("can't get affected rows: %w", err)
+		}
+		if affected == 0 {
+			return sql.ErrNoRows
+		}
+
+		return recordStoredVersion(dbCtx, tx, entity.VersionRestored, orderUID)
+	})
+}
+
+// PurgeDeletedOrders permanently deletes orders soft-deleted more than olderThan ago in a single transaction.
+// Payments and items are removed by their foreign keys, deliveries are removed unless other orders use them.
+// It takes a context and the retention period as input parameters.
+// Returns the number of purged orders or an error if the operation fails.
+func (s *source) PurgeDeletedOrders(ctx context.Context, olderThan time.Duration) (int64, error) {
+	// Create a database context with a timeout
+	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
+	defer dbCancel()
+
+	var purged int64
+	err := s.withTx(dbCtx, func(tx *sqlx.Tx) error {
+		// Delete the order records, their payments and items are deleted by cascade
+		var deliveryUIDs []string
+		err := sqlx.SelectContext(
+			dbCtx,
+			tx,
+			&deliveryUIDs,
+			`DELETE FROM orders WHERE deleted_at < now() - make_interval(secs => $1)
+			RETURNING delivery_uid`,
+			olderThan.Seconds(),
+		)
+		if err != nil {
+			return fmt.Errorf("can't execute query: %w", err)
+		}
+		purged = int64(len(deliveryUIDs))
+
+		// Delete the deliveries no order uses anymore
+		seen := make(map[string]bool, len(deliveryUIDs))
+		for _, uid := range deliveryUIDs {
+			if seen[uid] {
+				continue
+			}
+			seen[uid] = true
+
+			if err := deleteUnusedDelivery(dbCtx, tx, uid); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("can't execute query: %w", err)
+		return 0, err
 	}
 
-	// Return nil as the operation was successful
-	return nil
+	return purged, nil
 }
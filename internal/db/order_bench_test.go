@@ -0,0 +1,136 @@
+package db
+
+import (
+	"L0/internal/entity"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+// benchmarkLatency simulates the network round trip of a single query.
+const benchmarkLatency = 50 * time.Microsecond
+
+// getAllOrdersNPlusOne is the former implementation of GetAllOrders, which runs
+// three extra queries per order. It is kept as a baseline for BenchmarkGetAllOrders.
+func getAllOrdersNPlusOne(ctx context.Context, s *source) ([]*entity.Order, error) {
+	rows, err := s.db.QueryxContext(ctx, `SELECT * FROM orders`)
+	if err != nil {
+		return nil, fmt.Errorf("can't execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []*entity.Order
+	for rows.Next() {
+		var orderDB entity.OrderDB
+		if err := rows.StructScan(&orderDB); err != nil {
+			return nil, fmt.Errorf("can't scan row: %w", err)
+		}
+		order := orderDB.Order()
+
+		delivery, err := s.GetDeliveryById(ctx, orderDB.DeliveryUID)
+		if err != nil {
+			return nil, fmt.Errorf("can't get delivery: %w", err)
+		}
+		order.Delivery = delivery.Delivery()
+
+		payment, err := s.GetPaymentByTransaction(ctx, orderDB.PaymentTransaction)
+		if err != nil {
+			return nil, fmt.Errorf("can't get payment: %w", err)
+		}
+		order.Payment = *payment
+
+		items, err := s.GetItemsByTrackNumber(ctx, orderDB.TrackNumber)
+		if err != nil {
+			return nil, fmt.Errorf("can't get items: %w", err)
+		}
+		order.Items = items
+
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return orders, nil
+}
+
+func BenchmarkGetAllOrders(b *testing.B) {
+	const count = 200
+
+	orders := make([]*entity.Order, count)
+	for i := range orders {
+		orders[i] = numberedOrder(i)
+	}
+
+	benchmarks := []struct {
+		name  string
+		setup func(mock sqlmock.Sqlmock)
+		load  func(s *source) ([]*entity.Order, error)
+	}{
+		{
+			name: "n+1",
+			setup: func(mock sqlmock.Sqlmock) {
+				orderRows, _, _, _ := storedOrderRows(orders[0])
+				for _, o := range orders[1:] {
+					orderRows.AddRow(
+						o.OrderUID, o.TrackNumber, o.Entry, "delivery_uid_1", o.Payment.Transaction, o.Locale,
+						o.InternalSignature, o.CustomerID, o.DeliveryService, o.Shardkey, o.SmID, o.DateCreated, o.OofShard,
+						nil, "", "", o.Version,
+					)
+				}
+				mock.ExpectQuery(`SELECT \* FROM orders`).WillDelayFor(benchmarkLatency).WillReturnRows(orderRows)
+				for _, o := range orders {
+					_, deliveryRows, paymentRows, _ := storedOrderRows(o)
+					mock.ExpectQuery(`SELECT \* FROM deliveries`).WillDelayFor(benchmarkLatency).WillReturnRows(deliveryRows)
+					mock.ExpectQuery(`SELECT .* FROM payments`).WillDelayFor(benchmarkLatency).WillReturnRows(paymentRows)
+					mock.ExpectQuery(`SELECT .* FROM items`).WillDelayFor(benchmarkLatency).WillReturnRows(itemRowsOf(o))
+				}
+			},
+			load: func(s *source) ([]*entity.Order, error) {
+				return getAllOrdersNPlusOne(context.Background(), s)
+			},
+		},
+		{
+			name: "batched",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM orders o`).WillDelayFor(benchmarkLatency).WillReturnRows(joinedOrderRows(orders...))
+				mock.ExpectQuery(`SELECT order_uid, .* FROM items`).WillDelayFor(benchmarkLatency).WillReturnRows(orderItemRowsOf(orders...))
+			},
+			load: func(s *source) ([]*entity.Order, error) {
+				return s.GetAllOrders(context.Background())
+			},
+		},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					b.Fatalf("can't connect to database: %v", err)
+				}
+				s := &source{
+					db: sqlx.NewDb(db, "sqlmock"),
+				}
+				bm.setup(mock)
+				b.StartTimer()
+
+				got, err := bm.load(s)
+				if err != nil {
+					b.Fatalf("can't load orders: %v", err)
+				}
+				if len(got) != count {
+					b.Fatalf("got %d orders, want %d", len(got), count)
+				}
+
+				b.StopTimer()
+				db.Close()
+				b.StartTimer()
+			}
+		})
+	}
+}
@@ -3,13 +3,18 @@ package db
 import (
 	"L0/internal/entity"
 	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 func MustParseTime(layout string, s string) time.Time {
@@ -20,607 +25,1419 @@ func MustParseTime(layout string, s string) time.Time {
 	return tt
 }
 
-// func Test_source_CreateOrder(t *testing.T) {
-// 	type fields struct {
-// 		db sqlmock.Sqlmock
-// 	}
-// 	type args struct {
-// 		ctx   context.Context
-// 		order *entity.Order
-// 	}
-// 	tests := []struct {
-// 		name    string
-// 		fields  fields
-// 		args    args
-// 		want    string
-// 		setup   func(f fields, a args)
-// 		wantErr bool
-// 	}{
-// 		{
-// 			name: "CreateOrder_Successful",
-// 			args: args{
-// 				ctx: context.Background(),
-// 				order: &entity.Order{
-// 					OrderUID:          "order_uid_1",
-// 					TrackNumber:       "track_number_1",
-// 					Entry:             "entry_1",
-// 					Locale:            "en",
-// 					InternalSignature: "",
-// 					CustomerID:        "customer_1",
-// 					DeliveryService:   "delivery_service_1",
-// 					Shardkey:          "shardkey_1",
-// 					SmID:              1,
-// 					DateCreated:       time.Now(),
-// 					OofShard:          "1",
-// 					Delivery: entity.Delivery{
-// 						Name:    "Test Name",
-// 						Phone:   "+1234567890",
-// 						Zip:     "12345",
-// 						City:    "City",
-// 						Address: "Address",
-// 						Region:  "Region",
-// 						Email:   "test@example.com",
-// 					},
-// 					Payment: entity.Payment{
-// 						Transaction:  "transaction_1",
-// 						Currency:     "USD",
-// 						Provider:     "provider_1",
-// 						Amount:       100,
-// 						PaymentDt:    int(time.Now().Unix()),
-// 						DeliveryCost: 10,
-// 						GoodsTotal:   90,
-// 						CustomFee:    0,
-// 					},
-// 					Items: []entity.Item{
-// 						{
-// 							ChrtID:      1,
-// 							TrackNumber: "track_number_1",
-// 							Price:       50,
-// 							Rid:         "rid_1",
-// 							Name:        "Item 1",
-// 							Sale:        0,
-// 							Size:        "S",
-// 							TotalPrice:  50,
-// 							NmID:        1,
-// 							Brand:       "Brand 1",
-// 							Status:      1,
-// 						},
-// 					},
-// 				},
-// 			},
-// 			want: "order_uid_1",
-// 			setup: func(f fields, a args) {
-// 				f.db.ExpectQuery("SELECT * FROM deliveries WHERE phone = $1").
-// 					WithArgs(a.order.Delivery.Phone).
-// 					WillReturnRows(sqlmock.NewRows([]string{"delivery_uid"}).AddRow("delivery_uid_1"))
-// 				f.db.ExpectQuery("SELECT * FROM payments WHERE transaction = $1").
-// 					WithArgs(a.order.Payment.Transaction).
-// 					WillReturnRows(sqlmock.NewRows([]string{"transaction"}).AddRow("transaction_1"))
-// 				f.db.ExpectQuery("SELECT * FROM items WHERE track_number = $1").
-// 					WithArgs(a.order.Items[0].TrackNumber).
-// 					WillReturnRows(sqlmock.NewRows([]string{"track_number"}).AddRow("track_number_1"))
-// 				f.db.ExpectQuery(`INSERT INTO orders
-// 					(order_uid, track_number, entry, delivery_uid, payment_transaction, locale, 
-// 					internal_signature, customer_id, delivery_service, shardkey, sm_id, 
-// 					date_created, oof_shard) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`).
-// 					WillReturnRows(sqlmock.NewRows([]string{"order_uid"}).AddRow("order_uid_1"))
-// 			},
-// 			wantErr: false,
-// 		},
-// 		{
-// 			name: "CreateOrder_DeliveryByPhoneError",
-// 			args: args{
-// 				ctx: context.Background(),
-// 				order: &entity.Order{
-// 					Delivery: entity.Delivery{
-// 						Phone: "+1234567890",
-// 					},
-// 				},
-// 			},
-// 			want:    "",
-// 			wantErr: true,
-// 		},
-// 	}
-// 	for _, tt := range tests {
-// 		t.Run(tt.name, func(t *testing.T) {
-// 			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
-// 			if err != nil {
-// 				t.Errorf("can't connect to database: %v", err)
-// 				return
-// 			}
-
-// 			f := fields{
-// 				db: mock,
-// 			}
-
-// 			s := &source{
-// 				db: sqlx.NewDb(db, "sqlmock"),
-// 			}
-
-// 			tt.setup(f, tt.args)
-
-// 			got, err := s.CreateOrder(tt.args.ctx, tt.args.order)
-// 			if (err != nil) != tt.wantErr {
-// 				t.Errorf("source.CreateOrder() error = %v, wantErr %v", err, tt.wantErr)
-// 				return
-// 			}
-// 			if got != tt.want {
-// 				t.Errorf("source.CreateOrder() = %v, want %v", got, tt.want)
-// 			}
-// 		})
-// 	}
-// }
-
-func Test_source_GetOrderByUid(t *testing.T) {
-	type fields struct {
-		db sqlmock.Sqlmock
-	}
-	type args struct {
-		ctx context.Context
-		uid string
+func testOrder() *entity.Order {
+	return &entity.Order{
+		OrderUID:    "order_uid_1",
+		TrackNumber: "track_number_1",
+		Entry:       "entry_1",
+		Locale:      "en",
+		CustomerID:  "customer_1",
+		SmID:        1,
+		DateCreated: MustParseTime(time.RFC3339, "2021-11-26T06:22:19Z"),
+		OofShard:    "1",
+		Delivery: entity.Delivery{
+			Name:  "Test Name",
+			Phone: "+1234567890",
+			Email: "test@example.com",
+		},
+		Payment: entity.Payment{
+			Transaction:  "transaction_1",
+			Currency:     "USD",
+			Amount:       100,
+			DeliveryCost: 10,
+			GoodsTotal:   90,
+		},
+		Items: []entity.Item{
+			{ChrtID: 1, TrackNumber: "track_number_1", Price: 50, Rid: "rid_1", TotalPrice: 45},
+			{ChrtID: 2, TrackNumber: "track_number_1", Price: 50, Rid: "rid_2", TotalPrice: 45},
+		},
 	}
+}
+
+func Test_source_CreateOrder(t *testing.T) {
+	deliveryColumns := []string{"delivery_uid", "name", "phone", "zip", "city", "address", "region", "email"}
+	errQuery := fmt.Errorf("query error")
+
 	tests := []struct {
 		name    string
-		args    args
-		want    *entity.Order
-		setup   func(a args, f fields)
+		setup   func(mock sqlmock.Sqlmock)
+		want    string
 		wantErr bool
 	}{
 		{
-			name: "ok",
-			args: args{
-				ctx: context.Background(),
-				uid: "b563feb7b2b84b6test",
-			},
-			want: &entity.Order{
-				OrderUID:    "b563feb7b2b84b6test",
-				TrackNumber: "WBILMTESTTRACK",
-				Entry:       "WBIL",
-				Delivery: entity.Delivery{
-					Name:    "Test Testov",
-					Phone:   "+9720000000",
-					Zip:     "2639809",
-					City:    "Kiryat Mozkin",
-					Address: "Ploshad Mira 15",
-					Region:  "Kraiot",
-					Email:   "test@gmail.com",
-				},
-				Payment: entity.Payment{
-					Transaction:  "b563feb7b2b84b6test",
-					RequestID:    "",
-					Currency:     "USD",
-					Provider:     "wbpay",
-					Amount:       1817,
-					PaymentDt:    1637907727,
-					Bank:         "alpha",
-					DeliveryCost: 1500,
-					GoodsTotal:   317,
-					CustomFee:    0,
-				},
-				Items: []entity.Item{
-					{
-						ChrtID:      9934930,
-						TrackNumber: "WBILMTESTTRACK",
-						Price:       453,
-						Rid:         "ab4219087a764ae0btest",
-						Name:        "Mascaras",
-						Sale:        30,
-						Size:        "0",
-						TotalPrice:  317,
-						NmID:        2389212,
-						Brand:       "Vivienne Sabo",
-						Status:      202,
-					},
-				},
-				Locale:            "en",
-				InternalSignature: "",
-				CustomerID:        "test",
-				DeliveryService:   "meest",
-				Shardkey:          "9",
-				SmID:              99,
-				DateCreated:       MustParseTime(time.RFC3339, "2021-11-26T06:22:19Z"),
-				OofShard:          "1",
-			},
-			setup: func(a args, f fields) {
-				orderRows := sqlmock.NewRows([]string{
-					"track_number",
-					"entry",
-					"delivery_uid",
-					"payment_transaction",
-					"locale",
-					"internal_signature",
-					"customer_id",
-					"delivery_service",
-					"shardkey",
-					"sm_id",
-					"date_created",
-					"oof_shard",
-				}).AddRow(
-					"WBILMTESTTRACK",                       // track_number
-					"WBIL",                                 // entry
-					"4a6e104d-9d7f-45ff-8de6-37993d709522", // delivery_uid
-					"b563feb7b2b84b6test",                  // payment_transaction
-					"en",                                   // locale
-					"",                                     // internal_signature
-					"test",                                 // customer_id
-					"meest",                                // delivery_service
-					"9",                                    // shardkey
-					99,                                     // sm_id
-					MustParseTime(time.RFC3339, "2021-11-26T06:22:19Z"), // date_created
-					"1", // oof_shard
+			name: "success: new delivery",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE phone = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE email = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectExec(`INSERT INTO deliveries`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO payments`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WithArgs(1, "track_number_1", 50, "rid_1", "", 0, "", 45, 0, "", 0, "order_uid_1").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WithArgs(2, "track_number_1", 50, "rid_2", "", 0, "", 45, 0, "", 0, "order_uid_1").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO orders`).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+				mock.ExpectExec(`INSERT INTO order_versions`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want:    "order_uid_1",
+			wantErr: false,
+		},
+		{
+			name: "success: created again after purge",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE phone = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE email = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectExec(`INSERT INTO deliveries`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO payments`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				// The versions of the purged order end at 3
+				mock.ExpectQuery(`INSERT INTO orders .*\(SELECT COALESCE\(MAX\(version\), 0\) \+ 1 FROM order_versions WHERE order_uid = \$1`).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(4))
+				mock.ExpectExec(`INSERT INTO order_versions`).WithArgs("order_uid_1", 4, entity.VersionCreated, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want: "order_uid_1",
+		},
+		{
+			name: "success: existing delivery",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE phone = \$1`).WillReturnRows(
+					sqlmock.NewRows(deliveryColumns).AddRow("delivery_uid_1", "Test Name", "+1234567890", "", "", "", "", "test@example.com"),
 				)
+				mock.ExpectExec(`INSERT INTO payments`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO orders`).WithArgs(
+					"order_uid_1", "track_number_1", "entry_1", "delivery_uid_1", "transaction_1", "en",
+					"", "customer_1", "", "", 1, MustParseTime(time.RFC3339, "2021-11-26T06:22:19Z"), "1", entity.StatusCreated,
+				).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+				mock.ExpectExec(`INSERT INTO order_versions`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want:    "order_uid_1",
+			wantErr: false,
+		},
+		{
+			name: "fail: can't begin transaction",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin().WillReturnError(errQuery)
+			},
+			wantErr: true,
+		},
+		{
+			name: "fail: rollback on delivery lookup error",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE phone = \$1`).WillReturnError(errQuery)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "fail: rollback on delivery insert error",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE phone = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE email = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectExec(`INSERT INTO deliveries`).WillReturnError(errQuery)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "fail: rollback on payment insert error",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE phone = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE email = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectExec(`INSERT INTO deliveries`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO payments`).WillReturnError(errQuery)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "fail: rollback on second item insert error",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE phone = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE email = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectExec(`INSERT INTO deliveries`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO payments`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnError(errQuery)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "fail: rollback on order insert error",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE phone = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE email = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectExec(`INSERT INTO deliveries`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO payments`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO orders`).WillReturnError(errQuery)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "fail: can't commit",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE phone = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE email = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectExec(`INSERT INTO deliveries`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO payments`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO orders`).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+				mock.ExpectExec(`INSERT INTO order_versions`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit().WillReturnError(errQuery)
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Errorf("can't connect to database: %v", err)
+				return
+			}
+			defer db.Close()
 
-				deliveryRows := sqlmock.NewRows([]string{
-					"delivery_uid",
-					"name",
-					"phone",
-					"zip",
-					"city",
-					"address",
-					"region",
-					"email",
-				}).AddRow(
-					"4a6e104d-9d7f-45ff-8de6-37993d709522", // delivery_uid
-					"Test Testov",                          // name
-					"+9720000000",                          // phone
-					"2639809",                              // zip
-					"Kiryat Mozkin",                        // city
-					"Ploshad Mira 15",                      // address
-					"Kraiot",                               // region
-					"test@gmail.com",                       // email
-				)
+			s := &source{
+				db: sqlx.NewDb(db, "sqlmock"),
+			}
 
-				paymentRows := sqlmock.NewRows([]string{
-					"transaction",
-					"request_id",
-					"currency",
-					"provider",
-					"amount",
-					"payment_dt",
-					"bank",
-					"delivery_cost",
-					"goods_total",
-					"custom_fee",
-				}).AddRow(
-					"b563feb7b2b84b6test", // transaction
-					"",                    // request_id
-					"USD",                 // currency
-					"wbpay",               // provider
-					1817,                  // amount
-					1637907727,            // payment_dt
-					"alpha",               // bank
-					1500,                  // delivery_cost
-					317,                   // goods_total
-					0,                     // custom_fee
-				)
+			tt.setup(mock)
 
-				itemRows := sqlmock.NewRows([]string{
-					"chrt_id",
-					"track_number",
-					"price",
-					"rid",
-					"name",
-					"sale",
-					"size",
-					"total_price",
-					"nm_id",
-					"brand",
-					"status",
-				}).AddRow(
-					9934930,                 // chrt_id
-					"WBILMTESTTRACK",        // track_number
-					453,                     // price
-					"ab4219087a764ae0btest", // rid
-					"Mascaras",              // name
-					30,                      // sale
-					"0",                     // size
-					317,                     // total_price
-					2389212,                 // nm_id
-					"Vivienne Sabo",         // brand
-					202,                     // status
-				)
+			got, err := s.CreateOrder(context.Background(), testOrder())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("source.CreateOrder() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("source.CreateOrder() = %v, want %v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+// storedOrderRows returns the rows of testOrder as they are stored in the database.
+func storedOrderRows(order *entity.Order) (orderRows, deliveryRows, paymentRows, itemRows *sqlmock.Rows) {
+	var deletedAt *time.Time
+	var deletedBy, deleteReason string
+	if order.Deleted != nil {
+		deletedAt, deletedBy, deleteReason = &order.Deleted.DeletedAt, order.Deleted.DeletedBy, order.Deleted.Reason
+	}
+	orderRows = sqlmock.NewRows([]string{
+		"order_uid", "track_number", "entry", "delivery_uid", "payment_transaction", "locale",
+		"internal_signature", "customer_id", "delivery_service", "shardkey", "sm_id", "date_created", "oof_shard",
+		"deleted_at", "deleted_by", "delete_reason", "version",
+	}).AddRow(
+		order.OrderUID, order.TrackNumber, order.Entry, "delivery_uid_1", order.Payment.Transaction, order.Locale,
+		order.InternalSignature, order.CustomerID, order.DeliveryService, order.Shardkey, order.SmID, order.DateCreated, order.OofShard,
+		deletedAt, deletedBy, deleteReason, order.Version,
+	)
+	d := order.Delivery
+	deliveryRows = sqlmock.NewRows([]string{"delivery_uid", "name", "phone", "zip", "city", "address", "region", "email"}).
+		AddRow("delivery_uid_1", d.Name, d.Phone, d.Zip, d.City, d.Address, d.Region, d.Email)
+	p := order.Payment
+	paymentRows = sqlmock.NewRows([]string{
+		"transaction", "request_id", "currency", "provider", "amount", "payment_dt", "bank", "delivery_cost", "goods_total", "custom_fee",
+	}).AddRow(p.Transaction, p.RequestID, p.Currency, p.Provider, p.Amount, p.PaymentDt, p.Bank, p.DeliveryCost, p.GoodsTotal, p.CustomFee)
+	itemRows = sqlmock.NewRows([]string{
+		"chrt_id", "track_number", "price", "rid", "name", "sale", "size", "total_price", "nm_id", "brand", "status",
+	})
+	// Return items in reverse order to check that comparison ignores it
+	for i := len(order.Items) - 1; i >= 0; i-- {
+		it := order.Items[i]
+		itemRows.AddRow(it.ChrtID, it.TrackNumber, it.Price, it.Rid, it.Name, it.Sale, it.Size, it.TotalPrice, it.NmID, it.Brand, it.Status)
+	}
+	return orderRows, deliveryRows, paymentRows, itemRows
+}
 
-				f.db.ExpectQuery(
-					`SELECT
-						track_number, entry, delivery_uid, payment_transaction, locale,
-						internal_signature, customer_id, delivery_service, shardkey, sm_id,
-						date_created, oof_shard
-					FROM orders
-					WHERE order_uid = $1`,
-				).WithArgs(a.uid).WillReturnRows(orderRows)
+// dateCreatedArgs returns the arguments of an order statement with the given count,
+// matching the creation date, which is bound twelfth, and any other value.
+func dateCreatedArgs(count int, dateCreated time.Time) []driver.Value {
+	args := make([]driver.Value, count)
+	for i := range args {
+		args[i] = sqlmock.AnyArg()
+	}
+	args[11] = dateCreated
+	return args
+}
 
-				f.db.ExpectQuery(
-					"SELECT * FROM delivery WHERE delivery_uid = $1",
-				).WithArgs("4a6e104d-9d7f-45ff-8de6-37993d709522").WillReturnRows(deliveryRows)
+func Test_source_SaveOrder(t *testing.T) {
+	deliveryColumns := []string{"delivery_uid", "name", "phone", "zip", "city", "address", "region", "email"}
+	orderColumns := []string{"order_uid"}
 
-				f.db.ExpectQuery(
-					"SELECT * FROM payments WHERE transaction = $1",
-				).WithArgs("b563feb7b2b84b6test").WillReturnRows(paymentRows)
+	expectStored := func(mock sqlmock.Sqlmock, stored *entity.Order) {
+		orderRows, deliveryRows, paymentRows, itemRows := storedOrderRows(stored)
+		mock.ExpectQuery(`SELECT .* FROM orders WHERE order_uid = \$1 FOR UPDATE`).WithArgs("order_uid_1").WillReturnRows(orderRows)
+		mock.ExpectQuery(`SELECT .* FROM deliveries WHERE delivery_uid = \$1`).WithArgs("delivery_uid_1").WillReturnRows(deliveryRows)
+		mock.ExpectQuery(`SELECT .* FROM payments WHERE transaction = \$1`).WithArgs("transaction_1").WillReturnRows(paymentRows)
+		mock.ExpectQuery(`SELECT .* FROM items WHERE order_uid = \$1`).WithArgs("order_uid_1").WillReturnRows(itemRows)
+	}
 
-				f.db.ExpectQuery(
-					"SELECT * FROM items WHERE track_number = $1",
-				).WithArgs("WBILMTESTTRACK").WillReturnRows(itemRows)
+	tests := []struct {
+		name         string
+		policy       entity.UpdatePolicy
+		modify       func(o *entity.Order)
+		setup        func(mock sqlmock.Sqlmock)
+		want         entity.SaveOutcome
+		wantErr      bool
+		wantConflict bool
+	}{
+		{
+			name:   "created",
+			policy: entity.UpdatePolicyUpdate,
+			modify: func(o *entity.Order) {},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM orders WHERE order_uid = \$1 FOR UPDATE`).WillReturnRows(sqlmock.NewRows(orderColumns))
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE phone = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE email = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectExec(`INSERT INTO deliveries`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO payments`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO orders`).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+				mock.ExpectExec(`INSERT INTO order_versions`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 			},
-			wantErr: false,
+			want: entity.SaveCreated,
+		},
+		{
+			name:   "created: creation date with offset",
+			policy: entity.UpdatePolicyUpdate,
+			modify: func(o *entity.Order) {
+				o.DateCreated = o.DateCreated.In(time.FixedZone("MSK", 3*60*60))
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM orders WHERE order_uid = \$1 FOR UPDATE`).WillReturnRows(sqlmock.NewRows(orderColumns))
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE phone = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE email = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectExec(`INSERT INTO deliveries`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO payments`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO orders`).WithArgs(dateCreatedArgs(14, testOrder().DateCreated)...).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+				mock.ExpectExec(`INSERT INTO order_versions`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want: entity.SaveCreated,
+		},
+		{
+			name:   "unchanged: identical redelivery",
+			policy: entity.UpdatePolicyReject,
+			modify: func(o *entity.Order) {},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectStored(mock, testOrder())
+				mock.ExpectCommit()
+			},
+			want: entity.SaveUnchanged,
+		},
+		{
+			name:   "unchanged: redelivery with sub-microsecond timestamp",
+			policy: entity.UpdatePolicyUpdate,
+			modify: func(o *entity.Order) {
+				o.DateCreated = o.DateCreated.Add(400 * time.Nanosecond).In(time.FixedZone("MSK", 3*60*60))
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectStored(mock, testOrder())
+				mock.ExpectCommit()
+			},
+			want: entity.SaveUnchanged,
+		},
+		{
+			name:   "updated: changed payload",
+			policy: entity.UpdatePolicyUpdate,
+			modify: func(o *entity.Order) {
+				o.Locale = "ru"
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectStored(mock, testOrder())
+				mock.ExpectExec(`DELETE FROM items WHERE order_uid = \$1`).WithArgs("order_uid_1").WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`DELETE FROM payments WHERE order_uid = \$1`).WithArgs("order_uid_1").WillReturnResult(sqlmock.NewResult(0, 1))
+				_, deliveryRows, _, _ := storedOrderRows(testOrder())
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE phone = \$1`).WillReturnRows(deliveryRows)
+				mock.ExpectExec(`INSERT INTO payments`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`UPDATE orders SET .* version = version \+ 1 WHERE order_uid = \$1 RETURNING version`).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+				mock.ExpectExec(`INSERT INTO order_versions`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want: entity.SaveUpdated,
+		},
+		{
+			name:   "updated: creation date with offset",
+			policy: entity.UpdatePolicyUpdate,
+			modify: func(o *entity.Order) {
+				o.DateCreated = o.DateCreated.Add(time.Hour).In(time.FixedZone("MSK", 3*60*60))
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectStored(mock, testOrder())
+				mock.ExpectExec(`DELETE FROM items WHERE order_uid = \$1`).WithArgs("order_uid_1").WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`DELETE FROM payments WHERE order_uid = \$1`).WithArgs("order_uid_1").WillReturnResult(sqlmock.NewResult(0, 1))
+				_, deliveryRows, _, _ := storedOrderRows(testOrder())
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE phone = \$1`).WillReturnRows(deliveryRows)
+				mock.ExpectExec(`INSERT INTO payments`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`UPDATE orders SET`).WithArgs(dateCreatedArgs(13, testOrder().DateCreated.Add(time.Hour))...).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+				mock.ExpectExec(`INSERT INTO order_versions`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want: entity.SaveUpdated,
+		},
+		{
+			name:   "fail: changed payload with reject policy",
+			policy: entity.UpdatePolicyReject,
+			modify: func(o *entity.Order) {
+				o.Locale = "ru"
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectStored(mock, testOrder())
+				mock.ExpectRollback()
+			},
+			wantErr:      true,
+			wantConflict: true,
+		},
+		{
+			name:   "unchanged: redelivery of deleted order",
+			policy: entity.UpdatePolicyUpdate,
+			modify: func(o *entity.Order) {},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectStored(mock, deletedOrder())
+				mock.ExpectCommit()
+			},
+			want: entity.SaveUnchanged,
+		},
+		{
+			name:   "fail: changed payload for deleted order",
+			policy: entity.UpdatePolicyUpdate,
+			modify: func(o *entity.Order) {
+				o.Locale = "ru"
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectStored(mock, deletedOrder())
+				mock.ExpectRollback()
+			},
+			wantErr:      true,
+			wantConflict: true,
+		},
+		{
+			name:   "unchanged: identical order created concurrently",
+			policy: entity.UpdatePolicyReject,
+			modify: func(o *entity.Order) {},
+			setup: func(mock sqlmock.Sqlmock) {
+				// Both saves find no order, the other one inserts it first
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM orders WHERE order_uid = \$1 FOR UPDATE`).WillReturnRows(sqlmock.NewRows(orderColumns))
+				_, deliveryRows, _, _ := storedOrderRows(testOrder())
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE phone = \$1`).WillReturnRows(deliveryRows)
+				mock.ExpectExec(`INSERT INTO payments`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO orders`).WillReturnError(&pq.Error{Code: uniqueViolation})
+				mock.ExpectRollback()
+				// The retry compares the payload with the created order
+				mock.ExpectBegin()
+				expectStored(mock, testOrder())
+				mock.ExpectCommit()
+			},
+			want: entity.SaveUnchanged,
+		},
+		{
+			name:   "fail: unique violation",
+			policy: entity.UpdatePolicyUpdate,
+			modify: func(o *entity.Order) {},
+			setup: func(mock sqlmock.Sqlmock) {
+				for i := 0; i < 2; i++ {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`SELECT .* FROM orders WHERE order_uid = \$1 FOR UPDATE`).WillReturnRows(sqlmock.NewRows(orderColumns))
+					mock.ExpectQuery(`SELECT .* FROM deliveries WHERE phone = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+					mock.ExpectQuery(`SELECT .* FROM deliveries WHERE email = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+					mock.ExpectExec(`INSERT INTO deliveries`).WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(`INSERT INTO payments`).WillReturnError(&pq.Error{Code: uniqueViolation})
+					mock.ExpectRollback()
+				}
+			},
+			wantErr:      true,
+			wantConflict: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Errorf("can't connect to database: %v", err)
+				return
+			}
+			defer db.Close()
+
+			s := &source{
+				db: sqlx.NewDb(db, "sqlmock"),
+			}
+
+			tt.setup(mock)
+
+			order := testOrder()
+			tt.modify(order)
+
+			got, err := s.SaveOrder(context.Background(), order, tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("source.SaveOrder() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if errors.Is(err, entity.ErrConflict) != tt.wantConflict {
+				t.Errorf("source.SaveOrder() error = %v, wantConflict %v", err, tt.wantConflict)
+			}
+			if got != tt.want {
+				t.Errorf("source.SaveOrder() = %v, want %v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+// deletedOrder returns a copy of testOrder that is soft-deleted.
+func deletedOrder() *entity.Order {
+	order := testOrder()
+	order.Deleted = &entity.OrderDeletion{
+		DeletedAt: MustParseTime(time.RFC3339, "2024-02-01T10:00:00Z"),
+		DeletedBy: "admin",
+		Reason:    "duplicate",
+	}
+	return order
+}
+
+// numberedOrder returns a copy of testOrder with identifiers suffixed by n.
+func numberedOrder(n int) *entity.Order {
+	order := testOrder()
+	order.OrderUID = fmt.Sprintf("order_uid_%d", n)
+	order.TrackNumber = fmt.Sprintf("track_number_%d", n)
+	order.Payment.Transaction = fmt.Sprintf("transaction_%d", n)
+	for i := range order.Items {
+		order.Items[i].TrackNumber = order.TrackNumber
+		order.Items[i].Rid = fmt.Sprintf("rid_%d_%d", n, i+1)
+	}
+	return order
+}
+
+// joinedOrderRows returns the rows of orders joined with their delivery and payment.
+func joinedOrderRows(orders ...*entity.Order) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"order_uid", "track_number", "entry", "delivery_uid", "payment_transaction", "locale",
+		"internal_signature", "customer_id", "delivery_service", "shardkey", "sm_id", "date_created", "oof_shard",
+		"delivery.delivery_uid", "delivery.name", "delivery.phone", "delivery.zip", "delivery.city",
+		"delivery.address", "delivery.region", "delivery.email",
+		"payment.transaction", "payment.request_id", "payment.currency", "payment.provider", "payment.amount",
+		"payment.payment_dt", "payment.bank", "payment.delivery_cost", "payment.goods_total", "payment.custom_fee",
+	})
+	for _, o := range orders {
+		d, p := o.Delivery, o.Payment
+		rows.AddRow(
+			o.OrderUID, o.TrackNumber, o.Entry, "delivery_uid_1", p.Transaction, o.Locale,
+			o.InternalSignature, o.CustomerID, o.DeliveryService, o.Shardkey, o.SmID, o.DateCreated, o.OofShard,
+			"delivery_uid_1", d.Name, d.Phone, d.Zip, d.City, d.Address, d.Region, d.Email,
+			p.Transaction, p.RequestID, p.Currency, p.Provider, p.Amount, p.PaymentDt, p.Bank, p.DeliveryCost, p.GoodsTotal, p.CustomFee,
+		)
+	}
+	return rows
+}
+
+// orderItemRowsOf returns the item rows of orders with the order they belong to,
+// as they are returned by the batched items query.
+func orderItemRowsOf(orders ...*entity.Order) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"order_uid", "chrt_id", "track_number", "price", "rid", "name", "sale", "size", "total_price", "nm_id", "brand", "status",
+	})
+	for _, o := range orders {
+		for _, it := range o.Items {
+			rows.AddRow(o.OrderUID, it.ChrtID, it.TrackNumber, it.Price, it.Rid, it.Name, it.Sale, it.Size, it.TotalPrice, it.NmID, it.Brand, it.Status)
+		}
+	}
+	return rows
+}
+
+// itemRowsOf returns the item rows of orders.
+func itemRowsOf(orders ...*entity.Order) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"chrt_id", "track_number", "price", "rid", "name", "sale", "size", "total_price", "nm_id", "brand", "status",
+	})
+	for _, o := range orders {
+		for _, it := range o.Items {
+			rows.AddRow(it.ChrtID, it.TrackNumber, it.Price, it.Rid, it.Name, it.Sale, it.Size, it.TotalPrice, it.NmID, it.Brand, it.Status)
+		}
+	}
+	return rows
+}
+
+func Test_source_GetOrderByUid(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(mock sqlmock.Sqlmock)
+		want      *entity.Order
+		wantErr   bool
+		wantNoRow bool
+	}{
+		{
+			name: "ok",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM orders o JOIN deliveries d .* JOIN payments p .* WHERE o.order_uid = \$1 AND o.deleted_at IS NULL`).
+					WithArgs("order_uid_1").WillReturnRows(joinedOrderRows(testOrder()))
+				mock.ExpectQuery(`SELECT .* FROM items WHERE order_uid = \$1`).
+					WithArgs("order_uid_1").WillReturnRows(itemRowsOf(testOrder()))
+			},
+			want: testOrder(),
+		},
+		{
+			name: "ok: order without items",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM orders o`).WillReturnRows(joinedOrderRows(testOrder()))
+				mock.ExpectQuery(`SELECT .* FROM items`).WillReturnRows(itemRowsOf())
+			},
+			want: func() *entity.Order {
+				order := testOrder()
+				order.Items = []entity.Item{}
+				return order
+			}(),
+		},
+		{
+			name: "fail: not found",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM orders o`).WillReturnRows(joinedOrderRows())
+			},
+			wantErr:   true,
+			wantNoRow: true,
 		},
 		{
 			name: "fail: can't exec query",
-			args: args{
-				ctx: context.Background(),
-				uid: "b563feb7b2b84b6test",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM orders o`).WillReturnError(fmt.Errorf("can't exec query"))
 			},
-			want: nil,
-			setup: func(a args, f fields) {
-				f.db.ExpectQuery(
-					`BUM BUM`,
-				).WithArgs(a.uid).WillReturnError(fmt.Errorf("can't exec query"))
+			wantErr: true,
+		},
+		{
+			name: "fail: can't get items",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM orders o`).WillReturnRows(joinedOrderRows(testOrder()))
+				mock.ExpectQuery(`SELECT .* FROM items`).WillReturnError(fmt.Errorf("can't exec query"))
 			},
 			wantErr: true,
 		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Errorf("can't connect to database: %v", err)
+				return
+			}
+			defer db.Close()
+
+			s := &source{
+				db: sqlx.NewDb(db, "sqlmock"),
+			}
+
+			tt.setup(mock)
+
+			got, err := s.GetOrderByUid(context.Background(), "order_uid_1")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("source.GetOrderByUid() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if errors.Is(err, sql.ErrNoRows) != tt.wantNoRow {
+				t.Errorf("source.GetOrderByUid() error = %v, wantNoRow %v", err, tt.wantNoRow)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("source.GetOrderByUid() = %v, want %v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func Test_source_StreamOrders(t *testing.T) {
+	// One full batch and one partial batch
+	orders := make([]*entity.Order, orderBatchSize+2)
+	for i := range orders {
+		orders[i] = numberedOrder(i)
+	}
+	// The last order has no items
+	orders[len(orders)-1].Items = []entity.Item{}
+
+	errStop := errors.New("stop")
+
+	tests := []struct {
+		name    string
+		setup   func(mock sqlmock.Sqlmock)
+		fnErr   error
+		want    []*entity.Order
+		wantErr error
+	}{
+		{
+			name: "ok: items are loaded per batch",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM orders o .* WHERE o.deleted_at IS NULL ORDER BY o.order_uid`).WillReturnRows(joinedOrderRows(orders...))
+				mock.ExpectQuery(`SELECT order_uid, .* FROM items WHERE order_uid = ANY\(\$1\)`).
+					WillReturnRows(orderItemRowsOf(orders[:orderBatchSize]...))
+				mock.ExpectQuery(`SELECT order_uid, .* FROM items WHERE order_uid = ANY\(\$1\)`).
+					WillReturnRows(orderItemRowsOf(orders[orderBatchSize:]...))
+			},
+			want: orders,
+		},
+		{
+			name: "ok: no orders",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM orders o`).WillReturnRows(joinedOrderRows())
+			},
+		},
 		{
-			name: "fail: can't scan rows",
-			args: args{
-				ctx: context.Background(),
-				uid: "b563feb7b2b84b6test",
+			name: "fail: can't exec query",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM orders o`).WillReturnError(fmt.Errorf("can't exec query"))
 			},
-			want: nil,
-			setup: func(a args, f fields) {
-				f.db.ExpectQuery(
-					`SELECT
-						track_number, entry, delivery_uid, payment_transaction, locale,
-						internal_signature, customer_id, delivery_service, shardkey, sm_id,
-						date_created, oof_shard
-					FROM orders
-					WHERE order_uid = $1`,
-				).WithArgs(a.uid).WillReturnRows(sqlmock.NewRows([]string{"track_number"}))
+			wantErr: errors.New("any"),
+		},
+		{
+			name: "fail: can't get items",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM orders o`).WillReturnRows(joinedOrderRows(orders[:1]...))
+				mock.ExpectQuery(`SELECT .* FROM items`).WillReturnError(fmt.Errorf("can't exec query"))
 			},
+			wantErr: errors.New("any"),
+		},
+		{
+			name: "fail: callback error stops the stream",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM orders o`).WillReturnRows(joinedOrderRows(orders[:2]...))
+				mock.ExpectQuery(`SELECT .* FROM items`).WillReturnRows(orderItemRowsOf(orders[:2]...))
+			},
+			fnErr:   errStop,
+			want:    orders[:1],
+			wantErr: errStop,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Errorf("can't connect to database: %v", err)
+				return
+			}
+			defer db.Close()
+
+			s := &source{
+				db: sqlx.NewDb(db, "sqlmock"),
+			}
+
+			tt.setup(mock)
+
+			var got []*entity.Order
+			err = s.StreamOrders(context.Background(), func(order *entity.Order) error {
+				got = append(got, order)
+				return tt.fnErr
+			})
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("source.StreamOrders() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.fnErr != nil && !errors.Is(err, tt.fnErr) {
+				t.Errorf("source.StreamOrders() error = %v, want %v", err, tt.fnErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("source.StreamOrders() got %d orders, want %d", len(got), len(tt.want))
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func Test_source_ListOrders(t *testing.T) {
+	orders := []*entity.Order{numberedOrder(1), numberedOrder(2), numberedOrder(3)}
+
+	tests := []struct {
+		name    string
+		query   entity.OrderListQuery
+		setup   func(mock sqlmock.Sqlmock)
+		want    *entity.OrderPage
+		wantErr bool
+	}{
+		{
+			name:  "ok: first page has a next cursor",
+			query: entity.OrderListQuery{Limit: 2, Sort: entity.OrderSortDateCreated},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM orders o .* ORDER BY o.date_created ASC, o.order_uid ASC LIMIT \$1`).
+					WithArgs(3).WillReturnRows(joinedOrderRows(orders...))
+				mock.ExpectQuery(`SELECT order_uid, .* FROM items WHERE order_uid = ANY\(\$1\)`).
+					WillReturnRows(orderItemRowsOf(orders[:2]...))
+			},
+			want: &entity.OrderPage{
+				Orders: orders[:2],
+				Next:   entity.CursorFor(orders[1], entity.OrderSortDateCreated, false),
+			},
+		},
+		{
+			name: "ok: last page by date",
+			query: entity.OrderListQuery{
+				Limit: 2,
+				Sort:  entity.OrderSortDateCreated,
+				Desc:  true,
+				After: entity.CursorFor(orders[0], entity.OrderSortDateCreated, true),
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`WHERE o.deleted_at IS NULL AND \(o.date_created, o.order_uid\) < \(\$1, \$2\) ORDER BY o.date_created DESC, o.order_uid DESC LIMIT \$3`).
+					WithArgs(orders[0].DateCreated, orders[0].OrderUID, 3).WillReturnRows(joinedOrderRows(orders[2]))
+				mock.ExpectQuery(`SELECT .* FROM items`).WillReturnRows(orderItemRowsOf(orders[2]))
+			},
+			want: &entity.OrderPage{
+				Orders: orders[2:],
+			},
+		},
+		{
+			name: "ok: cursor with offset",
+			query: entity.OrderListQuery{
+				Limit: 2,
+				Sort:  entity.OrderSortDateCreated,
+				After: &entity.OrderCursor{
+					Sort:        entity.OrderSortDateCreated,
+					DateCreated: orders[0].DateCreated.In(time.FixedZone("MSK", 3*60*60)),
+					OrderUID:    orders[0].OrderUID,
+				},
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`WHERE o.deleted_at IS NULL AND \(o.date_created, o.order_uid\) > \(\$1, \$2\)`).
+					WithArgs(orders[0].DateCreated, orders[0].OrderUID, 3).WillReturnRows(joinedOrderRows())
+			},
+			want: &entity.OrderPage{Orders: []*entity.Order{}},
+		},
+		{
+			name: "ok: by order uid",
+			query: entity.OrderListQuery{
+				Limit: 2,
+				Sort:  entity.OrderSortOrderUID,
+				After: entity.CursorFor(orders[0], entity.OrderSortOrderUID, false),
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`WHERE o.deleted_at IS NULL AND o.order_uid > \$1 ORDER BY o.order_uid ASC LIMIT \$2`).
+					WithArgs(orders[0].OrderUID, 3).WillReturnRows(joinedOrderRows(orders[1:]...))
+				mock.ExpectQuery(`SELECT .* FROM items`).WillReturnRows(orderItemRowsOf(orders[1:]...))
+			},
+			want: &entity.OrderPage{
+				Orders: orders[1:],
+			},
+		},
+		{
+			name:  "ok: empty",
+			query: entity.OrderListQuery{Limit: 2, Sort: entity.OrderSortOrderUID},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM orders o`).WillReturnRows(joinedOrderRows())
+			},
+			want: &entity.OrderPage{
+				Orders: []*entity.Order{},
+			},
+		},
+		{
+			name:    "fail: unknown sort",
+			query:   entity.OrderListQuery{Limit: 2, Sort: "price"},
+			setup:   func(mock sqlmock.Sqlmock) {},
 			wantErr: true,
 		},
 		{
-			name: "fail: can't get delivery",
-			args: args{
-				ctx: context.Background(),
-				uid: "b563feb7b2b84b6test",
-			},
-			want: nil,
-			setup: func(a args, f fields) {
-				orderRows := sqlmock.NewRows([]string{
-					"track_number",
-					"entry",
-					"delivery_uid",
-					"payment_transaction",
-					"locale",
-					"internal_signature",
-					"customer_id",
-					"delivery_service",
-					"shardkey",
-					"sm_id",
-					"date_created",
-					"oof_shard",
-				}).AddRow(
-					"WBILMTESTTRACK",                       // track_number
-					"WBIL",                                 // entry
-					"4a6e104d-9d7f-45ff-8de6-37993d709522", // delivery_uid
-					"b563feb7b2b84b6test",                  // payment_transaction
-					"en",                                   // locale
-					"",                                     // internal_signature
-					"test",                                 // customer_id
-					"meest",                                // delivery_service
-					"9",                                    // shardkey
-					99,                                     // sm_id
-					MustParseTime(time.RFC3339, "2021-11-26T06:22:19Z"), // date_created
-					"1", // oof_shard
-				)
-				f.db.ExpectQuery(
-					`SELECT
-						track_number, entry, delivery_uid, payment_transaction, locale,
-						internal_signature, customer_id, delivery_service, shardkey, sm_id,
-						date_created, oof_shard
-					FROM orders
-					WHERE order_uid = $1`,
-				).WithArgs(a.uid).WillReturnRows(orderRows)
-				f.db.ExpectQuery("Bum bum").WillReturnError(fmt.Errorf("can't get delivery: can't exec query"))
+			name:  "fail: can't exec query",
+			query: entity.OrderListQuery{Limit: 2, Sort: entity.OrderSortOrderUID},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM orders o`).WillReturnError(fmt.Errorf("can't exec query"))
 			},
 			wantErr: true,
 		},
 		{
-			name: "fail: can't get payment",
-			args: args{
-				ctx: context.Background(),
-				uid: "b563feb7b2b84b6test",
-			},
-			want: nil,
-			setup: func(a args, f fields) {
-				orderRows := sqlmock.NewRows([]string{
-					"track_number",
-					"entry",
-					"delivery_uid",
-					"payment_transaction",
-					"locale",
-					"internal_signature",
-					"customer_id",
-					"delivery_service",
-					"shardkey",
-					"sm_id",
-					"date_created",
-					"oof_shard",
-				}).AddRow(
-					"WBILMTESTTRACK",                       // track_number
-					"WBIL",                                 // entry
-					"4a6e104d-9d7f-45ff-8de6-37993d709522", // delivery_uid
-					"b563feb7b2b84b6test",                  // payment_transaction
-					"en",                                   // locale
-					"",                                     // internal_signature
-					"test",                                 // customer_id
-					"meest",                                // delivery_service
-					"9",                                    // shardkey
-					99,                                     // sm_id
-					MustParseTime(time.RFC3339, "2021-11-26T06:22:19Z"), // date_created
-					"1", // oof_shard
-				)
+			name:  "fail: can't get items",
+			query: entity.OrderListQuery{Limit: 2, Sort: entity.OrderSortOrderUID},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM orders o`).WillReturnRows(joinedOrderRows(orders[:1]...))
+				mock.ExpectQuery(`SELECT .* FROM items`).WillReturnError(fmt.Errorf("can't exec query"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Errorf("can't connect to database: %v", err)
+				return
+			}
+			defer db.Close()
 
-				deliveryRows := sqlmock.NewRows([]string{
-					"delivery_uid",
-					"name",
-					"phone",
-					"zip",
-					"city",
-					"address",
-					"region",
-					"email",
-				}).AddRow(
-					"4a6e104d-9d7f-45ff-8de6-37993d709522", // delivery_uid
-					"Test Testov",                          // name
-					"+9720000000",                          // phone
-					"2639809",                              // zip
-					"Kiryat Mozkin",                        // city
-					"Ploshad Mira 15",                      // address
-					"Kraiot",                               // region
-					"test@gmail.com",                       // email
-				)
+			s := &source{
+				db: sqlx.NewDb(db, "sqlmock"),
+			}
 
-				f.db.ExpectQuery(
-					`SELECT
-						track_number, entry, delivery_uid, payment_transaction, locale,
-						internal_signature, customer_id, delivery_service, shardkey, sm_id,
-						date_created, oof_shard
-					FROM orders
-					WHERE order_uid = $1`,
-				).WithArgs(a.uid).WillReturnRows(orderRows)
+			tt.setup(mock)
 
-				f.db.ExpectQuery(
-					"SELECT * FROM delivery WHERE delivery_uid = $1",
-				).WithArgs("4a6e104d-9d7f-45ff-8de6-37993d709522").WillReturnRows(deliveryRows)
+			got, err := s.ListOrders(context.Background(), tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("source.ListOrders() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("source.ListOrders() = %+v, want %+v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
 
-				f.db.ExpectQuery("Bum bum").WillReturnError(fmt.Errorf("can't get payment: can't exec query"))
+func Test_source_SearchOrders(t *testing.T) {
+	from := MustParseTime(time.RFC3339, "2021-11-01T00:00:00Z")
+	to := MustParseTime(time.RFC3339, "2021-12-01T00:00:00Z")
+	fromMSK, toMSK := from.In(time.FixedZone("MSK", 3*60*60)), to.In(time.FixedZone("MSK", 3*60*60))
+	nmID := 2389212
+	order := testOrder()
+
+	tests := []struct {
+		name    string
+		query   entity.OrderSearchQuery
+		setup   func(mock sqlmock.Sqlmock)
+		want    *entity.OrderPage
+		wantErr bool
+	}{
+		{
+			name: "ok: all filters",
+			query: entity.OrderSearchQuery{
+				OrderListQuery: entity.OrderListQuery{
+					Limit: 10,
+					Sort:  entity.OrderSortOrderUID,
+					After: &entity.OrderCursor{Sort: entity.OrderSortOrderUID, OrderUID: "order_uid_0"},
+				},
+				Filter: entity.OrderFilter{
+					TrackNumber:     "track_number_1",
+					CustomerID:      "customer_1",
+					DeliveryService: "meest",
+					Locale:          "en",
+					CreatedFrom:     &from,
+					CreatedTo:       &to,
+					PaymentBank:     "alpha",
+					PaymentProvider: "wbpay",
+					PaymentCurrency: "USD",
+					DeliveryPhone:   "+1234567890",
+					DeliveryEmail:   "test@example.com",
+					DeliveryCity:    "Kiryat Mozkin",
+					ItemBrand:       "Vivienne Sabo",
+					ItemNmID:        &nmID,
+				},
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(` WHERE o.deleted_at IS NULL AND o.track_number = $1 AND o.customer_id = $2 AND o.delivery_service = $3`+
+					` AND o.locale = $4 AND o.date_created >= $5 AND o.date_created < $6`+
+					` AND p.bank = $7 AND p.provider = $8 AND p.currency = $9`+
+					` AND d.phone = $10 AND d.email = $11 AND d.city = $12`+
+					` AND EXISTS (SELECT 1 FROM items i WHERE i.order_uid = o.order_uid AND i.brand = $13 AND i.nm_id = $14)`+
+					` AND o.order_uid > $15 ORDER BY o.order_uid ASC LIMIT $16`)).
+					WithArgs(
+						"track_number_1", "customer_1", "meest", "en", from, to,
+						"alpha", "wbpay", "USD", "+1234567890", "test@example.com", "Kiryat Mozkin",
+						"Vivienne Sabo", nmID, "order_uid_0", 11,
+					).
+					WillReturnRows(joinedOrderRows(order))
+				mock.ExpectQuery(`SELECT .* FROM items`).WillReturnRows(orderItemRowsOf(order))
+			},
+			want: &entity.OrderPage{
+				Orders: []*entity.Order{order},
 			},
-			wantErr: true,
 		},
 		{
-			name: "fail: can't get items",
-			args: args{
-				ctx: context.Background(),
-				uid: "b563feb7b2b84b6test",
-			},
-			want: nil,
-			setup: func(a args, f fields) {
-				orderRows := sqlmock.NewRows([]string{
-					"track_number",
-					"entry",
-					"delivery_uid",
-					"payment_transaction",
-					"locale",
-					"internal_signature",
-					"customer_id",
-					"delivery_service",
-					"shardkey",
-					"sm_id",
-					"date_created",
-					"oof_shard",
-				}).AddRow(
-					"WBILMTESTTRACK",                       // track_number
-					"WBIL",                                 // entry
-					"4a6e104d-9d7f-45ff-8de6-37993d709522", // delivery_uid
-					"b563feb7b2b84b6test",                  // payment_transaction
-					"en",                                   // locale
-					"",                                     // internal_signature
-					"test",                                 // customer_id
-					"meest",                                // delivery_service
-					"9",                                    // shardkey
-					99,                                     // sm_id
-					MustParseTime(time.RFC3339, "2021-11-26T06:22:19Z"), // date_created
-					"1", // oof_shard
-				)
+			name: "ok: creation bounds with offset",
+			query: entity.OrderSearchQuery{
+				OrderListQuery: entity.OrderListQuery{Limit: 10, Sort: entity.OrderSortDateCreated},
+				Filter: entity.OrderFilter{
+					CreatedFrom: &fromMSK,
+					CreatedTo:   &toMSK,
+				},
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(` WHERE o.deleted_at IS NULL AND o.date_created >= $1 AND o.date_created < $2`)).
+					WithArgs(from, to, 11).
+					WillReturnRows(joinedOrderRows())
+			},
+			want: &entity.OrderPage{Orders: []*entity.Order{}},
+		},
+		{
+			name: "ok: brand only",
+			query: entity.OrderSearchQuery{
+				OrderListQuery: entity.OrderListQuery{Limit: 10, Sort: entity.OrderSortDateCreated},
+				Filter:         entity.OrderFilter{ItemBrand: "Vivienne Sabo"},
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(` WHERE o.deleted_at IS NULL AND EXISTS (SELECT 1 FROM items i WHERE i.order_uid = o.order_uid AND i.brand = $1)`+
+					` ORDER BY o.date_created ASC, o.order_uid ASC LIMIT $2`)).
+					WithArgs("Vivienne Sabo", 11).
+					WillReturnRows(joinedOrderRows())
+			},
+			want: &entity.OrderPage{
+				Orders: []*entity.Order{},
+			},
+		},
+		{
+			name: "ok: deleted orders",
+			query: entity.OrderSearchQuery{
+				OrderListQuery: entity.OrderListQuery{Limit: 10, Sort: entity.OrderSortOrderUID},
+				Filter:         entity.OrderFilter{Deleted: true},
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(` WHERE o.deleted_at IS NOT NULL ORDER BY o.order_uid ASC LIMIT $1`)).
+					WithArgs(11).
+					WillReturnRows(joinedOrderRows())
+			},
+			want: &entity.OrderPage{
+				Orders: []*entity.Order{},
+			},
+		},
+		{
+			name: "fail: can't exec query",
+			query: entity.OrderSearchQuery{
+				OrderListQuery: entity.OrderListQuery{Limit: 10, Sort: entity.OrderSortDateCreated},
+				Filter:         entity.OrderFilter{Locale: "en"},
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM orders o`).WillReturnError(fmt.Errorf("can't exec query"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Errorf("can't connect to database: %v", err)
+				return
+			}
+			defer db.Close()
 
-				deliveryRows := sqlmock.NewRows([]string{
-					"delivery_uid",
-					"name",
-					"phone",
-					"zip",
-					"city",
-					"address",
-					"region",
-					"email",
-				}).AddRow(
-					"4a6e104d-9d7f-45ff-8de6-37993d709522", // delivery_uid
-					"Test Testov",                          // name
-					"+9720000000",                          // phone
-					"2639809",                              // zip
-					"Kiryat Mozkin",                        // city
-					"Ploshad Mira 15",                      // address
-					"Kraiot",                               // region
-					"test@gmail.com",                       // email
-				)
+			s := &source{
+				db: sqlx.NewDb(db, "sqlmock"),
+			}
 
-				paymentRows := sqlmock.NewRows([]string{
-					"transaction",
-					"request_id",
-					"currency",
-					"provider",
-					"amount",
-					"payment_dt",
-					"bank",
-					"delivery_cost",
-					"goods_total",
-					"custom_fee",
-				}).AddRow(
-					"b563feb7b2b84b6test", // transaction
-					"",                    // request_id
-					"USD",                 // currency
-					"wbpay",               // provider
-					1817,                  // amount
-					1637907727,            // payment_dt
-					"alpha",               // bank
-					1500,                  // delivery_cost
-					317,                   // goods_total
-					0,                     // custom_fee
-				)
+			tt.setup(mock)
+
+			got, err := s.SearchOrders(context.Background(), tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("source.SearchOrders() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("source.SearchOrders() = %+v, want %+v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func Test_source_UpdateOrder(t *testing.T) {
+	orderColumns := []string{"order_uid"}
+
+	tests := []struct {
+		name         string
+		version      int
+		setup        func(mock sqlmock.Sqlmock)
+		wantErr      bool
+		wantNoRows   bool
+		wantConflict bool
+		wantMismatch bool
+	}{
+		{
+			name: "ok",
+			setup: func(mock sqlmock.Sqlmock) {
+				orderRows, deliveryRows, _, _ := storedOrderRows(testOrder())
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM orders WHERE order_uid = \$1 AND deleted_at IS NULL FOR UPDATE`).WithArgs("order_uid_1").WillReturnRows(orderRows)
+				mock.ExpectExec(`DELETE FROM items WHERE order_uid = \$1`).WithArgs("order_uid_1").WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`DELETE FROM payments WHERE order_uid = \$1`).WithArgs("order_uid_1").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE phone = \$1`).WillReturnRows(deliveryRows)
+				mock.ExpectExec(`INSERT INTO payments`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`UPDATE orders SET .* version = version \+ 1 WHERE order_uid = \$1 RETURNING version`).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+				mock.ExpectExec(`INSERT INTO order_versions`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "ok: previous delivery deleted",
+			setup: func(mock sqlmock.Sqlmock) {
+				orderRows, _, _, _ := storedOrderRows(testOrder())
+				deliveryColumns := []string{"delivery_uid", "name", "phone", "zip", "city", "address", "region", "email"}
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM orders WHERE order_uid = \$1 AND deleted_at IS NULL FOR UPDATE`).WithArgs("order_uid_1").WillReturnRows(orderRows)
+				mock.ExpectExec(`DELETE FROM items`).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`DELETE FROM payments`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE phone = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE email = \$1`).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+				mock.ExpectExec(`INSERT INTO deliveries`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO payments`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`UPDATE orders SET .* version = version \+ 1 WHERE order_uid = \$1 RETURNING version`).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+				mock.ExpectExec(`DELETE FROM deliveries WHERE delivery_uid = \$1 AND NOT EXISTS`).
+					WithArgs("delivery_uid_1").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO order_versions`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "fail: not found",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM orders WHERE order_uid = \$1 AND deleted_at IS NULL FOR UPDATE`).WillReturnRows(sqlmock.NewRows(orderColumns))
+				mock.ExpectRollback()
+			},
+			wantErr:    true,
+			wantNoRows: true,
+		},
+		{
+			name:    "ok: expected version",
+			version: 3,
+			setup: func(mock sqlmock.Sqlmock) {
+				stored := testOrder()
+				stored.Version = 3
+				orderRows, deliveryRows, _, _ := storedOrderRows(stored)
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM orders WHERE order_uid = \$1 AND deleted_at IS NULL FOR UPDATE`).WillReturnRows(orderRows)
+				mock.ExpectExec(`DELETE FROM items`).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`DELETE FROM payments`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE phone = \$1`).WillReturnRows(deliveryRows)
+				mock.ExpectExec(`INSERT INTO payments`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO items`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`UPDATE orders SET`).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(4))
+				mock.ExpectExec(`INSERT INTO order_versions`).WithArgs("order_uid_1", 4, entity.VersionUpdated, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "fail: version mismatch",
+			version: 2,
+			setup: func(mock sqlmock.Sqlmock) {
+				stored := testOrder()
+				stored.Version = 3
+				orderRows, _, _, _ := storedOrderRows(stored)
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM orders WHERE order_uid = \$1 AND deleted_at IS NULL FOR UPDATE`).WillReturnRows(orderRows)
+				mock.ExpectRollback()
+			},
+			wantErr:      true,
+			wantMismatch: true,
+		},
+		{
+			name: "fail: unique violation",
+			setup: func(mock sqlmock.Sqlmock) {
+				orderRows, deliveryRows, _, _ := storedOrderRows(testOrder())
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM orders WHERE order_uid = \$1 AND deleted_at IS NULL FOR UPDATE`).WillReturnRows(orderRows)
+				mock.ExpectExec(`DELETE FROM items`).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`DELETE FROM payments`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT .* FROM deliveries WHERE phone = \$1`).WillReturnRows(deliveryRows)
+				mock.ExpectExec(`INSERT INTO payments`).WillReturnError(&pq.Error{Code: uniqueViolation})
+				mock.ExpectRollback()
+			},
+			wantErr:      true,
+			wantConflict: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Errorf("can't connect to database: %v", err)
+				return
+			}
+			defer db.Close()
 
-				f.db.ExpectQuery(
-					`SELECT
-						track_number, entry, delivery_uid, payment_transaction, locale,
-						internal_signature, customer_id, delivery_service, shardkey, sm_id,
-						date_created, oof_shard
-					FROM orders
-					WHERE order_uid = $1`,
-				).WithArgs(a.uid).WillReturnRows(orderRows)
+			s := &source{
+				db: sqlx.NewDb(db, "sqlmock"),
+			}
 
-				f.db.ExpectQuery(
-					"SELECT * FROM delivery WHERE delivery_uid = $1",
-				).WithArgs("4a6e104d-9d7f-45ff-8de6-37993d709522").WillReturnRows(deliveryRows)
+			tt.setup(mock)
+
+			err = s.UpdateOrder(context.Background(), testOrder(), tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("source.UpdateOrder() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if errors.Is(err, sql.ErrNoRows) != tt.wantNoRows {
+				t.Errorf("source.UpdateOrder() error = %v, wantNoRows %v", err, tt.wantNoRows)
+			}
+			if errors.Is(err, entity.ErrConflict) != tt.wantConflict {
+				t.Errorf("source.UpdateOrder() error = %v, wantConflict %v", err, tt.wantConflict)
+			}
+			if errors.Is(err, entity.ErrVersionMismatch) != tt.wantMismatch {
+				t.Errorf("source.UpdateOrder() error = %v, wantMismatch %v", err, tt.wantMismatch)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
 
-				f.db.ExpectQuery(
-					"SELECT * FROM payments WHERE transaction = $1",
-				).WithArgs("b563feb7b2b84b6test").WillReturnRows(paymentRows)
+func Test_source_DeleteOrder(t *testing.T) {
+	orderColumns := []string{"order_uid"}
 
-				f.db.ExpectQuery("Bum bum").WillReturnError(fmt.Errorf("can't get items: can't exec query"))
+	tests := []struct {
+		name         string
+		version      int
+		setup        func(mock sqlmock.Sqlmock)
+		wantErr      bool
+		wantNoRows   bool
+		wantMismatch bool
+	}{
+		{
+			name:    "ok",
+			version: 1,
+			setup: func(mock sqlmock.Sqlmock) {
+				stored := testOrder()
+				stored.Version = 1
+				orderRows, _, _, _ := storedOrderRows(stored)
+				deleted := deletedOrder()
+				deleted.Version = 2
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT \* FROM orders WHERE order_uid = \$1 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs("order_uid_1").WillReturnRows(orderRows)
+				mock.ExpectExec(`UPDATE orders SET deleted_at = now\(\), deleted_by = \$2, delete_reason = \$3, version = version \+ 1\s+WHERE order_uid = \$1`).
+					WithArgs("order_uid_1", "admin", "duplicate").WillReturnResult(sqlmock.NewResult(0, 1))
+				expectStoredVersion(mock, deleted, entity.VersionDeleted)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "fail: not found",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT \* FROM orders`).WillReturnRows(sqlmock.NewRows(orderColumns))
+				mock.ExpectRollback()
+			},
+			wantErr:    true,
+			wantNoRows: true,
+		},
+		{
+			name:    "fail: version mismatch",
+			version: 1,
+			setup: func(mock sqlmock.Sqlmock) {
+				stored := testOrder()
+				stored.Version = 2
+				orderRows, _, _, _ := storedOrderRows(stored)
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT \* FROM orders`).WillReturnRows(orderRows)
+				mock.ExpectRollback()
+			},
+			wantErr:      true,
+			wantMismatch: true,
+		},
+		{
+			name: "fail: can't execute query",
+			setup: func(mock sqlmock.Sqlmock) {
+				orderRows, _, _, _ := storedOrderRows(testOrder())
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT \* FROM orders`).WillReturnRows(orderRows)
+				mock.ExpectExec(`UPDATE orders SET deleted_at`).WillReturnError(fmt.Errorf("can't exec query"))
+				mock.ExpectRollback()
 			},
 			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Errorf("can't connect to database: %v", err)
+				return
+			}
+			defer db.Close()
+
+			s := &source{
+				db: sqlx.NewDb(db, "sqlmock"),
+			}
+
+			tt.setup(mock)
+
+			err = s.DeleteOrder(context.Background(), "order_uid_1", "admin", "duplicate", tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("source.DeleteOrder() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if errors.Is(err, sql.ErrNoRows) != tt.wantNoRows {
+				t.Errorf("source.DeleteOrder() error = %v, wantNoRows %v", err, tt.wantNoRows)
+			}
+			if errors.Is(err, entity.ErrVersionMismatch) != tt.wantMismatch {
+				t.Errorf("source.DeleteOrder() error = %v, wantMismatch %v", err, tt.wantMismatch)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func Test_source_RestoreOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(mock sqlmock.Sqlmock)
+		wantErr    bool
+		wantNoRows bool
+	}{
+		{
+			name: "ok",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE orders SET deleted_at = NULL, .* WHERE order_uid = \$1 AND deleted_at IS NOT NULL`).
+					WithArgs("order_uid_1").WillReturnResult(sqlmock.NewResult(0, 1))
+				expectStoredVersion(mock, testOrder(), entity.VersionRestored)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "fail: not deleted",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE orders SET deleted_at = NULL`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			wantErr:    true,
+			wantNoRows: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
 			if err != nil {
 				t.Errorf("can't connect to database: %v", err)
 				return
 			}
-			f := fields{
-				db: mock,
+			defer db.Close()
+
+			s := &source{
+				db: sqlx.NewDb(db, "sqlmock"),
+			}
+
+			tt.setup(mock)
+
+			err = s.RestoreOrder(context.Background(), "order_uid_1")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("source.RestoreOrder() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if errors.Is(err, sql.ErrNoRows) != tt.wantNoRows {
+				t.Errorf("source.RestoreOrder() error = %v, wantNoRows %v", err, tt.wantNoRows)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
 			}
+		})
+	}
+}
+
+func Test_source_PurgeDeletedOrders(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(mock sqlmock.Sqlmock)
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "ok: shared delivery deleted once",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`DELETE FROM orders WHERE deleted_at < now\(\) - make_interval\(secs => \$1\) RETURNING delivery_uid`).
+					WithArgs(float64(3600)).
+					WillReturnRows(sqlmock.NewRows([]string{"delivery_uid"}).AddRow("delivery_uid_1").AddRow("delivery_uid_1"))
+				mock.ExpectExec(`DELETE FROM deliveries WHERE delivery_uid = \$1 AND NOT EXISTS`).
+					WithArgs("delivery_uid_1").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want: 2,
+		},
+		{
+			name: "ok: nothing to purge",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`DELETE FROM orders`).WillReturnRows(sqlmock.NewRows([]string{"delivery_uid"}))
+				mock.ExpectCommit()
+			},
+			want: 0,
+		},
+		{
+			name: "fail: can't delete delivery",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`DELETE FROM orders`).WillReturnRows(sqlmock.NewRows([]string{"delivery_uid"}).AddRow("delivery_uid_1"))
+				mock.ExpectExec(`DELETE FROM deliveries`).WillReturnError(fmt.Errorf("can't exec query"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Errorf("can't connect to database: %v", err)
+				return
+			}
+			defer db.Close()
 
 			s := &source{
 				db: sqlx.NewDb(db, "sqlmock"),
 			}
 
-			tt.setup(tt.args, f)
-			got, err := s.GetOrderByUid(tt.args.ctx, tt.args.uid)
+			tt.setup(mock)
+
+			got, err := s.PurgeDeletedOrders(context.Background(), time.Hour)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("source.GetOrderByUid() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("source.PurgeDeletedOrders() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("source.GetOrderByUid() = %v, want %v", got, tt.want)
+			if got != tt.want {
+				t.Errorf("source.PurgeDeletedOrders() = %v, want %v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
 			}
 		})
 	}
@@ -9,21 +9,29 @@ import (
 	"fmt"
 )
 
-// CreatePayment inserts a new payment record into the database.
-// It takes a context and a payment entity as input parameters.
+// paymentColumns lists the columns of the payments table mapped to entity.Payment.
+const paymentColumns = `transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee`
+
+// CreatePayment inserts a new payment record of an order into the database.
+// It takes a context, an order UID and a payment entity as input parameters.
 // Returns the transaction ID of the created payment or an error if the operation fails.
-func (s *source) CreatePayment(ctx context.Context, payment *entity.Payment) (string, error) {
+func (s *source) CreatePayment(ctx context.Context, orderUID string, payment *entity.Payment) (string, error) {
 	// Create a database context with a timeout
 	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
 	defer dbCancel()
 
+	return createPayment(dbCtx, s.db, orderUID, payment)
+}
+
+// createPayment inserts a payment record of an order using the given executor.
+func createPayment(ctx context.Context, ex executor, orderUID string, payment *entity.Payment) (string, error) {
 	// Execute the query to insert payment details into the database
-	row := s.db.QueryRowContext(
-		dbCtx,
+	_, err := ex.ExecContext(
+		ctx,
 		`INSERT INTO payments 
 		(transaction, request_id, currency, provider, amount, payment_dt, 
-		bank, delivery_cost, goods_total, custom_fee) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		bank, delivery_cost, goods_total, custom_fee, order_uid) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
 		payment.Transaction,
 		payment.RequestID,
 		payment.Currency,
@@ -34,10 +42,9 @@ func (s *source) CreatePayment(ctx context.Context, payment *entity.Payment) (st
 		payment.DeliveryCost,
 		payment.GoodsTotal,
 		payment.CustomFee,
+		orderUID,
 	)
-
-	// Check for any errors after executing the query
-	if err := row.Err(); err != nil {
+	if err != nil {
 		return "", fmt.Errorf("can't execute query: %w", err)
 	}
 
@@ -53,10 +60,15 @@ func (s *source) GetPaymentByTransaction(ctx context.Context, transaction string
 	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
 	defer dbCancel()
 
+	return getPaymentByTransaction(dbCtx, s.db, transaction)
+}
+
+// getPaymentByTransaction retrieves payment details by transaction ID using the given executor.
+func getPaymentByTransaction(ctx context.Context, ex executor, transaction string) (*entity.Payment, error) {
 	// Query payment details from the database by transaction ID
-	row := s.db.QueryRowxContext(
-		dbCtx,
-		"SELECT * FROM payments WHERE transaction = $1",
+	row := ex.QueryRowxContext(
+		ctx,
+		"SELECT "+paymentColumns+" FROM payments WHERE transaction = $1",
 		transaction,
 	)
 	// Check for any errors after executing the query
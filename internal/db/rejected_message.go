@@ -0,0 +1,135 @@
+package db
+
+import (
+	"L0/internal/entity"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CreateRejectedMessage inserts a rejected message into the database.
+// It takes a context and a rejected message entity as input parameters.
+// Returns the identifier of the stored message or an error if the operation fails.
+func (s *source) CreateRejectedMessage(ctx context.Context, msg *entity.RejectedMessage) (int64, error) {
+	// Create a database context with a timeout
+	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
+	defer dbCancel()
+
+	// Execute the query to insert the message and return its identifier
+	var id int64
+	err := s.db.QueryRowContext(
+		dbCtx,
+		`INSERT INTO rejected_messages
+		(subject, sequence, data, category, error, rejected_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		msg.Subject,
+		msg.Sequence,
+		msg.Data,
+		msg.Category,
+		msg.Error,
+		msg.RejectedAt,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("can't execute query: %w", err)
+	}
+
+	// Return the identifier of the stored message
+	return id, nil
+}
+
+// GetRejectedMessageById retrieves a rejected message from the database by its identifier.
+// It takes a context and a message ID as input parameters.
+// Returns the message or an error if the operation fails.
+func (s *source) GetRejectedMessageById(ctx context.Context, id int64) (*entity.RejectedMessage, error) {
+	// Create a database context with a timeout
+	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
+	defer dbCancel()
+
+	// Query the message from the database by ID
+	row := s.db.QueryRowxContext(
+		dbCtx,
+		"SELECT * FROM rejected_messages WHERE id = $1",
+		id,
+	)
+	if err := row.Err(); err != nil {
+		return nil, fmt.Errorf("can't execute query: %w", err)
+	}
+
+	// Scan the message from the database into a struct
+	var msg entity.RejectedMessage
+	if err := row.StructScan(&msg); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("can't scan rejected message: %w", err)
+	}
+
+	// Return the message
+	return &msg, nil
+}
+
+// GetAllRejectedMessages retrieves all rejected messages from the database, newest first.
+// It takes a context as an input parameter.
+// Returns a slice of messages or an error if the operation fails.
+func (s *source) GetAllRejectedMessages(ctx context.Context) ([]*entity.RejectedMessage, error) {
+	// Create a database context with a timeout
+	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
+	defer dbCancel()
+
+	// Query all messages from the database
+	rows, err := s.db.QueryxContext(
+		dbCtx,
+		"SELECT * FROM rejected_messages ORDER BY rejected_at DESC, id DESC",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("can't execute query: %w", err)
+	}
+	defer rows.Close()
+
+	// Scan each row into a message entity
+	var msgs []*entity.RejectedMessage
+	for rows.Next() {
+		var msg entity.RejectedMessage
+		if err := rows.StructScan(&msg); err != nil {
+			return nil, fmt.Errorf("can't scan rejected message: %w", err)
+		}
+		msgs = append(msgs, &msg)
+	}
+
+	// Check for errors after iterating over rows
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return msgs, nil
+}
+
+// DeleteRejectedMessage deletes a rejected message from the database.
+// It takes a context and a message ID as input parameters.
+// Returns sql.ErrNoRows if the message is not found or an error if the operation fails.
+func (s *source) DeleteRejectedMessage(ctx context.Context, id int64) error {
+	// Create a database context with a timeout
+	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
+	defer dbCancel()
+
+	// Execute the SQL query to delete the message from the database
+	res, err := s.db.ExecContext(
+		dbCtx,
+		"DELETE FROM rejected_messages WHERE id = $1",
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("can't execute query: %w", err)
+	}
+
+	// Report a missing message
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("can't get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
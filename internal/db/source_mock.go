@@ -8,6 +8,7 @@ import (
 	entity "L0/internal/entity"
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,6 +36,20 @@ func (m *MockOrderSource) EXPECT() *MockOrderSourceMockRecorder {
 	return m.recorder
 }
 
+// ChangeOrderStatus mocks base method.
+func (m *MockOrderSource) ChangeOrderStatus(ctx context.Context, change *entity.StatusChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeOrderStatus", ctx, change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeOrderStatus indicates an expected call of ChangeOrderStatus.
+func (mr *MockOrderSourceMockRecorder) ChangeOrderStatus(ctx, change interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeOrderStatus", reflect.TypeOf((*MockOrderSource)(nil).ChangeOrderStatus), ctx, change)
+}
+
 // CreateOrder mocks base method.
 func (m *MockOrderSource) CreateOrder(ctx context.Context, order *entity.Order) (string, error) {
 	m.ctrl.T.Helper()
@@ -51,17 +66,17 @@ func (mr *MockOrderSourceMockRecorder) CreateOrder(ctx, order interface{}) *gomo
 }
 
 // DeleteOrder mocks base method.
-func (m *MockOrderSource) DeleteOrder(ctx context.Context, orderUID string) error {
+func (m *MockOrderSource) DeleteOrder(ctx context.Context, orderUID, deletedBy, reason string, version int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteOrder", ctx, orderUID)
+	ret := m.ctrl.Call(m, "DeleteOrder", ctx, orderUID, deletedBy, reason, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteOrder indicates an expected call of DeleteOrder.
-func (mr *MockOrderSourceMockRecorder) DeleteOrder(ctx, orderUID interface{}) *gomock.Call {
+func (mr *MockOrderSourceMockRecorder) DeleteOrder(ctx, orderUID, deletedBy, reason, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrder", reflect.TypeOf((*MockOrderSource)(nil).DeleteOrder), ctx, orderUID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrder", reflect.TypeOf((*MockOrderSource)(nil).DeleteOrder), ctx, orderUID, deletedBy, reason, version)
 }
 
 // GetAllOrders mocks base method.
@@ -79,6 +94,21 @@ func (mr *MockOrderSourceMockRecorder) GetAllOrders(ctx interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllOrders", reflect.TypeOf((*MockOrderSource)(nil).GetAllOrders), ctx)
 }
 
+// GetChangedOrderUids mocks base method.
+func (m *MockOrderSource) GetChangedOrderUids(ctx context.Context, since time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChangedOrderUids", ctx, since)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChangedOrderUids indicates an expected call of GetChangedOrderUids.
+func (mr *MockOrderSourceMockRecorder) GetChangedOrderUids(ctx, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChangedOrderUids", reflect.TypeOf((*MockOrderSource)(nil).GetChangedOrderUids), ctx, since)
+}
+
 // GetOrderByUid mocks base method.
 func (m *MockOrderSource) GetOrderByUid(ctx context.Context, uid string) (*entity.Order, error) {
 	m.ctrl.T.Helper()
@@ -94,6 +124,153 @@ func (mr *MockOrderSourceMockRecorder) GetOrderByUid(ctx, uid interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByUid", reflect.TypeOf((*MockOrderSource)(nil).GetOrderByUid), ctx, uid)
 }
 
+// GetOrderVersion mocks base method.
+func (m *MockOrderSource) GetOrderVersion(ctx context.Context, orderUID string, version int) (*entity.OrderVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderVersion", ctx, orderUID, version)
+	ret0, _ := ret[0].(*entity.OrderVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderVersion indicates an expected call of GetOrderVersion.
+func (mr *MockOrderSourceMockRecorder) GetOrderVersion(ctx, orderUID, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderVersion", reflect.TypeOf((*MockOrderSource)(nil).GetOrderVersion), ctx, orderUID, version)
+}
+
+// GetOrderVersions mocks base method.
+func (m *MockOrderSource) GetOrderVersions(ctx context.Context, orderUID string) ([]*entity.OrderVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderVersions", ctx, orderUID)
+	ret0, _ := ret[0].([]*entity.OrderVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderVersions indicates an expected call of GetOrderVersions.
+func (mr *MockOrderSourceMockRecorder) GetOrderVersions(ctx, orderUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderVersions", reflect.TypeOf((*MockOrderSource)(nil).GetOrderVersions), ctx, orderUID)
+}
+
+// GetStatusHistory mocks base method.
+func (m *MockOrderSource) GetStatusHistory(ctx context.Context, orderUID string) ([]*entity.StatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatusHistory", ctx, orderUID)
+	ret0, _ := ret[0].([]*entity.StatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatusHistory indicates an expected call of GetStatusHistory.
+func (mr *MockOrderSourceMockRecorder) GetStatusHistory(ctx, orderUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatusHistory", reflect.TypeOf((*MockOrderSource)(nil).GetStatusHistory), ctx, orderUID)
+}
+
+// ListOrders mocks base method.
+func (m *MockOrderSource) ListOrders(ctx context.Context, query entity.OrderListQuery) (*entity.OrderPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrders", ctx, query)
+	ret0, _ := ret[0].(*entity.OrderPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrders indicates an expected call of ListOrders.
+func (mr *MockOrderSourceMockRecorder) ListOrders(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrders", reflect.TypeOf((*MockOrderSource)(nil).ListOrders), ctx, query)
+}
+
+// PurgeDeletedOrders mocks base method.
+func (m *MockOrderSource) PurgeDeletedOrders(ctx context.Context, olderThan time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedOrders", ctx, olderThan)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedOrders indicates an expected call of PurgeDeletedOrders.
+func (mr *MockOrderSourceMockRecorder) PurgeDeletedOrders(ctx, olderThan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedOrders", reflect.TypeOf((*MockOrderSource)(nil).PurgeDeletedOrders), ctx, olderThan)
+}
+
+// RestoreOrder mocks base method.
+func (m *MockOrderSource) RestoreOrder(ctx context.Context, orderUID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreOrder", ctx, orderUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreOrder indicates an expected call of RestoreOrder.
+func (mr *MockOrderSourceMockRecorder) RestoreOrder(ctx, orderUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreOrder", reflect.TypeOf((*MockOrderSource)(nil).RestoreOrder), ctx, orderUID)
+}
+
+// SaveOrder mocks base method.
+func (m *MockOrderSource) SaveOrder(ctx context.Context, order *entity.Order, policy entity.UpdatePolicy) (entity.SaveOutcome, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOrder", ctx, order, policy)
+	ret0, _ := ret[0].(entity.SaveOutcome)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveOrder indicates an expected call of SaveOrder.
+func (mr *MockOrderSourceMockRecorder) SaveOrder(ctx, order, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrder", reflect.TypeOf((*MockOrderSource)(nil).SaveOrder), ctx, order, policy)
+}
+
+// SearchOrders mocks base method.
+func (m *MockOrderSource) SearchOrders(ctx context.Context, query entity.OrderSearchQuery) (*entity.OrderPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchOrders", ctx, query)
+	ret0, _ := ret[0].(*entity.OrderPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchOrders indicates an expected call of SearchOrders.
+func (mr *MockOrderSourceMockRecorder) SearchOrders(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchOrders", reflect.TypeOf((*MockOrderSource)(nil).SearchOrders), ctx, query)
+}
+
+// StreamOrders mocks base method.
+func (m *MockOrderSource) StreamOrders(ctx context.Context, fn func(*entity.Order) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamOrders", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamOrders indicates an expected call of StreamOrders.
+func (mr *MockOrderSourceMockRecorder) StreamOrders(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamOrders", reflect.TypeOf((*MockOrderSource)(nil).StreamOrders), ctx, fn)
+}
+
+// UpdateOrder mocks base method.
+func (m *MockOrderSource) UpdateOrder(ctx context.Context, order *entity.Order, version int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrder", ctx, order, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOrder indicates an expected call of UpdateOrder.
+func (mr *MockOrderSourceMockRecorder) UpdateOrder(ctx, order, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrder", reflect.TypeOf((*MockOrderSource)(nil).UpdateOrder), ctx, order, version)
+}
+
 // MockDeliverySource is a mock of DeliverySource interface.
 type MockDeliverySource struct {
 	ctrl     *gomock.Controller
@@ -201,18 +378,18 @@ func (m *MockPaymentSource) EXPECT() *MockPaymentSourceMockRecorder {
 }
 
 // CreatePayment mocks base method.
-func (m *MockPaymentSource) CreatePayment(ctx context.Context, payment *entity.Payment) (string, error) {
+func (m *MockPaymentSource) CreatePayment(ctx context.Context, orderUID string, payment *entity.Payment) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreatePayment", ctx, payment)
+	ret := m.ctrl.Call(m, "CreatePayment", ctx, orderUID, payment)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreatePayment indicates an expected call of CreatePayment.
-func (mr *MockPaymentSourceMockRecorder) CreatePayment(ctx, payment interface{}) *gomock.Call {
+func (mr *MockPaymentSourceMockRecorder) CreatePayment(ctx, orderUID, payment interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePayment", reflect.TypeOf((*MockPaymentSource)(nil).CreatePayment), ctx, payment)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePayment", reflect.TypeOf((*MockPaymentSource)(nil).CreatePayment), ctx, orderUID, payment)
 }
 
 // GetPaymentByTransaction mocks base method.
@@ -254,33 +431,33 @@ func (m *MockItemSource) EXPECT() *MockItemSourceMockRecorder {
 }
 
 // CreateItem mocks base method.
-func (m *MockItemSource) CreateItem(ctx context.Context, item *entity.Item) (string, error) {
+func (m *MockItemSource) CreateItem(ctx context.Context, orderUID string, item *entity.Item) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateItem", ctx, item)
+	ret := m.ctrl.Call(m, "CreateItem", ctx, orderUID, item)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateItem indicates an expected call of CreateItem.
-func (mr *MockItemSourceMockRecorder) CreateItem(ctx, item interface{}) *gomock.Call {
+func (mr *MockItemSourceMockRecorder) CreateItem(ctx, orderUID, item interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateItem", reflect.TypeOf((*MockItemSource)(nil).CreateItem), ctx, item)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateItem", reflect.TypeOf((*MockItemSource)(nil).CreateItem), ctx, orderUID, item)
 }
 
 // CreateItems mocks base method.
-func (m *MockItemSource) CreateItems(ctx context.Context, items []entity.Item) ([]string, error) {
+func (m *MockItemSource) CreateItems(ctx context.Context, orderUID string, items []entity.Item) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateItems", ctx, items)
+	ret := m.ctrl.Call(m, "CreateItems", ctx, orderUID, items)
 	ret0, _ := ret[0].([]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateItems indicates an expected call of CreateItems.
-func (mr *MockItemSourceMockRecorder) CreateItems(ctx, items interface{}) *gomock.Call {
+func (mr *MockItemSourceMockRecorder) CreateItems(ctx, orderUID, items interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateItems", reflect.TypeOf((*MockItemSource)(nil).CreateItems), ctx, items)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateItems", reflect.TypeOf((*MockItemSource)(nil).CreateItems), ctx, orderUID, items)
 }
 
 // GetItemByUid mocks base method.
@@ -312,3 +489,137 @@ func (mr *MockItemSourceMockRecorder) GetItemsByTrackNumber(ctx, trackNumber int
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemsByTrackNumber", reflect.TypeOf((*MockItemSource)(nil).GetItemsByTrackNumber), ctx, trackNumber)
 }
+
+// MockRejectedMessageSource is a mock of RejectedMessageSource interface.
+type MockRejectedMessageSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockRejectedMessageSourceMockRecorder
+}
+
+// MockRejectedMessageSourceMockRecorder is the mock recorder for MockRejectedMessageSource.
+type MockRejectedMessageSourceMockRecorder struct {
+	mock *MockRejectedMessageSource
+}
+
+// NewMockRejectedMessageSource creates a new mock instance.
+func NewMockRejectedMessageSource(ctrl *gomock.Controller) *MockRejectedMessageSource {
+	mock := &MockRejectedMessageSource{ctrl: ctrl}
+	mock.recorder = &MockRejectedMessageSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRejectedMessageSource) EXPECT() *MockRejectedMessageSourceMockRecorder {
+	return m.recorder
+}
+
+// CreateRejectedMessage mocks base method.
+func (m *MockRejectedMessageSource) CreateRejectedMessage(ctx context.Context, msg *entity.RejectedMessage) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRejectedMessage", ctx, msg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRejectedMessage indicates an expected call of CreateRejectedMessage.
+func (mr *MockRejectedMessageSourceMockRecorder) CreateRejectedMessage(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRejectedMessage", reflect.TypeOf((*MockRejectedMessageSource)(nil).CreateRejectedMessage), ctx, msg)
+}
+
+// DeleteRejectedMessage mocks base method.
+func (m *MockRejectedMessageSource) DeleteRejectedMessage(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRejectedMessage", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRejectedMessage indicates an expected call of DeleteRejectedMessage.
+func (mr *MockRejectedMessageSourceMockRecorder) DeleteRejectedMessage(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRejectedMessage", reflect.TypeOf((*MockRejectedMessageSource)(nil).DeleteRejectedMessage), ctx, id)
+}
+
+// GetAllRejectedMessages mocks base method.
+func (m *MockRejectedMessageSource) GetAllRejectedMessages(ctx context.Context) ([]*entity.RejectedMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllRejectedMessages", ctx)
+	ret0, _ := ret[0].([]*entity.RejectedMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllRejectedMessages indicates an expected call of GetAllRejectedMessages.
+func (mr *MockRejectedMessageSourceMockRecorder) GetAllRejectedMessages(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllRejectedMessages", reflect.TypeOf((*MockRejectedMessageSource)(nil).GetAllRejectedMessages), ctx)
+}
+
+// GetRejectedMessageById mocks base method.
+func (m *MockRejectedMessageSource) GetRejectedMessageById(ctx context.Context, id int64) (*entity.RejectedMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRejectedMessageById", ctx, id)
+	ret0, _ := ret[0].(*entity.RejectedMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRejectedMessageById indicates an expected call of GetRejectedMessageById.
+func (mr *MockRejectedMessageSourceMockRecorder) GetRejectedMessageById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRejectedMessageById", reflect.TypeOf((*MockRejectedMessageSource)(nil).GetRejectedMessageById), ctx, id)
+}
+
+// MockIngestionSource is a mock of IngestionSource interface.
+type MockIngestionSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockIngestionSourceMockRecorder
+}
+
+// MockIngestionSourceMockRecorder is the mock recorder for MockIngestionSource.
+type MockIngestionSourceMockRecorder struct {
+	mock *MockIngestionSource
+}
+
+// NewMockIngestionSource creates a new mock instance.
+func NewMockIngestionSource(ctrl *gomock.Controller) *MockIngestionSource {
+	mock := &MockIngestionSource{ctrl: ctrl}
+	mock.recorder = &MockIngestionSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIngestionSource) EXPECT() *MockIngestionSourceMockRecorder {
+	return m.recorder
+}
+
+// GetIngestion mocks base method.
+func (m *MockIngestionSource) GetIngestion(ctx context.Context, uid string) (*entity.Ingestion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIngestion", ctx, uid)
+	ret0, _ := ret[0].(*entity.Ingestion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIngestion indicates an expected call of GetIngestion.
+func (mr *MockIngestionSourceMockRecorder) GetIngestion(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIngestion", reflect.TypeOf((*MockIngestionSource)(nil).GetIngestion), ctx, uid)
+}
+
+// SaveIngestion mocks base method.
+func (m *MockIngestionSource) SaveIngestion(ctx context.Context, ingestion *entity.Ingestion) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveIngestion", ctx, ingestion)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveIngestion indicates an expected call of SaveIngestion.
+func (mr *MockIngestionSourceMockRecorder) SaveIngestion(ctx, ingestion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveIngestion", reflect.TypeOf((*MockIngestionSource)(nil).SaveIngestion), ctx, ingestion)
+}
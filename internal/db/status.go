@@ -0,0 +1,88 @@
+package db
+
+import (
+	"L0/internal/entity"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ChangeOrderStatus moves an order from one status to another and records the transition
+// in the status history and the changed order as a new version in a single transaction.
+// The status is changed only if the order is still in change.From, so concurrent transitions
+// of the same order can't both succeed. The identifier and the time of the recorded transition
+// are set on change.
+// It takes a context and a status change as input parameters.
+// Returns sql.ErrNoRows if the order does not exist, is deleted or is not in change.From,
+// or an error if the operation fails.
+func (s *source) ChangeOrderStatus(ctx context.Context, change *entity.StatusChange) error {
+	// Create a database context with a timeout
+	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
+	defer dbCancel()
+
+	return s.withTx(dbCtx, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(
+			dbCtx,
+			`UPDATE orders SET status = $3, version = version + 1
+			WHERE order_uid = $1 AND status = $2 AND deleted_at IS NULL`,
+			change.OrderUID,
+			change.From,
+			change.To,
+		)
+		if err != nil {
+			return fmt.Errorf("can't execute query: %w", err)
+		}
+
+		// Report a missing order or a concurrent transition
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("can't get affected rows: %w", err)
+		}
+		if affected == 0 {
+			return sql.ErrNoRows
+		}
+
+		// Record the transition
+		err = tx.QueryRowxContext(
+			dbCtx,
+			`INSERT INTO status_history (order_uid, from_status, to_status, actor, reason)
+			VALUES ($1, $2, $3, $4, $5) RETURNING id, changed_at`,
+			change.OrderUID,
+			change.From,
+			change.To,
+			change.Actor,
+			change.Reason,
+		).Scan(&change.ID, &change.ChangedAt)
+		if err != nil {
+			return fmt.Errorf("can't record status change: %w", err)
+		}
+
+		return recordStoredVersion(dbCtx, tx, entity.VersionStatusChanged, change.OrderUID)
+	})
+}
+
+// GetStatusHistory retrieves the status transitions of an order, oldest first.
+// It takes a context and an order UID as input parameters.
+// Returns a slice of status changes or an error if the operation fails.
+func (s *source) GetStatusHistory(ctx context.Context, orderUID string) ([]*entity.StatusChange, error) {
+	// Create a database context with a timeout
+	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
+	defer dbCancel()
+
+	history := []*entity.StatusChange{}
+	err := sqlx.SelectContext(
+		dbCtx,
+		s.db,
+		&history,
+		`SELECT id, order_uid, from_status, to_status, actor, reason, changed_at
+		FROM status_history WHERE order_uid = $1 ORDER BY id`,
+		orderUID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("can't execute query: %w", err)
+	}
+
+	return history, nil
+}
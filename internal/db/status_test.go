@@ -0,0 +1,178 @@
+package db
+
+import (
+	"L0/internal/entity"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func testStatusChange() *entity.StatusChange {
+	return &entity.StatusChange{
+		OrderUID: "order_uid_1",
+		From:     entity.StatusCreated,
+		To:       entity.StatusPaid,
+		Actor:    "billing",
+		Reason:   "payment received",
+	}
+}
+
+func Test_source_ChangeOrderStatus(t *testing.T) {
+	errQuery := fmt.Errorf("query error")
+	changedAt := MustParseTime(time.RFC3339, "2021-11-27T10:00:00Z")
+
+	tests := []struct {
+		name       string
+		setup      func(mock sqlmock.Sqlmock)
+		wantErr    bool
+		wantNoRows bool
+	}{
+		{
+			name: "ok",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE orders SET status = \$3, version = version \+ 1 WHERE order_uid = \$1 AND status = \$2 AND deleted_at IS NULL`).
+					WithArgs("order_uid_1", entity.StatusCreated, entity.StatusPaid).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO status_history`).
+					WithArgs("order_uid_1", entity.StatusCreated, entity.StatusPaid, "billing", "payment received").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "changed_at"}).AddRow(7, changedAt))
+				expectStoredVersion(mock, testOrder(), entity.VersionStatusChanged)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "fail: order is not in the expected status",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE orders SET status`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			wantErr:    true,
+			wantNoRows: true,
+		},
+		{
+			name: "fail: rollback on history error",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE orders SET status`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`INSERT INTO status_history`).WillReturnError(errQuery)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Errorf("can't connect to database: %v", err)
+				return
+			}
+			defer db.Close()
+
+			s := &source{
+				db: sqlx.NewDb(db, "sqlmock"),
+			}
+
+			tt.setup(mock)
+
+			change := testStatusChange()
+			err = s.ChangeOrderStatus(context.Background(), change)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("source.ChangeOrderStatus() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if errors.Is(err, sql.ErrNoRows) != tt.wantNoRows {
+				t.Errorf("source.ChangeOrderStatus() error = %v, wantNoRows %v", err, tt.wantNoRows)
+			}
+			if err == nil && (change.ID != 7 || !change.ChangedAt.Equal(changedAt)) {
+				t.Errorf("source.ChangeOrderStatus() change = %+v, want id 7 changed at %v", change, changedAt)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func Test_source_GetStatusHistory(t *testing.T) {
+	changedAt := MustParseTime(time.RFC3339, "2021-11-27T10:00:00Z")
+	errQuery := fmt.Errorf("query error")
+	columns := []string{"id", "order_uid", "from_status", "to_status", "actor", "reason", "changed_at"}
+
+	tests := []struct {
+		name    string
+		setup   func(mock sqlmock.Sqlmock)
+		want    []*entity.StatusChange
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .* FROM status_history WHERE order_uid = \$1 ORDER BY id`).
+					WithArgs("order_uid_1").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(7, "order_uid_1", "created", "paid", "billing", "payment received", changedAt))
+			},
+			want: []*entity.StatusChange{{
+				ID:        7,
+				OrderUID:  "order_uid_1",
+				From:      entity.StatusCreated,
+				To:        entity.StatusPaid,
+				Actor:     "billing",
+				Reason:    "payment received",
+				ChangedAt: changedAt,
+			}},
+		},
+		{
+			name: "ok: empty history",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .* FROM status_history`).WillReturnRows(sqlmock.NewRows(columns))
+			},
+			want: []*entity.StatusChange{},
+		},
+		{
+			name: "fail: query error",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .* FROM status_history`).WillReturnError(errQuery)
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Errorf("can't connect to database: %v", err)
+				return
+			}
+			defer db.Close()
+
+			s := &source{
+				db: sqlx.NewDb(db, "sqlmock"),
+			}
+
+			tt.setup(mock)
+
+			got, err := s.GetStatusHistory(context.Background(), "order_uid_1")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("source.GetStatusHistory() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("source.GetStatusHistory() = %v, want %v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// executor is implemented by both *sqlx.DB and *sqlx.Tx,
+// so the same query functions can run inside or outside a transaction.
+type executor interface {
+	sqlx.ExtContext
+}
+
+// withTx runs fn inside a transaction.
+// The transaction is committed if fn succeeds and rolled back otherwise.
+func (s *source) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	// Begin a transaction
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("can't begin transaction: %w", err)
+	}
+
+	// Roll back everything written so far if any step fails
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("can't commit transaction: %w", err)
+	}
+
+	return nil
+}
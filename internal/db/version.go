@@ -0,0 +1,151 @@
+package db
+
+import (
+	"L0/internal/entity"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// orderVersionRow represents an order version row with the encoded snapshot.
+type orderVersionRow struct {
+	entity.OrderVersion
+	Data []byte `db:"data"`
+}
+
+// recordVersion appends a snapshot of the order aggregate to its versions using the given executor.
+// The snapshot is numbered with the version of the order, which is kept out of the snapshot itself.
+// Items are sorted and timestamps taken as the database stores them, so snapshots of the same content are equal.
+func recordVersion(ctx context.Context, ex executor, operation entity.VersionOperation, order *entity.Order) error {
+	snapshot := *order
+	snapshot.Version = 0
+	snapshot.DateCreated = entity.StoredTime(order.DateCreated)
+	snapshot.Items = append([]entity.Item{}, order.Items...)
+	sort.Slice(snapshot.Items, func(i, j int) bool { return snapshot.Items[i].Rid < snapshot.Items[j].Rid })
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("can't encode order version: %w", err)
+	}
+
+	_, err = ex.ExecContext(
+		ctx,
+		`INSERT INTO order_versions (order_uid, version, operation, data) VALUES ($1, $2, $3, $4)`,
+		order.OrderUID,
+		order.Version,
+		operation,
+		data,
+	)
+	if err != nil {
+		return fmt.Errorf("can't record order version: %w", err)
+	}
+
+	return nil
+}
+
+// recordStoredVersion appends a snapshot of the stored order aggregate to its versions using the given executor.
+func recordStoredVersion(ctx context.Context, ex executor, operation entity.VersionOperation, orderUID string) error {
+	var stored entity.OrderDB
+	err := ex.QueryRowxContext(
+		ctx,
+		`SELECT * FROM orders WHERE order_uid = $1`,
+		orderUID,
+	).StructScan(&stored)
+	if err != nil {
+		return fmt.Errorf("can't get stored order: %w", err)
+	}
+
+	order, err := loadOrder(ctx, ex, &stored)
+	if err != nil {
+		return fmt.Errorf("can't load stored order: %w", err)
+	}
+
+	return recordVersion(ctx, ex, operation, order)
+}
+
+// GetOrderVersions retrieves the versions of an order without snapshots, oldest first.
+// It takes a context and an order UID as input parameters.
+// Returns a slice of versions or an error if the operation fails.
+func (s *source) GetOrderVersions(ctx context.Context, orderUID string) ([]*entity.OrderVersion, error) {
+	// Create a database context with a timeout
+	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
+	defer dbCancel()
+
+	versions := []*entity.OrderVersion{}
+	err := sqlx.SelectContext(
+		dbCtx,
+		s.db,
+		&versions,
+		`SELECT order_uid, version, operation, created_at
+		FROM order_versions WHERE order_uid = $1 ORDER BY version`,
+		orderUID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("can't execute query: %w", err)
+	}
+
+	return versions, nil
+}
+
+// GetOrderVersion retrieves a version of an order with its snapshot.
+// It takes a context, an order UID and a version number as input parameters.
+// Returns the version, sql.ErrNoRows if it does not exist, or an error if the operation fails.
+func (s *source) GetOrderVersion(ctx context.Context, orderUID string, version int) (*entity.OrderVersion, error) {
+	// Create a database context with a timeout
+	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
+	defer dbCancel()
+
+	var row orderVersionRow
+	err := s.db.QueryRowxContext(
+		dbCtx,
+		`SELECT order_uid, version, operation, created_at, data
+		FROM order_versions WHERE order_uid = $1 AND version = $2`,
+		orderUID,
+		version,
+	).StructScan(&row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("can't scan order version: %w", err)
+	}
+
+	// Decode the snapshot
+	var order entity.Order
+	if err := json.Unmarshal(row.Data, &order); err != nil {
+		return nil, fmt.Errorf("can't decode order version: %w", err)
+	}
+	order.Version = row.Version
+	row.OrderVersion.Order = &order
+
+	return &row.OrderVersion, nil
+}
+
+// GetChangedOrderUids retrieves the UIDs of orders with versions taken at or after since.
+// Versions are kept after an order is purged, so purged orders are listed as well.
+// It takes a context and the start time as input parameters.
+// Returns a slice of order UIDs or an error if the operation fails.
+func (s *source) GetChangedOrderUids(ctx context.Context, since time.Time) ([]string, error) {
+	// Create a database context with a timeout
+	dbCtx, dbCancel := context.WithTimeout(ctx, QueryTimeout)
+	defer dbCancel()
+
+	orderUIDs := []string{}
+	err := sqlx.SelectContext(
+		dbCtx,
+		s.db,
+		&orderUIDs,
+		`SELECT DISTINCT order_uid FROM order_versions WHERE created_at >= $1`,
+		since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("can't execute query: %w", err)
+	}
+
+	return orderUIDs, nil
+}
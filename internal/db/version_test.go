@@ -0,0 +1,288 @@
+package db
+
+import (
+	"L0/internal/entity"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+// expectStoredVersion expects the stored order to be loaded and recorded as a new version.
+func expectStoredVersion(mock sqlmock.Sqlmock, stored *entity.Order, operation entity.VersionOperation) {
+	orderRows, deliveryRows, paymentRows, itemRows := storedOrderRows(stored)
+	mock.ExpectQuery(`SELECT \* FROM orders WHERE order_uid = \$1`).WithArgs(stored.OrderUID).WillReturnRows(orderRows)
+	mock.ExpectQuery(`SELECT .* FROM deliveries WHERE delivery_uid = \$1`).WillReturnRows(deliveryRows)
+	mock.ExpectQuery(`SELECT .* FROM payments WHERE transaction = \$1`).WillReturnRows(paymentRows)
+	mock.ExpectQuery(`SELECT .* FROM items WHERE order_uid = \$1`).WillReturnRows(itemRows)
+	mock.ExpectExec(`INSERT INTO order_versions \(order_uid, version, operation, data\) VALUES \(\$1, \$2, \$3, \$4\)`).
+		WithArgs(stored.OrderUID, stored.Version, operation, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+// snapshotArg matches an encoded order snapshot with the given items order and creation date.
+type snapshotArg struct {
+	rids        []string
+	dateCreated time.Time
+}
+
+// Match implements sqlmock.Argument.
+func (a snapshotArg) Match(v driver.Value) bool {
+	data, ok := v.([]byte)
+	if !ok {
+		return false
+	}
+	var order entity.Order
+	if err := json.Unmarshal(data, &order); err != nil {
+		return false
+	}
+	rids := make([]string, 0, len(order.Items))
+	for _, item := range order.Items {
+		rids = append(rids, item.Rid)
+	}
+	return reflect.DeepEqual(rids, a.rids) && order.DateCreated.Location() == time.UTC && order.DateCreated.Equal(a.dateCreated)
+}
+
+func Test_recordVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Errorf("can't connect to database: %v", err)
+		return
+	}
+	defer db.Close()
+
+	order := testOrder()
+	order.Version = 3
+	order.Items[0], order.Items[1] = order.Items[1], order.Items[0]
+	order.DateCreated = order.DateCreated.In(time.FixedZone("MSK", 3*60*60)).Add(1500 * time.Nanosecond)
+
+	mock.ExpectExec(`INSERT INTO order_versions`).
+		WithArgs("order_uid_1", 3, entity.VersionUpdated, snapshotArg{
+			rids:        []string{testOrder().Items[0].Rid, testOrder().Items[1].Rid},
+			dateCreated: testOrder().DateCreated.Add(2 * time.Microsecond),
+		}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = recordVersion(context.Background(), sqlx.NewDb(db, "sqlmock"), entity.VersionUpdated, order)
+	if err != nil {
+		t.Errorf("recordVersion() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func Test_source_GetOrderVersions(t *testing.T) {
+	createdAt := MustParseTime(time.RFC3339, "2021-11-27T10:00:00Z")
+	columns := []string{"order_uid", "version", "operation", "created_at"}
+
+	tests := []struct {
+		name    string
+		setup   func(mock sqlmock.Sqlmock)
+		want    []*entity.OrderVersion
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT order_uid, version, operation, created_at FROM order_versions WHERE order_uid = \$1 ORDER BY version`).
+					WithArgs("order_uid_1").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("order_uid_1", 1, "create", createdAt).
+						AddRow("order_uid_1", 2, "delete", createdAt))
+			},
+			want: []*entity.OrderVersion{
+				{OrderUID: "order_uid_1", Version: 1, Operation: entity.VersionCreated, CreatedAt: createdAt},
+				{OrderUID: "order_uid_1", Version: 2, Operation: entity.VersionDeleted, CreatedAt: createdAt},
+			},
+		},
+		{
+			name: "fail: can't exec query",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .* FROM order_versions`).WillReturnError(fmt.Errorf("query error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Errorf("can't connect to database: %v", err)
+				return
+			}
+			defer db.Close()
+
+			s := &source{
+				db: sqlx.NewDb(db, "sqlmock"),
+			}
+
+			tt.setup(mock)
+
+			got, err := s.GetOrderVersions(context.Background(), "order_uid_1")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("source.GetOrderVersions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("source.GetOrderVersions() = %v, want %v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func Test_source_GetOrderVersion(t *testing.T) {
+	createdAt := MustParseTime(time.RFC3339, "2021-11-27T10:00:00Z")
+	columns := []string{"order_uid", "version", "operation", "created_at", "data"}
+	data, err := json.Marshal(testOrder())
+	if err != nil {
+		t.Fatalf("can't encode order: %v", err)
+	}
+	versioned := testOrder()
+	versioned.Version = 1
+
+	tests := []struct {
+		name       string
+		setup      func(mock sqlmock.Sqlmock)
+		want       *entity.OrderVersion
+		wantErr    bool
+		wantNoRows bool
+	}{
+		{
+			name: "ok",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .* FROM order_versions WHERE order_uid = \$1 AND version = \$2`).
+					WithArgs("order_uid_1", 1).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("order_uid_1", 1, "create", createdAt, data))
+			},
+			want: &entity.OrderVersion{
+				OrderUID:  "order_uid_1",
+				Version:   1,
+				Operation: entity.VersionCreated,
+				CreatedAt: createdAt,
+				Order:     versioned,
+			},
+		},
+		{
+			name: "fail: not found",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .* FROM order_versions`).WillReturnRows(sqlmock.NewRows(columns))
+			},
+			wantErr:    true,
+			wantNoRows: true,
+		},
+		{
+			name: "fail: broken snapshot",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .* FROM order_versions`).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("order_uid_1", 1, "create", createdAt, []byte(`{`)))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Errorf("can't connect to database: %v", err)
+				return
+			}
+			defer db.Close()
+
+			s := &source{
+				db: sqlx.NewDb(db, "sqlmock"),
+			}
+
+			tt.setup(mock)
+
+			got, err := s.GetOrderVersion(context.Background(), "order_uid_1", 1)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("source.GetOrderVersion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if errors.Is(err, sql.ErrNoRows) != tt.wantNoRows {
+				t.Errorf("source.GetOrderVersion() error = %v, wantNoRows %v", err, tt.wantNoRows)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("source.GetOrderVersion() = %+v, want %+v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func Test_source_GetChangedOrderUids(t *testing.T) {
+	since := MustParseTime(time.RFC3339, "2021-11-27T10:00:00Z")
+
+	tests := []struct {
+		name    string
+		setup   func(mock sqlmock.Sqlmock)
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT DISTINCT order_uid FROM order_versions WHERE created_at >= \$1`).
+					WithArgs(since).
+					WillReturnRows(sqlmock.NewRows([]string{"order_uid"}).AddRow("order_uid_1").AddRow("order_uid_2"))
+			},
+			want: []string{"order_uid_1", "order_uid_2"},
+		},
+		{
+			name: "ok: no changes",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT DISTINCT order_uid FROM order_versions`).
+					WillReturnRows(sqlmock.NewRows([]string{"order_uid"}))
+			},
+			want: []string{},
+		},
+		{
+			name: "fail: can't exec query",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT DISTINCT order_uid FROM order_versions`).WillReturnError(fmt.Errorf("query error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Errorf("can't connect to database: %v", err)
+				return
+			}
+			defer db.Close()
+
+			s := &source{
+				db: sqlx.NewDb(db, "sqlmock"),
+			}
+
+			tt.setup(mock)
+
+			got, err := s.GetChangedOrderUids(context.Background(), since)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("source.GetChangedOrderUids() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("source.GetChangedOrderUids() = %v, want %v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
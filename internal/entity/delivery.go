@@ -20,3 +20,16 @@ type DeliveryDB struct {
 	Region      string `db:"region"`
 	Email       string `db:"email"`
 }
+
+// Delivery converts the database record into a delivery entity.
+func (d DeliveryDB) Delivery() Delivery {
+	return Delivery{
+		Name:    d.Name,
+		Phone:   d.Phone,
+		Zip:     d.Zip,
+		City:    d.City,
+		Address: d.Address,
+		Region:  d.Region,
+		Email:   d.Email,
+	}
+}
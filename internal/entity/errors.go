@@ -0,0 +1,18 @@
+package entity
+
+import "errors"
+
+// ErrNotFound is returned when the requested entity does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrValidation is returned when an entity violates validation rules.
+var ErrValidation = errors.New("validation failed")
+
+// ErrConflict is returned when an entity conflicts with the stored state.
+var ErrConflict = errors.New("conflict")
+
+// ErrVersionMismatch is returned when an entity was changed since the version the caller expects.
+var ErrVersionMismatch = errors.New("version mismatch")
+
+// ErrUnavailable is returned when a service the operation depends on can't be reached.
+var ErrUnavailable = errors.New("unavailable")
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// IngestionState is a stage of the ingestion of a submitted order.
+type IngestionState string
+
+const (
+	// IngestionReceived is the state of an order accepted over HTTP and published to NATS.
+	IngestionReceived IngestionState = "received"
+	// IngestionValidated is the state of an order decoded and validated by the subscriber.
+	IngestionValidated IngestionState = "validated"
+	// IngestionPersisted is the state of an order stored in the database.
+	IngestionPersisted IngestionState = "persisted"
+	// IngestionRejected is the state of an order that will never be stored, the reason tells why.
+	IngestionRejected IngestionState = "rejected"
+)
+
+// Terminal reports whether the ingestion of an order in the state is over.
+func (s IngestionState) Terminal() bool {
+	return s == IngestionPersisted || s == IngestionRejected
+}
+
+// Ingestion tracks the ingestion of an order from its submission to its storage or rejection.
+type Ingestion struct {
+	OrderUID string         `json:"order_uid" db:"order_uid"`
+	State    IngestionState `json:"state" db:"state"`
+	// Reason describes the rejection of the order.
+	Reason string `json:"reason,omitempty" db:"reason"`
+	// Sequence is the sequence of the NATS message carrying the order, zero until the message is handled.
+	Sequence   uint64    `json:"sequence,omitempty" db:"sequence"`
+	ReceivedAt time.Time `json:"received_at" db:"received_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -19,20 +19,78 @@ type Order struct {
 	SmID              int       `json:"sm_id"`
 	DateCreated       time.Time `json:"date_created"`
 	OofShard          string    `json:"oof_shard"`
+	// Status is the current stage of the order lifecycle, managed by status transitions only.
+	Status OrderStatus `json:"status,omitempty"`
+	// Version is incremented on every change of the stored order and is used as its ETag.
+	Version int `json:"version,omitempty"`
+	// Deleted describes the deletion of a soft-deleted order, nil for a live order.
+	Deleted *OrderDeletion `json:"deleted,omitempty"`
+}
+
+// StoredTime returns a timestamp as the database stores it: in UTC, rounded to microseconds.
+// Timestamps are compared after this conversion, so a decoded timestamp matches the stored one.
+func StoredTime(t time.Time) time.Time {
+	return t.UTC().Round(time.Microsecond)
+}
+
+// OrderDeletion describes the soft deletion of an order.
+type OrderDeletion struct {
+	DeletedAt time.Time `json:"deleted_at"`
+	DeletedBy string    `json:"deleted_by,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
 }
 
 type OrderDB struct {
-	OrderUID           string    `db:"order_uid"`
-	TrackNumber        string    `db:"track_number"`
-	Entry              string    `db:"entry"`
-	DeliveryUID        string    `db:"delivery_uid"`
-	PaymentTransaction string    `db:"payment_transaction"`
-	Locale             string    `db:"locale"`
-	InternalSignature  string    `db:"internal_signature"`
-	CustomerID         string    `db:"customer_id"`
-	DeliveryService    string    `db:"delivery_service"`
-	Shardkey           string    `db:"shardkey"`
-	SmID               int       `db:"sm_id"`
-	DateCreated        time.Time `db:"date_created"`
-	OofShard           string    `db:"oof_shard"`
+	OrderUID           string      `db:"order_uid"`
+	TrackNumber        string      `db:"track_number"`
+	Entry              string      `db:"entry"`
+	DeliveryUID        string      `db:"delivery_uid"`
+	PaymentTransaction string      `db:"payment_transaction"`
+	Locale             string      `db:"locale"`
+	InternalSignature  string      `db:"internal_signature"`
+	CustomerID         string      `db:"customer_id"`
+	DeliveryService    string      `db:"delivery_service"`
+	Shardkey           string      `db:"shardkey"`
+	SmID               int         `db:"sm_id"`
+	DateCreated        time.Time   `db:"date_created"`
+	OofShard           string      `db:"oof_shard"`
+	Status             OrderStatus `db:"status"`
+	Version            int         `db:"version"`
+	// DeletedAt is set when the order is soft-deleted.
+	DeletedAt    *time.Time `db:"deleted_at"`
+	DeletedBy    string     `db:"deleted_by"`
+	DeleteReason string     `db:"delete_reason"`
+}
+
+// Order converts the database record into an order entity without delivery, payment and items.
+func (o *OrderDB) Order() *Order {
+	return &Order{
+		OrderUID:          o.OrderUID,
+		TrackNumber:       o.TrackNumber,
+		Entry:             o.Entry,
+		Locale:            o.Locale,
+		InternalSignature: o.InternalSignature,
+		CustomerID:        o.CustomerID,
+		DeliveryService:   o.DeliveryService,
+		Shardkey:          o.Shardkey,
+		SmID:              o.SmID,
+		DateCreated:       o.DateCreated,
+		OofShard:          o.OofShard,
+		Status:            o.Status,
+		Version:           o.Version,
+		Deleted:           o.Deletion(),
+	}
+}
+
+// Deletion returns the deletion of a soft-deleted order record or nil for a live one.
+func (o *OrderDB) Deletion() *OrderDeletion {
+	if o.DeletedAt == nil {
+		return nil
+	}
+
+	return &OrderDeletion{
+		DeletedAt: *o.DeletedAt,
+		DeletedBy: o.DeletedBy,
+		Reason:    o.DeleteReason,
+	}
 }
@@ -0,0 +1,137 @@
+package entity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// OrderSort is a field orders are listed by.
+type OrderSort string
+
+const (
+	// OrderSortDateCreated lists orders by creation date, ties broken by order UID.
+	OrderSortDateCreated OrderSort = "date_created"
+	// OrderSortOrderUID lists orders by order UID.
+	OrderSortOrderUID OrderSort = "order_uid"
+)
+
+// OrderListQuery describes a page of orders to list.
+type OrderListQuery struct {
+	Limit int
+	Sort  OrderSort
+	Desc  bool
+	// After is the position of the last order of the previous page, nil for the first page.
+	After *OrderCursor
+}
+
+// OrderFilter describes conditions orders must match. Zero-valued fields are ignored.
+type OrderFilter struct {
+	TrackNumber     string
+	CustomerID      string
+	DeliveryService string
+	Locale          string
+	// CreatedFrom is the inclusive lower bound of the creation date.
+	CreatedFrom *time.Time
+	// CreatedTo is the exclusive upper bound of the creation date.
+	CreatedTo *time.Time
+
+	PaymentBank     string
+	PaymentProvider string
+	PaymentCurrency string
+
+	DeliveryPhone string
+	DeliveryEmail string
+	DeliveryCity  string
+
+	// ItemBrand and ItemNmID match orders having an item with both of them.
+	ItemBrand string
+	ItemNmID  *int
+
+	// Deleted selects soft-deleted orders instead of live ones.
+	Deleted bool
+}
+
+// OrderSearchQuery describes a page of orders matching a filter.
+type OrderSearchQuery struct {
+	OrderListQuery
+	Filter OrderFilter
+}
+
+// OrderCursor is a keyset position in an order listing.
+// The sort and direction are kept so that a cursor can't be used with another listing.
+type OrderCursor struct {
+	Sort        OrderSort `json:"s"`
+	Desc        bool      `json:"d,omitempty"`
+	DateCreated time.Time `json:"t"`
+	OrderUID    string    `json:"u"`
+}
+
+// OrderPage is a page of listed orders.
+type OrderPage struct {
+	Orders []*Order
+	// Next is the position to continue the listing from, nil on the last page.
+	Next *OrderCursor
+}
+
+// OrderSummary is a short representation of a listed order.
+type OrderSummary struct {
+	OrderUID        string         `json:"order_uid"`
+	TrackNumber     string         `json:"track_number,omitempty"`
+	CustomerID      string         `json:"customer_id,omitempty"`
+	DeliveryService string         `json:"delivery_service,omitempty"`
+	DateCreated     *time.Time     `json:"date_created,omitempty"`
+	Amount          *int           `json:"amount,omitempty"`
+	Currency        string         `json:"currency,omitempty"`
+	ItemsCount      *int           `json:"items_count,omitempty"`
+	Deleted         *OrderDeletion `json:"deleted,omitempty"`
+}
+
+// Summary returns the short representation of the order.
+func (o *Order) Summary() OrderSummary {
+	itemsCount := len(o.Items)
+	return OrderSummary{
+		OrderUID:        o.OrderUID,
+		TrackNumber:     o.TrackNumber,
+		CustomerID:      o.CustomerID,
+		DeliveryService: o.DeliveryService,
+		DateCreated:     &o.DateCreated,
+		Amount:          &o.Payment.Amount,
+		Currency:        o.Payment.Currency,
+		ItemsCount:      &itemsCount,
+		Deleted:         o.Deleted,
+	}
+}
+
+// CursorFor returns the position of the order in a listing with the given sort and direction.
+func CursorFor(order *Order, sort OrderSort, desc bool) *OrderCursor {
+	return &OrderCursor{
+		Sort:        sort,
+		Desc:        desc,
+		DateCreated: order.DateCreated,
+		OrderUID:    order.OrderUID,
+	}
+}
+
+// Encode returns the opaque string representation of the cursor.
+func (c *OrderCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeOrderCursor parses a cursor returned by OrderCursor.Encode.
+// It returns an error wrapping ErrValidation if the cursor is malformed.
+func DecodeOrderCursor(s string) (*OrderCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor: %v", ErrValidation, err)
+	}
+
+	var c OrderCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor: %v", ErrValidation, err)
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,20 @@
+package entity
+
+// SaveOutcome describes what happened to an order when it was saved.
+type SaveOutcome string
+
+const (
+	SaveCreated   SaveOutcome = "created"
+	SaveUpdated   SaveOutcome = "updated"
+	SaveUnchanged SaveOutcome = "unchanged"
+)
+
+// UpdatePolicy defines how a changed payload for an already stored order is handled.
+type UpdatePolicy string
+
+const (
+	// UpdatePolicyUpdate replaces the stored order with the new payload.
+	UpdatePolicyUpdate UpdatePolicy = "update"
+	// UpdatePolicyReject rejects the new payload with ErrConflict.
+	UpdatePolicyReject UpdatePolicy = "reject"
+)
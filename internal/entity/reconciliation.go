@@ -0,0 +1,33 @@
+package entity
+
+import "time"
+
+// Reconciliation is the outcome of a comparison of the order cache with the database.
+type Reconciliation struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	// Checked is the number of live orders in the database compared with the cache.
+	Checked int `json:"checked"`
+	// Cached is the number of cached orders when the comparison started.
+	Cached int `json:"cached"`
+	// Stale is the number of cached orders absent from the database.
+	Stale int `json:"stale"`
+	// Mismatched is the number of cached orders whose content differs from the database.
+	Mismatched int `json:"mismatched"`
+	// Missing is the number of orders absent from a cache that claimed to hold all orders.
+	Missing int `json:"missing"`
+	// Repaired is the number of stale, mismatched and missing orders fixed,
+	// the others changed meanwhile and are compared again next time.
+	Repaired int `json:"repaired"`
+	// Loaded is the number of orders added to a cache that did not hold all orders.
+	Loaded int `json:"loaded"`
+	// Complete reports whether the cache holds all orders afterwards.
+	Complete bool `json:"complete"`
+	// Error is the reason the comparison failed, empty on success.
+	Error string `json:"error,omitempty"`
+}
+
+// Drift returns the number of cached orders found diverging from the database.
+func (r *Reconciliation) Drift() int {
+	return r.Stale + r.Mismatched + r.Missing
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// Error categories of rejected messages.
+const (
+	RejectCategoryDecode     = "decode"
+	RejectCategoryValidation = "validation"
+	RejectCategoryConflict   = "conflict"
+	RejectCategoryPersist    = "persist"
+)
+
+type RejectedMessage struct {
+	ID         int64     `json:"id" db:"id"`
+	Subject    string    `json:"subject" db:"subject"`
+	Sequence   uint64    `json:"sequence" db:"sequence"`
+	Data       []byte    `json:"data" db:"data"`
+	Category   string    `json:"category" db:"category"`
+	Error      string    `json:"error" db:"error"`
+	RejectedAt time.Time `json:"rejected_at" db:"rejected_at"`
+}
@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+// OrderStatus is a stage of the order lifecycle.
+type OrderStatus string
+
+const (
+	StatusCreated    OrderStatus = "created"
+	StatusPaid       OrderStatus = "paid"
+	StatusAssembling OrderStatus = "assembling"
+	StatusShipped    OrderStatus = "shipped"
+	StatusDelivered  OrderStatus = "delivered"
+	StatusCancelled  OrderStatus = "cancelled"
+	StatusReturned   OrderStatus = "returned"
+)
+
+// StatusUpdate is a request to move an order to another status.
+type StatusUpdate struct {
+	OrderUID string      `json:"order_uid,omitempty"`
+	Status   OrderStatus `json:"status"`
+	Actor    string      `json:"actor"`
+	Reason   string      `json:"reason,omitempty"`
+}
+
+// StatusChange is a recorded transition of an order from one status to another.
+type StatusChange struct {
+	ID        int64       `json:"id" db:"id"`
+	OrderUID  string      `json:"order_uid" db:"order_uid"`
+	From      OrderStatus `json:"from" db:"from_status"`
+	To        OrderStatus `json:"to" db:"to_status"`
+	Actor     string      `json:"actor" db:"actor"`
+	Reason    string      `json:"reason,omitempty" db:"reason"`
+	ChangedAt time.Time   `json:"changed_at" db:"changed_at"`
+}
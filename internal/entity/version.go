@@ -0,0 +1,41 @@
+package entity
+
+import "time"
+
+// VersionOperation is the change of an order captured by a version.
+type VersionOperation string
+
+const (
+	VersionCreated       VersionOperation = "create"
+	VersionUpdated       VersionOperation = "update"
+	VersionDeleted       VersionOperation = "delete"
+	VersionRestored      VersionOperation = "restore"
+	VersionStatusChanged VersionOperation = "status"
+)
+
+// OrderVersion is a snapshot of the whole order aggregate taken after a change.
+// Versions of an order are numbered from 1 in the order they were taken.
+type OrderVersion struct {
+	OrderUID  string           `json:"order_uid" db:"order_uid"`
+	Version   int              `json:"version" db:"version"`
+	Operation VersionOperation `json:"operation" db:"operation"`
+	CreatedAt time.Time        `json:"created_at" db:"created_at"`
+	// Order is the snapshot, it is omitted when versions are listed.
+	Order *Order `json:"order,omitempty" db:"-"`
+}
+
+// FieldChange is a difference between two JSON documents at a path such as items[0].price.
+// From is absent for an added value and To is absent for a removed one.
+type FieldChange struct {
+	Path string      `json:"path"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// OrderDiff lists the differences between two versions of an order.
+type OrderDiff struct {
+	OrderUID string        `json:"order_uid"`
+	From     int           `json:"from"`
+	To       int           `json:"to"`
+	Changes  []FieldChange `json:"changes"`
+}
@@ -0,0 +1,36 @@
+// Package invalidation keeps the order caches of several application instances consistent.
+
+package invalidation
+
+import (
+	"L0/internal/entity"
+	"context"
+)
+
+//go:generate mockgen -source=interfaces.go -destination=invalidation_mock.go -package=invalidation
+
+// Bus carries encoded invalidation events between application instances.
+type Bus interface {
+	// Publish sends an encoded event to all instances, the sender included.
+	// It takes a context and the encoded event as input parameters.
+	// Returns an error if the event can't be sent.
+	Publish(ctx context.Context, data []byte) error
+
+	// Subscribe passes the encoded events of all instances to handle until the context is canceled.
+	// It takes a context and a callback as input parameters.
+	// Returns an error if the subscription fails.
+	Subscribe(ctx context.Context, handle func(data []byte)) error
+}
+
+// Broadcaster tells the other instances about the changes of orders made by this instance.
+type Broadcaster interface {
+	// Set broadcasts that an order has been created or changed.
+	// It takes a context and the changed order as input parameters.
+	// Returns nothing, a failure is logged since the change is already stored.
+	Set(ctx context.Context, order *entity.Order)
+
+	// Delete broadcasts that an order has been deleted.
+	// It takes a context and the UID of the order as input parameters.
+	// Returns nothing, a failure is logged since the deletion is already stored.
+	Delete(ctx context.Context, uid string)
+}
@@ -0,0 +1,160 @@
+package invalidation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"L0/internal/cache"
+	"L0/internal/entity"
+	"L0/internal/repository"
+)
+
+// Operation is the change of an order announced by an event.
+type Operation string
+
+const (
+	// OperationSet announces a created or changed order.
+	OperationSet Operation = "set"
+	// OperationDelete announces a deleted order.
+	OperationDelete Operation = "delete"
+)
+
+// Event announces a change of an order made by an instance.
+type Event struct {
+	// Instance is the ID of the instance that made the change.
+	Instance  string    `json:"instance"`
+	Operation Operation `json:"operation"`
+	OrderUID  string    `json:"order_uid"`
+	// Version is the version of the changed order, zero for a deletion.
+	Version int `json:"version,omitempty"`
+}
+
+// NewInstanceID returns a random instance ID.
+func NewInstanceID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("can't generate instance id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// service broadcasts the changes of this instance and applies the changes of the others to the cache.
+type service struct {
+	bus             Bus
+	instance        string
+	cache           cache.OrderCache
+	orderRepository repository.OrderRepository
+	logger          *zap.Logger
+}
+
+// NewService creates a new instance of service.
+// A nil bus disables invalidation, then changes are neither broadcast nor received.
+func NewService(
+	bus Bus,
+	instance string,
+	cache cache.OrderCache,
+	orderRepository repository.OrderRepository,
+	logger *zap.Logger,
+) *service {
+	return &service{
+		bus:             bus,
+		instance:        instance,
+		cache:           cache,
+		orderRepository: orderRepository,
+		logger:          logger,
+	}
+}
+
+// Set broadcasts that an order has been created or changed.
+func (s *service) Set(ctx context.Context, order *entity.Order) {
+	s.publish(ctx, &Event{Operation: OperationSet, OrderUID: order.OrderUID, Version: order.Version})
+}
+
+// Delete broadcasts that an order has been deleted.
+func (s *service) Delete(ctx context.Context, uid string) {
+	s.publish(ctx, &Event{Operation: OperationDelete, OrderUID: uid})
+}
+
+// publish sends an event of this instance to the bus.
+func (s *service) publish(ctx context.Context, event *Event) {
+	if s.bus == nil {
+		return
+	}
+	event.Instance = s.instance
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Error("can't encode invalidation event", zap.String("order_uid", event.OrderUID), zap.Error(err))
+		return
+	}
+
+	err = s.bus.Publish(ctx, data)
+	if err != nil {
+		s.logger.Error("can't publish invalidation event", zap.String("order_uid", event.OrderUID), zap.Error(err))
+	}
+}
+
+// Listen applies the events of the other instances to the cache until the context is canceled.
+func (s *service) Listen(ctx context.Context) error {
+	if s.bus == nil {
+		return nil
+	}
+
+	err := s.bus.Subscribe(ctx, func(data []byte) {
+		var event Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			s.logger.Warn("can't decode invalidation event", zap.Error(err))
+			return
+		}
+		s.apply(ctx, &event)
+	})
+	if err != nil {
+		return fmt.Errorf("can't subscribe to invalidation events: %w", err)
+	}
+
+	return nil
+}
+
+// apply updates the cache with an event of another instance.
+// A changed order is read from the repository, unless the cached one is at least as recent.
+// If it can't be read, it is removed from the cache, so that it is read on the next request.
+func (s *service) apply(ctx context.Context, event *Event) {
+	if event.Instance == s.instance {
+		return
+	}
+
+	switch event.Operation {
+	case OperationDelete:
+		s.cache.Delete(event.OrderUID)
+	case OperationSet:
+		if cached, ok := s.cache.Get(event.OrderUID); ok && event.Version > 0 && cached.Version >= event.Version {
+			return
+		}
+
+		order, err := s.orderRepository.GetByUid(ctx, event.OrderUID)
+		if err != nil {
+			s.logger.Warn("can't get changed order, evicting it from cache", zap.String("order_uid", event.OrderUID), zap.Error(err))
+			s.cache.Delete(event.OrderUID)
+			return
+		}
+		if order == nil {
+			s.cache.Delete(event.OrderUID)
+			return
+		}
+		s.cache.Set(event.OrderUID, order)
+	default:
+		s.logger.Warn("unknown invalidation operation", zap.String("operation", string(event.Operation)))
+		return
+	}
+
+	s.logger.Debug("applied invalidation event",
+		zap.String("instance", event.Instance),
+		zap.String("operation", string(event.Operation)),
+		zap.String("order_uid", event.OrderUID),
+	)
+}
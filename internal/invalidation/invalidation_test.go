@@ -0,0 +1,146 @@
+package invalidation
+
+import (
+	"L0/internal/cache"
+	"L0/internal/entity"
+	"L0/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestService_Set(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	bus := NewMockBus(ctrl)
+	s := NewService(bus, "instance_1", cache.NewMockOrderCache(ctrl), repository.NewMockOrderRepository(ctrl), zap.NewNop())
+
+	bus.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, data []byte) error {
+		var event Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			t.Errorf("can't decode event: %v", err)
+		}
+		want := Event{Instance: "instance_1", Operation: OperationSet, OrderUID: "b563feb7b2b84b6test", Version: 3}
+		if event != want {
+			t.Errorf("published event = %+v, want %+v", event, want)
+		}
+		return errors.New("bus error")
+	})
+
+	// A failed publication is logged only
+	s.Set(context.Background(), &entity.Order{OrderUID: "b563feb7b2b84b6test", Version: 3})
+}
+
+func TestService_Disabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	s := NewService(nil, "instance_1", cache.NewMockOrderCache(ctrl), repository.NewMockOrderRepository(ctrl), zap.NewNop())
+
+	s.Set(context.Background(), &entity.Order{OrderUID: "b563feb7b2b84b6test"})
+	s.Delete(context.Background(), "b563feb7b2b84b6test")
+	if err := s.Listen(context.Background()); err != nil {
+		t.Errorf("service.Listen() error = %v", err)
+	}
+}
+
+func TestService_Listen(t *testing.T) {
+	type fields struct {
+		cache           *cache.MockOrderCache
+		orderRepository *repository.MockOrderRepository
+	}
+	const uid = "b563feb7b2b84b6test"
+	tests := []struct {
+		name  string
+		event string
+		setup func(f fields)
+	}{
+		{
+			name:  "delete",
+			event: `{"instance":"instance_2","operation":"delete","order_uid":"b563feb7b2b84b6test"}`,
+			setup: func(f fields) {
+				f.cache.EXPECT().Delete(uid)
+			},
+		},
+		{
+			name:  "set: refreshes outdated order",
+			event: `{"instance":"instance_2","operation":"set","order_uid":"b563feb7b2b84b6test","version":3}`,
+			setup: func(f fields) {
+				f.cache.EXPECT().Get(uid).Return(&entity.Order{OrderUID: uid, Version: 2}, true)
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), uid).Return(&entity.Order{OrderUID: uid, Version: 3}, nil)
+				f.cache.EXPECT().Set(uid, &entity.Order{OrderUID: uid, Version: 3})
+			},
+		},
+		{
+			name:  "set: loads uncached order",
+			event: `{"instance":"instance_2","operation":"set","order_uid":"b563feb7b2b84b6test","version":1}`,
+			setup: func(f fields) {
+				f.cache.EXPECT().Get(uid).Return(nil, false)
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), uid).Return(&entity.Order{OrderUID: uid, Version: 1}, nil)
+				f.cache.EXPECT().Set(uid, &entity.Order{OrderUID: uid, Version: 1})
+			},
+		},
+		{
+			name:  "set: keeps recent order",
+			event: `{"instance":"instance_2","operation":"set","order_uid":"b563feb7b2b84b6test","version":3}`,
+			setup: func(f fields) {
+				f.cache.EXPECT().Get(uid).Return(&entity.Order{OrderUID: uid, Version: 3}, true)
+			},
+		},
+		{
+			name:  "set: evicts order deleted meanwhile",
+			event: `{"instance":"instance_2","operation":"set","order_uid":"b563feb7b2b84b6test","version":3}`,
+			setup: func(f fields) {
+				f.cache.EXPECT().Get(uid).Return(nil, false)
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), uid).Return(nil, nil)
+				f.cache.EXPECT().Delete(uid)
+			},
+		},
+		{
+			name:  "set: evicts order that can't be read",
+			event: `{"instance":"instance_2","operation":"set","order_uid":"b563feb7b2b84b6test","version":3}`,
+			setup: func(f fields) {
+				f.cache.EXPECT().Get(uid).Return(nil, false)
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), uid).Return(nil, errors.New("db error"))
+				f.cache.EXPECT().Delete(uid)
+			},
+		},
+		{
+			name:  "ignores own event",
+			event: `{"instance":"instance_1","operation":"delete","order_uid":"b563feb7b2b84b6test"}`,
+			setup: func(f fields) {},
+		},
+		{
+			name:  "ignores malformed event",
+			event: `{"instance":`,
+			setup: func(f fields) {},
+		},
+		{
+			name:  "ignores unknown operation",
+			event: `{"instance":"instance_2","operation":"purge","order_uid":"b563feb7b2b84b6test"}`,
+			setup: func(f fields) {},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			f := fields{
+				cache:           cache.NewMockOrderCache(ctrl),
+				orderRepository: repository.NewMockOrderRepository(ctrl),
+			}
+			bus := NewMockBus(ctrl)
+			s := NewService(bus, "instance_1", f.cache, f.orderRepository, zap.NewNop())
+
+			tt.setup(f)
+			bus.EXPECT().Subscribe(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, handle func(data []byte)) error {
+				handle([]byte(tt.event))
+				return nil
+			})
+
+			if err := s.Listen(context.Background()); err != nil {
+				t.Errorf("service.Listen() error = %v", err)
+			}
+		})
+	}
+}
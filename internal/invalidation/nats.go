@@ -0,0 +1,53 @@
+package invalidation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/stan.go"
+)
+
+// natsBus carries events over a NATS Streaming subject.
+type natsBus struct {
+	connect stan.Conn
+	subject string
+}
+
+// NewNatsBus creates a new instance of natsBus.
+// Every instance needs its own connection, since NATS Streaming requires unique client IDs.
+func NewNatsBus(connect stan.Conn, subject string) *natsBus {
+	return &natsBus{
+		connect: connect,
+		subject: subject,
+	}
+}
+
+// Publish publishes an event to the subject.
+func (b *natsBus) Publish(_ context.Context, data []byte) error {
+	err := b.connect.Publish(b.subject, data)
+	if err != nil {
+		return fmt.Errorf("can't publish to NATS: %w", err)
+	}
+
+	return nil
+}
+
+// Subscribe subscribes to the subject and passes the events published from now on to handle.
+// The subscription is not durable, since events missed while an instance is down are stale once it starts.
+func (b *natsBus) Subscribe(ctx context.Context, handle func(data []byte)) error {
+	sub, err := b.connect.Subscribe(b.subject, func(msg *stan.Msg) {
+		handle(msg.Data)
+	})
+	if err != nil {
+		return fmt.Errorf("can't subscribe to NATS: %w", err)
+	}
+
+	<-ctx.Done()
+
+	err = sub.Unsubscribe()
+	if err != nil {
+		return fmt.Errorf("can't unsubscribe from NATS: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package invalidation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/nats-io/stan.go"
+
+	"L0/internal/nats"
+)
+
+func TestNatsBus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	connect := nats.NewMockConn(ctrl)
+	sub := nats.NewMockSubscription(ctrl)
+	bus := NewNatsBus(connect, "cache-invalidation")
+
+	connect.EXPECT().Publish("cache-invalidation", []byte("event")).Return(errors.New("nats error"))
+	if err := bus.Publish(context.Background(), []byte("event")); err == nil {
+		t.Errorf("natsBus.Publish() error = nil, want an error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	connect.EXPECT().Subscribe("cache-invalidation", gomock.Any()).DoAndReturn(
+		func(subject string, cb stan.MsgHandler, opts ...stan.SubscriptionOption) (stan.Subscription, error) {
+			cb(&stan.Msg{})
+			return sub, nil
+		},
+	)
+	sub.EXPECT().Unsubscribe().Return(nil)
+
+	var received int
+	err := bus.Subscribe(ctx, func(data []byte) {
+		received++
+		cancel()
+	})
+	if err != nil {
+		t.Errorf("natsBus.Subscribe() error = %v", err)
+	}
+	if received != 1 {
+		t.Errorf("natsBus.Subscribe() received %d events, want 1", received)
+	}
+}
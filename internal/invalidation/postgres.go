@@ -0,0 +1,88 @@
+package invalidation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// Reconnection intervals and the interval at which the connection of a listener is checked.
+const (
+	listenerMinReconnectInterval = time.Second
+	listenerMaxReconnectInterval = time.Minute
+	listenerPingInterval         = 90 * time.Second
+)
+
+// postgresBus carries events over a PostgreSQL LISTEN/NOTIFY channel.
+type postgresBus struct {
+	db      *sqlx.DB
+	dsn     string
+	channel string
+	logger  *zap.Logger
+}
+
+// NewPostgresBus creates a new instance of postgresBus.
+// Events are published with the database connection and received with a dedicated connection to dsn.
+func NewPostgresBus(db *sqlx.DB, dsn string, channel string, logger *zap.Logger) *postgresBus {
+	return &postgresBus{
+		db:      db,
+		dsn:     dsn,
+		channel: channel,
+		logger:  logger,
+	}
+}
+
+// Publish notifies the listeners of the channel.
+func (b *postgresBus) Publish(ctx context.Context, data []byte) error {
+	_, err := b.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, b.channel, string(data))
+	if err != nil {
+		return fmt.Errorf("can't notify channel: %w", err)
+	}
+
+	return nil
+}
+
+// Subscribe listens to the channel and passes the notifications to handle until the context is canceled.
+// The listener reconnects by itself, notifications sent while it is disconnected are lost.
+func (b *postgresBus) Subscribe(ctx context.Context, handle func(data []byte)) error {
+	listener := pq.NewListener(b.dsn, listenerMinReconnectInterval, listenerMaxReconnectInterval,
+		func(event pq.ListenerEventType, err error) {
+			switch event {
+			case pq.ListenerEventDisconnected:
+				b.logger.Warn("invalidation listener disconnected", zap.Error(err))
+			case pq.ListenerEventReconnected:
+				b.logger.Warn("invalidation listener reconnected, events sent meanwhile are lost")
+			case pq.ListenerEventConnectionAttemptFailed:
+				b.logger.Warn("invalidation listener can't connect", zap.Error(err))
+			}
+		},
+	)
+	defer listener.Close()
+
+	err := listener.Listen(b.channel)
+	if err != nil {
+		return fmt.Errorf("can't listen to channel: %w", err)
+	}
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n := <-listener.Notify:
+			// A nil notification follows a reconnection
+			if n != nil {
+				handle([]byte(n.Extra))
+			}
+		case <-ticker.C:
+			// A broken connection is reported by the listener callback and reconnected by the listener itself
+			_ = listener.Ping()
+		}
+	}
+}
@@ -2,7 +2,10 @@
 
 package nats
 
-import "context"
+import (
+	"L0/internal/entity"
+	"context"
+)
 
 //go:generate mockgen -source=interfaces.go -destination=nats_mock.go -package=nats
 
@@ -20,4 +23,25 @@ type NATSService interface {
 	// It takes a NATS cluster ID, NATS client ID, NATS subject,
 	// and NATS URL and returns an error.
 	Publish(data []byte) error
+
+	// PublishTo publishes a message to the given NATS subject.
+	PublishTo(subject string, data []byte) error
+
+	// SubscribeStatus subscribes to the subject of order status events and applies them with changer.
+	// The subscription uses the settings of the orders subscription with its own durable name.
+	SubscribeStatus(ctx context.Context, subject string, durableName string, changer StatusChanger) error
+}
+
+// StatusChanger applies status updates to orders.
+type StatusChanger interface {
+	// ChangeStatus moves an order to another status.
+	// It returns the changed order or an error if the update is invalid or can't be applied.
+	ChangeStatus(ctx context.Context, update entity.StatusUpdate) (*entity.Order, error)
+}
+
+// OrderSaver stores the orders received from NATS.
+type OrderSaver interface {
+	// Save validates and idempotently stores an order, then refreshes its cache entry.
+	// It returns the outcome of the operation or an error if the order can't be stored.
+	Save(ctx context.Context, order *entity.Order, policy entity.UpdatePolicy) (entity.SaveOutcome, error)
 }
@@ -4,67 +4,255 @@ package nats
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/nats-io/stan.go"
+	"go.uber.org/zap"
 
-	"L0/internal/cache"
 	"L0/internal/entity"
 	"L0/internal/repository"
+	"L0/internal/validator"
 )
 
+// ErrInvalidMessage is returned when a message can never be processed, so redelivering it is pointless.
+var ErrInvalidMessage = errors.New("invalid message")
+
 // natsService represents a service for handling NATS messaging.
 type natsService struct {
-	orderRepository repository.OrderRepository
-	cache           cache.Cache
-	connect         stan.Conn
-	subject         string
+	orders              OrderSaver
+	rejectedRepository  repository.RejectedMessageRepository
+	ingestionRepository repository.IngestionRepository
+	validator           validator.OrderValidator
+	connect             stan.Conn
+	subject             string
+	subConfig           SubscriptionConfig
+	logger              *zap.Logger
 }
 
 // NewNatsService creates a new instance of natsService.
-func NewNatsService(orderRepository repository.OrderRepository, cache cache.Cache, connect stan.Conn, subject string) *natsService {
+func NewNatsService(
+	orders OrderSaver,
+	rejectedRepository repository.RejectedMessageRepository,
+	ingestionRepository repository.IngestionRepository,
+	validator validator.OrderValidator,
+	connect stan.Conn,
+	subject string,
+	subConfig SubscriptionConfig,
+	logger *zap.Logger,
+) *natsService {
 	return &natsService{
-		orderRepository: orderRepository,
-		cache:           cache,
-		connect:         connect,
-		subject:         subject,
+		orders:              orders,
+		rejectedRepository:  rejectedRepository,
+		ingestionRepository: ingestionRepository,
+		validator:           validator,
+		connect:             connect,
+		subject:             subject,
+		subConfig:           subConfig,
+		logger:              logger,
 	}
 }
 
 // Subscribe subscribes to a NATS subject and processes incoming messages.
+// The subscription is durable and uses manual acks, so messages that were not
+// processed before a restart or a failure are redelivered by the server.
 func (ns *natsService) Subscribe(ctx context.Context) error {
-	sub, err := ns.connect.Subscribe(ns.subject, ns.process)
+	opts, err := ns.subConfig.options()
+	if err != nil {
+		return fmt.Errorf("invalid subscription config: %w", err)
+	}
+
+	sub, err := ns.connect.Subscribe(ns.subject, ns.process, opts...)
 	if err != nil {
 		return fmt.Errorf("can't subscribe to NATS: %w", err)
 	}
 
 	<-ctx.Done()
 
-	sub.Unsubscribe()
+	// Close keeps the durable interest on the server, unlike Unsubscribe
+	err = sub.Close()
+	if err != nil {
+		return fmt.Errorf("can't close subscription: %w", err)
+	}
 
 	return nil
 }
 
 // process handles incoming NATS messages.
+// A message is acknowledged only after the order is stored in the database and the cache,
+// or after it is rejected to the dead-letter subject and the quarantine store.
 func (ns *natsService) process(msg *stan.Msg) {
-	var order entity.Order
-	if err := json.Unmarshal(msg.Data, &order); err != nil {
+	if ns.subConfig.Watermark != nil {
+		ns.subConfig.Watermark.Received(msg.Sequence)
+	}
+
+	var handleErr error
+	handled := ns.consume(msg, ns.subject, func(ctx context.Context, data []byte) (string, error) {
+		outcome, err := ns.handle(ctx, data, msg.Sequence)
+		handleErr = err
+		return string(outcome), err
+	})
+	if !handled {
 		return
 	}
 
-	id, err := ns.orderRepository.Create(context.Background(), &order)
+	// A message handled with an error has been rejected
+	if handleErr != nil {
+		ns.trackRejection(context.Background(), msg.Data, msg.Sequence, handleErr)
+	}
+	if ns.subConfig.Watermark != nil {
+		ns.subConfig.Watermark.Handled(msg.Sequence)
+	}
+}
+
+// consume handles a message of the subject and acknowledges it once it is handled or rejected.
+// A message that can't be handled yet is left unacknowledged, so the server redelivers it.
+// It reports whether the message has been handled or rejected.
+func (ns *natsService) consume(msg *stan.Msg, subject string, handle func(ctx context.Context, data []byte) (string, error)) bool {
+	ctx := context.Background()
+
+	outcome, err := handle(ctx, msg.Data)
+	if err != nil {
+		category, rejected := ns.rejectCategory(err, msg.RedeliveryCount)
+		if !rejected {
+			ns.logger.Warn("can't process message, waiting for redelivery",
+				zap.Uint64("sequence", msg.Sequence),
+				zap.Uint32("redelivery_count", msg.RedeliveryCount),
+				zap.Error(err),
+			)
+			return false
+		}
+
+		ns.logger.Error("rejecting message",
+			zap.String("subject", subject),
+			zap.Uint64("sequence", msg.Sequence),
+			zap.String("category", category),
+			zap.Error(err),
+		)
+		if err := ns.reject(ctx, subject, msg.Sequence, msg.Data, category, err); err != nil {
+			ns.logger.Error("can't reject message, waiting for redelivery", zap.Uint64("sequence", msg.Sequence), zap.Error(err))
+			return false
+		}
+	} else {
+		ns.logger.Debug("message processed", zap.Uint64("sequence", msg.Sequence), zap.String("outcome", outcome))
+	}
+
+	if err := msg.Ack(); err != nil {
+		ns.logger.Error("can't ack message", zap.Uint64("sequence", msg.Sequence), zap.Error(err))
+	}
+
+	return true
+}
+
+// rejectCategory decides whether a processing error rejects the message and returns its category.
+// Invalid messages are rejected at once, other failures only after MaxRedeliveries attempts.
+func (ns *natsService) rejectCategory(err error, redeliveryCount uint32) (string, bool) {
+	if errors.Is(err, entity.ErrValidation) {
+		return entity.RejectCategoryValidation, true
+	}
+	if errors.Is(err, entity.ErrConflict) {
+		return entity.RejectCategoryConflict, true
+	}
+	if errors.Is(err, ErrInvalidMessage) {
+		return entity.RejectCategoryDecode, true
+	}
+	if ns.subConfig.MaxRedeliveries > 0 && int(redeliveryCount) >= ns.subConfig.MaxRedeliveries {
+		return entity.RejectCategoryPersist, true
+	}
+	return "", false
+}
+
+// reject republishes the raw payload to the dead-letter subject and stores it in the quarantine store
+// along with the subject it was received from.
+func (ns *natsService) reject(ctx context.Context, subject string, sequence uint64, data []byte, category string, cause error) error {
+	if ns.subConfig.DeadLetterSubject != "" {
+		err := ns.connect.Publish(ns.subConfig.DeadLetterSubject, data)
+		if err != nil {
+			return fmt.Errorf("can't publish to dead-letter subject: %w", err)
+		}
+	}
+
+	_, err := ns.rejectedRepository.Create(ctx, &entity.RejectedMessage{
+		Subject:    subject,
+		Sequence:   sequence,
+		Data:       data,
+		Category:   category,
+		Error:      cause.Error(),
+		RejectedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("can't store rejected message: %w", err)
+	}
+
+	return nil
+}
+
+// handle decodes an order from the message payload with the sequence, validates and stores it
+// the same way as an order submitted over HTTP, which also refreshes the cache.
+// Storing is idempotent, so a redelivered message is reported as unchanged.
+func (ns *natsService) handle(ctx context.Context, data []byte, sequence uint64) (entity.SaveOutcome, error) {
+	var order entity.Order
+	if err := json.Unmarshal(data, &order); err != nil {
+		return "", fmt.Errorf("%w: can't unmarshal order: %v", ErrInvalidMessage, err)
+	}
+
+	if err := ns.validator.Validate(&order); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidMessage, err)
+	}
+	ns.track(ctx, order.OrderUID, entity.IngestionValidated, "", sequence)
+
+	outcome, err := ns.orders.Save(ctx, &order, ns.subConfig.UpdatePolicy)
 	if err != nil {
+		return "", fmt.Errorf("can't save order: %w", err)
+	}
+	ns.track(ctx, order.OrderUID, entity.IngestionPersisted, "", sequence)
+
+	return outcome, nil
+}
+
+// trackRejection records the rejection of the order carried by a message.
+// A payload without an order UID can't be tracked.
+func (ns *natsService) trackRejection(ctx context.Context, data []byte, sequence uint64, cause error) {
+	var order struct {
+		OrderUID string `json:"order_uid"`
+	}
+	if err := json.Unmarshal(data, &order); err != nil || order.OrderUID == "" {
 		return
 	}
 
-	ns.cache.Set(id, &order)
+	ns.track(ctx, order.OrderUID, entity.IngestionRejected, cause.Error(), sequence)
+}
+
+// track records the ingestion state of an order.
+// A failure is only logged, since tracking must not hold the processing of orders back.
+func (ns *natsService) track(ctx context.Context, uid string, state entity.IngestionState, reason string, sequence uint64) {
+	err := ns.ingestionRepository.Save(ctx, &entity.Ingestion{
+		OrderUID:  uid,
+		State:     state,
+		Reason:    reason,
+		Sequence:  sequence,
+		UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		ns.logger.Warn("can't record order ingestion",
+			zap.String("order_uid", uid),
+			zap.String("state", string(state)),
+			zap.Error(err),
+		)
+	}
 }
 
 // Publish publishes a message to a NATS subject.
 func (ns *natsService) Publish(data []byte) error {
-	err := ns.connect.Publish(ns.subject, data)
+	return ns.PublishTo(ns.subject, data)
+}
+
+// PublishTo publishes a message to the given NATS subject.
+func (ns *natsService) PublishTo(subject string, data []byte) error {
+	err := ns.connect.Publish(subject, data)
 	if err != nil {
-		return fmt.Errorf("can't publish message: %w", err)
+		return fmt.Errorf("%w: can't publish message: %w", entity.ErrUnavailable, err)
 	}
 
 	return nil
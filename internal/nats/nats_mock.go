@@ -5,6 +5,7 @@
 package nats
 
 import (
+	entity "L0/internal/entity"
 	context "context"
 	reflect "reflect"
 
@@ -48,6 +49,20 @@ func (mr *MockNATSServiceMockRecorder) Publish(data interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockNATSService)(nil).Publish), data)
 }
 
+// PublishTo mocks base method.
+func (m *MockNATSService) PublishTo(subject string, data []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishTo", subject, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishTo indicates an expected call of PublishTo.
+func (mr *MockNATSServiceMockRecorder) PublishTo(subject, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishTo", reflect.TypeOf((*MockNATSService)(nil).PublishTo), subject, data)
+}
+
 // Subscribe mocks base method.
 func (m *MockNATSService) Subscribe(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -61,3 +76,93 @@ func (mr *MockNATSServiceMockRecorder) Subscribe(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockNATSService)(nil).Subscribe), ctx)
 }
+
+// SubscribeStatus mocks base method.
+func (m *MockNATSService) SubscribeStatus(ctx context.Context, subject, durableName string, changer StatusChanger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeStatus", ctx, subject, durableName, changer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SubscribeStatus indicates an expected call of SubscribeStatus.
+func (mr *MockNATSServiceMockRecorder) SubscribeStatus(ctx, subject, durableName, changer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeStatus", reflect.TypeOf((*MockNATSService)(nil).SubscribeStatus), ctx, subject, durableName, changer)
+}
+
+// MockStatusChanger is a mock of StatusChanger interface.
+type MockStatusChanger struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatusChangerMockRecorder
+}
+
+// MockStatusChangerMockRecorder is the mock recorder for MockStatusChanger.
+type MockStatusChangerMockRecorder struct {
+	mock *MockStatusChanger
+}
+
+// NewMockStatusChanger creates a new mock instance.
+func NewMockStatusChanger(ctrl *gomock.Controller) *MockStatusChanger {
+	mock := &MockStatusChanger{ctrl: ctrl}
+	mock.recorder = &MockStatusChangerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatusChanger) EXPECT() *MockStatusChangerMockRecorder {
+	return m.recorder
+}
+
+// ChangeStatus mocks base method.
+func (m *MockStatusChanger) ChangeStatus(ctx context.Context, update entity.StatusUpdate) (*entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeStatus", ctx, update)
+	ret0, _ := ret[0].(*entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeStatus indicates an expected call of ChangeStatus.
+func (mr *MockStatusChangerMockRecorder) ChangeStatus(ctx, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeStatus", reflect.TypeOf((*MockStatusChanger)(nil).ChangeStatus), ctx, update)
+}
+
+// MockOrderSaver is a mock of OrderSaver interface.
+type MockOrderSaver struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderSaverMockRecorder
+}
+
+// MockOrderSaverMockRecorder is the mock recorder for MockOrderSaver.
+type MockOrderSaverMockRecorder struct {
+	mock *MockOrderSaver
+}
+
+// NewMockOrderSaver creates a new mock instance.
+func NewMockOrderSaver(ctrl *gomock.Controller) *MockOrderSaver {
+	mock := &MockOrderSaver{ctrl: ctrl}
+	mock.recorder = &MockOrderSaverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderSaver) EXPECT() *MockOrderSaverMockRecorder {
+	return m.recorder
+}
+
+// Save mocks base method.
+func (m *MockOrderSaver) Save(ctx context.Context, order *entity.Order, policy entity.UpdatePolicy) (entity.SaveOutcome, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, order, policy)
+	ret0, _ := ret[0].(entity.SaveOutcome)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockOrderSaverMockRecorder) Save(ctx, order, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockOrderSaver)(nil).Save), ctx, order, policy)
+}
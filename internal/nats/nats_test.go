@@ -2,23 +2,26 @@ package nats
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
-	"L0/internal/cache"
+	"L0/internal/entity"
 	"L0/internal/repository"
+	"L0/internal/validator"
 
 	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
 )
 
 func TestNatsService_Subscribe(t *testing.T) {
 	type fields struct {
-		orderRepository repository.OrderRepository
-		cache           cache.Cache
-		connect         *MockConn
-		subject         string
-		subscription    *MockSubscription
+		orders       *MockOrderSaver
+		connect      *MockConn
+		subject      string
+		subConfig    SubscriptionConfig
+		subscription *MockSubscription
 	}
 	tests := []struct {
 		name    string
@@ -28,15 +31,15 @@ func TestNatsService_Subscribe(t *testing.T) {
 		{
 			name: "success",
 			setup: func(f fields) {
-				f.connect.EXPECT().Subscribe(f.subject, gomock.Any()).Return(f.subscription, nil)
-				f.subscription.EXPECT().Unsubscribe().Return(nil)
+				f.connect.EXPECT().Subscribe(f.subject, gomock.Any(), gomock.Any()).Return(f.subscription, nil)
+				f.subscription.EXPECT().Close().Return(nil)
 			},
 			wantErr: false,
 		},
 		{
 			name: "fail: can't subscribe",
 			setup: func(f fields) {
-				f.connect.EXPECT().Subscribe(f.subject, gomock.Any()).Return(nil, fmt.Errorf("subscribe error"))
+				f.connect.EXPECT().Subscribe(f.subject, gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("subscribe error"))
 			},
 			wantErr: true,
 		},
@@ -46,13 +49,13 @@ func TestNatsService_Subscribe(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			f := fields{
-				orderRepository: repository.NewMockOrderRepository(ctrl),
-				cache:           cache.NewCache(),
-				connect:         NewMockConn(ctrl),
-				subject:         "test",
-				subscription:    NewMockSubscription(ctrl),
+				orders:       NewMockOrderSaver(ctrl),
+				connect:      NewMockConn(ctrl),
+				subject:      "test",
+				subConfig:    SubscriptionConfig{DurableName: "durable", AckWait: time.Second, MaxInflight: 1, StartPosition: "all"},
+				subscription: NewMockSubscription(ctrl),
 			}
-			service := NewNatsService(f.orderRepository, f.cache, f.connect, f.subject)
+			service := NewNatsService(f.orders, repository.NewMockRejectedMessageRepository(ctrl), repository.NewMockIngestionRepository(ctrl), validator.NewMockOrderValidator(ctrl), f.connect, f.subject, f.subConfig, zap.NewNop())
 			tt.setup(f)
 
 			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
@@ -67,10 +70,9 @@ func TestNatsService_Subscribe(t *testing.T) {
 
 func TestNatsService_Publish(t *testing.T) {
 	type fields struct {
-		orderRepository repository.OrderRepository
-		cache           cache.Cache
-		connect         *MockConn
-		subject         string
+		orders  *MockOrderSaver
+		connect *MockConn
+		subject string
 	}
 	tests := []struct {
 		name    string
@@ -97,12 +99,11 @@ func TestNatsService_Publish(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			f := fields{
-				orderRepository: repository.NewMockOrderRepository(ctrl),
-				cache:           cache.NewCache(),
-				connect:         NewMockConn(ctrl),
-				subject:         "test",
+				orders:  NewMockOrderSaver(ctrl),
+				connect: NewMockConn(ctrl),
+				subject: "test",
 			}
-			service := NewNatsService(f.orderRepository, f.cache, f.connect, f.subject)
+			service := NewNatsService(f.orders, repository.NewMockRejectedMessageRepository(ctrl), repository.NewMockIngestionRepository(ctrl), validator.NewMockOrderValidator(ctrl), f.connect, f.subject, SubscriptionConfig{}, zap.NewNop())
 
 			tt.setup(f)
 
@@ -113,3 +114,296 @@ func TestNatsService_Publish(t *testing.T) {
 		})
 	}
 }
+
+func TestNatsService_Subscribe_InvalidStartPosition(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := NewNatsService(
+		NewMockOrderSaver(ctrl),
+		repository.NewMockRejectedMessageRepository(ctrl),
+		repository.NewMockIngestionRepository(ctrl),
+		validator.NewMockOrderValidator(ctrl),
+		NewMockConn(ctrl),
+		"test",
+		SubscriptionConfig{StartPosition: "yesterday"},
+		zap.NewNop(),
+	)
+
+	err := service.Subscribe(context.Background())
+	if err == nil {
+		t.Errorf("Subscribe() error = nil, want error")
+	}
+}
+
+func TestNatsService_handle(t *testing.T) {
+	type fields struct {
+		orders              *MockOrderSaver
+		ingestionRepository *repository.MockIngestionRepository
+		validator           *validator.MockOrderValidator
+	}
+	tests := []struct {
+		name           string
+		data           []byte
+		setup          func(f fields)
+		wantErr        bool
+		wantInvalid    bool
+		wantValidation bool
+		wantOutcome    entity.SaveOutcome
+	}{
+		{
+			name: "success",
+			data: []byte(`{"order_uid":"b563feb7b2b84b6test"}`),
+			setup: func(f fields) {
+				f.validator.EXPECT().Validate(gomock.Any()).Return(nil)
+				expectIngestion(f.ingestionRepository, entity.IngestionValidated, entity.IngestionPersisted)
+				f.orders.EXPECT().Save(gomock.Any(), gomock.Any(), entity.UpdatePolicyUpdate).Return(entity.SaveCreated, nil)
+			},
+			wantOutcome: entity.SaveCreated,
+			wantErr:     false,
+		},
+		{
+			name: "success: redelivered order",
+			data: []byte(`{"order_uid":"b563feb7b2b84b6test"}`),
+			setup: func(f fields) {
+				f.validator.EXPECT().Validate(gomock.Any()).Return(nil)
+				expectIngestion(f.ingestionRepository, entity.IngestionValidated, entity.IngestionPersisted)
+				f.orders.EXPECT().Save(gomock.Any(), gomock.Any(), entity.UpdatePolicyUpdate).Return(entity.SaveUnchanged, nil)
+			},
+			wantOutcome: entity.SaveUnchanged,
+			wantErr:     false,
+		},
+		{
+			name:        "fail: invalid json",
+			data:        []byte(`{"order_uid":`),
+			setup:       func(f fields) {},
+			wantErr:     true,
+			wantInvalid: true,
+		},
+		{
+			name: "fail: invalid order",
+			data: []byte(`{"order_uid":"b563feb7b2b84b6test"}`),
+			setup: func(f fields) {
+				f.validator.EXPECT().Validate(gomock.Any()).Return(&validator.ValidationError{
+					Fields: []validator.FieldError{{Path: "track_number", Rule: validator.RuleRequired, Message: "must not be empty"}},
+				})
+			},
+			wantErr:        true,
+			wantInvalid:    true,
+			wantValidation: true,
+		},
+		{
+			name: "fail: can't create order",
+			data: []byte(`{"order_uid":"b563feb7b2b84b6test"}`),
+			setup: func(f fields) {
+				f.validator.EXPECT().Validate(gomock.Any()).Return(nil)
+				expectIngestion(f.ingestionRepository, entity.IngestionValidated)
+				f.orders.EXPECT().Save(gomock.Any(), gomock.Any(), entity.UpdatePolicyUpdate).Return(entity.SaveOutcome(""), fmt.Errorf("db is down"))
+			},
+			wantErr:     true,
+			wantInvalid: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				orders:              NewMockOrderSaver(ctrl),
+				ingestionRepository: repository.NewMockIngestionRepository(ctrl),
+				validator:           validator.NewMockOrderValidator(ctrl),
+			}
+			service := NewNatsService(f.orders, repository.NewMockRejectedMessageRepository(ctrl), f.ingestionRepository, f.validator, NewMockConn(ctrl), "test", SubscriptionConfig{UpdatePolicy: entity.UpdatePolicyUpdate}, zap.NewNop())
+
+			tt.setup(f)
+
+			outcome, err := service.handle(context.Background(), tt.data, 42)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("handle() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if outcome != tt.wantOutcome {
+				t.Errorf("handle() outcome = %v, wantOutcome %v", outcome, tt.wantOutcome)
+			}
+			if errors.Is(err, entity.ErrValidation) != tt.wantValidation {
+				t.Errorf("handle() validation = %v, wantValidation %v", errors.Is(err, entity.ErrValidation), tt.wantValidation)
+			}
+			if errors.Is(err, ErrInvalidMessage) != tt.wantInvalid {
+				t.Errorf("handle() invalid = %v, wantInvalid %v", errors.Is(err, ErrInvalidMessage), tt.wantInvalid)
+			}
+		})
+	}
+}
+
+func TestNatsService_trackRejection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ingestionRepository := repository.NewMockIngestionRepository(ctrl)
+	ingestionRepository.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, ingestion *entity.Ingestion) error {
+		if ingestion.OrderUID != "b563feb7b2b84b6test" || ingestion.State != entity.IngestionRejected || ingestion.Reason != "bad order" {
+			t.Errorf("trackRejection() recorded %+v", ingestion)
+		}
+		return fmt.Errorf("db error")
+	})
+	service := NewNatsService(
+		NewMockOrderSaver(ctrl),
+		repository.NewMockRejectedMessageRepository(ctrl),
+		ingestionRepository,
+		validator.NewMockOrderValidator(ctrl),
+		NewMockConn(ctrl),
+		"test",
+		SubscriptionConfig{},
+		zap.NewNop(),
+	)
+
+	// A failure to record is only logged
+	service.trackRejection(context.Background(), []byte(`{"order_uid":"b563feb7b2b84b6test"}`), 42, fmt.Errorf("bad order"))
+	// A payload without an order UID is not recorded
+	service.trackRejection(context.Background(), []byte(`{"order_uid":`), 43, fmt.Errorf("bad json"))
+}
+
+// expectIngestion expects the ingestion states of a message with sequence 42 to be recorded in order.
+func expectIngestion(r *repository.MockIngestionRepository, states ...entity.IngestionState) {
+	calls := make([]*gomock.Call, 0, len(states))
+	for _, state := range states {
+		calls = append(calls, r.EXPECT().Save(gomock.Any(), ingestionMatcher{state: state, sequence: 42}).Return(nil))
+	}
+	gomock.InOrder(calls...)
+}
+
+// ingestionMatcher matches an ingestion record by its state and sequence.
+type ingestionMatcher struct {
+	state    entity.IngestionState
+	sequence uint64
+}
+
+func (m ingestionMatcher) Matches(x interface{}) bool {
+	ingestion, ok := x.(*entity.Ingestion)
+	return ok && ingestion.State == m.state && ingestion.Sequence == m.sequence
+}
+
+func (m ingestionMatcher) String() string {
+	return fmt.Sprintf("ingestion %s at sequence %d", m.state, m.sequence)
+}
+
+func TestNatsService_rejectCategory(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		redeliveryCount uint32
+		wantCategory    string
+		wantRejected    bool
+	}{
+		{
+			name:         "invalid message",
+			err:          fmt.Errorf("%w: bad json", ErrInvalidMessage),
+			wantCategory: entity.RejectCategoryDecode,
+			wantRejected: true,
+		},
+		{
+			name:         "validation error",
+			err:          fmt.Errorf("%w: %w", ErrInvalidMessage, &validator.ValidationError{}),
+			wantCategory: entity.RejectCategoryValidation,
+			wantRejected: true,
+		},
+		{
+			name:         "conflict error",
+			err:          fmt.Errorf("can't save order: %w", entity.ErrConflict),
+			wantCategory: entity.RejectCategoryConflict,
+			wantRejected: true,
+		},
+		{
+			name:            "persist error before limit",
+			err:             fmt.Errorf("db is down"),
+			redeliveryCount: 2,
+			wantRejected:    false,
+		},
+		{
+			name:            "persist error after limit",
+			err:             fmt.Errorf("db is down"),
+			redeliveryCount: 3,
+			wantCategory:    entity.RejectCategoryPersist,
+			wantRejected:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &natsService{subConfig: SubscriptionConfig{MaxRedeliveries: 3}}
+
+			category, rejected := service.rejectCategory(tt.err, tt.redeliveryCount)
+			if rejected != tt.wantRejected || category != tt.wantCategory {
+				t.Errorf("rejectCategory() = %v, %v, want %v, %v", category, rejected, tt.wantCategory, tt.wantRejected)
+			}
+		})
+	}
+}
+
+func TestNatsService_reject(t *testing.T) {
+	type fields struct {
+		rejectedRepository *repository.MockRejectedMessageRepository
+		connect            *MockConn
+	}
+	tests := []struct {
+		name    string
+		setup   func(f fields)
+		wantErr bool
+	}{
+		{
+			name: "success",
+			setup: func(f fields) {
+				f.connect.EXPECT().Publish("test-dlq", []byte("data")).Return(nil)
+				f.rejectedRepository.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, msg *entity.RejectedMessage) (int64, error) {
+						if msg.Sequence != 42 || msg.Category != entity.RejectCategoryDecode || string(msg.Data) != "data" {
+							t.Errorf("unexpected rejected message: %+v", msg)
+						}
+						return 1, nil
+					},
+				)
+			},
+			wantErr: false,
+		},
+		{
+			name: "fail: can't publish to dead-letter subject",
+			setup: func(f fields) {
+				f.connect.EXPECT().Publish("test-dlq", []byte("data")).Return(fmt.Errorf("publish error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "fail: can't store rejected message",
+			setup: func(f fields) {
+				f.connect.EXPECT().Publish("test-dlq", []byte("data")).Return(nil)
+				f.rejectedRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(int64(0), fmt.Errorf("db error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				rejectedRepository: repository.NewMockRejectedMessageRepository(ctrl),
+				connect:            NewMockConn(ctrl),
+			}
+			service := NewNatsService(
+				NewMockOrderSaver(ctrl),
+				f.rejectedRepository,
+				repository.NewMockIngestionRepository(ctrl),
+				validator.NewMockOrderValidator(ctrl),
+				f.connect,
+				"test",
+				SubscriptionConfig{DeadLetterSubject: "test-dlq"},
+				zap.NewNop(),
+			)
+
+			tt.setup(f)
+
+			err := service.reject(context.Background(), "test", 42, []byte("data"), entity.RejectCategoryDecode, fmt.Errorf("bad json"))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("reject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
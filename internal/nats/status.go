@@ -0,0 +1,60 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nats-io/stan.go"
+
+	"L0/internal/entity"
+)
+
+// SubscribeStatus subscribes to the subject of order status events and applies them with changer.
+// Status events are processed with the same acknowledgement and rejection rules as orders.
+func (ns *natsService) SubscribeStatus(ctx context.Context, subject string, durableName string, changer StatusChanger) error {
+	subConfig := ns.subConfig
+	subConfig.DurableName = durableName
+	opts, err := subConfig.options()
+	if err != nil {
+		return fmt.Errorf("invalid subscription config: %w", err)
+	}
+
+	sub, err := ns.connect.Subscribe(subject, func(msg *stan.Msg) {
+		ns.consume(msg, subject, func(ctx context.Context, data []byte) (string, error) {
+			return handleStatus(ctx, changer, data)
+		})
+	}, opts...)
+	if err != nil {
+		return fmt.Errorf("can't subscribe to NATS: %w", err)
+	}
+
+	<-ctx.Done()
+
+	// Close keeps the durable interest on the server, unlike Unsubscribe
+	err = sub.Close()
+	if err != nil {
+		return fmt.Errorf("can't close subscription: %w", err)
+	}
+
+	return nil
+}
+
+// handleStatus decodes a status update from the message payload and applies it.
+// It returns the resulting status of the order.
+func handleStatus(ctx context.Context, changer StatusChanger, data []byte) (string, error) {
+	var update entity.StatusUpdate
+	if err := json.Unmarshal(data, &update); err != nil {
+		return "", fmt.Errorf("%w: can't unmarshal status update: %v", ErrInvalidMessage, err)
+	}
+	if update.OrderUID == "" {
+		return "", fmt.Errorf("%w: order_uid is required", ErrInvalidMessage)
+	}
+
+	order, err := changer.ChangeStatus(ctx, update)
+	if err != nil {
+		return "", fmt.Errorf("can't change order status: %w", err)
+	}
+
+	return string(order.Status), nil
+}
@@ -0,0 +1,136 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"L0/internal/entity"
+	"L0/internal/repository"
+	"L0/internal/validator"
+
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestNatsService_SubscribeStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	connect := NewMockConn(ctrl)
+	subscription := NewMockSubscription(ctrl)
+	connect.EXPECT().Subscribe("order-status", gomock.Any(), gomock.Any()).Return(subscription, nil)
+	subscription.EXPECT().Close().Return(nil)
+
+	service := NewNatsService(
+		NewMockOrderSaver(ctrl),
+		repository.NewMockRejectedMessageRepository(ctrl),
+		repository.NewMockIngestionRepository(ctrl),
+		validator.NewMockOrderValidator(ctrl),
+		connect,
+		"test",
+		SubscriptionConfig{DurableName: "orders-durable"},
+		zap.NewNop(),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := service.SubscribeStatus(ctx, "order-status", "order-status-durable", NewMockStatusChanger(ctrl))
+	if err != nil {
+		t.Errorf("SubscribeStatus() error = %v", err)
+	}
+}
+
+func Test_handleStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           []byte
+		setup          func(changer *MockStatusChanger)
+		want           string
+		wantErr        bool
+		wantInvalid    bool
+		wantValidation bool
+		wantConflict   bool
+	}{
+		{
+			name: "success",
+			data: []byte(`{"order_uid":"b563feb7b2b84b6test","status":"paid","actor":"billing"}`),
+			setup: func(changer *MockStatusChanger) {
+				changer.EXPECT().ChangeStatus(gomock.Any(), entity.StatusUpdate{
+					OrderUID: "b563feb7b2b84b6test",
+					Status:   entity.StatusPaid,
+					Actor:    "billing",
+				}).Return(&entity.Order{OrderUID: "b563feb7b2b84b6test", Status: entity.StatusPaid}, nil)
+			},
+			want: "paid",
+		},
+		{
+			name:        "fail: invalid json",
+			data:        []byte(`{"order_uid":`),
+			setup:       func(changer *MockStatusChanger) {},
+			wantErr:     true,
+			wantInvalid: true,
+		},
+		{
+			name:        "fail: missing order uid",
+			data:        []byte(`{"status":"paid","actor":"billing"}`),
+			setup:       func(changer *MockStatusChanger) {},
+			wantErr:     true,
+			wantInvalid: true,
+		},
+		{
+			name: "fail: unknown status",
+			data: []byte(`{"order_uid":"b563feb7b2b84b6test","status":"lost","actor":"billing"}`),
+			setup: func(changer *MockStatusChanger) {
+				changer.EXPECT().ChangeStatus(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("%w: unknown status", entity.ErrValidation))
+			},
+			wantErr:        true,
+			wantValidation: true,
+		},
+		{
+			name: "fail: transition not allowed",
+			data: []byte(`{"order_uid":"b563feb7b2b84b6test","status":"paid","actor":"billing"}`),
+			setup: func(changer *MockStatusChanger) {
+				changer.EXPECT().ChangeStatus(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("%w: not allowed", entity.ErrConflict))
+			},
+			wantErr:      true,
+			wantConflict: true,
+		},
+		{
+			name: "fail: order not stored yet",
+			data: []byte(`{"order_uid":"b563feb7b2b84b6test","status":"paid","actor":"billing"}`),
+			setup: func(changer *MockStatusChanger) {
+				changer.EXPECT().ChangeStatus(gomock.Any(), gomock.Any()).Return(nil, entity.ErrNotFound)
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			changer := NewMockStatusChanger(ctrl)
+
+			tt.setup(changer)
+
+			got, err := handleStatus(context.Background(), changer, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("handleStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("handleStatus() = %v, want %v", got, tt.want)
+			}
+			if errors.Is(err, ErrInvalidMessage) != tt.wantInvalid {
+				t.Errorf("handleStatus() invalid = %v, wantInvalid %v", errors.Is(err, ErrInvalidMessage), tt.wantInvalid)
+			}
+			if errors.Is(err, entity.ErrValidation) != tt.wantValidation {
+				t.Errorf("handleStatus() validation = %v, wantValidation %v", errors.Is(err, entity.ErrValidation), tt.wantValidation)
+			}
+			if errors.Is(err, entity.ErrConflict) != tt.wantConflict {
+				t.Errorf("handleStatus() conflict = %v, wantConflict %v", errors.Is(err, entity.ErrConflict), tt.wantConflict)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package nats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nats-io/stan.go"
+
+	"L0/internal/entity"
+)
+
+// SubscriptionConfig describes the durable subscription used to consume orders.
+type SubscriptionConfig struct {
+	// DurableName is the name under which the server remembers the last acknowledged message.
+	DurableName string
+	// AckWait is the time the server waits for an ack before redelivering a message.
+	AckWait time.Duration
+	// MaxInflight is the maximum number of unacknowledged messages delivered at once.
+	MaxInflight int
+	// StartPosition is applied only when the durable is created for the first time.
+	// Supported values: all, last, new, seq:<n>, since:<duration>.
+	StartPosition string
+	// DeadLetterSubject receives the raw payload of rejected messages. Empty disables republishing.
+	DeadLetterSubject string
+	// MaxRedeliveries is the number of redeliveries after which a message that still
+	// can't be stored is rejected. Zero means such messages are redelivered forever.
+	MaxRedeliveries int
+	// UpdatePolicy defines how a changed payload for an already stored order is handled.
+	UpdatePolicy entity.UpdatePolicy
+	// Watermark tracks the order messages received and handled or rejected, it may be nil.
+	// Status events are not tracked.
+	Watermark *Watermark
+}
+
+// options converts the configuration into NATS Streaming subscription options.
+// Manual ack mode is always enabled so that messages are acknowledged only after processing.
+func (sc SubscriptionConfig) options() ([]stan.SubscriptionOption, error) {
+	opts := []stan.SubscriptionOption{stan.SetManualAckMode()}
+
+	if sc.DurableName != "" {
+		opts = append(opts, stan.DurableName(sc.DurableName))
+	}
+	if sc.AckWait > 0 {
+		opts = append(opts, stan.AckWait(sc.AckWait))
+	}
+	if sc.MaxInflight > 0 {
+		opts = append(opts, stan.MaxInflight(sc.MaxInflight))
+	}
+
+	start, err := startPosition(sc.StartPosition)
+	if err != nil {
+		return nil, err
+	}
+	if start != nil {
+		opts = append(opts, start)
+	}
+
+	return opts, nil
+}
+
+// startPosition parses a start position string into a subscription option.
+// It returns nil for an empty value or "new", which is the server default.
+func startPosition(position string) (stan.SubscriptionOption, error) {
+	switch {
+	case position == "" || position == "new":
+		return nil, nil
+	case position == "all":
+		return stan.DeliverAllAvailable(), nil
+	case position == "last":
+		return stan.StartWithLastReceived(), nil
+	case strings.HasPrefix(position, "seq:"):
+		seq, err := strconv.ParseUint(strings.TrimPrefix(position, "seq:"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start sequence %q: %w", position, err)
+		}
+		return stan.StartAtSequence(seq), nil
+	case strings.HasPrefix(position, "since:"):
+		ago, err := time.ParseDuration(strings.TrimPrefix(position, "since:"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid start time delta %q: %w", position, err)
+		}
+		return stan.StartAtTimeDelta(ago), nil
+	default:
+		return nil, fmt.Errorf("unknown start position %q", position)
+	}
+}
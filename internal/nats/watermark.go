@@ -0,0 +1,58 @@
+package nats
+
+import "sync"
+
+// Watermark tracks the order messages being handled and reports the highest sequence
+// below which every message has been handled or rejected.
+// With MaxInflight above one, messages finish out of order, and a message that can't be handled yet
+// stays pending until it is redelivered, so the highest handled sequence may leave gaps behind it.
+type Watermark struct {
+	mutex sync.Mutex
+	// pending holds the sequences of the messages received and not handled yet.
+	pending map[uint64]struct{}
+	// handled is the highest sequence handled or set by Advance.
+	handled uint64
+}
+
+// NewWatermark creates a new instance of Watermark.
+func NewWatermark() *Watermark {
+	return &Watermark{pending: make(map[uint64]struct{})}
+}
+
+// Received records a message delivered for handling, a redelivered message is recorded once.
+func (w *Watermark) Received(sequence uint64) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.pending[sequence] = struct{}{}
+}
+
+// Handled records a message handled or rejected, that is acknowledged.
+func (w *Watermark) Handled(sequence uint64) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	delete(w.pending, sequence)
+	if sequence > w.handled {
+		w.handled = sequence
+	}
+}
+
+// Advance records that every message up to sequence has been handled before, for example by a restored snapshot.
+func (w *Watermark) Advance(sequence uint64) {
+	w.Handled(sequence)
+}
+
+// Sequence returns the sequence up to which every message has been handled:
+// the one before the lowest pending message, or the highest handled one if no message is pending.
+func (w *Watermark) Sequence() uint64 {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	sequence := w.handled
+	for pending := range w.pending {
+		if pending <= sequence {
+			sequence = pending - 1
+		}
+	}
+
+	return sequence
+}
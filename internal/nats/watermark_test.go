@@ -0,0 +1,38 @@
+package nats
+
+import "testing"
+
+func TestWatermark(t *testing.T) {
+	w := NewWatermark()
+	if got := w.Sequence(); got != 0 {
+		t.Errorf("Watermark.Sequence() = %d, want 0", got)
+	}
+
+	// A restored snapshot handled every message up to its sequence
+	w.Advance(10)
+
+	steps := []struct {
+		name     string
+		received []uint64
+		handled  []uint64
+		want     uint64
+	}{
+		{name: "in flight", received: []uint64{11, 12, 13}, want: 10},
+		{name: "later message handled first", handled: []uint64{13}, want: 10},
+		{name: "gap closes", handled: []uint64{11}, want: 11},
+		{name: "failed message stays pending", received: []uint64{14}, want: 11},
+		{name: "redelivered and handled", received: []uint64{12}, handled: []uint64{12}, want: 13},
+		{name: "failed message handled", received: []uint64{14}, handled: []uint64{14}, want: 14},
+	}
+	for _, step := range steps {
+		for _, sequence := range step.received {
+			w.Received(sequence)
+		}
+		for _, sequence := range step.handled {
+			w.Handled(sequence)
+		}
+		if got := w.Sequence(); got != step.want {
+			t.Errorf("%s: Watermark.Sequence() = %d, want %d", step.name, got, step.want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Package repository provides implementations for interacting with order ingestion repositories.
+package repository
+
+import (
+	"L0/internal/db"
+	"L0/internal/entity"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ingestionRepository implements the IngestionRepository interface.
+type ingestionRepository struct {
+	source db.IngestionSource
+}
+
+// NewIngestionRepository creates a new instance of ingestionRepository.
+func NewIngestionRepository(source db.IngestionSource) *ingestionRepository {
+	return &ingestionRepository{
+		source: source,
+	}
+}
+
+// Save records the ingestion state of an order in the repository.
+func (r *ingestionRepository) Save(ctx context.Context, ingestion *entity.Ingestion) error {
+	err := r.source.SaveIngestion(ctx, ingestion)
+	if err != nil {
+		return fmt.Errorf("can't save ingestion in db: %w", err)
+	}
+
+	return nil
+}
+
+// GetByUid retrieves the ingestion record of an order from the repository.
+func (r *ingestionRepository) GetByUid(ctx context.Context, uid string) (*entity.Ingestion, error) {
+	ingestion, err := r.source.GetIngestion(ctx, uid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("can't get ingestion by uid from db: %w", err)
+	}
+
+	return ingestion, nil
+}
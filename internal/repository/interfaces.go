@@ -4,6 +4,7 @@ package repository
 import (
 	"L0/internal/entity"
 	"context"
+	"time"
 )
 
 //go:generate mockgen -source=./interfaces.go -destination=repositories_mock.go -package=repository
@@ -15,6 +16,18 @@ type OrderRepository interface {
 	// Returns the UID of the created order or an error if the operation fails.
 	Create(ctx context.Context, order *entity.Order) (string, error)
 
+	// Save idempotently stores an order in the repository.
+	// It takes a context, an order entity and an update policy for changed payloads as input parameters.
+	// Returns the outcome of the operation, an error wrapping entity.ErrConflict, or an error if the operation fails.
+	Save(ctx context.Context, order *entity.Order, policy entity.UpdatePolicy) (entity.SaveOutcome, error)
+
+	// Update replaces a stored order.
+	// It takes a context, an order entity and the version the stored order must be at as input parameters,
+	// zero matches any version.
+	// Returns entity.ErrNotFound if the order does not exist, an error wrapping entity.ErrVersionMismatch
+	// or entity.ErrConflict, or an error if the operation fails.
+	Update(ctx context.Context, order *entity.Order, version int) error
+
 	// GetByUid retrieves an order from the repository by its UID.
 	// It takes a context and a UID string as input parameters.
 	// Returns the order entity or an error if the operation fails.
@@ -25,8 +38,99 @@ type OrderRepository interface {
 	// Returns a slice of order entities or an error if the operation fails.
 	GetAll(ctx context.Context) ([]*entity.Order, error)
 
-	// Delete deletes an order.
+	// Stream passes all orders from the repository to fn one by one.
+	// It takes a context and a callback as input parameters.
+	// Returns the error returned by fn or an error if the operation fails.
+	Stream(ctx context.Context, fn func(order *entity.Order) error) error
+
+	// List retrieves a page of orders.
+	// It takes a context and a list query as input parameters.
+	// Returns the page of orders or an error if the operation fails.
+	List(ctx context.Context, query entity.OrderListQuery) (*entity.OrderPage, error)
+
+	// Search retrieves a page of orders matching a filter.
+	// It takes a context and a search query as input parameters.
+	// Returns the page of orders or an error if the operation fails.
+	Search(ctx context.Context, query entity.OrderSearchQuery) (*entity.OrderPage, error)
+
+	// Delete soft-deletes an order.
+	// It takes a context, a UID string, the author and the reason of the deletion and the version
+	// the order must be at as input parameters, zero matches any version.
+	// Returns entity.ErrNotFound if the order does not exist or is already deleted, an error wrapping
+	// entity.ErrVersionMismatch if the order is at another version, or an error if the operation fails.
+	Delete(ctx context.Context, uid string, deletedBy string, reason string, version int) error
+
+	// Restore restores a soft-deleted order.
+	// It takes a context and a UID string as input parameters.
+	// Returns entity.ErrNotFound if the order does not exist or is not deleted, or an error if the operation fails.
+	Restore(ctx context.Context, uid string) error
+
+	// Purge permanently deletes orders soft-deleted more than olderThan ago.
+	// It takes a context and the retention period as input parameters.
+	// Returns the number of purged orders or an error if the operation fails.
+	Purge(ctx context.Context, olderThan time.Duration) (int64, error)
+
+	// ChangeStatus moves an order from change.From to change.To and records the transition.
+	// It takes a context and a status change as input parameters.
+	// Returns an error wrapping entity.ErrConflict if the order is missing, deleted or no longer in change.From,
+	// or an error if the operation fails.
+	ChangeStatus(ctx context.Context, change *entity.StatusChange) error
+
+	// StatusHistory retrieves the status transitions of an order, oldest first.
+	// It takes a context and a UID string as input parameters.
+	// Returns a slice of status changes or an error if the operation fails.
+	StatusHistory(ctx context.Context, uid string) ([]*entity.StatusChange, error)
+
+	// Versions retrieves the versions of an order without snapshots, oldest first.
 	// It takes a context and a UID string as input parameters.
+	// Returns a slice of versions or an error if the operation fails.
+	Versions(ctx context.Context, uid string) ([]*entity.OrderVersion, error)
+
+	// Version retrieves a version of an order with its snapshot.
+	// It takes a context, a UID string and a version number as input parameters.
+	// Returns the version, nil if it does not exist, or an error if the operation fails.
+	Version(ctx context.Context, uid string, version int) (*entity.OrderVersion, error)
+
+	// ChangedSince retrieves the UIDs of orders changed at or after since, including deleted and purged orders.
+	// It takes a context and the start time as input parameters.
+	// Returns a slice of order UIDs or an error if the operation fails.
+	ChangedSince(ctx context.Context, since time.Time) ([]string, error)
+}
+
+// RejectedMessageRepository defines the interface for rejected message repositories.
+type RejectedMessageRepository interface {
+	// Create stores a rejected message in the repository.
+	// It takes a context and a rejected message entity as input parameters.
+	// Returns the identifier of the stored message or an error if the operation fails.
+	Create(ctx context.Context, msg *entity.RejectedMessage) (int64, error)
+
+	// GetById retrieves a rejected message from the repository by its identifier.
+	// It takes a context and a message ID as input parameters.
+	// Returns the message, nil if it does not exist, or an error if the operation fails.
+	GetById(ctx context.Context, id int64) (*entity.RejectedMessage, error)
+
+	// GetAll retrieves all rejected messages from the repository.
+	// It takes a context as an input parameter.
+	// Returns a slice of messages or an error if the operation fails.
+	GetAll(ctx context.Context) ([]*entity.RejectedMessage, error)
+
+	// Delete deletes a rejected message.
+	// It takes a context and a message ID as input parameters.
+	// Returns entity.ErrNotFound if the message does not exist or an error if the operation fails.
+	Delete(ctx context.Context, id int64) error
+}
+
+// IngestionRepository defines the interface for order ingestion repositories.
+type IngestionRepository interface {
+	// Save records the ingestion state of the latest submission of an order.
+	// States of earlier NATS messages are ignored and a final state (persisted or rejected) of a message
+	// is kept on redelivery, while a resubmitted order is recorded as received again.
+	// It takes a context and an ingestion entity as input parameters.
 	// Returns an error if the operation fails.
-	Delete(ctx context.Context, uid string) error
+	Save(ctx context.Context, ingestion *entity.Ingestion) error
+
+	// GetByUid retrieves the ingestion record of an order.
+	// It takes a context and a UID string as input parameters.
+	// Returns the record, nil if the order has none, or an error if the operation fails.
+	GetByUid(ctx context.Context, uid string) (*entity.Ingestion, error)
 }
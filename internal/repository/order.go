@@ -6,7 +6,9 @@ import (
 	"L0/internal/entity"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 )
 
 // orderRepository implements the OrderRepository interface.
@@ -31,6 +33,29 @@ func (o *orderRepository) Create(ctx context.Context, order *entity.Order) (stri
 	return id, nil
 }
 
+// Save idempotently stores an order in the repository.
+func (o *orderRepository) Save(ctx context.Context, order *entity.Order, policy entity.UpdatePolicy) (entity.SaveOutcome, error) {
+	outcome, err := o.source.SaveOrder(ctx, order, policy)
+	if err != nil {
+		return "", fmt.Errorf("can't save order in db: %w", err)
+	}
+
+	return outcome, nil
+}
+
+// Update replaces a stored order in the repository.
+func (o *orderRepository) Update(ctx context.Context, order *entity.Order, version int) error {
+	err := o.source.UpdateOrder(ctx, order, version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ErrNotFound
+		}
+		return fmt.Errorf("can't update order in db: %w", err)
+	}
+
+	return nil
+}
+
 // GetByUid retrieves an order from the repository by its UID.
 func (o *orderRepository) GetByUid(ctx context.Context, uid string) (*entity.Order, error) {
 	order, err := o.source.GetOrderByUid(ctx, uid)
@@ -54,12 +79,124 @@ func (o *orderRepository) GetAll(ctx context.Context) ([]*entity.Order, error) {
 	return orders, nil
 }
 
-// Delete deletes an order.
-func (o *orderRepository) Delete(ctx context.Context, uid string) error {
-	err := o.source.DeleteOrder(ctx, uid)
+// Stream passes all orders from the repository to fn one by one.
+func (o *orderRepository) Stream(ctx context.Context, fn func(order *entity.Order) error) error {
+	err := o.source.StreamOrders(ctx, fn)
+	if err != nil {
+		return fmt.Errorf("can't stream orders from db: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves a page of orders from the repository.
+func (o *orderRepository) List(ctx context.Context, query entity.OrderListQuery) (*entity.OrderPage, error) {
+	page, err := o.source.ListOrders(ctx, query)
 	if err != nil {
+		return nil, fmt.Errorf("can't list orders from db: %w", err)
+	}
+
+	return page, nil
+}
+
+// Search retrieves a page of orders matching a filter from the repository.
+func (o *orderRepository) Search(ctx context.Context, query entity.OrderSearchQuery) (*entity.OrderPage, error) {
+	page, err := o.source.SearchOrders(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("can't search orders in db: %w", err)
+	}
+
+	return page, nil
+}
+
+// Delete soft-deletes an order.
+func (o *orderRepository) Delete(ctx context.Context, uid string, deletedBy string, reason string, version int) error {
+	err := o.source.DeleteOrder(ctx, uid, deletedBy, reason, version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ErrNotFound
+		}
 		return fmt.Errorf("can't delete order: %w", err)
 	}
 
 	return nil
 }
+
+// Restore restores a soft-deleted order.
+func (o *orderRepository) Restore(ctx context.Context, uid string) error {
+	err := o.source.RestoreOrder(ctx, uid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ErrNotFound
+		}
+		return fmt.Errorf("can't restore order: %w", err)
+	}
+
+	return nil
+}
+
+// Purge permanently deletes orders soft-deleted more than olderThan ago.
+func (o *orderRepository) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	purged, err := o.source.PurgeDeletedOrders(ctx, olderThan)
+	if err != nil {
+		return 0, fmt.Errorf("can't purge deleted orders: %w", err)
+	}
+
+	return purged, nil
+}
+
+// ChangeStatus moves an order to another status and records the transition.
+func (o *orderRepository) ChangeStatus(ctx context.Context, change *entity.StatusChange) error {
+	err := o.source.ChangeOrderStatus(ctx, change)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: order %s is not in status %s", entity.ErrConflict, change.OrderUID, change.From)
+		}
+		return fmt.Errorf("can't change order status: %w", err)
+	}
+
+	return nil
+}
+
+// StatusHistory retrieves the status transitions of an order.
+func (o *orderRepository) StatusHistory(ctx context.Context, uid string) ([]*entity.StatusChange, error) {
+	history, err := o.source.GetStatusHistory(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("can't get status history: %w", err)
+	}
+
+	return history, nil
+}
+
+// Versions retrieves the versions of an order.
+func (o *orderRepository) Versions(ctx context.Context, uid string) ([]*entity.OrderVersion, error) {
+	versions, err := o.source.GetOrderVersions(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("can't get order versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// Version retrieves a version of an order.
+func (o *orderRepository) Version(ctx context.Context, uid string, version int) (*entity.OrderVersion, error) {
+	v, err := o.source.GetOrderVersion(ctx, uid, version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("can't get order version: %w", err)
+	}
+
+	return v, nil
+}
+
+// ChangedSince retrieves the UIDs of orders changed at or after since.
+func (o *orderRepository) ChangedSince(ctx context.Context, since time.Time) ([]string, error) {
+	orderUIDs, err := o.source.GetChangedOrderUids(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("can't get changed orders: %w", err)
+	}
+
+	return orderUIDs, nil
+}
@@ -4,6 +4,7 @@ import (
 	"L0/internal/db"
 	"L0/internal/entity"
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	reflect "reflect"
@@ -300,6 +301,239 @@ func TestOrderRepository_GetAll(t *testing.T) {
 	}
 }
 
+func TestOrderRepository_Stream(t *testing.T) {
+	type fields struct {
+		source *db.MockOrderSource
+	}
+	tests := []struct {
+		name    string
+		setup   func(f fields)
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "success",
+			setup: func(f fields) {
+				f.source.EXPECT().StreamOrders(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, fn func(order *entity.Order) error) error {
+						for _, uid := range []string{"b563feb7b2b84b6test1", "b563feb7b2b84b6test2"} {
+							if err := fn(&entity.Order{OrderUID: uid}); err != nil {
+								return err
+							}
+						}
+						return nil
+					},
+				)
+			},
+			want:    []string{"b563feb7b2b84b6test1", "b563feb7b2b84b6test2"},
+			wantErr: false,
+		},
+		{
+			name: "fail: can't stream orders",
+			setup: func(f fields) {
+				f.source.EXPECT().StreamOrders(gomock.Any(), gomock.Any()).Return(fmt.Errorf("some error"))
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				source: db.NewMockOrderSource(ctrl),
+			}
+			repo := NewOrderRepository(f.source)
+			tt.setup(f)
+
+			var got []string
+			err := repo.Stream(context.Background(), func(order *entity.Order) error {
+				got = append(got, order.OrderUID)
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Stream() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Stream() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderRepository_List(t *testing.T) {
+	type fields struct {
+		source *db.MockOrderSource
+	}
+	query := entity.OrderListQuery{Limit: 2, Sort: entity.OrderSortOrderUID}
+	page := &entity.OrderPage{Orders: []*entity.Order{{OrderUID: "b563feb7b2b84b6test1"}}}
+	tests := []struct {
+		name    string
+		setup   func(f fields)
+		want    *entity.OrderPage
+		wantErr bool
+	}{
+		{
+			name: "success",
+			setup: func(f fields) {
+				f.source.EXPECT().ListOrders(gomock.Any(), query).Return(page, nil)
+			},
+			want:    page,
+			wantErr: false,
+		},
+		{
+			name: "fail: can't list orders",
+			setup: func(f fields) {
+				f.source.EXPECT().ListOrders(gomock.Any(), query).Return(nil, fmt.Errorf("some error"))
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				source: db.NewMockOrderSource(ctrl),
+			}
+			repo := NewOrderRepository(f.source)
+			tt.setup(f)
+
+			got, err := repo.List(context.Background(), query)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("List() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("List() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderRepository_Search(t *testing.T) {
+	type fields struct {
+		source *db.MockOrderSource
+	}
+	query := entity.OrderSearchQuery{
+		OrderListQuery: entity.OrderListQuery{Limit: 2, Sort: entity.OrderSortOrderUID},
+		Filter:         entity.OrderFilter{CustomerID: "test"},
+	}
+	page := &entity.OrderPage{Orders: []*entity.Order{{OrderUID: "b563feb7b2b84b6test1"}}}
+	tests := []struct {
+		name    string
+		setup   func(f fields)
+		want    *entity.OrderPage
+		wantErr bool
+	}{
+		{
+			name: "success",
+			setup: func(f fields) {
+				f.source.EXPECT().SearchOrders(gomock.Any(), query).Return(page, nil)
+			},
+			want:    page,
+			wantErr: false,
+		},
+		{
+			name: "fail: can't search orders",
+			setup: func(f fields) {
+				f.source.EXPECT().SearchOrders(gomock.Any(), query).Return(nil, fmt.Errorf("some error"))
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				source: db.NewMockOrderSource(ctrl),
+			}
+			repo := NewOrderRepository(f.source)
+			tt.setup(f)
+
+			got, err := repo.Search(context.Background(), query)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Search() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderRepository_Update(t *testing.T) {
+	type fields struct {
+		source *db.MockOrderSource
+	}
+	order := &entity.Order{OrderUID: "b563feb7b2b84b6test"}
+	tests := []struct {
+		name         string
+		setup        func(f fields)
+		wantErr      bool
+		wantNotFound bool
+		wantMismatch bool
+	}{
+		{
+			name: "success",
+			setup: func(f fields) {
+				f.source.EXPECT().UpdateOrder(gomock.Any(), order, 2).Return(nil)
+			},
+		},
+		{
+			name: "fail: not found",
+			setup: func(f fields) {
+				f.source.EXPECT().UpdateOrder(gomock.Any(), order, 2).Return(sql.ErrNoRows)
+			},
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		{
+			name: "fail: can't update order",
+			setup: func(f fields) {
+				f.source.EXPECT().UpdateOrder(gomock.Any(), order, 2).Return(fmt.Errorf("some error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "fail: version mismatch",
+			setup: func(f fields) {
+				f.source.EXPECT().UpdateOrder(gomock.Any(), order, 2).Return(fmt.Errorf("%w: order is at version 3", entity.ErrVersionMismatch))
+			},
+			wantErr:      true,
+			wantMismatch: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				source: db.NewMockOrderSource(ctrl),
+			}
+			repo := NewOrderRepository(f.source)
+			tt.setup(f)
+
+			err := repo.Update(context.Background(), order, 2)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Update() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, entity.ErrNotFound) != tt.wantNotFound {
+				t.Errorf("Update() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if errors.Is(err, entity.ErrVersionMismatch) != tt.wantMismatch {
+				t.Errorf("Update() error = %v, wantMismatch %v", err, tt.wantMismatch)
+			}
+		})
+	}
+}
+
 func TestOrderRepository_Delete(t *testing.T) {
 	type fields struct {
 		source *db.MockOrderSource
@@ -309,11 +543,12 @@ func TestOrderRepository_Delete(t *testing.T) {
 		uid string
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		setup   func(args, fields)
-		wantErr bool
+		name         string
+		fields       fields
+		args         args
+		setup        func(args, fields)
+		wantErr      bool
+		wantNotFound bool
 	}{
 		{
 			name: "success",
@@ -322,10 +557,22 @@ func TestOrderRepository_Delete(t *testing.T) {
 				uid: "test_uid",
 			},
 			setup: func(a args, f fields) {
-				f.source.EXPECT().DeleteOrder(a.ctx, a.uid).Return(nil)
+				f.source.EXPECT().DeleteOrder(a.ctx, a.uid, "admin", "duplicate", 1).Return(nil)
 			},
 			wantErr: false,
 		},
+		{
+			name: "fail: not found",
+			args: args{
+				ctx: context.Background(),
+				uid: "test_uid",
+			},
+			setup: func(a args, f fields) {
+				f.source.EXPECT().DeleteOrder(a.ctx, a.uid, "admin", "duplicate", 1).Return(sql.ErrNoRows)
+			},
+			wantErr:      true,
+			wantNotFound: true,
+		},
 		{
 			name: "fail: can't delete order",
 			args: args{
@@ -333,7 +580,7 @@ func TestOrderRepository_Delete(t *testing.T) {
 				uid: "test_uid",
 			},
 			setup: func(a args, f fields) {
-				f.source.EXPECT().DeleteOrder(a.ctx, a.uid).Return(errors.New("delete error"))
+				f.source.EXPECT().DeleteOrder(a.ctx, a.uid, "admin", "duplicate", 1).Return(errors.New("delete error"))
 			},
 			wantErr: true,
 		},
@@ -351,12 +598,269 @@ func TestOrderRepository_Delete(t *testing.T) {
 
 			tt.setup(tt.args, f)
 
-			err := repo.Delete(tt.args.ctx, tt.args.uid)
+			err := repo.Delete(tt.args.ctx, tt.args.uid, "admin", "duplicate", 1)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("orderRepository.Delete() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if errors.Is(err, entity.ErrNotFound) != tt.wantNotFound {
+				t.Errorf("orderRepository.Delete() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+		})
+	}
+}
+
+func TestOrderRepository_Restore(t *testing.T) {
+	type fields struct {
+		source *db.MockOrderSource
+	}
+	tests := []struct {
+		name         string
+		setup        func(f fields)
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{
+			name: "success",
+			setup: func(f fields) {
+				f.source.EXPECT().RestoreOrder(gomock.Any(), "test_uid").Return(nil)
+			},
+		},
+		{
+			name: "fail: not found",
+			setup: func(f fields) {
+				f.source.EXPECT().RestoreOrder(gomock.Any(), "test_uid").Return(sql.ErrNoRows)
+			},
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		{
+			name: "fail: can't restore order",
+			setup: func(f fields) {
+				f.source.EXPECT().RestoreOrder(gomock.Any(), "test_uid").Return(errors.New("restore error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				source: db.NewMockOrderSource(ctrl),
+			}
+			repo := NewOrderRepository(f.source)
+			tt.setup(f)
+
+			err := repo.Restore(context.Background(), "test_uid")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Restore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, entity.ErrNotFound) != tt.wantNotFound {
+				t.Errorf("Restore() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+		})
+	}
+}
+
+func TestOrderRepository_Purge(t *testing.T) {
+	type fields struct {
+		source *db.MockOrderSource
+	}
+	tests := []struct {
+		name    string
+		setup   func(f fields)
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "success",
+			setup: func(f fields) {
+				f.source.EXPECT().PurgeDeletedOrders(gomock.Any(), 24*time.Hour).Return(int64(3), nil)
+			},
+			want: 3,
+		},
+		{
+			name: "fail: can't purge orders",
+			setup: func(f fields) {
+				f.source.EXPECT().PurgeDeletedOrders(gomock.Any(), 24*time.Hour).Return(int64(0), errors.New("purge error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				source: db.NewMockOrderSource(ctrl),
+			}
+			repo := NewOrderRepository(f.source)
+			tt.setup(f)
+
+			got, err := repo.Purge(context.Background(), 24*time.Hour)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Purge() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Purge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderRepository_ChangeStatus(t *testing.T) {
+	type fields struct {
+		source *db.MockOrderSource
+	}
+	change := &entity.StatusChange{OrderUID: "test_uid", From: entity.StatusCreated, To: entity.StatusPaid, Actor: "billing"}
+	tests := []struct {
+		name         string
+		setup        func(f fields)
+		wantErr      bool
+		wantConflict bool
+	}{
+		{
+			name: "success",
+			setup: func(f fields) {
+				f.source.EXPECT().ChangeOrderStatus(gomock.Any(), change).Return(nil)
+			},
+		},
+		{
+			name: "fail: order left the expected status",
+			setup: func(f fields) {
+				f.source.EXPECT().ChangeOrderStatus(gomock.Any(), change).Return(sql.ErrNoRows)
+			},
+			wantErr:      true,
+			wantConflict: true,
+		},
+		{
+			name: "fail: can't change status",
+			setup: func(f fields) {
+				f.source.EXPECT().ChangeOrderStatus(gomock.Any(), change).Return(errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				source: db.NewMockOrderSource(ctrl),
+			}
+			repo := NewOrderRepository(f.source)
+			tt.setup(f)
+
+			err := repo.ChangeStatus(context.Background(), change)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ChangeStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, entity.ErrConflict) != tt.wantConflict {
+				t.Errorf("ChangeStatus() error = %v, wantConflict %v", err, tt.wantConflict)
+			}
+		})
+	}
+}
+
+func TestOrderRepository_Version(t *testing.T) {
+	type fields struct {
+		source *db.MockOrderSource
+	}
+	version := &entity.OrderVersion{OrderUID: "test_uid", Version: 2, Operation: entity.VersionUpdated}
+	tests := []struct {
+		name    string
+		setup   func(f fields)
+		want    *entity.OrderVersion
+		wantErr bool
+	}{
+		{
+			name: "success",
+			setup: func(f fields) {
+				f.source.EXPECT().GetOrderVersion(gomock.Any(), "test_uid", 2).Return(version, nil)
+			},
+			want: version,
+		},
+		{
+			name: "success: not found",
+			setup: func(f fields) {
+				f.source.EXPECT().GetOrderVersion(gomock.Any(), "test_uid", 2).Return(nil, sql.ErrNoRows)
+			},
+		},
+		{
+			name: "fail: can't get version",
+			setup: func(f fields) {
+				f.source.EXPECT().GetOrderVersion(gomock.Any(), "test_uid", 2).Return(nil, errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				source: db.NewMockOrderSource(ctrl),
+			}
+			repo := NewOrderRepository(f.source)
+			tt.setup(f)
+
+			got, err := repo.Version(context.Background(), "test_uid", 2)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Version() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Version() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderRepository_ChangedSince(t *testing.T) {
+	type fields struct {
+		source *db.MockOrderSource
+	}
+	since := MustParseTime(time.RFC3339, "2021-11-27T10:00:00Z")
+	tests := []struct {
+		name    string
+		setup   func(f fields)
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "success",
+			setup: func(f fields) {
+				f.source.EXPECT().GetChangedOrderUids(gomock.Any(), since).Return([]string{"test_uid"}, nil)
+			},
+			want: []string{"test_uid"},
+		},
+		{
+			name: "fail: can't get changed orders",
+			setup: func(f fields) {
+				f.source.EXPECT().GetChangedOrderUids(gomock.Any(), since).Return(nil, errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			f := fields{
+				source: db.NewMockOrderSource(ctrl),
+			}
+			repo := NewOrderRepository(f.source)
+			tt.setup(f)
+
+			got, err := repo.ChangedSince(context.Background(), since)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ChangedSince() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChangedSince() = %v, want %v", got, tt.want)
+			}
 		})
 	}
 }
@@ -0,0 +1,69 @@
+// Package repository provides implementations for interacting with rejected message repositories.
+package repository
+
+import (
+	"L0/internal/db"
+	"L0/internal/entity"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// rejectedMessageRepository implements the RejectedMessageRepository interface.
+type rejectedMessageRepository struct {
+	source db.RejectedMessageSource
+}
+
+// NewRejectedMessageRepository creates a new instance of rejectedMessageRepository.
+func NewRejectedMessageRepository(source db.RejectedMessageSource) *rejectedMessageRepository {
+	return &rejectedMessageRepository{
+		source: source,
+	}
+}
+
+// Create stores a rejected message in the repository.
+func (r *rejectedMessageRepository) Create(ctx context.Context, msg *entity.RejectedMessage) (int64, error) {
+	id, err := r.source.CreateRejectedMessage(ctx, msg)
+	if err != nil {
+		return 0, fmt.Errorf("can't create rejected message in db: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetById retrieves a rejected message from the repository by its identifier.
+func (r *rejectedMessageRepository) GetById(ctx context.Context, id int64) (*entity.RejectedMessage, error) {
+	msg, err := r.source.GetRejectedMessageById(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("can't get rejected message by id from db: %w", err)
+	}
+
+	return msg, nil
+}
+
+// GetAll retrieves all rejected messages from the repository.
+func (r *rejectedMessageRepository) GetAll(ctx context.Context) ([]*entity.RejectedMessage, error) {
+	msgs, err := r.source.GetAllRejectedMessages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't get all rejected messages from db: %w", err)
+	}
+
+	return msgs, nil
+}
+
+// Delete deletes a rejected message.
+func (r *rejectedMessageRepository) Delete(ctx context.Context, id int64) error {
+	err := r.source.DeleteRejectedMessage(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ErrNotFound
+		}
+		return fmt.Errorf("can't delete rejected message: %w", err)
+	}
+
+	return nil
+}
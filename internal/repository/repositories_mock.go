@@ -8,6 +8,7 @@ import (
 	entity "L0/internal/entity"
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,6 +36,35 @@ func (m *MockOrderRepository) EXPECT() *MockOrderRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ChangeStatus mocks base method.
+func (m *MockOrderRepository) ChangeStatus(ctx context.Context, change *entity.StatusChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeStatus", ctx, change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeStatus indicates an expected call of ChangeStatus.
+func (mr *MockOrderRepositoryMockRecorder) ChangeStatus(ctx, change interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeStatus", reflect.TypeOf((*MockOrderRepository)(nil).ChangeStatus), ctx, change)
+}
+
+// ChangedSince mocks base method.
+func (m *MockOrderRepository) ChangedSince(ctx context.Context, since time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangedSince", ctx, since)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangedSince indicates an expected call of ChangedSince.
+func (mr *MockOrderRepositoryMockRecorder) ChangedSince(ctx, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangedSince", reflect.TypeOf((*MockOrderRepository)(nil).ChangedSince), ctx, since)
+}
+
 // Create mocks base method.
 func (m *MockOrderRepository) Create(ctx context.Context, order *entity.Order) (string, error) {
 	m.ctrl.T.Helper()
@@ -51,17 +81,17 @@ func (mr *MockOrderRepositoryMockRecorder) Create(ctx, order interface{}) *gomoc
 }
 
 // Delete mocks base method.
-func (m *MockOrderRepository) Delete(ctx context.Context, uid string) error {
+func (m *MockOrderRepository) Delete(ctx context.Context, uid, deletedBy, reason string, version int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, uid)
+	ret := m.ctrl.Call(m, "Delete", ctx, uid, deletedBy, reason, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockOrderRepositoryMockRecorder) Delete(ctx, uid interface{}) *gomock.Call {
+func (mr *MockOrderRepositoryMockRecorder) Delete(ctx, uid, deletedBy, reason, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOrderRepository)(nil).Delete), ctx, uid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOrderRepository)(nil).Delete), ctx, uid, deletedBy, reason, version)
 }
 
 // GetAll mocks base method.
@@ -93,3 +123,284 @@ func (mr *MockOrderRepositoryMockRecorder) GetByUid(ctx, uid interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUid", reflect.TypeOf((*MockOrderRepository)(nil).GetByUid), ctx, uid)
 }
+
+// List mocks base method.
+func (m *MockOrderRepository) List(ctx context.Context, query entity.OrderListQuery) (*entity.OrderPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, query)
+	ret0, _ := ret[0].(*entity.OrderPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockOrderRepositoryMockRecorder) List(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockOrderRepository)(nil).List), ctx, query)
+}
+
+// Purge mocks base method.
+func (m *MockOrderRepository) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, olderThan)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockOrderRepositoryMockRecorder) Purge(ctx, olderThan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockOrderRepository)(nil).Purge), ctx, olderThan)
+}
+
+// Restore mocks base method.
+func (m *MockOrderRepository) Restore(ctx context.Context, uid string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockOrderRepositoryMockRecorder) Restore(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockOrderRepository)(nil).Restore), ctx, uid)
+}
+
+// Save mocks base method.
+func (m *MockOrderRepository) Save(ctx context.Context, order *entity.Order, policy entity.UpdatePolicy) (entity.SaveOutcome, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, order, policy)
+	ret0, _ := ret[0].(entity.SaveOutcome)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockOrderRepositoryMockRecorder) Save(ctx, order, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockOrderRepository)(nil).Save), ctx, order, policy)
+}
+
+// Search mocks base method.
+func (m *MockOrderRepository) Search(ctx context.Context, query entity.OrderSearchQuery) (*entity.OrderPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, query)
+	ret0, _ := ret[0].(*entity.OrderPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockOrderRepositoryMockRecorder) Search(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockOrderRepository)(nil).Search), ctx, query)
+}
+
+// StatusHistory mocks base method.
+func (m *MockOrderRepository) StatusHistory(ctx context.Context, uid string) ([]*entity.StatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatusHistory", ctx, uid)
+	ret0, _ := ret[0].([]*entity.StatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StatusHistory indicates an expected call of StatusHistory.
+func (mr *MockOrderRepositoryMockRecorder) StatusHistory(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatusHistory", reflect.TypeOf((*MockOrderRepository)(nil).StatusHistory), ctx, uid)
+}
+
+// Stream mocks base method.
+func (m *MockOrderRepository) Stream(ctx context.Context, fn func(*entity.Order) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stream", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stream indicates an expected call of Stream.
+func (mr *MockOrderRepositoryMockRecorder) Stream(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stream", reflect.TypeOf((*MockOrderRepository)(nil).Stream), ctx, fn)
+}
+
+// Update mocks base method.
+func (m *MockOrderRepository) Update(ctx context.Context, order *entity.Order, version int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, order, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockOrderRepositoryMockRecorder) Update(ctx, order, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockOrderRepository)(nil).Update), ctx, order, version)
+}
+
+// Version mocks base method.
+func (m *MockOrderRepository) Version(ctx context.Context, uid string, version int) (*entity.OrderVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Version", ctx, uid, version)
+	ret0, _ := ret[0].(*entity.OrderVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Version indicates an expected call of Version.
+func (mr *MockOrderRepositoryMockRecorder) Version(ctx, uid, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*MockOrderRepository)(nil).Version), ctx, uid, version)
+}
+
+// Versions mocks base method.
+func (m *MockOrderRepository) Versions(ctx context.Context, uid string) ([]*entity.OrderVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Versions", ctx, uid)
+	ret0, _ := ret[0].([]*entity.OrderVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Versions indicates an expected call of Versions.
+func (mr *MockOrderRepositoryMockRecorder) Versions(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Versions", reflect.TypeOf((*MockOrderRepository)(nil).Versions), ctx, uid)
+}
+
+// MockRejectedMessageRepository is a mock of RejectedMessageRepository interface.
+type MockRejectedMessageRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRejectedMessageRepositoryMockRecorder
+}
+
+// MockRejectedMessageRepositoryMockRecorder is the mock recorder for MockRejectedMessageRepository.
+type MockRejectedMessageRepositoryMockRecorder struct {
+	mock *MockRejectedMessageRepository
+}
+
+// NewMockRejectedMessageRepository creates a new mock instance.
+func NewMockRejectedMessageRepository(ctrl *gomock.Controller) *MockRejectedMessageRepository {
+	mock := &MockRejectedMessageRepository{ctrl: ctrl}
+	mock.recorder = &MockRejectedMessageRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRejectedMessageRepository) EXPECT() *MockRejectedMessageRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockRejectedMessageRepository) Create(ctx context.Context, msg *entity.RejectedMessage) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, msg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRejectedMessageRepositoryMockRecorder) Create(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRejectedMessageRepository)(nil).Create), ctx, msg)
+}
+
+// Delete mocks base method.
+func (m *MockRejectedMessageRepository) Delete(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockRejectedMessageRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRejectedMessageRepository)(nil).Delete), ctx, id)
+}
+
+// GetAll mocks base method.
+func (m *MockRejectedMessageRepository) GetAll(ctx context.Context) ([]*entity.RejectedMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret0, _ := ret[0].([]*entity.RejectedMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockRejectedMessageRepositoryMockRecorder) GetAll(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRejectedMessageRepository)(nil).GetAll), ctx)
+}
+
+// GetById mocks base method.
+func (m *MockRejectedMessageRepository) GetById(ctx context.Context, id int64) (*entity.RejectedMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, id)
+	ret0, _ := ret[0].(*entity.RejectedMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockRejectedMessageRepositoryMockRecorder) GetById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockRejectedMessageRepository)(nil).GetById), ctx, id)
+}
+
+// MockIngestionRepository is a mock of IngestionRepository interface.
+type MockIngestionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIngestionRepositoryMockRecorder
+}
+
+// MockIngestionRepositoryMockRecorder is the mock recorder for MockIngestionRepository.
+type MockIngestionRepositoryMockRecorder struct {
+	mock *MockIngestionRepository
+}
+
+// NewMockIngestionRepository creates a new mock instance.
+func NewMockIngestionRepository(ctrl *gomock.Controller) *MockIngestionRepository {
+	mock := &MockIngestionRepository{ctrl: ctrl}
+	mock.recorder = &MockIngestionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIngestionRepository) EXPECT() *MockIngestionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetByUid mocks base method.
+func (m *MockIngestionRepository) GetByUid(ctx context.Context, uid string) (*entity.Ingestion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUid", ctx, uid)
+	ret0, _ := ret[0].(*entity.Ingestion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUid indicates an expected call of GetByUid.
+func (mr *MockIngestionRepositoryMockRecorder) GetByUid(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUid", reflect.TypeOf((*MockIngestionRepository)(nil).GetByUid), ctx, uid)
+}
+
+// Save mocks base method.
+func (m *MockIngestionRepository) Save(ctx context.Context, ingestion *entity.Ingestion) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, ingestion)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockIngestionRepositoryMockRecorder) Save(ctx, ingestion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockIngestionRepository)(nil).Save), ctx, ingestion)
+}
@@ -7,28 +7,38 @@ document.addEventListener('DOMContentLoaded', function () {
     fetchOrderButton.addEventListener('click', fetchOrderDetails);
     createOrderButton.addEventListener('click', createOrder);
     deleteOrderButton.addEventListener('click', deleteOrder);
+    // ETags of fetched orders by id, sent back in If-Match when an order is deleted
+    const orderETags = {};
     // document.addEventListener('DOMContentLoaded', fetchOrderIds);
 
     
-    fetch('/orders/all')
-        .then(response => {
-            if (!response.ok) {
-                throw new Error('Failed to fetch IDs');
-            }
-            return response.json();
-        })
-        .then(data => {
-            console.log('IDs fetched successfully:', data);
-            const savedOrdersList = document.getElementById('savedOrdersList');
-            for (const id of data) {
-                const orderIdItem = document.createElement('li');
-                orderIdItem.textContent = "Order ID: " + id;
-                savedOrdersList.appendChild(orderIdItem);
-            }
-        })
-        .catch(error => {
-            console.error('Error fetching IDs:', error);
-        });
+    fetchOrderIds();
+
+    function fetchOrderIds(cursor) {
+        const query = cursor ? `?cursor=${encodeURIComponent(cursor)}` : '';
+        fetch(`/orders/all${query}`)
+            .then(response => {
+                if (!response.ok) {
+                    throw new Error('Failed to fetch IDs');
+                }
+                return response.json();
+            })
+            .then(data => {
+                console.log('IDs fetched successfully:', data);
+                const savedOrdersList = document.getElementById('savedOrdersList');
+                for (const order of data.orders) {
+                    const orderIdItem = document.createElement('li');
+                    orderIdItem.textContent = "Order ID: " + order.order_uid;
+                    savedOrdersList.appendChild(orderIdItem);
+                }
+                if (data.next_cursor) {
+                    fetchOrderIds(data.next_cursor);
+                }
+            })
+            .catch(error => {
+                console.error('Error fetching IDs:', error);
+            });
+    }
 
     function fetchOrderDetails() {
         const id = orderIdInput.value;
@@ -42,6 +52,7 @@ document.addEventListener('DOMContentLoaded', function () {
                 if (!response.ok) {
                     throw new Error('Failed to fetch order details');
                 }
+                orderETags[id] = response.headers.get('ETag');
                 return response.json();
             })
             .then(order => {
@@ -56,7 +67,7 @@ document.addEventListener('DOMContentLoaded', function () {
     }
 
     function createOrder() {
-        fetch(`/orders/new`, {
+        fetch(`/dev/orders/random`, {
             method: 'POST',
         })
         .then(response => {
@@ -86,13 +97,24 @@ document.addEventListener('DOMContentLoaded', function () {
             errorDiv.textContent = 'Input field not must be empty';
             return;
         };
-        fetch(`/orders/id/${id}`, {
+        const reason = prompt(`Delete order ${id}? It can be restored until it is purged. Reason:`, '');
+        if (reason === null) {
+            return;
+        }
+        fetchETag(id)
+        .then(etag => fetch(`/orders/id/${id}?deleted_by=web&reason=${encodeURIComponent(reason)}`, {
             method: 'DELETE',
-        })
+            headers: { 'If-Match': etag },
+        }))
         .then(response => {
+            if (response.status === 412) {
+                delete orderETags[id];
+                throw new Error('Order was changed by someone else, fetch it again');
+            }
             if (!response.ok) {
                 throw new Error('Failed to delete order');
             }
+            delete orderETags[id];
         })
         .then(data => {
             const savedOrdersList = document.getElementById('savedOrdersList');
@@ -111,5 +133,19 @@ document.addEventListener('DOMContentLoaded', function () {
             const orderDetailsDiv = document.getElementById('orderDetails');
             orderDetailsDiv.innerHTML = '<p>Error deleting order: ' + error.message + '</p>';
         });
-    }    
+    }
+
+    function fetchETag(id) {
+        if (orderETags[id]) {
+            return Promise.resolve(orderETags[id]);
+        }
+        return fetch(`/orders/id/${id}`)
+            .then(response => {
+                if (!response.ok) {
+                    throw new Error('Failed to fetch order');
+                }
+                orderETags[id] = response.headers.get('ETag');
+                return orderETags[id];
+            });
+    }
 })
\ No newline at end of file
@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"L0/internal/cache"
+	"L0/internal/entity"
+	"L0/internal/repository"
+	"context"
+	"fmt"
+)
+
+// cacheInteractor implements the CacheInteractor interface.
+type cacheInteractor struct {
+	repo  repository.OrderRepository
+	cache cache.OrderCache
+}
+
+// NewCacheInteractor creates a new instance of cacheInteractor.
+func NewCacheInteractor(repo repository.OrderRepository, cache cache.OrderCache) *cacheInteractor {
+	return &cacheInteractor{
+		repo:  repo,
+		cache: cache,
+	}
+}
+
+// Reconcile compares the order cache with the database and repairs the divergence.
+func (u *cacheInteractor) Reconcile(ctx context.Context) (*entity.Reconciliation, error) {
+	report, err := u.cache.Reconcile(ctx, u.repo)
+	if err != nil {
+		return report, fmt.Errorf("can't reconcile cache: %w", err)
+	}
+
+	return report, nil
+}
+
+// LastReconciliation returns the outcome of the latest reconciliation.
+func (u *cacheInteractor) LastReconciliation() *entity.Reconciliation {
+	return u.cache.LastReconciliation()
+}
@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"L0/internal/cache"
+	"L0/internal/entity"
+	"L0/internal/repository"
+	"context"
+	"errors"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+func TestCacheInteractor_Reconcile(t *testing.T) {
+	type fields struct {
+		orderRepository *repository.MockOrderRepository
+		cache           *cache.MockOrderCache
+	}
+	report := &entity.Reconciliation{Checked: 1, Cached: 1, Complete: true}
+	tests := []struct {
+		name    string
+		setup   func(f fields)
+		wantErr bool
+	}{
+		{
+			name: "success",
+			setup: func(f fields) {
+				f.cache.EXPECT().Reconcile(gomock.Any(), f.orderRepository).Return(report, nil)
+			},
+		},
+		{
+			name: "fail: can't reconcile",
+			setup: func(f fields) {
+				f.cache.EXPECT().Reconcile(gomock.Any(), f.orderRepository).Return(report, errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			f := fields{
+				orderRepository: repository.NewMockOrderRepository(ctrl),
+				cache:           cache.NewMockOrderCache(ctrl),
+			}
+			u := NewCacheInteractor(f.orderRepository, f.cache)
+
+			tt.setup(f)
+
+			got, err := u.Reconcile(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("cacheInteractor.Reconcile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			// The report is returned with the error too
+			if got != report {
+				t.Errorf("cacheInteractor.Reconcile() = %v, want %v", got, report)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"L0/internal/entity"
+	"L0/internal/nats"
+	"L0/internal/repository"
+	"L0/internal/validator"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// IngestionMode chooses how orders submitted over HTTP are stored.
+type IngestionMode string
+
+// Ingestion modes.
+const (
+	// IngestionPublish publishes submitted orders to NATS Streaming, the subscriber stores them.
+	IngestionPublish IngestionMode = "publish"
+	// IngestionDirect stores submitted orders right away.
+	IngestionDirect IngestionMode = "direct"
+)
+
+// MaxIngestionWait is the longest time a status request may wait for the ingestion of an order to finish.
+const MaxIngestionWait = 30 * time.Second
+
+// ingestionPollInterval is the interval between reads of an ingestion record while waiting for it to finish.
+const ingestionPollInterval = 500 * time.Millisecond
+
+// ingestionInteractor implements the IngestionInteractor interface.
+type ingestionInteractor struct {
+	orders       OrderInteractor
+	repo         repository.IngestionRepository
+	validator    validator.OrderValidator
+	natsService  nats.NATSService
+	mode         IngestionMode
+	policy       entity.UpdatePolicy
+	pollInterval time.Duration
+}
+
+// NewIngestionInteractor creates a new instance of ingestionInteractor.
+// The update policy applies to orders stored in the direct mode, the same way the NATS subscriber applies it.
+func NewIngestionInteractor(
+	orders OrderInteractor,
+	repo repository.IngestionRepository,
+	validator validator.OrderValidator,
+	natsService nats.NATSService,
+	mode IngestionMode,
+	policy entity.UpdatePolicy,
+) *ingestionInteractor {
+	return &ingestionInteractor{
+		orders:       orders,
+		repo:         repo,
+		validator:    validator,
+		natsService:  natsService,
+		mode:         mode,
+		policy:       policy,
+		pollInterval: ingestionPollInterval,
+	}
+}
+
+// Submit validates an order and stores it or publishes it to NATS Streaming depending on the mode.
+// A published order is validated before publishing, so that upstream systems learn about invalid orders
+// from the response rather than from the rejected messages.
+// The order is recorded as received before it is published, so that the states recorded by the subscriber
+// are never overwritten; a published order is persisted or rejected later by the subscriber.
+func (u *ingestionInteractor) Submit(ctx context.Context, order *entity.Order) error {
+	if u.mode == IngestionDirect {
+		return u.submitDirect(ctx, order)
+	}
+
+	err := u.validator.Validate(order)
+	if err != nil {
+		u.track(ctx, order.OrderUID, entity.IngestionRejected, err.Error())
+		return fmt.Errorf("invalid order: %w", err)
+	}
+
+	data, err := json.Marshal(order)
+	if err != nil {
+		return fmt.Errorf("can't marshal order: %w", err)
+	}
+
+	err = u.repo.Save(ctx, u.ingestion(order.OrderUID, entity.IngestionReceived, ""))
+	if err != nil {
+		return fmt.Errorf("can't record order ingestion: %w", err)
+	}
+
+	err = u.natsService.Publish(data)
+	if err != nil {
+		u.track(ctx, order.OrderUID, entity.IngestionRejected, err.Error())
+		return fmt.Errorf("can't publish order: %w", err)
+	}
+
+	return nil
+}
+
+// submitDirect stores an order right away, recording it as received and then as persisted or rejected.
+// Storing is idempotent as in the publish mode, so a retried submission of the same order is accepted.
+func (u *ingestionInteractor) submitDirect(ctx context.Context, order *entity.Order) error {
+	if order.OrderUID != "" {
+		err := u.repo.Save(ctx, u.ingestion(order.OrderUID, entity.IngestionReceived, ""))
+		if err != nil {
+			return fmt.Errorf("can't record order ingestion: %w", err)
+		}
+	}
+
+	_, err := u.orders.Save(ctx, order, u.policy)
+	if err != nil {
+		u.track(ctx, order.OrderUID, entity.IngestionRejected, err.Error())
+		return err
+	}
+	u.track(ctx, order.OrderUID, entity.IngestionPersisted, "")
+
+	return nil
+}
+
+// Status retrieves the ingestion record of an order.
+// If wait is positive, the record is read again until the ingestion finishes, wait elapses or ctx is done,
+// and the latest record is returned.
+// Returns nil if the order has no record.
+func (u *ingestionInteractor) Status(ctx context.Context, uid string, wait time.Duration) (*entity.Ingestion, error) {
+	if uid == "" {
+		return nil, fmt.Errorf("%w: order uid is required", entity.ErrValidation)
+	}
+	if wait < 0 || wait > MaxIngestionWait {
+		return nil, fmt.Errorf("%w: wait must be between 0 and %s", entity.ErrValidation, MaxIngestionWait)
+	}
+
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	ticker := time.NewTicker(u.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		ingestion, err := u.repo.GetByUid(ctx, uid)
+		if err != nil {
+			return nil, fmt.Errorf("can't get ingestion from repository: %w", err)
+		}
+		if ingestion == nil || ingestion.State.Terminal() || wait == 0 {
+			return ingestion, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-deadline.C:
+			return ingestion, nil
+		case <-ctx.Done():
+			return ingestion, nil
+		}
+	}
+}
+
+// track records the ingestion state of an order once it is known whether it was stored.
+// A failure is ignored, the outcome of the submission has already been decided.
+func (u *ingestionInteractor) track(ctx context.Context, uid string, state entity.IngestionState, reason string) {
+	if uid == "" {
+		return
+	}
+
+	_ = u.repo.Save(ctx, u.ingestion(uid, state, reason))
+}
+
+// ingestion creates an ingestion record of an order updated now.
+func (u *ingestionInteractor) ingestion(uid string, state entity.IngestionState, reason string) *entity.Ingestion {
+	return &entity.Ingestion{
+		OrderUID:  uid,
+		State:     state,
+		Reason:    reason,
+		UpdatedAt: time.Now().UTC(),
+	}
+}
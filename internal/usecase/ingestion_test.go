@@ -0,0 +1,250 @@
+package usecase
+
+import (
+	"L0/internal/entity"
+	"L0/internal/nats"
+	"L0/internal/repository"
+	"L0/internal/validator"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+func TestIngestionInteractor_Submit(t *testing.T) {
+	type fields struct {
+		orders      *MockOrderInteractor
+		repo        *repository.MockIngestionRepository
+		validator   *validator.MockOrderValidator
+		natsService *nats.MockNATSService
+	}
+	order := &entity.Order{OrderUID: "b563feb7b2b84b6test", TrackNumber: "WBILMTESTTRACK"}
+	tests := []struct {
+		name       string
+		mode       IngestionMode
+		setup      func(f fields)
+		wantErr    error
+		wantAnyErr bool
+	}{
+		{
+			name: "success: publish",
+			mode: IngestionPublish,
+			setup: func(f fields) {
+				f.validator.EXPECT().Validate(order).Return(nil)
+				f.repo.EXPECT().Save(gomock.Any(), ingestionState(entity.IngestionReceived)).Return(nil)
+				f.natsService.EXPECT().Publish(gomock.Any()).DoAndReturn(func(data []byte) error {
+					var published entity.Order
+					if err := json.Unmarshal(data, &published); err != nil || published.OrderUID != order.OrderUID {
+						t.Errorf("published order = %s, error = %v", data, err)
+					}
+					return nil
+				})
+			},
+		},
+		{
+			name: "success: direct",
+			mode: IngestionDirect,
+			setup: func(f fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Save(gomock.Any(), ingestionState(entity.IngestionReceived)).Return(nil),
+					f.orders.EXPECT().Save(gomock.Any(), order, entity.UpdatePolicyUpdate).Return(entity.SaveCreated, nil),
+					f.repo.EXPECT().Save(gomock.Any(), ingestionState(entity.IngestionPersisted)).Return(nil),
+				)
+			},
+		},
+		{
+			name: "success: direct retry of a stored order",
+			mode: IngestionDirect,
+			setup: func(f fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Save(gomock.Any(), ingestionState(entity.IngestionReceived)).Return(nil),
+					f.orders.EXPECT().Save(gomock.Any(), order, entity.UpdatePolicyUpdate).Return(entity.SaveUnchanged, nil),
+					f.repo.EXPECT().Save(gomock.Any(), ingestionState(entity.IngestionPersisted)).Return(nil),
+				)
+			},
+		},
+		{
+			name: "fail: invalid order",
+			mode: IngestionPublish,
+			setup: func(f fields) {
+				f.validator.EXPECT().Validate(order).Return(fmt.Errorf("%w: track_number is required", entity.ErrValidation))
+				f.repo.EXPECT().Save(gomock.Any(), ingestionState(entity.IngestionRejected)).Return(nil)
+			},
+			wantErr: entity.ErrValidation,
+		},
+		{
+			name: "fail: can't publish",
+			mode: IngestionPublish,
+			setup: func(f fields) {
+				f.validator.EXPECT().Validate(order).Return(nil)
+				f.repo.EXPECT().Save(gomock.Any(), ingestionState(entity.IngestionReceived)).Return(nil)
+				f.natsService.EXPECT().Publish(gomock.Any()).Return(errors.New("nats error"))
+				f.repo.EXPECT().Save(gomock.Any(), ingestionState(entity.IngestionRejected)).Return(nil)
+			},
+			wantAnyErr: true,
+		},
+		{
+			name: "fail: can't save",
+			mode: IngestionDirect,
+			setup: func(f fields) {
+				f.repo.EXPECT().Save(gomock.Any(), ingestionState(entity.IngestionReceived)).Return(nil)
+				f.orders.EXPECT().Save(gomock.Any(), order, entity.UpdatePolicyUpdate).Return(entity.SaveOutcome(""), errors.New("db error"))
+				f.repo.EXPECT().Save(gomock.Any(), ingestionState(entity.IngestionRejected)).Return(errors.New("db error"))
+			},
+			wantAnyErr: true,
+		},
+		{
+			name: "fail: can't record received order",
+			mode: IngestionPublish,
+			setup: func(f fields) {
+				f.validator.EXPECT().Validate(order).Return(nil)
+				f.repo.EXPECT().Save(gomock.Any(), ingestionState(entity.IngestionReceived)).Return(errors.New("db error"))
+			},
+			wantAnyErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			f := fields{
+				orders:      NewMockOrderInteractor(ctrl),
+				repo:        repository.NewMockIngestionRepository(ctrl),
+				validator:   validator.NewMockOrderValidator(ctrl),
+				natsService: nats.NewMockNATSService(ctrl),
+			}
+			u := NewIngestionInteractor(f.orders, f.repo, f.validator, f.natsService, tt.mode, entity.UpdatePolicyUpdate)
+
+			tt.setup(f)
+
+			err := u.Submit(context.Background(), order)
+			if tt.wantErr != nil || tt.wantAnyErr {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Errorf("ingestionInteractor.Submit() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("ingestionInteractor.Submit() unexpected error = %v", err)
+			}
+		})
+	}
+}
+
+func TestIngestionInteractor_Status(t *testing.T) {
+	const uid = "b563feb7b2b84b6test"
+	received := &entity.Ingestion{OrderUID: uid, State: entity.IngestionReceived}
+	persisted := &entity.Ingestion{OrderUID: uid, State: entity.IngestionPersisted, Sequence: 42}
+	tests := []struct {
+		name       string
+		uid        string
+		wait       time.Duration
+		setup      func(repo *repository.MockIngestionRepository)
+		want       *entity.Ingestion
+		wantErr    error
+		wantAnyErr bool
+	}{
+		{
+			name: "success: no wait",
+			uid:  uid,
+			setup: func(repo *repository.MockIngestionRepository) {
+				repo.EXPECT().GetByUid(gomock.Any(), uid).Return(received, nil)
+			},
+			want: received,
+		},
+		{
+			name: "success: waits until persisted",
+			uid:  uid,
+			wait: time.Second,
+			setup: func(repo *repository.MockIngestionRepository) {
+				gomock.InOrder(
+					repo.EXPECT().GetByUid(gomock.Any(), uid).Return(received, nil).Times(2),
+					repo.EXPECT().GetByUid(gomock.Any(), uid).Return(persisted, nil),
+				)
+			},
+			want: persisted,
+		},
+		{
+			name: "success: wait elapses",
+			uid:  uid,
+			wait: 5 * time.Millisecond,
+			setup: func(repo *repository.MockIngestionRepository) {
+				repo.EXPECT().GetByUid(gomock.Any(), uid).Return(received, nil).MinTimes(1)
+			},
+			want: received,
+		},
+		{
+			name: "success: not found",
+			uid:  uid,
+			wait: time.Second,
+			setup: func(repo *repository.MockIngestionRepository) {
+				repo.EXPECT().GetByUid(gomock.Any(), uid).Return(nil, nil)
+			},
+			want: nil,
+		},
+		{
+			name:    "fail: empty uid",
+			setup:   func(repo *repository.MockIngestionRepository) {},
+			wantErr: entity.ErrValidation,
+		},
+		{
+			name:    "fail: wait too long",
+			uid:     uid,
+			wait:    MaxIngestionWait + time.Second,
+			setup:   func(repo *repository.MockIngestionRepository) {},
+			wantErr: entity.ErrValidation,
+		},
+		{
+			name: "fail: can't get ingestion",
+			uid:  uid,
+			setup: func(repo *repository.MockIngestionRepository) {
+				repo.EXPECT().GetByUid(gomock.Any(), uid).Return(nil, errors.New("db error"))
+			},
+			wantAnyErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repo := repository.NewMockIngestionRepository(ctrl)
+			u := NewIngestionInteractor(NewMockOrderInteractor(ctrl), repo, validator.NewMockOrderValidator(ctrl), nats.NewMockNATSService(ctrl), IngestionPublish, entity.UpdatePolicyUpdate)
+			u.pollInterval = time.Millisecond
+
+			tt.setup(repo)
+
+			got, err := u.Status(context.Background(), tt.uid, tt.wait)
+			if tt.wantErr != nil || tt.wantAnyErr {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Errorf("ingestionInteractor.Status() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("ingestionInteractor.Status() unexpected error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ingestionInteractor.Status() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// ingestionState matches an ingestion record by its state.
+func ingestionState(state entity.IngestionState) gomock.Matcher {
+	return ingestionStateMatcher(state)
+}
+
+type ingestionStateMatcher entity.IngestionState
+
+func (m ingestionStateMatcher) Matches(x interface{}) bool {
+	ingestion, ok := x.(*entity.Ingestion)
+	return ok && ingestion.State == entity.IngestionState(m)
+}
+
+func (m ingestionStateMatcher) String() string {
+	return "ingestion " + string(m)
+}
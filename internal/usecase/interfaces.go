@@ -4,17 +4,39 @@ package usecase
 import (
 	"L0/internal/entity"
 	"context"
+	"time"
 )
 
 //go:generate mockgen -source=./interfaces.go -destination=usecases_mock.go -package=usecase
 
 // OrderInteractor defines the interface for order use cases.
 type OrderInteractor interface {
-	// Create creates a new order.
+	// Create validates and creates a new order.
 	// It takes a context and an order entity as input parameters.
-	// Returns an error if the operation fails.
+	// Returns an error wrapping entity.ErrValidation if the order is invalid or an error if the operation fails.
 	Create(ctx context.Context, order *entity.Order) error
 
+	// Save validates and idempotently stores an order, as the NATS subscriber does.
+	// It takes a context, an order entity and the update policy for a changed payload of a stored order
+	// as input parameters.
+	// Returns the outcome, an error wrapping entity.ErrValidation if the order is invalid, an error wrapping
+	// entity.ErrConflict if the payload can't replace the stored order, or an error if the operation fails.
+	Save(ctx context.Context, order *entity.Order, policy entity.UpdatePolicy) (entity.SaveOutcome, error)
+
+	// Update validates and replaces a stored order.
+	// It takes a context, the UID of the order, the new order and the version the stored order must be at
+	// as input parameters, zero matches any version.
+	// Returns an error wrapping entity.ErrValidation if the order is invalid, entity.ErrNotFound
+	// if the order does not exist, an error wrapping entity.ErrVersionMismatch if the order is at another
+	// version, an error wrapping entity.ErrConflict, or an error if the operation fails.
+	Update(ctx context.Context, uid string, order *entity.Order, version int) error
+
+	// Patch applies a JSON Merge Patch (RFC 7396) to a stored order and replaces it with the result.
+	// It takes a context, the UID of the order, the patch and the version the stored order must be at
+	// as input parameters, zero matches any version.
+	// Returns the patched order or the same errors as Update.
+	Patch(ctx context.Context, uid string, patch []byte, version int) (*entity.Order, error)
+
 	// GetByUid retrieves an order by its UID.
 	// It takes a context and a UID string as input parameters.
 	// Returns the order entity or an error if the operation fails.
@@ -25,8 +47,140 @@ type OrderInteractor interface {
 	// Returns a slice of order entities or an error if the operation fails.
 	GetAll(ctx context.Context) ([]*entity.Order, error)
 
-	// Delete deletes an order.
+	// List retrieves a page of orders using keyset pagination.
+	// It takes a context and a list query as input parameters, zero values are replaced with defaults.
+	// Returns the page of orders, an error wrapping entity.ErrValidation if the query is invalid,
+	// or an error if the operation fails.
+	List(ctx context.Context, query entity.OrderListQuery) (*entity.OrderPage, error)
+
+	// Search retrieves a page of orders matching a filter using keyset pagination.
+	// It takes a context and a search query as input parameters, zero values are replaced with defaults.
+	// Returns the page of orders, an error wrapping entity.ErrValidation if the query is invalid,
+	// or an error if the operation fails.
+	Search(ctx context.Context, query entity.OrderSearchQuery) (*entity.OrderPage, error)
+
+	// GetByTrackNumber retrieves the most recent order with a track number.
+	// It takes a context and a track number as input parameters.
+	// Returns the order entity, nil if there is no such order, an error wrapping entity.ErrValidation
+	// if the track number is empty, or an error if the operation fails.
+	GetByTrackNumber(ctx context.Context, trackNumber string) (*entity.Order, error)
+
+	// ListByCustomer retrieves the orders of a customer, most recent first.
+	// It takes a context and a customer ID as input parameters.
+	// Returns a slice of order entities, an error wrapping entity.ErrValidation if the customer ID is empty,
+	// or an error if the operation fails.
+	ListByCustomer(ctx context.Context, customerID string) ([]*entity.Order, error)
+
+	// ListByPhone retrieves the orders delivered to a phone number, most recent first.
+	// It takes a context and a phone number as input parameters.
+	// Returns a slice of order entities, an error wrapping entity.ErrValidation if the phone is empty,
+	// or an error if the operation fails.
+	ListByPhone(ctx context.Context, phone string) ([]*entity.Order, error)
+
+	// ListByEmail retrieves the orders delivered to an email, most recent first.
+	// It takes a context and an email as input parameters.
+	// Returns a slice of order entities, an error wrapping entity.ErrValidation if the email is empty,
+	// or an error if the operation fails.
+	ListByEmail(ctx context.Context, email string) ([]*entity.Order, error)
+
+	// Delete soft-deletes an order and evicts it from the cache.
+	// It takes a context, a UID string, the author and the reason of the deletion and the version
+	// the order must be at as input parameters, zero matches any version.
+	// Returns an error wrapping entity.ErrNotFound if the order does not exist or is already deleted,
+	// an error wrapping entity.ErrVersionMismatch if the order is at another version,
+	// or an error if the operation fails.
+	Delete(ctx context.Context, uid string, deletedBy string, reason string, version int) error
+
+	// Restore restores a soft-deleted order and puts it back into the cache.
+	// It takes a context and a UID string as input parameters.
+	// Returns the restored order, an error wrapping entity.ErrNotFound if the order does not exist
+	// or is not deleted, or an error if the operation fails.
+	Restore(ctx context.Context, uid string) (*entity.Order, error)
+
+	// ListDeleted retrieves a page of soft-deleted orders using keyset pagination.
+	// It takes a context and a list query as input parameters, zero values are replaced with defaults.
+	// Returns the page of orders, an error wrapping entity.ErrValidation if the query is invalid,
+	// or an error if the operation fails.
+	ListDeleted(ctx context.Context, query entity.OrderListQuery) (*entity.OrderPage, error)
+
+	// Purge permanently deletes orders soft-deleted more than olderThan ago.
+	// It takes a context and the retention period as input parameters.
+	// Returns the number of purged orders or an error if the operation fails.
+	Purge(ctx context.Context, olderThan time.Duration) (int64, error)
+
+	// ChangeStatus moves an order to another status following the order lifecycle and records the transition.
+	// It takes a context and a status update as input parameters, moving to the current status is a no-op.
+	// Returns the changed order, an error wrapping entity.ErrValidation if the status is unknown or the actor
+	// is missing, entity.ErrNotFound if the order does not exist, an error wrapping entity.ErrConflict
+	// if the transition is not allowed, or an error if the operation fails.
+	ChangeStatus(ctx context.Context, update entity.StatusUpdate) (*entity.Order, error)
+
+	// StatusHistory retrieves the status transitions of an order, oldest first.
+	// It takes a context and a UID string as input parameters.
+	// Returns a slice of status changes, entity.ErrNotFound if the order does not exist,
+	// or an error if the operation fails.
+	StatusHistory(ctx context.Context, uid string) ([]*entity.StatusChange, error)
+
+	// History retrieves the versions of an order without snapshots, oldest first.
 	// It takes a context and a UID string as input parameters.
-	// Returns an error if the operation fails.
-	Delete(ctx context.Context, uid string) error
+	// Returns a slice of versions, entity.ErrNotFound if the order has no versions,
+	// or an error if the operation fails.
+	History(ctx context.Context, uid string) ([]*entity.OrderVersion, error)
+
+	// Version retrieves a version of an order with its snapshot.
+	// It takes a context, a UID string and a version number as input parameters.
+	// Returns the version, an error wrapping entity.ErrValidation if the number is not positive,
+	// entity.ErrNotFound if the version does not exist, or an error if the operation fails.
+	Version(ctx context.Context, uid string, version int) (*entity.OrderVersion, error)
+
+	// Diff lists the differences between two versions of an order.
+	// It takes a context, a UID string and the numbers of the versions to compare as input parameters.
+	// Returns the differences or the same errors as Version.
+	Diff(ctx context.Context, uid string, from int, to int) (*entity.OrderDiff, error)
+}
+
+// RejectedMessageInteractor defines the interface for rejected message use cases.
+type RejectedMessageInteractor interface {
+	// GetAll retrieves all rejected messages.
+	// It takes a context as an input parameter.
+	// Returns a slice of messages or an error if the operation fails.
+	GetAll(ctx context.Context) ([]*entity.RejectedMessage, error)
+
+	// GetById retrieves a rejected message by its identifier.
+	// It takes a context and a message ID as input parameters.
+	// Returns the message, nil if it does not exist, or an error if the operation fails.
+	GetById(ctx context.Context, id int64) (*entity.RejectedMessage, error)
+
+	// Redrive publishes the raw payload of a rejected message to the subject it was received from again
+	// and removes it from the quarantine store.
+	// Returns entity.ErrNotFound if the message does not exist or an error if the operation fails.
+	Redrive(ctx context.Context, id int64) error
+
+	// Discard removes a rejected message from the quarantine store.
+	// Returns entity.ErrNotFound if the message does not exist or an error if the operation fails.
+	Discard(ctx context.Context, id int64) error
+}
+
+// IngestionInteractor defines the interface for order ingestion use cases.
+type IngestionInteractor interface {
+	// Submit validates an order submitted by an upstream system and stores or publishes it.
+	// It takes a context and an order entity as input parameters.
+	// Returns an error wrapping entity.ErrValidation if the order is invalid or an error if the operation fails.
+	Submit(ctx context.Context, order *entity.Order) error
+
+	// Status retrieves the ingestion record of a submitted order, waiting for the ingestion to finish if asked to.
+	// It takes a context, a UID string and the longest time to wait, at most MaxIngestionWait, as input parameters.
+	// Returns the record, nil if the order has none, or an error if the operation fails.
+	Status(ctx context.Context, uid string, wait time.Duration) (*entity.Ingestion, error)
+}
+
+// CacheInteractor defines the interface for order cache use cases.
+type CacheInteractor interface {
+	// Reconcile compares the order cache with the database and repairs the divergence.
+	// It takes a context as an input parameter.
+	// Returns the outcome of the comparison, which is also returned with an error if the database can't be read.
+	Reconcile(ctx context.Context) (*entity.Reconciliation, error)
+
+	// LastReconciliation returns the outcome of the latest reconciliation, nil if there has been none.
+	LastReconciliation() *entity.Reconciliation
 }
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"L0/internal/cache"
+	"L0/internal/entity"
+	"context"
+	"fmt"
+	"sort"
+)
+
+// GetByTrackNumber retrieves the most recent order with a track number.
+// The cache index is used first, the repository is read only if the cache may miss the order.
+func (u *orderInteractor) GetByTrackNumber(ctx context.Context, trackNumber string) (*entity.Order, error) {
+	if trackNumber == "" {
+		return nil, fmt.Errorf("%w: track number is required", entity.ErrValidation)
+	}
+
+	orders, complete := u.cache.Find(cache.IndexTrackNumber, trackNumber)
+	if len(orders) > 0 {
+		sortByRecency(orders)
+		return orders[0], nil
+	}
+	if complete {
+		return nil, nil
+	}
+
+	page, err := u.repo.Search(ctx, entity.OrderSearchQuery{
+		OrderListQuery: entity.OrderListQuery{Limit: 1, Sort: entity.OrderSortDateCreated, Desc: true},
+		Filter:         entity.OrderFilter{TrackNumber: trackNumber},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't search orders in repository: %w", err)
+	}
+	if len(page.Orders) == 0 {
+		return nil, nil
+	}
+
+	order := page.Orders[0]
+	u.cache.Set(order.OrderUID, order)
+
+	return order, nil
+}
+
+// ListByCustomer retrieves the orders of a customer, most recent first.
+func (u *orderInteractor) ListByCustomer(ctx context.Context, customerID string) ([]*entity.Order, error) {
+	if customerID == "" {
+		return nil, fmt.Errorf("%w: customer id is required", entity.ErrValidation)
+	}
+
+	return u.listIndexed(ctx, cache.IndexCustomerID, customerID, entity.OrderFilter{CustomerID: customerID})
+}
+
+// ListByPhone retrieves the orders delivered to a phone number, most recent first.
+func (u *orderInteractor) ListByPhone(ctx context.Context, phone string) ([]*entity.Order, error) {
+	if phone == "" {
+		return nil, fmt.Errorf("%w: phone is required", entity.ErrValidation)
+	}
+
+	return u.listIndexed(ctx, cache.IndexPhone, phone, entity.OrderFilter{DeliveryPhone: phone})
+}
+
+// ListByEmail retrieves the orders delivered to an email, most recent first.
+func (u *orderInteractor) ListByEmail(ctx context.Context, email string) ([]*entity.Order, error) {
+	if email == "" {
+		return nil, fmt.Errorf("%w: email is required", entity.ErrValidation)
+	}
+
+	return u.listIndexed(ctx, cache.IndexEmail, email, entity.OrderFilter{DeliveryEmail: email})
+}
+
+// listIndexed retrieves the orders with the value of an indexed field, most recent first.
+// The cache answers only while it holds all orders, otherwise it may miss some of them
+// and the repository is read with the equivalent filter page by page.
+func (u *orderInteractor) listIndexed(
+	ctx context.Context,
+	index cache.Index,
+	value string,
+	filter entity.OrderFilter,
+) ([]*entity.Order, error) {
+	orders, complete := u.cache.Find(index, value)
+	if complete {
+		sortByRecency(orders)
+		return orders, nil
+	}
+
+	orders = []*entity.Order{}
+	query := entity.OrderSearchQuery{
+		OrderListQuery: entity.OrderListQuery{Limit: MaxListLimit, Sort: entity.OrderSortDateCreated, Desc: true},
+		Filter:         filter,
+	}
+	for {
+		page, err := u.repo.Search(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("can't search orders in repository: %w", err)
+		}
+		orders = append(orders, page.Orders...)
+		if page.Next == nil {
+			return orders, nil
+		}
+		query.After = page.Next
+	}
+}
+
+// sortByRecency sorts orders by creation date and UID, both descending, as the repository does.
+func sortByRecency(orders []*entity.Order) {
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].DateCreated.Equal(orders[j].DateCreated) {
+			return orders[i].DateCreated.After(orders[j].DateCreated)
+		}
+		return orders[i].OrderUID > orders[j].OrderUID
+	})
+}
@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"L0/internal/cache"
+	"L0/internal/entity"
+	"L0/internal/repository"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+func TestOrderInteractor_GetByTrackNumber(t *testing.T) {
+	type fields struct {
+		orderRepository *repository.MockOrderRepository
+		cache           *cache.MockOrderCache
+	}
+	const track = "WBILMTESTTRACK"
+	older := &entity.Order{OrderUID: "order_uid_1", TrackNumber: track, DateCreated: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	newer := &entity.Order{OrderUID: "order_uid_2", TrackNumber: track, DateCreated: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)}
+	tests := []struct {
+		name        string
+		trackNumber string
+		setup       func(f fields)
+		want        *entity.Order
+		wantErr     error
+		wantAnyErr  bool
+	}{
+		{
+			name:        "success: from cache",
+			trackNumber: track,
+			setup: func(f fields) {
+				f.cache.EXPECT().Find(cache.IndexTrackNumber, track).Return([]*entity.Order{older, newer}, false)
+			},
+			want: newer,
+		},
+		{
+			name:        "success: complete cache misses",
+			trackNumber: track,
+			setup: func(f fields) {
+				f.cache.EXPECT().Find(cache.IndexTrackNumber, track).Return([]*entity.Order{}, true)
+			},
+			want: nil,
+		},
+		{
+			name:        "success: from repository",
+			trackNumber: track,
+			setup: func(f fields) {
+				f.cache.EXPECT().Find(cache.IndexTrackNumber, track).Return([]*entity.Order{}, false)
+				f.orderRepository.EXPECT().Search(gomock.Any(), entity.OrderSearchQuery{
+					OrderListQuery: entity.OrderListQuery{Limit: 1, Sort: entity.OrderSortDateCreated, Desc: true},
+					Filter:         entity.OrderFilter{TrackNumber: track},
+				}).Return(&entity.OrderPage{Orders: []*entity.Order{newer}}, nil)
+				f.cache.EXPECT().Set(newer.OrderUID, newer)
+			},
+			want: newer,
+		},
+		{
+			name:        "success: not found in repository",
+			trackNumber: track,
+			setup: func(f fields) {
+				f.cache.EXPECT().Find(cache.IndexTrackNumber, track).Return([]*entity.Order{}, false)
+				f.orderRepository.EXPECT().Search(gomock.Any(), gomock.Any()).Return(&entity.OrderPage{Orders: []*entity.Order{}}, nil)
+			},
+			want: nil,
+		},
+		{
+			name:    "fail: empty track number",
+			setup:   func(f fields) {},
+			wantErr: entity.ErrValidation,
+		},
+		{
+			name:        "fail: can't search",
+			trackNumber: track,
+			setup: func(f fields) {
+				f.cache.EXPECT().Find(cache.IndexTrackNumber, track).Return([]*entity.Order{}, false)
+				f.orderRepository.EXPECT().Search(gomock.Any(), gomock.Any()).Return(nil, errors.New("db error"))
+			},
+			wantAnyErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			f := fields{
+				orderRepository: repository.NewMockOrderRepository(ctrl),
+				cache:           cache.NewMockOrderCache(ctrl),
+			}
+			u := &orderInteractor{
+				repo:  f.orderRepository,
+				cache: f.cache,
+			}
+
+			tt.setup(f)
+
+			got, err := u.GetByTrackNumber(context.Background(), tt.trackNumber)
+			if tt.wantErr != nil || tt.wantAnyErr {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Errorf("orderInteractor.GetByTrackNumber() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("orderInteractor.GetByTrackNumber() unexpected error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("orderInteractor.GetByTrackNumber() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderInteractor_ListByCustomer(t *testing.T) {
+	type fields struct {
+		orderRepository *repository.MockOrderRepository
+		cache           *cache.MockOrderCache
+	}
+	const customer = "test"
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := &entity.Order{OrderUID: "order_uid_1", CustomerID: customer, DateCreated: date}
+	second := &entity.Order{OrderUID: "order_uid_2", CustomerID: customer, DateCreated: date}
+	third := &entity.Order{OrderUID: "order_uid_3", CustomerID: customer, DateCreated: date.Add(time.Hour)}
+	query := entity.OrderSearchQuery{
+		OrderListQuery: entity.OrderListQuery{Limit: MaxListLimit, Sort: entity.OrderSortDateCreated, Desc: true},
+		Filter:         entity.OrderFilter{CustomerID: customer},
+	}
+	next := entity.CursorFor(third, entity.OrderSortDateCreated, true)
+	tests := []struct {
+		name       string
+		customerID string
+		setup      func(f fields)
+		want       []*entity.Order
+		wantErr    error
+		wantAnyErr bool
+	}{
+		{
+			name:       "success: from complete cache",
+			customerID: customer,
+			setup: func(f fields) {
+				f.cache.EXPECT().Find(cache.IndexCustomerID, customer).Return([]*entity.Order{first, third, second}, true)
+			},
+			want: []*entity.Order{third, second, first},
+		},
+		{
+			name:       "success: from repository page by page",
+			customerID: customer,
+			setup: func(f fields) {
+				f.cache.EXPECT().Find(cache.IndexCustomerID, customer).Return([]*entity.Order{first}, false)
+				f.orderRepository.EXPECT().Search(gomock.Any(), query).Return(&entity.OrderPage{Orders: []*entity.Order{third}, Next: next}, nil)
+				nextQuery := query
+				nextQuery.After = next
+				f.orderRepository.EXPECT().Search(gomock.Any(), nextQuery).Return(&entity.OrderPage{Orders: []*entity.Order{second, first}}, nil)
+			},
+			want: []*entity.Order{third, second, first},
+		},
+		{
+			name:    "fail: empty customer id",
+			setup:   func(f fields) {},
+			wantErr: entity.ErrValidation,
+		},
+		{
+			name:       "fail: can't search",
+			customerID: customer,
+			setup: func(f fields) {
+				f.cache.EXPECT().Find(cache.IndexCustomerID, customer).Return([]*entity.Order{}, false)
+				f.orderRepository.EXPECT().Search(gomock.Any(), query).Return(nil, errors.New("db error"))
+			},
+			wantAnyErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			f := fields{
+				orderRepository: repository.NewMockOrderRepository(ctrl),
+				cache:           cache.NewMockOrderCache(ctrl),
+			}
+			u := &orderInteractor{
+				repo:  f.orderRepository,
+				cache: f.cache,
+			}
+
+			tt.setup(f)
+
+			got, err := u.ListByCustomer(context.Background(), tt.customerID)
+			if tt.wantErr != nil || tt.wantAnyErr {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Errorf("orderInteractor.ListByCustomer() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("orderInteractor.ListByCustomer() unexpected error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderInteractor.ListByCustomer() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderInteractor_ListByContact(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderCache := cache.NewMockOrderCache(ctrl)
+	u := &orderInteractor{cache: orderCache}
+	order := &entity.Order{OrderUID: "order_uid_1"}
+
+	orderCache.EXPECT().Find(cache.IndexPhone, "+9720000000").Return([]*entity.Order{order}, true)
+	orderCache.EXPECT().Find(cache.IndexEmail, "test@gmail.com").Return([]*entity.Order{order}, true)
+
+	if got, err := u.ListByPhone(context.Background(), "+9720000000"); err != nil || len(got) != 1 {
+		t.Errorf("orderInteractor.ListByPhone() = %v, %v", got, err)
+	}
+	if got, err := u.ListByEmail(context.Background(), "test@gmail.com"); err != nil || len(got) != 1 {
+		t.Errorf("orderInteractor.ListByEmail() = %v, %v", got, err)
+	}
+	if _, err := u.ListByEmail(context.Background(), ""); !errors.Is(err, entity.ErrValidation) {
+		t.Errorf("orderInteractor.ListByEmail() error = %v, wantErr %v", err, entity.ErrValidation)
+	}
+}
@@ -4,62 +4,200 @@ package usecase
 import (
 	"L0/internal/cache"
 	"L0/internal/entity"
+	"L0/internal/invalidation"
 	"L0/internal/repository"
+	"L0/internal/utils"
+	"L0/internal/validator"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// OrderLookupTimeout bounds a repository lookup of an uncached order shared by concurrent callers.
+const OrderLookupTimeout = 10 * time.Second
+
+// Limits of the number of orders on a page.
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 500
 )
 
 // orderInteractor implements the OrderInteractor interface.
 type orderInteractor struct {
-	repo  repository.OrderRepository
-	cache cache.Cache
+	repo      repository.OrderRepository
+	cache     cache.OrderCache
+	validator validator.OrderValidator
+	// broadcaster tells the other instances about the changes of cached orders.
+	broadcaster invalidation.Broadcaster
+	// lookups coalesces concurrent repository lookups of one uncached order.
+	lookups singleflight.Group
 }
 
 // NewOrderInteractor creates a new instance of orderInteractor.
-func NewOrderInteractor(repo repository.OrderRepository, cache cache.Cache) *orderInteractor {
+func NewOrderInteractor(
+	repo repository.OrderRepository,
+	cache cache.OrderCache,
+	validator validator.OrderValidator,
+	broadcaster invalidation.Broadcaster,
+) *orderInteractor {
 	return &orderInteractor{
-		repo:  repo,
-		cache: cache,
+		repo:        repo,
+		cache:       cache,
+		validator:   validator,
+		broadcaster: broadcaster,
 	}
 }
 
-// Create creates a new order.
+// Create validates and creates a new order.
 func (u *orderInteractor) Create(ctx context.Context, order *entity.Order) error {
+	err := u.validator.Validate(order)
+	if err != nil {
+		return fmt.Errorf("invalid order: %w", err)
+	}
+
 	id, err := u.repo.Create(ctx, order)
 	if err != nil {
 		return fmt.Errorf("can't create order by repository: %w", err)
 	}
 
 	u.cache.Set(id, order)
+	u.broadcaster.Set(ctx, order)
 
 	return nil
 }
 
-// GetByUid retrieves an order by its UID.
-func (u *orderInteractor) GetByUid(ctx context.Context, uid string) (*entity.Order, error) {
-	orderCache, ok := u.cache.Get(uid)
-	if ok {
-		return orderCache.(*entity.Order), nil
+// Save validates and idempotently stores an order received from HTTP or NATS.
+// The deletion and the version of the payload are ignored, the order gets the stored ones.
+// A created or updated order is put into the cache, unless it is soft-deleted, and broadcast.
+func (u *orderInteractor) Save(ctx context.Context, order *entity.Order, policy entity.UpdatePolicy) (entity.SaveOutcome, error) {
+	// The deletion of an order is changed by Delete and Restore only, the version by the repository
+	order.Deleted = nil
+	order.Version = 0
+
+	err := u.validator.Validate(order)
+	if err != nil {
+		return "", fmt.Errorf("invalid order: %w", err)
 	}
 
-	order, err := u.repo.GetByUid(ctx, uid)
+	outcome, err := u.repo.Save(ctx, order, policy)
 	if err != nil {
-		return nil, fmt.Errorf("can't get order by uid from repository: %w", err)
+		return "", fmt.Errorf("can't save order by repository: %w", err)
+	}
+
+	if outcome != entity.SaveUnchanged && order.Deleted == nil {
+		u.cache.Set(order.OrderUID, order)
+		u.broadcaster.Set(ctx, order)
+	}
+
+	return outcome, nil
+}
+
+// Update validates and replaces a stored order, then refreshes its cache entry.
+// The order UID may be omitted in the payload, otherwise it must match uid.
+func (u *orderInteractor) Update(ctx context.Context, uid string, order *entity.Order, version int) error {
+	if order.OrderUID == "" {
+		order.OrderUID = uid
+	}
+	if order.OrderUID != uid {
+		return fmt.Errorf("%w: order_uid %q does not match %q", entity.ErrValidation, order.OrderUID, uid)
+	}
+	// The deletion of an order is changed by Delete and Restore only
+	order.Deleted = nil
+
+	err := u.validator.Validate(order)
+	if err != nil {
+		return fmt.Errorf("invalid order: %w", err)
+	}
+
+	err = u.repo.Update(ctx, order, version)
+	if err != nil {
+		return fmt.Errorf("can't update order by repository: %w", err)
 	}
 
 	u.cache.Set(uid, order)
+	u.broadcaster.Set(ctx, order)
 
-	return order, nil
+	return nil
+}
+
+// Patch applies a JSON Merge Patch to a stored order and replaces it with the result.
+// The stored order is read from the repository rather than the cache to patch the latest state.
+func (u *orderInteractor) Patch(ctx context.Context, uid string, patch []byte, version int) (*entity.Order, error) {
+	current, err := u.repo.GetByUid(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("can't get order by uid from repository: %w", err)
+	}
+	if current == nil {
+		return nil, entity.ErrNotFound
+	}
+
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return nil, fmt.Errorf("can't marshal order: %w", err)
+	}
+
+	merged, err := utils.MergePatch(doc, patch)
+	if err != nil {
+		return nil, fmt.Errorf("%w: can't apply patch: %v", entity.ErrValidation, err)
+	}
+
+	var order entity.Order
+	err = json.Unmarshal(merged, &order)
+	if err != nil {
+		return nil, fmt.Errorf("%w: patched order is malformed: %v", entity.ErrValidation, err)
+	}
+
+	err = u.Update(ctx, uid, &order, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// GetByUid retrieves an order by its UID.
+// Concurrent lookups of one uncached order share a single repository call,
+// and an order found missing is not looked up again until its negative cache entry expires.
+// The shared call is detached from the caller that started it and bounded by OrderLookupTimeout,
+// so a caller giving up returns on its own and does not fail the others.
+func (u *orderInteractor) GetByUid(ctx context.Context, uid string) (*entity.Order, error) {
+	order, ok := u.cache.Get(uid)
+	if ok {
+		return order, nil
+	}
+	if u.cache.Missing(uid) {
+		return nil, nil
+	}
+
+	lookup := u.lookups.DoChan(uid, func() (interface{}, error) {
+		lookupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), OrderLookupTimeout)
+		defer cancel()
+
+		return u.cache.Fill(uid, func() (*entity.Order, error) {
+			return u.repo.GetByUid(lookupCtx, uid)
+		})
+	})
+
+	select {
+	case result := <-lookup:
+		if result.Err != nil {
+			return nil, fmt.Errorf("can't get order by uid from repository: %w", result.Err)
+		}
+		return result.Val.(*entity.Order), nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("can't get order by uid from repository: %w", ctx.Err())
+	}
 }
 
 // GetAll retrieves all orders.
+// The cache is used only while it holds every order, a bounded cache may keep only a part of them.
 func (u *orderInteractor) GetAll(ctx context.Context) ([]*entity.Order, error) {
-	ordersCache, ok := u.cache.GetAll()
+	orders, ok := u.cache.GetAll()
 	if ok {
-		var orders []*entity.Order
-		for _, order := range ordersCache {
-			orders = append(orders, order.(*entity.Order))
-		}
 		return orders, nil
 	}
 
@@ -71,14 +209,130 @@ func (u *orderInteractor) GetAll(ctx context.Context) ([]*entity.Order, error) {
 	return orders, nil
 }
 
-// Delete deletes an order.
-func (u *orderInteractor) Delete(ctx context.Context, uid string) error {
-	err := u.repo.Delete(ctx, uid)
+// List retrieves a page of orders.
+// Listing always reads the database, since the cache keeps no order of its entries.
+func (u *orderInteractor) List(ctx context.Context, query entity.OrderListQuery) (*entity.OrderPage, error) {
+	err := normalizeListQuery(&query)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := u.repo.List(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("can't list orders from repository: %w", err)
+	}
+
+	return page, nil
+}
+
+// Search retrieves a page of orders matching a filter.
+func (u *orderInteractor) Search(ctx context.Context, query entity.OrderSearchQuery) (*entity.OrderPage, error) {
+	err := normalizeListQuery(&query.OrderListQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := query.Filter.CreatedFrom, query.Filter.CreatedTo
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, fmt.Errorf("%w: created_from must be before created_to", entity.ErrValidation)
+	}
+
+	page, err := u.repo.Search(ctx, query)
 	if err != nil {
+		return nil, fmt.Errorf("can't search orders in repository: %w", err)
+	}
+
+	return page, nil
+}
+
+// normalizeListQuery replaces zero values of a list query with defaults and validates it.
+func normalizeListQuery(query *entity.OrderListQuery) error {
+	if query.Limit == 0 {
+		query.Limit = DefaultListLimit
+	}
+	if query.Limit < 0 || query.Limit > MaxListLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d", entity.ErrValidation, MaxListLimit)
+	}
+
+	if query.Sort == "" {
+		query.Sort = entity.OrderSortDateCreated
+	}
+	if query.Sort != entity.OrderSortDateCreated && query.Sort != entity.OrderSortOrderUID {
+		return fmt.Errorf("%w: unknown sort %q", entity.ErrValidation, query.Sort)
+	}
+
+	if query.After != nil && (query.After.Sort != query.Sort || query.After.Desc != query.Desc) {
+		return fmt.Errorf("%w: cursor belongs to another sort order", entity.ErrValidation)
+	}
+
+	return nil
+}
+
+// Delete soft-deletes an order and evicts it from the cache.
+func (u *orderInteractor) Delete(ctx context.Context, uid string, deletedBy string, reason string, version int) error {
+	err := u.repo.Delete(ctx, uid, deletedBy, reason, version)
+	if err != nil {
+		// The order is gone from the database, so it must not stay in the cache either
+		if errors.Is(err, entity.ErrNotFound) {
+			u.cache.Delete(uid)
+			u.broadcaster.Delete(ctx, uid)
+		}
 		return fmt.Errorf("can't delete order: %w", err)
 	}
 
 	u.cache.Delete(uid)
+	u.broadcaster.Delete(ctx, uid)
 
 	return nil
 }
+
+// Restore restores a soft-deleted order and puts it back into the cache.
+func (u *orderInteractor) Restore(ctx context.Context, uid string) (*entity.Order, error) {
+	err := u.repo.Restore(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("can't restore order: %w", err)
+	}
+
+	order, err := u.repo.GetByUid(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("can't get order by uid from repository: %w", err)
+	}
+	// The order may have been deleted again in the meantime
+	if order == nil {
+		return nil, entity.ErrNotFound
+	}
+
+	u.cache.Set(uid, order)
+	u.broadcaster.Set(ctx, order)
+
+	return order, nil
+}
+
+// ListDeleted retrieves a page of soft-deleted orders.
+func (u *orderInteractor) ListDeleted(ctx context.Context, query entity.OrderListQuery) (*entity.OrderPage, error) {
+	err := normalizeListQuery(&query)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := u.repo.Search(ctx, entity.OrderSearchQuery{
+		OrderListQuery: query,
+		Filter:         entity.OrderFilter{Deleted: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't list deleted orders from repository: %w", err)
+	}
+
+	return page, nil
+}
+
+// Purge permanently deletes orders soft-deleted more than olderThan ago.
+// Deleted orders are not cached, so the cache is left as is.
+func (u *orderInteractor) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	purged, err := u.repo.Purge(ctx, olderThan)
+	if err != nil {
+		return 0, fmt.Errorf("can't purge deleted orders in repository: %w", err)
+	}
+
+	return purged, nil
+}
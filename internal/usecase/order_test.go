@@ -3,10 +3,14 @@ package usecase
 import (
 	"L0/internal/cache"
 	"L0/internal/entity"
+	"L0/internal/invalidation"
 	"L0/internal/repository"
+	"L0/internal/validator"
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
@@ -21,6 +25,192 @@ func MustParseTime(layout string, s string) time.Time {
 	return tt
 }
 
+func TestOrderInteractor_Create(t *testing.T) {
+	type fields struct {
+		orderRepository *repository.MockOrderRepository
+		validator       *validator.MockOrderValidator
+		broadcaster     *invalidation.MockBroadcaster
+	}
+	type args struct {
+		ctx   context.Context
+		order *entity.Order
+	}
+	tests := []struct {
+		name           string
+		args           args
+		setup          func(f fields, a args)
+		wantErr        bool
+		wantValidation bool
+	}{
+		{
+			name: "success",
+			args: args{
+				ctx:   context.Background(),
+				order: &entity.Order{OrderUID: "b563feb7b2b84b6test"},
+			},
+			setup: func(f fields, a args) {
+				f.validator.EXPECT().Validate(a.order).Return(nil)
+				f.orderRepository.EXPECT().Create(a.ctx, a.order).Return(a.order.OrderUID, nil)
+				f.broadcaster.EXPECT().Set(a.ctx, a.order)
+			},
+			wantErr: false,
+		},
+		{
+			name: "fail: invalid order",
+			args: args{
+				ctx:   context.Background(),
+				order: &entity.Order{},
+			},
+			setup: func(f fields, a args) {
+				f.validator.EXPECT().Validate(a.order).Return(&validator.ValidationError{})
+			},
+			wantErr:        true,
+			wantValidation: true,
+		},
+		{
+			name: "fail: can't create order",
+			args: args{
+				ctx:   context.Background(),
+				order: &entity.Order{OrderUID: "b563feb7b2b84b6test"},
+			},
+			setup: func(f fields, a args) {
+				f.validator.EXPECT().Validate(a.order).Return(nil)
+				f.orderRepository.EXPECT().Create(a.ctx, a.order).Return("", fmt.Errorf("some error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			f := fields{
+				orderRepository: repository.NewMockOrderRepository(ctrl),
+				validator:       validator.NewMockOrderValidator(ctrl),
+				broadcaster:     invalidation.NewMockBroadcaster(ctrl),
+			}
+			u := NewOrderInteractor(f.orderRepository, cache.NewOrderCache(cache.Options[*entity.Order]{}, 0), f.validator, f.broadcaster)
+
+			tt.setup(f, tt.args)
+
+			err := u.Create(tt.args.ctx, tt.args.order)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("orderInteractor.Create() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, entity.ErrValidation) != tt.wantValidation {
+				t.Errorf("orderInteractor.Create() error = %v, wantValidation %v", err, tt.wantValidation)
+			}
+		})
+	}
+}
+
+func TestOrderInteractor_Save(t *testing.T) {
+	type fields struct {
+		orderRepository *repository.MockOrderRepository
+		validator       *validator.MockOrderValidator
+		broadcaster     *invalidation.MockBroadcaster
+	}
+	ctx := context.Background()
+	order := &entity.Order{OrderUID: "b563feb7b2b84b6test"}
+	tests := []struct {
+		name           string
+		setup          func(f fields)
+		want           entity.SaveOutcome
+		wantErr        bool
+		wantValidation bool
+	}{
+		{
+			name: "success: created",
+			setup: func(f fields) {
+				f.validator.EXPECT().Validate(order).Return(nil)
+				f.orderRepository.EXPECT().Save(ctx, order, entity.UpdatePolicyReject).Return(entity.SaveCreated, nil)
+				f.broadcaster.EXPECT().Set(ctx, order)
+			},
+			want: entity.SaveCreated,
+		},
+		{
+			name: "success: unchanged",
+			setup: func(f fields) {
+				f.validator.EXPECT().Validate(order).Return(nil)
+				f.orderRepository.EXPECT().Save(ctx, order, entity.UpdatePolicyReject).Return(entity.SaveUnchanged, nil)
+			},
+			want: entity.SaveUnchanged,
+		},
+		{
+			name: "fail: invalid order",
+			setup: func(f fields) {
+				f.validator.EXPECT().Validate(order).Return(&validator.ValidationError{})
+			},
+			wantErr:        true,
+			wantValidation: true,
+		},
+		{
+			name: "fail: conflict",
+			setup: func(f fields) {
+				f.validator.EXPECT().Validate(order).Return(nil)
+				f.orderRepository.EXPECT().Save(ctx, order, entity.UpdatePolicyReject).Return(entity.SaveOutcome(""), entity.ErrConflict)
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			f := fields{
+				orderRepository: repository.NewMockOrderRepository(ctrl),
+				validator:       validator.NewMockOrderValidator(ctrl),
+				broadcaster:     invalidation.NewMockBroadcaster(ctrl),
+			}
+			u := NewOrderInteractor(f.orderRepository, cache.NewOrderCache(cache.Options[*entity.Order]{}, 0), f.validator, f.broadcaster)
+
+			tt.setup(f)
+
+			got, err := u.Save(ctx, order, entity.UpdatePolicyReject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("orderInteractor.Save() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, entity.ErrValidation) != tt.wantValidation {
+				t.Errorf("orderInteractor.Save() error = %v, wantValidation %v", err, tt.wantValidation)
+			}
+			if got != tt.want {
+				t.Errorf("orderInteractor.Save() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderInteractor_Save_UntrustedPayload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := repository.NewMockOrderRepository(ctrl)
+	val := validator.NewMockOrderValidator(ctrl)
+	broadcaster := invalidation.NewMockBroadcaster(ctrl)
+	u := NewOrderInteractor(repo, cache.NewOrderCache(cache.Options[*entity.Order]{}, 0), val, broadcaster)
+
+	// A payload can't soft-delete a new order or choose its version
+	order := &entity.Order{
+		OrderUID: "b563feb7b2b84b6test",
+		Version:  7,
+		Deleted:  &entity.OrderDeletion{DeletedBy: "publisher"},
+	}
+	val.EXPECT().Validate(order).Return(nil)
+	repo.EXPECT().Save(gomock.Any(), order, entity.UpdatePolicyUpdate).DoAndReturn(
+		func(ctx context.Context, order *entity.Order, policy entity.UpdatePolicy) (entity.SaveOutcome, error) {
+			if order.Deleted != nil || order.Version != 0 {
+				t.Errorf("orderInteractor.Save() stored deleted = %v, version = %d", order.Deleted, order.Version)
+			}
+			order.Version = 1
+			return entity.SaveCreated, nil
+		})
+	broadcaster.EXPECT().Set(gomock.Any(), order)
+
+	_, err := u.Save(context.Background(), order, entity.UpdatePolicyUpdate)
+	if err != nil {
+		t.Errorf("orderInteractor.Save() error = %v", err)
+	}
+	if cached, ok := u.cache.Get(order.OrderUID); !ok || cached.Version != 1 {
+		t.Errorf("orderInteractor.Save() cached = %v, %v, want version 1", cached, ok)
+	}
+}
+
 func Test_GetByUid(t *testing.T) {
 	type fields struct {
 		orderRepository *repository.MockOrderRepository
@@ -141,7 +331,7 @@ func Test_GetByUid(t *testing.T) {
 					DateCreated:       MustParseTime(time.RFC3339, "2021-11-26T06:22:19Z"),
 					OofShard:          "1",
 				}
-				f.orderRepository.EXPECT().GetByUid(a.ctx, a.uid).Return(order, nil)
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), a.uid).Return(order, nil)
 			},
 			wantErr: false,
 		},
@@ -153,10 +343,22 @@ func Test_GetByUid(t *testing.T) {
 			},
 			want: nil,
 			setup: func(a args, f fields) {
-				f.orderRepository.EXPECT().GetByUid(a.ctx, a.uid).Return(nil, fmt.Errorf("can't get order by uid from repository"))
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), a.uid).Return(nil, fmt.Errorf("can't get order by uid from repository"))
 			},
 			wantErr: true,
 		},
+		{
+			name: "success: missing order is not cached",
+			args: args{
+				ctx: context.Background(),
+				uid: "b563feb7b2b84b6test",
+			},
+			want: nil,
+			setup: func(a args, f fields) {
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), a.uid).Return(nil, nil)
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -165,7 +367,8 @@ func Test_GetByUid(t *testing.T) {
 				orderRepository: repository.NewMockOrderRepository(ctrl),
 			}
 			u := &orderInteractor{
-				repo: f.orderRepository,
+				repo:  f.orderRepository,
+				cache: cache.NewOrderCache(cache.Options[*entity.Order]{}, 0),
 			}
 
 			tt.setup(tt.args, f)
@@ -178,14 +381,188 @@ func Test_GetByUid(t *testing.T) {
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("userInteractor.GetUser() = %v, want %v", got, tt.want)
 			}
+			if _, cached := u.cache.Get(tt.args.uid); cached != (tt.want != nil) {
+				t.Errorf("userInteractor.GetUser() cached = %v, want %v", cached, tt.want != nil)
+			}
 		})
 	}
 }
 
+func Test_GetByUid_Coalesced(t *testing.T) {
+	const uid = "b563feb7b2b84b6test"
+	const callers = 20
+
+	ctrl := gomock.NewController(t)
+	repo := repository.NewMockOrderRepository(ctrl)
+	u := &orderInteractor{
+		repo:  repo,
+		cache: cache.NewOrderCache(cache.Options[*entity.Order]{}, time.Minute),
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	repo.EXPECT().GetByUid(gomock.Any(), uid).DoAndReturn(func(ctx context.Context, uid string) (*entity.Order, error) {
+		close(started)
+		<-release
+		return &entity.Order{OrderUID: uid}, nil
+	}).Times(1)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			order, err := u.GetByUid(context.Background(), uid)
+			if err == nil && (order == nil || order.OrderUID != uid) {
+				err = fmt.Errorf("got order %v, want %s", order, uid)
+			}
+			errs <- err
+		}()
+	}
+
+	// Give the callers time to join the lookup in flight before it completes
+	<-started
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("orderInteractor.GetByUid() error = %v", err)
+		}
+	}
+}
+
+func Test_GetByUid_CallerCanceled(t *testing.T) {
+	const uid = "b563feb7b2b84b6test"
+
+	ctrl := gomock.NewController(t)
+	repo := repository.NewMockOrderRepository(ctrl)
+	u := &orderInteractor{
+		repo:  repo,
+		cache: cache.NewOrderCache(cache.Options[*entity.Order]{}, time.Minute),
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	repo.EXPECT().GetByUid(gomock.Any(), uid).DoAndReturn(func(ctx context.Context, uid string) (*entity.Order, error) {
+		close(started)
+		<-release
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return &entity.Order{OrderUID: uid}, nil
+	}).Times(1)
+
+	// The first caller starts the lookup and gives up while the second one waits for it
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := u.GetByUid(ctx, uid)
+		first <- err
+	}()
+	<-started
+
+	second := make(chan *entity.Order, 1)
+	go func() {
+		order, err := u.GetByUid(context.Background(), uid)
+		if err != nil {
+			t.Errorf("orderInteractor.GetByUid() error = %v", err)
+		}
+		second <- order
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("orderInteractor.GetByUid() error = %v, want %v", err, context.Canceled)
+	}
+
+	close(release)
+	if order := <-second; order == nil || order.OrderUID != uid {
+		t.Errorf("orderInteractor.GetByUid() = %v, want order %s", order, uid)
+	}
+	if _, ok := u.cache.Get(uid); !ok {
+		t.Errorf("orderInteractor.GetByUid() did not cache the order")
+	}
+}
+
+func Test_GetByUid_DeletedDuringLookup(t *testing.T) {
+	const uid = "b563feb7b2b84b6test"
+
+	ctrl := gomock.NewController(t)
+	repo := repository.NewMockOrderRepository(ctrl)
+	broadcaster := invalidation.NewMockBroadcaster(ctrl)
+	u := &orderInteractor{
+		repo:        repo,
+		cache:       cache.NewOrderCache(cache.Options[*entity.Order]{}, time.Minute),
+		broadcaster: broadcaster,
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	repo.EXPECT().GetByUid(gomock.Any(), uid).DoAndReturn(func(ctx context.Context, uid string) (*entity.Order, error) {
+		close(started)
+		<-release
+		return &entity.Order{OrderUID: uid, Version: 1}, nil
+	})
+	repo.EXPECT().Delete(gomock.Any(), uid, "admin", "", 1).Return(nil)
+	broadcaster.EXPECT().Delete(gomock.Any(), uid)
+
+	lookup := make(chan error, 1)
+	go func() {
+		_, err := u.GetByUid(context.Background(), uid)
+		lookup <- err
+	}()
+	<-started
+
+	// The lookup has read the order before the deletion and must not put it back
+	if err := u.Delete(context.Background(), uid, "admin", "", 1); err != nil {
+		t.Errorf("orderInteractor.Delete() error = %v", err)
+	}
+	close(release)
+	if err := <-lookup; err != nil {
+		t.Errorf("orderInteractor.GetByUid() error = %v", err)
+	}
+
+	if _, ok := u.cache.Get(uid); ok {
+		t.Errorf("orderInteractor.GetByUid() cached an order deleted during the lookup")
+	}
+}
+
+func Test_GetByUid_Missing(t *testing.T) {
+	const uid = "b563feb7b2b84b6test"
+
+	ctrl := gomock.NewController(t)
+	repo := repository.NewMockOrderRepository(ctrl)
+	u := &orderInteractor{
+		repo:  repo,
+		cache: cache.NewOrderCache(cache.Options[*entity.Order]{}, time.Minute),
+	}
+
+	repo.EXPECT().GetByUid(gomock.Any(), uid).Return(nil, nil).Times(1)
+
+	for i := 0; i < 2; i++ {
+		order, err := u.GetByUid(context.Background(), uid)
+		if err != nil || order != nil {
+			t.Errorf("orderInteractor.GetByUid() = %v, %v, want nil, nil", order, err)
+		}
+	}
+
+	// A stored order replaces the negative entry
+	u.cache.Set(uid, &entity.Order{OrderUID: uid})
+	order, err := u.GetByUid(context.Background(), uid)
+	if err != nil || order == nil {
+		t.Errorf("orderInteractor.GetByUid() = %v, %v, want order %s", order, err, uid)
+	}
+}
+
 func TestOrderInteractor_GetAll(t *testing.T) {
 	type fields struct {
 		orderRepository *repository.MockOrderRepository
-		cache           *cache.MockCache
+		cache           *cache.MockOrderCache
 	}
 	type args struct {
 		ctx context.Context
@@ -211,11 +588,7 @@ func TestOrderInteractor_GetAll(t *testing.T) {
 						OrderUID: "b563feb7b2b84b6test2",
 					},
 				}
-				interfaceSlice := make([]interface{}, len(orders))
-				for i, v := range orders {
-					interfaceSlice[i] = v
-				}
-				f.cache.EXPECT().GetAll().Return(interfaceSlice, true)
+				f.cache.EXPECT().GetAll().Return(orders, true)
 			},
 			want:    []*entity.Order{{OrderUID: "b563feb7b2b84b6test1"}, {OrderUID: "b563feb7b2b84b6test2"}},
 			wantErr: false,
@@ -234,7 +607,7 @@ func TestOrderInteractor_GetAll(t *testing.T) {
 						OrderUID: "b563feb7b2b84b6test2",
 					},
 				}
-				f.cache.EXPECT().GetAll().Return([]interface{}{}, false)
+				f.cache.EXPECT().GetAll().Return([]*entity.Order{}, false)
 				f.orderRepository.EXPECT().GetAll(a.ctx).Return(orders, nil)
 			},
 			want:    []*entity.Order{{OrderUID: "b563feb7b2b84b6test1"}, {OrderUID: "b563feb7b2b84b6test2"}},
@@ -258,7 +631,7 @@ func TestOrderInteractor_GetAll(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			f := fields{
 				orderRepository: repository.NewMockOrderRepository(ctrl),
-				cache:           cache.NewMockCache(ctrl),
+				cache:           cache.NewMockOrderCache(ctrl),
 			}
 			u := &orderInteractor{
 				repo:  f.orderRepository,
@@ -283,7 +656,8 @@ func TestOrderInteractor_GetAll(t *testing.T) {
 func TestOrderInteractor_Delete(t *testing.T) {
 	type fields struct {
 		orderRepository *repository.MockOrderRepository
-		cache           *cache.MockCache
+		cache           *cache.MockOrderCache
+		broadcaster     *invalidation.MockBroadcaster
 	}
 	type args struct {
 		ctx context.Context
@@ -302,8 +676,9 @@ func TestOrderInteractor_Delete(t *testing.T) {
 				uid: "b563feb7b2b84b6test",
 			},
 			setup: func(f fields, a args) {
-				f.orderRepository.EXPECT().Delete(a.ctx, a.uid).Return(nil)
+				f.orderRepository.EXPECT().Delete(a.ctx, a.uid, "admin", "duplicate", 1).Return(nil)
 				f.cache.EXPECT().Delete(a.uid)
+				f.broadcaster.EXPECT().Delete(a.ctx, a.uid)
 			},
 			wantErr: false,
 		},
@@ -314,7 +689,20 @@ func TestOrderInteractor_Delete(t *testing.T) {
 				uid: "b563feb7b2b84b6test",
 			},
 			setup: func(f fields, a args) {
-				f.orderRepository.EXPECT().Delete(a.ctx, a.uid).Return(fmt.Errorf("some error"))
+				f.orderRepository.EXPECT().Delete(a.ctx, a.uid, "admin", "duplicate", 1).Return(fmt.Errorf("some error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "fail: not found evicts cache",
+			args: args{
+				ctx: context.Background(),
+				uid: "b563feb7b2b84b6test",
+			},
+			setup: func(f fields, a args) {
+				f.orderRepository.EXPECT().Delete(a.ctx, a.uid, "admin", "duplicate", 1).Return(entity.ErrNotFound)
+				f.cache.EXPECT().Delete(a.uid)
+				f.broadcaster.EXPECT().Delete(a.ctx, a.uid)
 			},
 			wantErr: true,
 		},
@@ -324,19 +712,562 @@ func TestOrderInteractor_Delete(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			f := fields{
 				orderRepository: repository.NewMockOrderRepository(ctrl),
-				cache:           cache.NewMockCache(ctrl),
+				cache:           cache.NewMockOrderCache(ctrl),
+				broadcaster:     invalidation.NewMockBroadcaster(ctrl),
 			}
 			u := &orderInteractor{
-				repo:  f.orderRepository,
-				cache: f.cache,
+				repo:        f.orderRepository,
+				cache:       f.cache,
+				broadcaster: f.broadcaster,
 			}
 
 			tt.setup(f, tt.args)
 
-			err := u.Delete(tt.args.ctx, tt.args.uid)
+			err := u.Delete(tt.args.ctx, tt.args.uid, "admin", "duplicate", 1)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("userInteractor.Delete() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestOrderInteractor_Restore(t *testing.T) {
+	type fields struct {
+		orderRepository *repository.MockOrderRepository
+		cache           *cache.MockOrderCache
+		broadcaster     *invalidation.MockBroadcaster
+	}
+	order := &entity.Order{OrderUID: "b563feb7b2b84b6test"}
+	tests := []struct {
+		name         string
+		setup        func(f fields)
+		want         *entity.Order
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{
+			name: "success",
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().Restore(gomock.Any(), "b563feb7b2b84b6test").Return(nil)
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), "b563feb7b2b84b6test").Return(order, nil)
+				f.cache.EXPECT().Set("b563feb7b2b84b6test", order)
+				f.broadcaster.EXPECT().Set(gomock.Any(), order)
+			},
+			want: order,
+		},
+		{
+			name: "fail: not found",
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().Restore(gomock.Any(), "b563feb7b2b84b6test").Return(entity.ErrNotFound)
+			},
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		{
+			name: "fail: deleted again",
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().Restore(gomock.Any(), "b563feb7b2b84b6test").Return(nil)
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), "b563feb7b2b84b6test").Return(nil, nil)
+			},
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		{
+			name: "fail: can't get restored order",
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().Restore(gomock.Any(), "b563feb7b2b84b6test").Return(nil)
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), "b563feb7b2b84b6test").Return(nil, fmt.Errorf("some error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			f := fields{
+				orderRepository: repository.NewMockOrderRepository(ctrl),
+				cache:           cache.NewMockOrderCache(ctrl),
+				broadcaster:     invalidation.NewMockBroadcaster(ctrl),
+			}
+			u := &orderInteractor{
+				repo:        f.orderRepository,
+				cache:       f.cache,
+				broadcaster: f.broadcaster,
+			}
+
+			tt.setup(f)
+
+			got, err := u.Restore(context.Background(), "b563feb7b2b84b6test")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("orderInteractor.Restore() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if errors.Is(err, entity.ErrNotFound) != tt.wantNotFound {
+				t.Errorf("orderInteractor.Restore() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderInteractor.Restore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderInteractor_ListDeleted(t *testing.T) {
+	type fields struct {
+		orderRepository *repository.MockOrderRepository
+	}
+	page := &entity.OrderPage{Orders: []*entity.Order{{OrderUID: "b563feb7b2b84b6test"}}}
+	tests := []struct {
+		name           string
+		query          entity.OrderListQuery
+		setup          func(f fields)
+		want           *entity.OrderPage
+		wantErr        bool
+		wantValidation bool
+	}{
+		{
+			name:  "success: defaults",
+			query: entity.OrderListQuery{},
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().Search(gomock.Any(), entity.OrderSearchQuery{
+					OrderListQuery: entity.OrderListQuery{
+						Limit: DefaultListLimit,
+						Sort:  entity.OrderSortDateCreated,
+					},
+					Filter: entity.OrderFilter{Deleted: true},
+				}).Return(page, nil)
+			},
+			want: page,
+		},
+		{
+			name:           "fail: limit too large",
+			query:          entity.OrderListQuery{Limit: MaxListLimit + 1},
+			setup:          func(f fields) {},
+			wantErr:        true,
+			wantValidation: true,
+		},
+		{
+			name:  "fail: can't list orders",
+			query: entity.OrderListQuery{},
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().Search(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("some error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			f := fields{
+				orderRepository: repository.NewMockOrderRepository(ctrl),
+			}
+			u := &orderInteractor{
+				repo: f.orderRepository,
+			}
+
+			tt.setup(f)
+
+			got, err := u.ListDeleted(context.Background(), tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("orderInteractor.ListDeleted() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if errors.Is(err, entity.ErrValidation) != tt.wantValidation {
+				t.Errorf("orderInteractor.ListDeleted() error = %v, wantValidation %v", err, tt.wantValidation)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderInteractor.ListDeleted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderInteractor_List(t *testing.T) {
+	type fields struct {
+		orderRepository *repository.MockOrderRepository
+	}
+	page := &entity.OrderPage{Orders: []*entity.Order{{OrderUID: "b563feb7b2b84b6test"}}}
+	tests := []struct {
+		name           string
+		query          entity.OrderListQuery
+		setup          func(f fields)
+		want           *entity.OrderPage
+		wantErr        bool
+		wantValidation bool
+	}{
+		{
+			name:  "success: defaults",
+			query: entity.OrderListQuery{},
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().List(gomock.Any(), entity.OrderListQuery{
+					Limit: DefaultListLimit,
+					Sort:  entity.OrderSortDateCreated,
+				}).Return(page, nil)
+			},
+			want: page,
+		},
+		{
+			name: "success: cursor of the same sort",
+			query: entity.OrderListQuery{
+				Limit: 10,
+				Sort:  entity.OrderSortOrderUID,
+				Desc:  true,
+				After: &entity.OrderCursor{Sort: entity.OrderSortOrderUID, Desc: true, OrderUID: "a"},
+			},
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().List(gomock.Any(), gomock.Any()).Return(page, nil)
+			},
+			want: page,
+		},
+		{
+			name:           "fail: limit too large",
+			query:          entity.OrderListQuery{Limit: MaxListLimit + 1},
+			setup:          func(f fields) {},
+			wantErr:        true,
+			wantValidation: true,
+		},
+		{
+			name:           "fail: unknown sort",
+			query:          entity.OrderListQuery{Sort: "price"},
+			setup:          func(f fields) {},
+			wantErr:        true,
+			wantValidation: true,
+		},
+		{
+			name: "fail: cursor of another sort",
+			query: entity.OrderListQuery{
+				Sort:  entity.OrderSortOrderUID,
+				After: &entity.OrderCursor{Sort: entity.OrderSortDateCreated, OrderUID: "a"},
+			},
+			setup:          func(f fields) {},
+			wantErr:        true,
+			wantValidation: true,
+		},
+		{
+			name:  "fail: can't list orders",
+			query: entity.OrderListQuery{},
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("some error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			f := fields{
+				orderRepository: repository.NewMockOrderRepository(ctrl),
+			}
+			u := &orderInteractor{
+				repo: f.orderRepository,
+			}
+
+			tt.setup(f)
+
+			got, err := u.List(context.Background(), tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("orderInteractor.List() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if errors.Is(err, entity.ErrValidation) != tt.wantValidation {
+				t.Errorf("orderInteractor.List() error = %v, wantValidation %v", err, tt.wantValidation)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderInteractor.List() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderInteractor_Search(t *testing.T) {
+	type fields struct {
+		orderRepository *repository.MockOrderRepository
+	}
+	from := MustParseTime(time.RFC3339, "2021-11-01T00:00:00Z")
+	to := MustParseTime(time.RFC3339, "2021-12-01T00:00:00Z")
+	page := &entity.OrderPage{Orders: []*entity.Order{{OrderUID: "b563feb7b2b84b6test"}}}
+	tests := []struct {
+		name           string
+		query          entity.OrderSearchQuery
+		setup          func(f fields)
+		want           *entity.OrderPage
+		wantErr        bool
+		wantValidation bool
+	}{
+		{
+			name:  "success: defaults",
+			query: entity.OrderSearchQuery{Filter: entity.OrderFilter{CustomerID: "test", CreatedFrom: &from, CreatedTo: &to}},
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().Search(gomock.Any(), entity.OrderSearchQuery{
+					OrderListQuery: entity.OrderListQuery{Limit: DefaultListLimit, Sort: entity.OrderSortDateCreated},
+					Filter:         entity.OrderFilter{CustomerID: "test", CreatedFrom: &from, CreatedTo: &to},
+				}).Return(page, nil)
+			},
+			want: page,
+		},
+		{
+			name:           "fail: empty date range",
+			query:          entity.OrderSearchQuery{Filter: entity.OrderFilter{CreatedFrom: &to, CreatedTo: &from}},
+			setup:          func(f fields) {},
+			wantErr:        true,
+			wantValidation: true,
+		},
+		{
+			name:           "fail: invalid limit",
+			query:          entity.OrderSearchQuery{OrderListQuery: entity.OrderListQuery{Limit: -1}},
+			setup:          func(f fields) {},
+			wantErr:        true,
+			wantValidation: true,
+		},
+		{
+			name:  "fail: can't search orders",
+			query: entity.OrderSearchQuery{},
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().Search(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("some error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			f := fields{
+				orderRepository: repository.NewMockOrderRepository(ctrl),
+			}
+			u := &orderInteractor{
+				repo: f.orderRepository,
+			}
+
+			tt.setup(f)
+
+			got, err := u.Search(context.Background(), tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("orderInteractor.Search() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if errors.Is(err, entity.ErrValidation) != tt.wantValidation {
+				t.Errorf("orderInteractor.Search() error = %v, wantValidation %v", err, tt.wantValidation)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderInteractor.Search() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderInteractor_Update(t *testing.T) {
+	type fields struct {
+		orderRepository *repository.MockOrderRepository
+		validator       *validator.MockOrderValidator
+		cache           *cache.MockOrderCache
+		broadcaster     *invalidation.MockBroadcaster
+	}
+	tests := []struct {
+		name           string
+		uid            string
+		order          *entity.Order
+		setup          func(f fields, order *entity.Order)
+		wantErr        bool
+		wantValidation bool
+		wantNotFound   bool
+		wantMismatch   bool
+	}{
+		{
+			name:  "success: uid taken from path",
+			uid:   "b563feb7b2b84b6test",
+			order: &entity.Order{TrackNumber: "WBILMTESTTRACK"},
+			setup: func(f fields, order *entity.Order) {
+				f.validator.EXPECT().Validate(order).Return(nil)
+				f.orderRepository.EXPECT().Update(gomock.Any(), &entity.Order{OrderUID: "b563feb7b2b84b6test", TrackNumber: "WBILMTESTTRACK"}, 2).Return(nil)
+				f.cache.EXPECT().Set("b563feb7b2b84b6test", order)
+				f.broadcaster.EXPECT().Set(gomock.Any(), order)
+			},
+		},
+		{
+			name:           "fail: uid mismatch",
+			uid:            "b563feb7b2b84b6test",
+			order:          &entity.Order{OrderUID: "other"},
+			setup:          func(f fields, order *entity.Order) {},
+			wantErr:        true,
+			wantValidation: true,
+		},
+		{
+			name:  "fail: invalid order",
+			uid:   "b563feb7b2b84b6test",
+			order: &entity.Order{OrderUID: "b563feb7b2b84b6test"},
+			setup: func(f fields, order *entity.Order) {
+				f.validator.EXPECT().Validate(order).Return(&validator.ValidationError{})
+			},
+			wantErr:        true,
+			wantValidation: true,
+		},
+		{
+			name:  "fail: not found",
+			uid:   "b563feb7b2b84b6test",
+			order: &entity.Order{OrderUID: "b563feb7b2b84b6test"},
+			setup: func(f fields, order *entity.Order) {
+				f.validator.EXPECT().Validate(order).Return(nil)
+				f.orderRepository.EXPECT().Update(gomock.Any(), order, 2).Return(entity.ErrNotFound)
+			},
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		{
+			name:  "fail: version mismatch",
+			uid:   "b563feb7b2b84b6test",
+			order: &entity.Order{OrderUID: "b563feb7b2b84b6test"},
+			setup: func(f fields, order *entity.Order) {
+				f.validator.EXPECT().Validate(order).Return(nil)
+				f.orderRepository.EXPECT().Update(gomock.Any(), order, 2).Return(fmt.Errorf("%w: order is at version 3", entity.ErrVersionMismatch))
+			},
+			wantErr:      true,
+			wantMismatch: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			f := fields{
+				orderRepository: repository.NewMockOrderRepository(ctrl),
+				validator:       validator.NewMockOrderValidator(ctrl),
+				cache:           cache.NewMockOrderCache(ctrl),
+				broadcaster:     invalidation.NewMockBroadcaster(ctrl),
+			}
+			u := NewOrderInteractor(f.orderRepository, f.cache, f.validator, f.broadcaster)
+
+			tt.setup(f, tt.order)
+
+			err := u.Update(context.Background(), tt.uid, tt.order, 2)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("orderInteractor.Update() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, entity.ErrValidation) != tt.wantValidation {
+				t.Errorf("orderInteractor.Update() error = %v, wantValidation %v", err, tt.wantValidation)
+			}
+			if errors.Is(err, entity.ErrNotFound) != tt.wantNotFound {
+				t.Errorf("orderInteractor.Update() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if errors.Is(err, entity.ErrVersionMismatch) != tt.wantMismatch {
+				t.Errorf("orderInteractor.Update() error = %v, wantMismatch %v", err, tt.wantMismatch)
+			}
+		})
+	}
+}
+
+func TestOrderInteractor_Patch(t *testing.T) {
+	type fields struct {
+		orderRepository *repository.MockOrderRepository
+		validator       *validator.MockOrderValidator
+		cache           *cache.MockOrderCache
+		broadcaster     *invalidation.MockBroadcaster
+	}
+	stored := func() *entity.Order {
+		return &entity.Order{
+			OrderUID:    "b563feb7b2b84b6test",
+			TrackNumber: "WBILMTESTTRACK",
+			Locale:      "en",
+			Delivery:    entity.Delivery{Name: "Test Testov", City: "Kiryat Mozkin"},
+			Items:       []entity.Item{{Rid: "ab4219087a764ae0btest", TrackNumber: "WBILMTESTTRACK"}},
+		}
+	}
+	tests := []struct {
+		name           string
+		patch          string
+		setup          func(f fields)
+		want           *entity.Order
+		wantErr        bool
+		wantValidation bool
+		wantNotFound   bool
+	}{
+		{
+			name:  "success",
+			patch: `{"locale":"ru","delivery":{"city":"Moscow"},"internal_signature":null}`,
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), "b563feb7b2b84b6test").Return(stored(), nil)
+				f.validator.EXPECT().Validate(gomock.Any()).Return(nil)
+				f.orderRepository.EXPECT().Update(gomock.Any(), gomock.Any(), 2).Return(nil)
+				f.cache.EXPECT().Set("b563feb7b2b84b6test", gomock.Any())
+				f.broadcaster.EXPECT().Set(gomock.Any(), gomock.Any())
+			},
+			want: func() *entity.Order {
+				order := stored()
+				order.Locale = "ru"
+				order.Delivery.City = "Moscow"
+				return order
+			}(),
+		},
+		{
+			name:  "fail: not found",
+			patch: `{"locale":"ru"}`,
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), "b563feb7b2b84b6test").Return(nil, nil)
+			},
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		{
+			name:  "fail: malformed patch",
+			patch: `{"locale":`,
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), "b563feb7b2b84b6test").Return(stored(), nil)
+			},
+			wantErr:        true,
+			wantValidation: true,
+		},
+		{
+			name:  "fail: patch changes field type",
+			patch: `{"sm_id":"ninety nine"}`,
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), "b563feb7b2b84b6test").Return(stored(), nil)
+			},
+			wantErr:        true,
+			wantValidation: true,
+		},
+		{
+			name:  "fail: patch changes uid",
+			patch: `{"order_uid":"other"}`,
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), "b563feb7b2b84b6test").Return(stored(), nil)
+			},
+			wantErr:        true,
+			wantValidation: true,
+		},
+		{
+			name:  "fail: can't get order",
+			patch: `{"locale":"ru"}`,
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), "b563feb7b2b84b6test").Return(nil, fmt.Errorf("some error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			f := fields{
+				orderRepository: repository.NewMockOrderRepository(ctrl),
+				validator:       validator.NewMockOrderValidator(ctrl),
+				cache:           cache.NewMockOrderCache(ctrl),
+				broadcaster:     invalidation.NewMockBroadcaster(ctrl),
+			}
+			u := NewOrderInteractor(f.orderRepository, f.cache, f.validator, f.broadcaster)
+
+			tt.setup(f)
+
+			got, err := u.Patch(context.Background(), "b563feb7b2b84b6test", []byte(tt.patch), 2)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("orderInteractor.Patch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if errors.Is(err, entity.ErrValidation) != tt.wantValidation {
+				t.Errorf("orderInteractor.Patch() error = %v, wantValidation %v", err, tt.wantValidation)
+			}
+			if errors.Is(err, entity.ErrNotFound) != tt.wantNotFound {
+				t.Errorf("orderInteractor.Patch() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderInteractor.Patch() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"L0/internal/entity"
+	"L0/internal/nats"
+	"L0/internal/repository"
+	"context"
+	"fmt"
+)
+
+// rejectedMessageInteractor implements the RejectedMessageInteractor interface.
+type rejectedMessageInteractor struct {
+	repo        repository.RejectedMessageRepository
+	natsService nats.NATSService
+}
+
+// NewRejectedMessageInteractor creates a new instance of rejectedMessageInteractor.
+func NewRejectedMessageInteractor(repo repository.RejectedMessageRepository, natsService nats.NATSService) *rejectedMessageInteractor {
+	return &rejectedMessageInteractor{
+		repo:        repo,
+		natsService: natsService,
+	}
+}
+
+// GetAll retrieves all rejected messages.
+func (u *rejectedMessageInteractor) GetAll(ctx context.Context) ([]*entity.RejectedMessage, error) {
+	msgs, err := u.repo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't get all rejected messages from repository: %w", err)
+	}
+
+	return msgs, nil
+}
+
+// GetById retrieves a rejected message by its identifier.
+func (u *rejectedMessageInteractor) GetById(ctx context.Context, id int64) (*entity.RejectedMessage, error) {
+	msg, err := u.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("can't get rejected message from repository: %w", err)
+	}
+
+	return msg, nil
+}
+
+// Redrive publishes a rejected message again and removes it from the quarantine store.
+func (u *rejectedMessageInteractor) Redrive(ctx context.Context, id int64) error {
+	msg, err := u.repo.GetById(ctx, id)
+	if err != nil {
+		return fmt.Errorf("can't get rejected message from repository: %w", err)
+	}
+	if msg == nil {
+		return entity.ErrNotFound
+	}
+
+	err = u.natsService.PublishTo(msg.Subject, msg.Data)
+	if err != nil {
+		return fmt.Errorf("can't redrive rejected message: %w", err)
+	}
+
+	err = u.repo.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("can't delete redriven message: %w", err)
+	}
+
+	return nil
+}
+
+// Discard removes a rejected message from the quarantine store.
+func (u *rejectedMessageInteractor) Discard(ctx context.Context, id int64) error {
+	err := u.repo.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("can't discard rejected message: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"L0/internal/entity"
+	"L0/internal/nats"
+	"L0/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+func TestRejectedMessageInteractor_Redrive(t *testing.T) {
+	type fields struct {
+		repo        *repository.MockRejectedMessageRepository
+		natsService *nats.MockNATSService
+	}
+	type args struct {
+		ctx context.Context
+		id  int64
+	}
+	tests := []struct {
+		name         string
+		args         args
+		setup        func(f fields, a args)
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{
+			name: "success",
+			args: args{
+				ctx: context.Background(),
+				id:  1,
+			},
+			setup: func(f fields, a args) {
+				f.repo.EXPECT().GetById(a.ctx, a.id).Return(&entity.RejectedMessage{ID: a.id, Subject: "orders", Data: []byte("data")}, nil)
+				f.natsService.EXPECT().PublishTo("orders", []byte("data")).Return(nil)
+				f.repo.EXPECT().Delete(a.ctx, a.id).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "fail: not found",
+			args: args{
+				ctx: context.Background(),
+				id:  1,
+			},
+			setup: func(f fields, a args) {
+				f.repo.EXPECT().GetById(a.ctx, a.id).Return(nil, nil)
+			},
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		{
+			name: "fail: can't publish",
+			args: args{
+				ctx: context.Background(),
+				id:  1,
+			},
+			setup: func(f fields, a args) {
+				f.repo.EXPECT().GetById(a.ctx, a.id).Return(&entity.RejectedMessage{ID: a.id, Subject: "orders", Data: []byte("data")}, nil)
+				f.natsService.EXPECT().PublishTo("orders", []byte("data")).Return(fmt.Errorf("publish error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			f := fields{
+				repo:        repository.NewMockRejectedMessageRepository(ctrl),
+				natsService: nats.NewMockNATSService(ctrl),
+			}
+			u := NewRejectedMessageInteractor(f.repo, f.natsService)
+
+			tt.setup(f, tt.args)
+
+			err := u.Redrive(tt.args.ctx, tt.args.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("rejectedMessageInteractor.Redrive() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, entity.ErrNotFound) != tt.wantNotFound {
+				t.Errorf("rejectedMessageInteractor.Redrive() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"L0/internal/entity"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// statusTransitions lists the statuses an order may move to from each status.
+// Cancelled and returned orders are final.
+var statusTransitions = map[entity.OrderStatus][]entity.OrderStatus{
+	entity.StatusCreated:    {entity.StatusPaid, entity.StatusCancelled},
+	entity.StatusPaid:       {entity.StatusAssembling, entity.StatusCancelled},
+	entity.StatusAssembling: {entity.StatusShipped, entity.StatusCancelled},
+	entity.StatusShipped:    {entity.StatusDelivered, entity.StatusReturned},
+	entity.StatusDelivered:  {entity.StatusReturned},
+	entity.StatusCancelled:  {},
+	entity.StatusReturned:   {},
+}
+
+// canTransition reports whether an order may move from one status to another.
+func canTransition(from, to entity.OrderStatus) bool {
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ChangeStatus moves an order to another status and refreshes its cache entry.
+// A request for the current status is a no-op, so a redelivered status event succeeds,
+// even if it is handled concurrently and another handler changes the status first.
+func (u *orderInteractor) ChangeStatus(ctx context.Context, update entity.StatusUpdate) (*entity.Order, error) {
+	if _, ok := statusTransitions[update.Status]; !ok {
+		return nil, fmt.Errorf("%w: unknown status %q", entity.ErrValidation, update.Status)
+	}
+	if update.Actor == "" {
+		return nil, fmt.Errorf("%w: actor is required", entity.ErrValidation)
+	}
+
+	order, err := u.repo.GetByUid(ctx, update.OrderUID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get order by uid from repository: %w", err)
+	}
+	if order == nil {
+		return nil, entity.ErrNotFound
+	}
+
+	if order.Status == update.Status {
+		return order, nil
+	}
+	if !canTransition(order.Status, update.Status) {
+		return nil, fmt.Errorf("%w: order can't move from %s to %s", entity.ErrConflict, order.Status, update.Status)
+	}
+
+	change := &entity.StatusChange{
+		OrderUID: update.OrderUID,
+		From:     order.Status,
+		To:       update.Status,
+		Actor:    update.Actor,
+		Reason:   update.Reason,
+	}
+	err = u.repo.ChangeStatus(ctx, change)
+	if errors.Is(err, entity.ErrConflict) {
+		// The order has left the status it was read in, a concurrent change may have applied the same update
+		current, getErr := u.repo.GetByUid(ctx, update.OrderUID)
+		if getErr == nil && current != nil && current.Status == update.Status {
+			return current, nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't change order status: %w", err)
+	}
+
+	// Reload the order to pick up the version assigned by the change.
+	order, err = u.repo.GetByUid(ctx, update.OrderUID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get order by uid from repository: %w", err)
+	}
+	if order == nil {
+		return nil, entity.ErrNotFound
+	}
+	u.cache.Set(order.OrderUID, order)
+	u.broadcaster.Set(ctx, order)
+
+	return order, nil
+}
+
+// StatusHistory retrieves the status transitions of an order, oldest first.
+func (u *orderInteractor) StatusHistory(ctx context.Context, uid string) ([]*entity.StatusChange, error) {
+	order, err := u.GetByUid(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, entity.ErrNotFound
+	}
+
+	history, err := u.repo.StatusHistory(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("can't get status history from repository: %w", err)
+	}
+
+	return history, nil
+}
@@ -0,0 +1,244 @@
+package usecase
+
+import (
+	"L0/internal/cache"
+	"L0/internal/entity"
+	"L0/internal/invalidation"
+	"L0/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from entity.OrderStatus
+		to   entity.OrderStatus
+		want bool
+	}{
+		{entity.StatusCreated, entity.StatusPaid, true},
+		{entity.StatusCreated, entity.StatusShipped, false},
+		{entity.StatusPaid, entity.StatusAssembling, true},
+		{entity.StatusAssembling, entity.StatusCancelled, true},
+		{entity.StatusShipped, entity.StatusCancelled, false},
+		{entity.StatusShipped, entity.StatusReturned, true},
+		{entity.StatusDelivered, entity.StatusReturned, true},
+		{entity.StatusCancelled, entity.StatusCreated, false},
+		{entity.StatusReturned, entity.StatusDelivered, false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s to %s", tt.from, tt.to), func(t *testing.T) {
+			if got := canTransition(tt.from, tt.to); got != tt.want {
+				t.Errorf("canTransition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderInteractor_ChangeStatus(t *testing.T) {
+	type fields struct {
+		orderRepository *repository.MockOrderRepository
+		cache           *cache.MockOrderCache
+		broadcaster     *invalidation.MockBroadcaster
+	}
+	const uid = "b563feb7b2b84b6test"
+	update := entity.StatusUpdate{OrderUID: uid, Status: entity.StatusPaid, Actor: "billing", Reason: "payment received"}
+	tests := []struct {
+		name         string
+		update       entity.StatusUpdate
+		setup        func(f fields)
+		want         entity.OrderStatus
+		wantErr      error
+		wantAnyError bool
+	}{
+		{
+			name:   "success",
+			update: update,
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), uid).Return(&entity.Order{OrderUID: uid, Status: entity.StatusCreated, Version: 1}, nil)
+				f.orderRepository.EXPECT().ChangeStatus(gomock.Any(), &entity.StatusChange{
+					OrderUID: uid,
+					From:     entity.StatusCreated,
+					To:       entity.StatusPaid,
+					Actor:    "billing",
+					Reason:   "payment received",
+				}).Return(nil)
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), uid).Return(&entity.Order{OrderUID: uid, Status: entity.StatusPaid, Version: 2}, nil)
+				f.cache.EXPECT().Set(uid, &entity.Order{OrderUID: uid, Status: entity.StatusPaid, Version: 2})
+				f.broadcaster.EXPECT().Set(gomock.Any(), &entity.Order{OrderUID: uid, Status: entity.StatusPaid, Version: 2})
+			},
+			want: entity.StatusPaid,
+		},
+		{
+			name:   "success: already in status",
+			update: update,
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), uid).Return(&entity.Order{OrderUID: uid, Status: entity.StatusPaid}, nil)
+			},
+			want: entity.StatusPaid,
+		},
+		{
+			name:   "success: changed concurrently",
+			update: update,
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), uid).Return(&entity.Order{OrderUID: uid, Status: entity.StatusCreated, Version: 1}, nil)
+				f.orderRepository.EXPECT().ChangeStatus(gomock.Any(), gomock.Any()).Return(fmt.Errorf("%w: order %s is not in status created", entity.ErrConflict, uid))
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), uid).Return(&entity.Order{OrderUID: uid, Status: entity.StatusPaid, Version: 2}, nil)
+			},
+			want: entity.StatusPaid,
+		},
+		{
+			name:   "fail: moved to another status concurrently",
+			update: update,
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), uid).Return(&entity.Order{OrderUID: uid, Status: entity.StatusCreated, Version: 1}, nil)
+				f.orderRepository.EXPECT().ChangeStatus(gomock.Any(), gomock.Any()).Return(fmt.Errorf("%w: order %s is not in status created", entity.ErrConflict, uid))
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), uid).Return(&entity.Order{OrderUID: uid, Status: entity.StatusCancelled, Version: 2}, nil)
+			},
+			wantErr: entity.ErrConflict,
+		},
+		{
+			name:    "fail: unknown status",
+			update:  entity.StatusUpdate{OrderUID: uid, Status: "lost", Actor: "billing"},
+			setup:   func(f fields) {},
+			wantErr: entity.ErrValidation,
+		},
+		{
+			name:    "fail: missing actor",
+			update:  entity.StatusUpdate{OrderUID: uid, Status: entity.StatusPaid},
+			setup:   func(f fields) {},
+			wantErr: entity.ErrValidation,
+		},
+		{
+			name:   "fail: not found",
+			update: update,
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), uid).Return(nil, nil)
+			},
+			wantErr: entity.ErrNotFound,
+		},
+		{
+			name:   "fail: transition not allowed",
+			update: update,
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), uid).Return(&entity.Order{OrderUID: uid, Status: entity.StatusCancelled}, nil)
+			},
+			wantErr: entity.ErrConflict,
+		},
+		{
+			name:   "fail: can't change status",
+			update: update,
+			setup: func(f fields) {
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), uid).Return(&entity.Order{OrderUID: uid, Status: entity.StatusCreated}, nil)
+				f.orderRepository.EXPECT().ChangeStatus(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+			},
+			wantAnyError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			f := fields{
+				orderRepository: repository.NewMockOrderRepository(ctrl),
+				cache:           cache.NewMockOrderCache(ctrl),
+				broadcaster:     invalidation.NewMockBroadcaster(ctrl),
+			}
+			u := &orderInteractor{
+				repo:        f.orderRepository,
+				cache:       f.cache,
+				broadcaster: f.broadcaster,
+			}
+
+			tt.setup(f)
+
+			got, err := u.ChangeStatus(context.Background(), tt.update)
+			if tt.wantErr != nil || tt.wantAnyError {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Errorf("orderInteractor.ChangeStatus() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("orderInteractor.ChangeStatus() unexpected error = %v", err)
+				return
+			}
+			if got.Status != tt.want {
+				t.Errorf("orderInteractor.ChangeStatus() status = %v, want %v", got.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderInteractor_StatusHistory(t *testing.T) {
+	type fields struct {
+		orderRepository *repository.MockOrderRepository
+		cache           *cache.MockOrderCache
+	}
+	const uid = "b563feb7b2b84b6test"
+	history := []*entity.StatusChange{{ID: 1, OrderUID: uid, From: entity.StatusCreated, To: entity.StatusPaid, Actor: "billing"}}
+	tests := []struct {
+		name         string
+		setup        func(f fields)
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{
+			name: "success",
+			setup: func(f fields) {
+				f.cache.EXPECT().Get(uid).Return(&entity.Order{OrderUID: uid}, true)
+				f.orderRepository.EXPECT().StatusHistory(gomock.Any(), uid).Return(history, nil)
+			},
+		},
+		{
+			name: "fail: not found",
+			setup: func(f fields) {
+				f.cache.EXPECT().Get(uid).Return(nil, false)
+				f.cache.EXPECT().Missing(uid).Return(false)
+				f.cache.EXPECT().Fill(uid, gomock.Any()).DoAndReturn(func(_ string, load func() (*entity.Order, error)) (*entity.Order, error) {
+					return load()
+				})
+				f.orderRepository.EXPECT().GetByUid(gomock.Any(), uid).Return(nil, nil)
+			},
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		{
+			name: "fail: can't get history",
+			setup: func(f fields) {
+				f.cache.EXPECT().Get(uid).Return(&entity.Order{OrderUID: uid}, true)
+				f.orderRepository.EXPECT().StatusHistory(gomock.Any(), uid).Return(nil, errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			f := fields{
+				orderRepository: repository.NewMockOrderRepository(ctrl),
+				cache:           cache.NewMockOrderCache(ctrl),
+			}
+			u := &orderInteractor{
+				repo:  f.orderRepository,
+				cache: f.cache,
+			}
+
+			tt.setup(f)
+
+			got, err := u.StatusHistory(context.Background(), uid)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("orderInteractor.StatusHistory() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if errors.Is(err, entity.ErrNotFound) != tt.wantNotFound {
+				t.Errorf("orderInteractor.StatusHistory() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if err == nil && len(got) != len(history) {
+				t.Errorf("orderInteractor.StatusHistory() = %v, want %v", got, history)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	entity "L0/internal/entity"
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,6 +36,21 @@ func (m *MockOrderInteractor) EXPECT() *MockOrderInteractorMockRecorder {
 	return m.recorder
 }
 
+// ChangeStatus mocks base method.
+func (m *MockOrderInteractor) ChangeStatus(ctx context.Context, update entity.StatusUpdate) (*entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeStatus", ctx, update)
+	ret0, _ := ret[0].(*entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeStatus indicates an expected call of ChangeStatus.
+func (mr *MockOrderInteractorMockRecorder) ChangeStatus(ctx, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeStatus", reflect.TypeOf((*MockOrderInteractor)(nil).ChangeStatus), ctx, update)
+}
+
 // Create mocks base method.
 func (m *MockOrderInteractor) Create(ctx context.Context, order *entity.Order) error {
 	m.ctrl.T.Helper()
@@ -50,17 +66,32 @@ func (mr *MockOrderInteractorMockRecorder) Create(ctx, order interface{}) *gomoc
 }
 
 // Delete mocks base method.
-func (m *MockOrderInteractor) Delete(ctx context.Context, uid string) error {
+func (m *MockOrderInteractor) Delete(ctx context.Context, uid, deletedBy, reason string, version int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, uid)
+	ret := m.ctrl.Call(m, "Delete", ctx, uid, deletedBy, reason, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockOrderInteractorMockRecorder) Delete(ctx, uid interface{}) *gomock.Call {
+func (mr *MockOrderInteractorMockRecorder) Delete(ctx, uid, deletedBy, reason, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOrderInteractor)(nil).Delete), ctx, uid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOrderInteractor)(nil).Delete), ctx, uid, deletedBy, reason, version)
+}
+
+// Diff mocks base method.
+func (m *MockOrderInteractor) Diff(ctx context.Context, uid string, from, to int) (*entity.OrderDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Diff", ctx, uid, from, to)
+	ret0, _ := ret[0].(*entity.OrderDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Diff indicates an expected call of Diff.
+func (mr *MockOrderInteractorMockRecorder) Diff(ctx, uid, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Diff", reflect.TypeOf((*MockOrderInteractor)(nil).Diff), ctx, uid, from, to)
 }
 
 // GetAll mocks base method.
@@ -78,6 +109,21 @@ func (mr *MockOrderInteractorMockRecorder) GetAll(ctx interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockOrderInteractor)(nil).GetAll), ctx)
 }
 
+// GetByTrackNumber mocks base method.
+func (m *MockOrderInteractor) GetByTrackNumber(ctx context.Context, trackNumber string) (*entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByTrackNumber", ctx, trackNumber)
+	ret0, _ := ret[0].(*entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByTrackNumber indicates an expected call of GetByTrackNumber.
+func (mr *MockOrderInteractorMockRecorder) GetByTrackNumber(ctx, trackNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByTrackNumber", reflect.TypeOf((*MockOrderInteractor)(nil).GetByTrackNumber), ctx, trackNumber)
+}
+
 // GetByUid mocks base method.
 func (m *MockOrderInteractor) GetByUid(ctx context.Context, uid string) (*entity.Order, error) {
 	m.ctrl.T.Helper()
@@ -92,3 +138,397 @@ func (mr *MockOrderInteractorMockRecorder) GetByUid(ctx, uid interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUid", reflect.TypeOf((*MockOrderInteractor)(nil).GetByUid), ctx, uid)
 }
+
+// History mocks base method.
+func (m *MockOrderInteractor) History(ctx context.Context, uid string) ([]*entity.OrderVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, uid)
+	ret0, _ := ret[0].([]*entity.OrderVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockOrderInteractorMockRecorder) History(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockOrderInteractor)(nil).History), ctx, uid)
+}
+
+// List mocks base method.
+func (m *MockOrderInteractor) List(ctx context.Context, query entity.OrderListQuery) (*entity.OrderPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, query)
+	ret0, _ := ret[0].(*entity.OrderPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockOrderInteractorMockRecorder) List(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockOrderInteractor)(nil).List), ctx, query)
+}
+
+// ListByCustomer mocks base method.
+func (m *MockOrderInteractor) ListByCustomer(ctx context.Context, customerID string) ([]*entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByCustomer", ctx, customerID)
+	ret0, _ := ret[0].([]*entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByCustomer indicates an expected call of ListByCustomer.
+func (mr *MockOrderInteractorMockRecorder) ListByCustomer(ctx, customerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByCustomer", reflect.TypeOf((*MockOrderInteractor)(nil).ListByCustomer), ctx, customerID)
+}
+
+// ListByEmail mocks base method.
+func (m *MockOrderInteractor) ListByEmail(ctx context.Context, email string) ([]*entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByEmail", ctx, email)
+	ret0, _ := ret[0].([]*entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByEmail indicates an expected call of ListByEmail.
+func (mr *MockOrderInteractorMockRecorder) ListByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByEmail", reflect.TypeOf((*MockOrderInteractor)(nil).ListByEmail), ctx, email)
+}
+
+// ListByPhone mocks base method.
+func (m *MockOrderInteractor) ListByPhone(ctx context.Context, phone string) ([]*entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByPhone", ctx, phone)
+	ret0, _ := ret[0].([]*entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByPhone indicates an expected call of ListByPhone.
+func (mr *MockOrderInteractorMockRecorder) ListByPhone(ctx, phone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByPhone", reflect.TypeOf((*MockOrderInteractor)(nil).ListByPhone), ctx, phone)
+}
+
+// ListDeleted mocks base method.
+func (m *MockOrderInteractor) ListDeleted(ctx context.Context, query entity.OrderListQuery) (*entity.OrderPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeleted", ctx, query)
+	ret0, _ := ret[0].(*entity.OrderPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeleted indicates an expected call of ListDeleted.
+func (mr *MockOrderInteractorMockRecorder) ListDeleted(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeleted", reflect.TypeOf((*MockOrderInteractor)(nil).ListDeleted), ctx, query)
+}
+
+// Patch mocks base method.
+func (m *MockOrderInteractor) Patch(ctx context.Context, uid string, patch []byte, version int) (*entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", ctx, uid, patch, version)
+	ret0, _ := ret[0].(*entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Patch indicates an expected call of Patch.
+func (mr *MockOrderInteractorMockRecorder) Patch(ctx, uid, patch, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockOrderInteractor)(nil).Patch), ctx, uid, patch, version)
+}
+
+// Purge mocks base method.
+func (m *MockOrderInteractor) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, olderThan)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockOrderInteractorMockRecorder) Purge(ctx, olderThan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockOrderInteractor)(nil).Purge), ctx, olderThan)
+}
+
+// Restore mocks base method.
+func (m *MockOrderInteractor) Restore(ctx context.Context, uid string) (*entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, uid)
+	ret0, _ := ret[0].(*entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockOrderInteractorMockRecorder) Restore(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockOrderInteractor)(nil).Restore), ctx, uid)
+}
+
+// Save mocks base method.
+func (m *MockOrderInteractor) Save(ctx context.Context, order *entity.Order, policy entity.UpdatePolicy) (entity.SaveOutcome, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, order, policy)
+	ret0, _ := ret[0].(entity.SaveOutcome)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockOrderInteractorMockRecorder) Save(ctx, order, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockOrderInteractor)(nil).Save), ctx, order, policy)
+}
+
+// Search mocks base method.
+func (m *MockOrderInteractor) Search(ctx context.Context, query entity.OrderSearchQuery) (*entity.OrderPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, query)
+	ret0, _ := ret[0].(*entity.OrderPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockOrderInteractorMockRecorder) Search(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockOrderInteractor)(nil).Search), ctx, query)
+}
+
+// StatusHistory mocks base method.
+func (m *MockOrderInteractor) StatusHistory(ctx context.Context, uid string) ([]*entity.StatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatusHistory", ctx, uid)
+	ret0, _ := ret[0].([]*entity.StatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StatusHistory indicates an expected call of StatusHistory.
+func (mr *MockOrderInteractorMockRecorder) StatusHistory(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatusHistory", reflect.TypeOf((*MockOrderInteractor)(nil).StatusHistory), ctx, uid)
+}
+
+// Update mocks base method.
+func (m *MockOrderInteractor) Update(ctx context.Context, uid string, order *entity.Order, version int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, uid, order, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockOrderInteractorMockRecorder) Update(ctx, uid, order, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockOrderInteractor)(nil).Update), ctx, uid, order, version)
+}
+
+// Version mocks base method.
+func (m *MockOrderInteractor) Version(ctx context.Context, uid string, version int) (*entity.OrderVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Version", ctx, uid, version)
+	ret0, _ := ret[0].(*entity.OrderVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Version indicates an expected call of Version.
+func (mr *MockOrderInteractorMockRecorder) Version(ctx, uid, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*MockOrderInteractor)(nil).Version), ctx, uid, version)
+}
+
+// MockRejectedMessageInteractor is a mock of RejectedMessageInteractor interface.
+type MockRejectedMessageInteractor struct {
+	ctrl     *gomock.Controller
+	recorder *MockRejectedMessageInteractorMockRecorder
+}
+
+// MockRejectedMessageInteractorMockRecorder is the mock recorder for MockRejectedMessageInteractor.
+type MockRejectedMessageInteractorMockRecorder struct {
+	mock *MockRejectedMessageInteractor
+}
+
+// NewMockRejectedMessageInteractor creates a new mock instance.
+func NewMockRejectedMessageInteractor(ctrl *gomock.Controller) *MockRejectedMessageInteractor {
+	mock := &MockRejectedMessageInteractor{ctrl: ctrl}
+	mock.recorder = &MockRejectedMessageInteractorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRejectedMessageInteractor) EXPECT() *MockRejectedMessageInteractorMockRecorder {
+	return m.recorder
+}
+
+// Discard mocks base method.
+func (m *MockRejectedMessageInteractor) Discard(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Discard", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Discard indicates an expected call of Discard.
+func (mr *MockRejectedMessageInteractorMockRecorder) Discard(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Discard", reflect.TypeOf((*MockRejectedMessageInteractor)(nil).Discard), ctx, id)
+}
+
+// GetAll mocks base method.
+func (m *MockRejectedMessageInteractor) GetAll(ctx context.Context) ([]*entity.RejectedMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret0, _ := ret[0].([]*entity.RejectedMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockRejectedMessageInteractorMockRecorder) GetAll(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRejectedMessageInteractor)(nil).GetAll), ctx)
+}
+
+// GetById mocks base method.
+func (m *MockRejectedMessageInteractor) GetById(ctx context.Context, id int64) (*entity.RejectedMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, id)
+	ret0, _ := ret[0].(*entity.RejectedMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockRejectedMessageInteractorMockRecorder) GetById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockRejectedMessageInteractor)(nil).GetById), ctx, id)
+}
+
+// Redrive mocks base method.
+func (m *MockRejectedMessageInteractor) Redrive(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redrive", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Redrive indicates an expected call of Redrive.
+func (mr *MockRejectedMessageInteractorMockRecorder) Redrive(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redrive", reflect.TypeOf((*MockRejectedMessageInteractor)(nil).Redrive), ctx, id)
+}
+
+// MockIngestionInteractor is a mock of IngestionInteractor interface.
+type MockIngestionInteractor struct {
+	ctrl     *gomock.Controller
+	recorder *MockIngestionInteractorMockRecorder
+}
+
+// MockIngestionInteractorMockRecorder is the mock recorder for MockIngestionInteractor.
+type MockIngestionInteractorMockRecorder struct {
+	mock *MockIngestionInteractor
+}
+
+// NewMockIngestionInteractor creates a new mock instance.
+func NewMockIngestionInteractor(ctrl *gomock.Controller) *MockIngestionInteractor {
+	mock := &MockIngestionInteractor{ctrl: ctrl}
+	mock.recorder = &MockIngestionInteractorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIngestionInteractor) EXPECT() *MockIngestionInteractorMockRecorder {
+	return m.recorder
+}
+
+// Status mocks base method.
+func (m *MockIngestionInteractor) Status(ctx context.Context, uid string, wait time.Duration) (*entity.Ingestion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status", ctx, uid, wait)
+	ret0, _ := ret[0].(*entity.Ingestion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockIngestionInteractorMockRecorder) Status(ctx, uid, wait interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockIngestionInteractor)(nil).Status), ctx, uid, wait)
+}
+
+// Submit mocks base method.
+func (m *MockIngestionInteractor) Submit(ctx context.Context, order *entity.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Submit", ctx, order)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Submit indicates an expected call of Submit.
+func (mr *MockIngestionInteractorMockRecorder) Submit(ctx, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Submit", reflect.TypeOf((*MockIngestionInteractor)(nil).Submit), ctx, order)
+}
+
+// MockCacheInteractor is a mock of CacheInteractor interface.
+type MockCacheInteractor struct {
+	ctrl     *gomock.Controller
+	recorder *MockCacheInteractorMockRecorder
+}
+
+// MockCacheInteractorMockRecorder is the mock recorder for MockCacheInteractor.
+type MockCacheInteractorMockRecorder struct {
+	mock *MockCacheInteractor
+}
+
+// NewMockCacheInteractor creates a new mock instance.
+func NewMockCacheInteractor(ctrl *gomock.Controller) *MockCacheInteractor {
+	mock := &MockCacheInteractor{ctrl: ctrl}
+	mock.recorder = &MockCacheInteractorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCacheInteractor) EXPECT() *MockCacheInteractorMockRecorder {
+	return m.recorder
+}
+
+// LastReconciliation mocks base method.
+func (m *MockCacheInteractor) LastReconciliation() *entity.Reconciliation {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastReconciliation")
+	ret0, _ := ret[0].(*entity.Reconciliation)
+	return ret0
+}
+
+// LastReconciliation indicates an expected call of LastReconciliation.
+func (mr *MockCacheInteractorMockRecorder) LastReconciliation() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastReconciliation", reflect.TypeOf((*MockCacheInteractor)(nil).LastReconciliation))
+}
+
+// Reconcile mocks base method.
+func (m *MockCacheInteractor) Reconcile(ctx context.Context) (*entity.Reconciliation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconcile", ctx)
+	ret0, _ := ret[0].(*entity.Reconciliation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reconcile indicates an expected call of Reconcile.
+func (mr *MockCacheInteractorMockRecorder) Reconcile(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockCacheInteractor)(nil).Reconcile), ctx)
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"L0/internal/entity"
+	"L0/internal/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// History retrieves the versions of an order, oldest first.
+func (u *orderInteractor) History(ctx context.Context, uid string) ([]*entity.OrderVersion, error) {
+	versions, err := u.repo.Versions(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("can't get order versions from repository: %w", err)
+	}
+	// Every stored order has at least the version of its creation
+	if len(versions) == 0 {
+		return nil, entity.ErrNotFound
+	}
+
+	return versions, nil
+}
+
+// Version retrieves a version of an order with its snapshot.
+func (u *orderInteractor) Version(ctx context.Context, uid string, version int) (*entity.OrderVersion, error) {
+	if version < 1 {
+		return nil, fmt.Errorf("%w: version must be positive", entity.ErrValidation)
+	}
+
+	v, err := u.repo.Version(ctx, uid, version)
+	if err != nil {
+		return nil, fmt.Errorf("can't get order version from repository: %w", err)
+	}
+	if v == nil {
+		return nil, entity.ErrNotFound
+	}
+
+	return v, nil
+}
+
+// Diff lists the differences between two versions of an order.
+func (u *orderInteractor) Diff(ctx context.Context, uid string, from int, to int) (*entity.OrderDiff, error) {
+	fromVersion, err := u.Version(ctx, uid, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := u.Version(ctx, uid, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Versions differ by definition, leave them out of the changes.
+	fromVersion.Order.Version, toVersion.Order.Version = 0, 0
+
+	fromData, err := json.Marshal(fromVersion.Order)
+	if err != nil {
+		return nil, fmt.Errorf("can't encode order version: %w", err)
+	}
+	toData, err := json.Marshal(toVersion.Order)
+	if err != nil {
+		return nil, fmt.Errorf("can't encode order version: %w", err)
+	}
+
+	changes, err := utils.DiffJSON(fromData, toData)
+	if err != nil {
+		return nil, fmt.Errorf("can't compare order versions: %w", err)
+	}
+
+	return &entity.OrderDiff{
+		OrderUID: uid,
+		From:     from,
+		To:       to,
+		Changes:  changes,
+	}, nil
+}
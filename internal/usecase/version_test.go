@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"L0/internal/entity"
+	"L0/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+func TestOrderInteractor_History(t *testing.T) {
+	const uid = "b563feb7b2b84b6test"
+	versions := []*entity.OrderVersion{{OrderUID: uid, Version: 1, Operation: entity.VersionCreated}}
+	tests := []struct {
+		name         string
+		setup        func(repo *repository.MockOrderRepository)
+		want         []*entity.OrderVersion
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{
+			name: "success",
+			setup: func(repo *repository.MockOrderRepository) {
+				repo.EXPECT().Versions(gomock.Any(), uid).Return(versions, nil)
+			},
+			want: versions,
+		},
+		{
+			name: "fail: no versions",
+			setup: func(repo *repository.MockOrderRepository) {
+				repo.EXPECT().Versions(gomock.Any(), uid).Return([]*entity.OrderVersion{}, nil)
+			},
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		{
+			name: "fail: can't get versions",
+			setup: func(repo *repository.MockOrderRepository) {
+				repo.EXPECT().Versions(gomock.Any(), uid).Return(nil, fmt.Errorf("some error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repo := repository.NewMockOrderRepository(ctrl)
+			u := &orderInteractor{repo: repo}
+
+			tt.setup(repo)
+
+			got, err := u.History(context.Background(), uid)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("orderInteractor.History() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if errors.Is(err, entity.ErrNotFound) != tt.wantNotFound {
+				t.Errorf("orderInteractor.History() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderInteractor.History() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderInteractor_Diff(t *testing.T) {
+	const uid = "b563feb7b2b84b6test"
+	version := func(n int, amount int) *entity.OrderVersion {
+		return &entity.OrderVersion{
+			OrderUID: uid,
+			Version:  n,
+			Order:    &entity.Order{OrderUID: uid, Version: n, Payment: entity.Payment{Amount: amount}},
+		}
+	}
+	tests := []struct {
+		name           string
+		from, to       int
+		setup          func(repo *repository.MockOrderRepository)
+		want           []entity.FieldChange
+		wantErr        bool
+		wantNotFound   bool
+		wantValidation bool
+	}{
+		{
+			name: "success",
+			from: 1,
+			to:   2,
+			setup: func(repo *repository.MockOrderRepository) {
+				repo.EXPECT().Version(gomock.Any(), uid, 1).Return(version(1, 100), nil)
+				repo.EXPECT().Version(gomock.Any(), uid, 2).Return(version(2, 150), nil)
+			},
+			want: []entity.FieldChange{{Path: "payment.amount", From: json.Number("100"), To: json.Number("150")}},
+		},
+		{
+			name: "success: same content",
+			from: 1,
+			to:   2,
+			setup: func(repo *repository.MockOrderRepository) {
+				repo.EXPECT().Version(gomock.Any(), uid, 1).Return(version(1, 100), nil)
+				repo.EXPECT().Version(gomock.Any(), uid, 2).Return(version(2, 100), nil)
+			},
+			want: []entity.FieldChange{},
+		},
+		{
+			name: "fail: version not found",
+			from: 1,
+			to:   3,
+			setup: func(repo *repository.MockOrderRepository) {
+				repo.EXPECT().Version(gomock.Any(), uid, 1).Return(version(1, 100), nil)
+				repo.EXPECT().Version(gomock.Any(), uid, 3).Return(nil, nil)
+			},
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		{
+			name:           "fail: invalid version",
+			from:           0,
+			to:             1,
+			setup:          func(repo *repository.MockOrderRepository) {},
+			wantErr:        true,
+			wantValidation: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repo := repository.NewMockOrderRepository(ctrl)
+			u := &orderInteractor{repo: repo}
+
+			tt.setup(repo)
+
+			got, err := u.Diff(context.Background(), uid, tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("orderInteractor.Diff() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if errors.Is(err, entity.ErrNotFound) != tt.wantNotFound {
+				t.Errorf("orderInteractor.Diff() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if errors.Is(err, entity.ErrValidation) != tt.wantValidation {
+				t.Errorf("orderInteractor.Diff() error = %v, wantValidation %v", err, tt.wantValidation)
+			}
+			if err == nil && !reflect.DeepEqual(got.Changes, tt.want) {
+				t.Errorf("orderInteractor.Diff() changes = %v, want %v", got.Changes, tt.want)
+			}
+		})
+	}
+}
@@ -63,13 +63,14 @@ type Item struct {
 
 func GenerateOrder() Order {
 	trackNumber := generateRandomString(10)
+	items := generateRandomItems(trackNumber)
 	return Order{
 		OrderUID:          generateRandomString(10),
 		TrackNumber:       trackNumber,
 		Entry:             generateRandomString(4),
 		Delivery:          generateRandomDelivery(),
-		Payment:           generateRandomPayment(),
-		Items:             generateRandomItems(trackNumber),
+		Payment:           generateRandomPayment(items),
+		Items:             items,
 		Locale:            "en",
 		InternalSignature: generateRandomString(10),
 		CustomerID:        generateRandomString(5),
@@ -117,19 +118,25 @@ func generateRandomDelivery() Delivery {
 	}
 }
 
-// generateRandomPayment generates random payment data.
-func generateRandomPayment() Payment {
+// generateRandomPayment generates random payment data consistent with the order items.
+func generateRandomPayment(items []Item) Payment {
+	goodsTotal := 0
+	for _, item := range items {
+		goodsTotal += item.TotalPrice
+	}
+	deliveryCost := generateRandomNumber(1000)
+	customFee := generateRandomNumber(100)
 	return Payment{
 		Transaction:  generateRandomString(10),
 		RequestID:    generateRandomString(10),
 		Currency:     "USD",
 		Provider:     generateRandomString(6),
-		Amount:       generateRandomNumber(1000),
+		Amount:       goodsTotal + deliveryCost + customFee,
 		PaymentDT:    int(time.Now().Unix()),
 		Bank:         generateRandomString(6),
-		DeliveryCost: generateRandomNumber(1000),
-		GoodsTotal:   generateRandomNumber(1000),
-		CustomFee:    generateRandomNumber(100),
+		DeliveryCost: deliveryCost,
+		GoodsTotal:   goodsTotal,
+		CustomFee:    customFee,
 	}
 }
 
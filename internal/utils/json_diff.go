@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"L0/internal/entity"
+)
+
+// DiffJSON lists the differences between two JSON documents.
+// Objects are compared member by member and arrays element by element,
+// so each change points at the deepest differing value, e.g. items[1].price.
+func DiffJSON(from, to []byte) ([]entity.FieldChange, error) {
+	a, err := decodeJSON(from)
+	if err != nil {
+		return nil, fmt.Errorf("can't decode document: %w", err)
+	}
+
+	b, err := decodeJSON(to)
+	if err != nil {
+		return nil, fmt.Errorf("can't decode document: %w", err)
+	}
+
+	changes := []entity.FieldChange{}
+	diffValue("", a, b, &changes)
+
+	return changes, nil
+}
+
+// diffValue appends the differences between two decoded values at the path to changes.
+func diffValue(path string, a, b interface{}, changes *[]entity.FieldChange) {
+	aObject, aIsObject := a.(map[string]interface{})
+	bObject, bIsObject := b.(map[string]interface{})
+	if aIsObject && bIsObject {
+		// Visit members in a stable order
+		names := make([]string, 0, len(aObject)+len(bObject))
+		for name := range aObject {
+			names = append(names, name)
+		}
+		for name := range bObject {
+			if _, ok := aObject[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			memberPath := name
+			if path != "" {
+				memberPath = path + "." + name
+			}
+			diffValue(memberPath, aObject[name], bObject[name], changes)
+		}
+		return
+	}
+
+	aArray, aIsArray := a.([]interface{})
+	bArray, bIsArray := b.([]interface{})
+	if aIsArray && bIsArray {
+		for i := 0; i < len(aArray) || i < len(bArray); i++ {
+			var av, bv interface{}
+			if i < len(aArray) {
+				av = aArray[i]
+			}
+			if i < len(bArray) {
+				bv = bArray[i]
+			}
+			diffValue(path+"["+strconv.Itoa(i)+"]", av, bv, changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, entity.FieldChange{Path: path, From: a, To: b})
+	}
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"L0/internal/entity"
+)
+
+func TestDiffJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		want    []entity.FieldChange
+		wantErr bool
+	}{
+		{
+			name: "equal documents",
+			from: `{"a":1,"b":[1,2]}`,
+			to:   `{"b":[1,2],"a":1}`,
+			want: []entity.FieldChange{},
+		},
+		{
+			name: "nested member replaced",
+			from: `{"payment":{"amount":100,"bank":"alpha"}}`,
+			to:   `{"payment":{"amount":150,"bank":"alpha"}}`,
+			want: []entity.FieldChange{{Path: "payment.amount", From: json.Number("100"), To: json.Number("150")}},
+		},
+		{
+			name: "members added and removed",
+			from: `{"a":"x"}`,
+			to:   `{"b":"y"}`,
+			want: []entity.FieldChange{{Path: "a", From: "x"}, {Path: "b", To: "y"}},
+		},
+		{
+			name: "array elements",
+			from: `{"items":[{"rid":"1","price":10}]}`,
+			to:   `{"items":[{"rid":"1","price":12},{"rid":"2"}]}`,
+			want: []entity.FieldChange{
+				{Path: "items[0].price", From: json.Number("10"), To: json.Number("12")},
+				{Path: "items[1]", To: map[string]interface{}{"rid": "2"}},
+			},
+		},
+		{
+			name: "type changed",
+			from: `{"deleted":null}`,
+			to:   `{"deleted":{"reason":"dup"}}`,
+			want: []entity.FieldChange{{Path: "deleted", To: map[string]interface{}{"reason": "dup"}}},
+		},
+		{
+			name:    "invalid document",
+			from:    `{`,
+			to:      `{}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DiffJSON([]byte(tt.from), []byte(tt.to))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DiffJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
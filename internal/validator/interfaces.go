@@ -0,0 +1,14 @@
+// Package validator provides interfaces for validating orders before persistence.
+package validator
+
+import "L0/internal/entity"
+
+//go:generate mockgen -source=./interfaces.go -destination=validator_mock.go -package=validator
+
+// OrderValidator defines the interface for order validators.
+type OrderValidator interface {
+	// Validate checks an order against the validation rules.
+	// It takes an order entity as an input parameter.
+	// Returns a *ValidationError listing every violated rule or nil if the order is valid.
+	Validate(order *entity.Order) error
+}
@@ -0,0 +1,128 @@
+// Package validator provides functionality for validating orders before persistence.
+package validator
+
+import (
+	"L0/internal/entity"
+	"fmt"
+	"strings"
+)
+
+// Mode defines how strictly orders are validated.
+type Mode string
+
+const (
+	// ModeStrict applies structural rules and cross-field consistency rules.
+	ModeStrict Mode = "strict"
+	// ModeLenient applies only structural rules: required fields and non-negative amounts.
+	ModeLenient Mode = "lenient"
+)
+
+// Validation rule names reported in field errors.
+const (
+	RuleRequired = "required"
+	RuleMin      = "min"
+	RuleMatch    = "match"
+	RuleSum      = "sum"
+)
+
+// FieldError describes a single violated rule.
+type FieldError struct {
+	Path    string `json:"path"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError contains all rules violated by an order.
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+// Error returns a summary of the violated rules.
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", f.Path, f.Message))
+	}
+	return fmt.Sprintf("%s: %s", entity.ErrValidation, strings.Join(msgs, "; "))
+}
+
+// Unwrap allows matching the error with errors.Is(err, entity.ErrValidation).
+func (e *ValidationError) Unwrap() error {
+	return entity.ErrValidation
+}
+
+// orderValidator implements the OrderValidator interface.
+type orderValidator struct {
+	mode Mode
+}
+
+// NewOrderValidator creates a new instance of orderValidator.
+// An unknown mode falls back to ModeStrict.
+func NewOrderValidator(mode Mode) *orderValidator {
+	if mode != ModeLenient {
+		mode = ModeStrict
+	}
+	return &orderValidator{
+		mode: mode,
+	}
+}
+
+// Validate checks an order against the validation rules.
+func (v *orderValidator) Validate(order *entity.Order) error {
+	var errs []FieldError
+	add := func(path, rule, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Path: path, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+	required := func(path, value string) {
+		if strings.TrimSpace(value) == "" {
+			add(path, RuleRequired, "must not be empty")
+		}
+	}
+	nonNegative := func(path string, value int) {
+		if value < 0 {
+			add(path, RuleMin, "must not be negative, got %d", value)
+		}
+	}
+
+	// Structural rules
+	required("order_uid", order.OrderUID)
+	required("track_number", order.TrackNumber)
+	required("payment.transaction", order.Payment.Transaction)
+	nonNegative("payment.amount", order.Payment.Amount)
+	nonNegative("payment.delivery_cost", order.Payment.DeliveryCost)
+	nonNegative("payment.goods_total", order.Payment.GoodsTotal)
+	nonNegative("payment.custom_fee", order.Payment.CustomFee)
+	for i, item := range order.Items {
+		path := fmt.Sprintf("items[%d]", i)
+		required(path+".rid", item.Rid)
+		nonNegative(path+".price", item.Price)
+		nonNegative(path+".sale", item.Sale)
+		nonNegative(path+".total_price", item.TotalPrice)
+	}
+
+	// Consistency rules
+	if v.mode == ModeStrict {
+		if len(order.Items) == 0 {
+			add("items", RuleRequired, "must contain at least one item")
+		}
+		if order.DateCreated.IsZero() {
+			add("date_created", RuleRequired, "must not be empty")
+		}
+		for i, item := range order.Items {
+			if item.TrackNumber != order.TrackNumber {
+				add(fmt.Sprintf("items[%d].track_number", i), RuleMatch,
+					"must match order track_number %q, got %q", order.TrackNumber, item.TrackNumber)
+			}
+		}
+		p := order.Payment
+		if sum := p.GoodsTotal + p.DeliveryCost + p.CustomFee; p.Amount != sum {
+			add("payment.amount", RuleSum,
+				"must equal goods_total + delivery_cost + custom_fee = %d, got %d", sum, p.Amount)
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Fields: errs}
+	}
+	return nil
+}
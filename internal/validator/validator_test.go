@@ -0,0 +1,124 @@
+package validator
+
+import (
+	"L0/internal/entity"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func validOrder() *entity.Order {
+	return &entity.Order{
+		OrderUID:    "b563feb7b2b84b6test",
+		TrackNumber: "WBILMTESTTRACK",
+		Payment: entity.Payment{
+			Transaction:  "b563feb7b2b84b6test",
+			Amount:       1817,
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+			CustomFee:    0,
+		},
+		Items: []entity.Item{
+			{
+				TrackNumber: "WBILMTESTTRACK",
+				Price:       453,
+				Rid:         "ab4219087a764ae0btest",
+				Sale:        30,
+				TotalPrice:  317,
+			},
+		},
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+	}
+}
+
+func TestOrderValidator_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      Mode
+		modify    func(o *entity.Order)
+		wantPaths []string
+		wantRules []string
+	}{
+		{
+			name:   "valid order",
+			mode:   ModeStrict,
+			modify: func(o *entity.Order) {},
+		},
+		{
+			name: "empty order uid",
+			mode: ModeLenient,
+			modify: func(o *entity.Order) {
+				o.OrderUID = " "
+			},
+			wantPaths: []string{"order_uid"},
+			wantRules: []string{RuleRequired},
+		},
+		{
+			name: "negative price",
+			mode: ModeLenient,
+			modify: func(o *entity.Order) {
+				o.Items[0].Price = -1
+			},
+			wantPaths: []string{"items[0].price"},
+			wantRules: []string{RuleMin},
+		},
+		{
+			name: "strict: item track number mismatch",
+			mode: ModeStrict,
+			modify: func(o *entity.Order) {
+				o.Items[0].TrackNumber = "OTHER"
+			},
+			wantPaths: []string{"items[0].track_number"},
+			wantRules: []string{RuleMatch},
+		},
+		{
+			name: "strict: payment amount mismatch",
+			mode: ModeStrict,
+			modify: func(o *entity.Order) {
+				o.Payment.Amount = 1
+			},
+			wantPaths: []string{"payment.amount"},
+			wantRules: []string{RuleSum},
+		},
+		{
+			name: "lenient: consistency rules are skipped",
+			mode: ModeLenient,
+			modify: func(o *entity.Order) {
+				o.Items[0].TrackNumber = "OTHER"
+				o.Payment.Amount = 1
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := validOrder()
+			tt.modify(order)
+
+			err := NewOrderValidator(tt.mode).Validate(order)
+			if len(tt.wantPaths) == 0 {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, entity.ErrValidation) {
+				t.Fatalf("Validate() error = %v, want entity.ErrValidation", err)
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate() error = %T, want *ValidationError", err)
+			}
+
+			var paths, rules []string
+			for _, f := range verr.Fields {
+				paths = append(paths, f.Path)
+				rules = append(rules, f.Rule)
+			}
+			if !reflect.DeepEqual(paths, tt.wantPaths) || !reflect.DeepEqual(rules, tt.wantRules) {
+				t.Errorf("Validate() fields = %v %v, want %v %v", paths, rules, tt.wantPaths, tt.wantRules)
+			}
+		})
+	}
+}